@@ -15,15 +15,28 @@ type Alumni struct {
 	TahunLulus int                `bson:"tahun_lulus" json:"tahun_lulus"`
 	Foto           string             `bson:"foto,omitempty" json:"foto,omitempty"`
 	SertifikatPath string             `bson:"sertifikat_path,omitempty" json:"sertifikat_path,omitempty"`
+	SertifikatStatus string           `bson:"sertifikat_status,omitempty" json:"sertifikat_status,omitempty"`
 	Email      string             `bson:"email" json:"email"`
 	NoTelepon  string             `bson:"no_telepon" json:"no_telepon"`
 	Alamat     string             `bson:"alamat" json:"alamat"`
+	// TanggalLahir dipakai sebagai faktor verifikasi klaim, tidak ikut dikirim di response
+	TanggalLahir *time.Time       `bson:"tanggal_lahir,omitempty" json:"-"`
 	UserID     primitive.ObjectID `bson:"user_id" json:"user_id"`
+	Version    int                `bson:"version" json:"version"`
 	CreatedAt  time.Time          `bson:"created_at" json:"created_at"`
 	UpdatedAt  time.Time          `bson:"updated_at" json:"updated_at"`
 	DeletedAt  *time.Time         `bson:"deleted_at,omitempty" json:"deleted_at,omitempty"`
 }
 
+// AlumniFilter adalah filter listing alumni, juga dipakai untuk menentukan penerima notifikasi;
+// field kosong berarti tidak difilter
+type AlumniFilter struct {
+	Search     string `json:"search,omitempty"`
+	Jurusan    string `json:"jurusan,omitempty"`
+	Angkatan   int    `json:"angkatan,omitempty"`
+	TahunLulus int    `json:"tahun_lulus,omitempty"`
+}
+
 type CreateAlumniRequest struct {
 	NIM, Nama, Jurusan, Email, NoTelepon, Alamat string
 	Angkatan, TahunLulus                        int
@@ -31,6 +44,14 @@ type CreateAlumniRequest struct {
 }
 
 type UpdateAlumniRequest struct {
-	Nama, Jurusan, Email, NoTelepon, Alamat string
-	Angkatan, TahunLulus                   int
+	Nama       string `json:"nama"`
+	Jurusan    string `json:"jurusan"`
+	Email      string `json:"email"`
+	NoTelepon  string `json:"no_telepon"`
+	Alamat     string `json:"alamat"`
+	Angkatan   int    `json:"angkatan"`
+	TahunLulus int    `json:"tahun_lulus"`
+	// TanggalLahir format YYYY-MM-DD; kosong berarti tidak diubah
+	TanggalLahir string `json:"tanggal_lahir,omitempty"`
+	Version    int    `json:"version"`
 }
@@ -0,0 +1,54 @@
+package model
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// status klaim data alumni
+const (
+	ClaimPending  = "pending"
+	ClaimApproved = "approved"
+	ClaimRejected = "rejected"
+)
+
+// alasan klaim diteruskan ke admin
+const (
+	// ClaimReasonUnverified: data alumni tidak punya tanggal lahir/email untuk dicocokkan
+	ClaimReasonUnverified = "unverified"
+	// ClaimReasonAlreadyLinked: data alumni sudah terhubung ke akun lain
+	ClaimReasonAlreadyLinked = "already_linked"
+	// ClaimReasonEmailOnly: hanya email yang cocok, padahal email tampil di daftar alumni
+	ClaimReasonEmailOnly = "email_only"
+)
+
+// AlumniClaim mencatat permintaan user untuk menghubungkan akunnya ke data alumni berdasarkan NIM
+type AlumniClaim struct {
+	ID       primitive.ObjectID `bson:"_id,omitempty" json:"id"`
+	UserID   primitive.ObjectID `bson:"user_id" json:"user_id"`
+	AlumniID primitive.ObjectID `bson:"alumni_id" json:"alumni_id"`
+	NIM      string             `bson:"nim" json:"nim"`
+	Status   string             `bson:"status" json:"status"`
+	Reason   string             `bson:"reason,omitempty" json:"reason,omitempty"`
+	// Method adalah faktor yang cocok: "tanggal_lahir" atau "email"
+	Method     string              `bson:"method,omitempty" json:"method,omitempty"`
+	Notes      string              `bson:"notes,omitempty" json:"notes,omitempty"`
+	ReviewerID *primitive.ObjectID `bson:"reviewer_id,omitempty" json:"reviewer_id,omitempty"`
+	ReviewedAt *time.Time          `bson:"reviewed_at,omitempty" json:"reviewed_at,omitempty"`
+	CreatedAt  time.Time           `bson:"created_at" json:"created_at"`
+}
+
+// ClaimRequest berisi NIM dan minimal satu faktor verifikasi
+type ClaimRequest struct {
+	NIM string `json:"nim"`
+	// TanggalLahir format YYYY-MM-DD
+	TanggalLahir string `json:"tanggal_lahir"`
+	Email        string `json:"email"`
+}
+
+type ClaimReviewRequest struct {
+	// Decision: "approved" atau "rejected"
+	Decision string `json:"decision"`
+	Notes    string `json:"notes"`
+}
@@ -0,0 +1,23 @@
+package model
+
+import "time"
+
+// Blob adalah isi file unik di storage (content-addressed SHA-256). Beberapa record File
+// dengan isi identik memakai blob yang sama; blob dihapus saat RefCount mencapai 0.
+type Blob struct {
+	SHA256      string    `json:"sha256" bson:"sha256"`
+	StorageKey  string    `json:"storage_key" bson:"storage_key"`
+	Size        int64     `json:"size" bson:"size"`
+	ContentType string    `json:"content_type" bson:"content_type"`
+	RefCount    int       `json:"ref_count" bson:"ref_count"`
+	CreatedAt   time.Time `json:"created_at" bson:"created_at"`
+}
+
+// DedupReport adalah ringkasan penghematan storage dari deduplikasi
+type DedupReport struct {
+	Blobs        int64 `json:"blobs" bson:"blobs"`
+	References   int64 `json:"references" bson:"references"`
+	LogicalBytes int64 `json:"logical_bytes" bson:"logical_bytes"`
+	StoredBytes  int64 `json:"stored_bytes" bson:"stored_bytes"`
+	SavedBytes   int64 `json:"saved_bytes" bson:"saved_bytes"`
+}
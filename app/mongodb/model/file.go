@@ -7,10 +7,79 @@ import (
 
 type File struct {
 	ID           primitive.ObjectID `json:"id" bson:"_id,omitempty"`
+	AlumniID     primitive.ObjectID `json:"alumni_id" bson:"alumni_id,omitempty"`
 	FileName     string             `json:"file_name" bson:"file_name"`
 	OriginalName string             `json:"original_name" bson:"original_name"`
 	FilePath     string             `json:"file_path" bson:"file_path"`
+	Storage      string             `json:"storage" bson:"storage,omitempty"`
+	StorageKey   string             `json:"storage_key" bson:"storage_key,omitempty"`
+	SHA256       string             `json:"sha256,omitempty" bson:"sha256,omitempty"`
 	FileSize     int64              `json:"file_size" bson:"file_size"`
 	FileType     string             `json:"file_type" bson:"file_type"`
+	Variants     []FileVariant      `json:"variants,omitempty" bson:"variants,omitempty"`
+	// SourceKey: salinan asli kiriman klien untuk foto yang masih quarantine, dipakai saat pindai ulang
+	SourceKey    string             `json:"-" bson:"source_key,omitempty"`
+	Status       string             `json:"status" bson:"status,omitempty"`
+	ScanResult   string             `json:"scan_result,omitempty" bson:"scan_result,omitempty"`
+	ScannedAt    *time.Time         `json:"scanned_at,omitempty" bson:"scanned_at,omitempty"`
+	Review       *CertificateReview `json:"review,omitempty" bson:"review,omitempty"`
 	UploadedAt   time.Time          `json:"uploaded_at" bson:"uploaded_at"`
 }
+
+// Status file hasil pemindaian malware. File lama tanpa status dianggap available.
+const (
+	FileStatusQuarantine = "quarantine"
+	FileStatusAvailable  = "available"
+	FileStatusRejected   = "rejected"
+)
+
+// IsAvailable: file boleh diunduh / ditampilkan
+func (f *File) IsAvailable() bool {
+	return f.Status == "" || f.Status == FileStatusAvailable
+}
+
+// Status verifikasi sertifikat oleh staff
+const (
+	ReviewPending  = "pending"
+	ReviewApproved = "approved"
+	ReviewRejected = "rejected"
+)
+
+// CertificateReview adalah hasil verifikasi sertifikat; dibuat pending saat sertifikat diunggah
+type CertificateReview struct {
+	Status      string              `json:"status" bson:"status"`
+	Notes       string              `json:"notes,omitempty" bson:"notes,omitempty"`
+	ReviewerID  *primitive.ObjectID `json:"reviewer_id,omitempty" bson:"reviewer_id,omitempty"`
+	SubmittedAt time.Time           `json:"submitted_at" bson:"submitted_at"`
+	ReviewedAt  *time.Time          `json:"reviewed_at,omitempty" bson:"reviewed_at,omitempty"`
+}
+
+// ReviewRequest adalah keputusan staff atas sertifikat
+type ReviewRequest struct {
+	Decision string `json:"decision"` // approved | rejected
+	Notes    string `json:"notes"`    // wajib jika rejected
+}
+
+// FileVariant adalah turunan ukuran sebuah foto (thumb, medium, original)
+type FileVariant struct {
+	Size       string `json:"size" bson:"size"`
+	FileName   string `json:"file_name" bson:"file_name"`
+	FilePath   string `json:"file_path" bson:"file_path"`
+	StorageKey string `json:"storage_key" bson:"storage_key,omitempty"`
+	SHA256     string `json:"sha256,omitempty" bson:"sha256,omitempty"`
+	FileSize   int64  `json:"file_size" bson:"file_size"`
+	Width      int    `json:"width" bson:"width"`
+	Height     int    `json:"height" bson:"height"`
+}
+
+// FileFilter adalah filter listing file; field kosong berarti tidak difilter
+type FileFilter struct {
+	AlumniIDs []primitive.ObjectID
+	Type      string // "foto", "sertifikat", atau MIME type lengkap
+	Status    string
+	Review    string // status verifikasi sertifikat
+	From      *time.Time
+	To        *time.Time
+	MinSize   int64
+	MaxSize   int64
+}
@@ -0,0 +1,102 @@
+package model
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// status lowongan kerja
+const (
+	LowonganOpen   = "open"
+	LowonganClosed = "closed"
+	// LowonganExpired diisi worker setelah batas lamaran lewat
+	LowonganExpired = "expired"
+)
+
+// Lowongan adalah lowongan kerja yang dipasang admin (mis. dari perusahaan mitra) atau alumni terverifikasi.
+// BidangIndustri dan LokasiKerja memakai kosakata yang sama dengan PekerjaanAlumni.
+type Lowongan struct {
+	ID             primitive.ObjectID `bson:"_id,omitempty" json:"id"`
+	NamaPerusahaan string             `bson:"nama_perusahaan" json:"nama_perusahaan"`
+	PosisiJabatan  string             `bson:"posisi_jabatan" json:"posisi_jabatan"`
+	BidangIndustri string             `bson:"bidang_industri" json:"bidang_industri"`
+	LokasiKerja    string             `bson:"lokasi_kerja" json:"lokasi_kerja"`
+	// GajiMin dan GajiMax bernilai 0 jika tidak dicantumkan
+	GajiMin            int64  `bson:"gaji_min" json:"gaji_min"`
+	GajiMax            int64  `bson:"gaji_max" json:"gaji_max"`
+	DeskripsiPekerjaan string `bson:"deskripsi_pekerjaan" json:"deskripsi_pekerjaan"`
+	// KontakLamaran adalah email atau URL untuk melamar langsung ke perusahaan
+	KontakLamaran string `bson:"kontak_lamaran,omitempty" json:"kontak_lamaran,omitempty"`
+	// BatasLamaran adalah tanggal terakhir (UTC) lowongan masih menerima peminat
+	BatasLamaran time.Time          `bson:"batas_lamaran" json:"batas_lamaran"`
+	Status       string             `bson:"status" json:"status"`
+	PostedBy     primitive.ObjectID `bson:"posted_by" json:"posted_by"`
+	// InterestCount hanya diisi pada detail lowongan
+	InterestCount int64     `bson:"-" json:"interest_count,omitempty"`
+	CreatedAt     time.Time `bson:"created_at" json:"created_at"`
+	UpdatedAt     time.Time `bson:"updated_at" json:"updated_at"`
+}
+
+// Expired melaporkan apakah batas lamaran sudah lewat pada waktu now
+func (l Lowongan) Expired(now time.Time) bool {
+	return now.UTC().Truncate(24 * time.Hour).After(l.BatasLamaran)
+}
+
+// LowonganFilter adalah filter pencarian lowongan; field kosong berarti tidak difilter
+type LowonganFilter struct {
+	Search         string
+	BidangIndustri string
+	LokasiKerja    string
+	// GajiMin mencari lowongan dengan gaji maksimum minimal sebesar ini
+	GajiMin int64
+	// Status: open (default), closed, expired, atau all. Lowongan open yang batas lamarannya lewat dianggap expired.
+	Status   string
+	PostedBy primitive.ObjectID
+}
+
+type LowonganRequest struct {
+	NamaPerusahaan     string `json:"nama_perusahaan"`
+	PosisiJabatan      string `json:"posisi_jabatan"`
+	BidangIndustri     string `json:"bidang_industri"`
+	LokasiKerja        string `json:"lokasi_kerja"`
+	GajiMin            int64  `json:"gaji_min"`
+	GajiMax            int64  `json:"gaji_max"`
+	DeskripsiPekerjaan string `json:"deskripsi_pekerjaan"`
+	KontakLamaran      string `json:"kontak_lamaran"`
+	// BatasLamaran format YYYY-MM-DD
+	BatasLamaran string `json:"batas_lamaran"`
+	// Status hanya dipakai saat update: "open" atau "closed"
+	Status string `json:"status"`
+}
+
+// LowonganInterest mencatat alumni yang berminat / melamar sebuah lowongan.
+// NIM, Nama, dan Email disalin saat mendaftar agar pemasang lowongan bisa menghubungi alumni.
+type LowonganInterest struct {
+	ID         primitive.ObjectID `bson:"_id,omitempty" json:"id"`
+	LowonganID primitive.ObjectID `bson:"lowongan_id" json:"lowongan_id"`
+	AlumniID   primitive.ObjectID `bson:"alumni_id" json:"alumni_id"`
+	UserID     primitive.ObjectID `bson:"user_id" json:"user_id"`
+	NIM        string             `bson:"nim" json:"nim"`
+	Nama       string             `bson:"nama" json:"nama"`
+	Email      string             `bson:"email,omitempty" json:"email,omitempty"`
+	Catatan    string             `bson:"catatan,omitempty" json:"catatan,omitempty"`
+	CreatedAt  time.Time          `bson:"created_at" json:"created_at"`
+}
+
+type LowonganInterestRequest struct {
+	Catatan string `json:"catatan"`
+}
+
+// LowonganVocabulary adalah nilai bidang industri dan lokasi kerja yang sudah dipakai
+// di data pekerjaan alumni dan lowongan, untuk pilihan filter dan isian form
+type LowonganVocabulary struct {
+	BidangIndustri []string `json:"bidang_industri"`
+	LokasiKerja    []string `json:"lokasi_kerja"`
+}
+
+// MyLowonganInterest adalah minat alumni login beserta lowongannya (kosong jika lowongan sudah dihapus)
+type MyLowonganInterest struct {
+	LowonganInterest
+	Lowongan *Lowongan `json:"lowongan,omitempty"`
+}
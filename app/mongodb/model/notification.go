@@ -0,0 +1,79 @@
+package model
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// channel pengiriman notifikasi
+const (
+	ChannelInApp = "in_app"
+	ChannelEmail = "email"
+)
+
+// status email di antrian pengiriman
+const (
+	EmailQueued  = "queued"
+	EmailSending = "sending"
+	EmailSent    = "sent"
+	EmailFailed  = "failed"
+)
+
+// NotificationTemplate disimpan di database agar isi pesan bisa diubah tanpa deploy.
+// Title dan Body memakai sintaks text/template dengan field .Nama, .NIM, .Jurusan, .Angkatan,
+// .TahunLulus, dan .Data (isian bebas dari pengirim, mis. {{.Data.link}}).
+type NotificationTemplate struct {
+	ID    primitive.ObjectID `bson:"_id,omitempty" json:"id"`
+	Key   string             `bson:"key" json:"key"`
+	Title string             `bson:"title" json:"title"`
+	Body  string             `bson:"body" json:"body"`
+	// Channels adalah channel default jika pengirim tidak memilih: "in_app" dan/atau "email"
+	Channels  []string  `bson:"channels" json:"channels"`
+	CreatedAt time.Time `bson:"created_at" json:"created_at"`
+	UpdatedAt time.Time `bson:"updated_at" json:"updated_at"`
+}
+
+// Notification adalah satu pesan di inbox in-app milik user
+type Notification struct {
+	ID          primitive.ObjectID `bson:"_id,omitempty" json:"id"`
+	UserID      primitive.ObjectID `bson:"user_id" json:"user_id"`
+	TemplateKey string             `bson:"template_key" json:"template_key"`
+	Title       string             `bson:"title" json:"title"`
+	Body        string             `bson:"body" json:"body"`
+	ReadAt      *time.Time         `bson:"read_at,omitempty" json:"read_at,omitempty"`
+	CreatedAt   time.Time          `bson:"created_at" json:"created_at"`
+}
+
+// EmailJob adalah email di antrian pengiriman; gagal kirim dicoba ulang dengan jeda yang makin lama
+type EmailJob struct {
+	ID            primitive.ObjectID `bson:"_id,omitempty" json:"id"`
+	TemplateKey   string             `bson:"template_key" json:"template_key"`
+	To            string             `bson:"to" json:"to"`
+	Subject       string             `bson:"subject" json:"subject"`
+	Body          string             `bson:"body" json:"body"`
+	Status        string             `bson:"status" json:"status"`
+	Attempts      int                `bson:"attempts" json:"attempts"`
+	NextAttemptAt time.Time          `bson:"next_attempt_at" json:"next_attempt_at"`
+	// LockedUntil mencegah email yang sedang dikirim diambil worker lain
+	LockedUntil *time.Time `bson:"locked_until,omitempty" json:"locked_until,omitempty"`
+	LastError   string     `bson:"last_error,omitempty" json:"last_error,omitempty"`
+	SentAt      *time.Time `bson:"sent_at,omitempty" json:"sent_at,omitempty"`
+	CreatedAt   time.Time  `bson:"created_at" json:"created_at"`
+}
+
+type NotificationTemplateRequest struct {
+	Key      string   `json:"key"`
+	Title    string   `json:"title"`
+	Body     string   `json:"body"`
+	Channels []string `json:"channels"`
+}
+
+// SendNotificationRequest mengirim template ke semua alumni yang cocok dengan Target
+type SendNotificationRequest struct {
+	TemplateKey string            `json:"template_key"`
+	Data        map[string]string `json:"data"`
+	Target      AlumniFilter      `json:"target"`
+	// Channels kosong berarti memakai channel default template
+	Channels []string `json:"channels"`
+}
@@ -18,6 +18,7 @@ type PekerjaanAlumni struct {
 	TanggalSelesaiKerja *time.Time          `bson:"tanggal_selesai_kerja,omitempty" json:"tanggal_selesai_kerja,omitempty"`
 	StatusPekerjaan     string              `bson:"status_pekerjaan" json:"status_pekerjaan"`
 	DeskripsiPekerjaan  string              `bson:"deskripsi_pekerjaan" json:"deskripsi_pekerjaan"`
+	Version             int                 `bson:"version" json:"version"`
 	CreatedAt           time.Time           `bson:"created_at" json:"created_at"`
 	UpdatedAt           time.Time           `bson:"updated_at" json:"updated_at"`
 	DeletedAt           *time.Time          `bson:"deleted_at,omitempty" json:"deleted_at,omitempty"`
@@ -42,4 +43,14 @@ type CreatePekerjaanRequest struct {
 
 type UpdatePekerjaanRequest struct {
 	PekerjaanRequestBase
+	Version int `json:"version"`
+}
+
+// PekerjaanHistory menyimpan snapshot pekerjaan sebelum diubah
+type PekerjaanHistory struct {
+	ID          primitive.ObjectID `bson:"_id,omitempty" json:"id"`
+	PekerjaanID primitive.ObjectID `bson:"pekerjaan_id" json:"pekerjaan_id"`
+	Version     int                `bson:"version" json:"version"`
+	Snapshot    PekerjaanAlumni    `bson:"snapshot" json:"snapshot"`
+	ArchivedAt  time.Time          `bson:"archived_at" json:"archived_at"`
 }
\ No newline at end of file
@@ -0,0 +1,45 @@
+package model
+
+import "time"
+
+// Aksi rekonsiliasi. "report" hanya melaporkan tanpa mengubah apa pun (dry run).
+const (
+	ReconcileDryRun     = "report"
+	ReconcileDelete     = "delete"
+	ReconcileQuarantine = "quarantine"
+	ReconcileRegister   = "register"
+	ReconcileRelink     = "relink"
+)
+
+// ReconcileOptions menentukan perbaikan yang dijalankan untuk tiap jenis temuan
+type ReconcileOptions struct {
+	Orphans  string `json:"orphans"`  // report | delete | quarantine | register
+	Dangling string `json:"dangling"` // report | delete | quarantine
+	Legacy   string `json:"legacy"`   // report | relink
+	// MinAge: object (ModTime) dan record (UploadedAt) yang lebih baru dari ini dilewati
+	MinAge time.Duration `json:"-"`
+}
+
+// ReconcileItem adalah satu temuan rekonsiliasi beserta hasil perbaikannya
+type ReconcileItem struct {
+	Key      string `json:"key"`
+	FileID   string `json:"file_id,omitempty"`
+	AlumniID string `json:"alumni_id,omitempty"`
+	Size     int64  `json:"size,omitempty"`
+	Action   string `json:"action,omitempty"`
+	Error    string `json:"error,omitempty"`
+}
+
+// ReconcileReport adalah hasil pencocokan object di storage dengan collection files.
+// Orphans: object tanpa record; Dangling: record yang object-nya hilang dari storage;
+// LegacyRefs: foto alumni yang masih menunjuk path statis /uploads/foto lama.
+type ReconcileReport struct {
+	Options        ReconcileOptions `json:"options"`
+	ScannedObjects int              `json:"scanned_objects"`
+	ScannedRecords int              `json:"scanned_records"`
+	// SkippedRecent adalah jumlah object dan record yang dilewati karena lebih baru dari MinAge
+	SkippedRecent int             `json:"skipped_recent"`
+	Orphans       []ReconcileItem `json:"orphans"`
+	Dangling      []ReconcileItem `json:"dangling"`
+	LegacyRefs    []ReconcileItem `json:"legacy_refs"`
+}
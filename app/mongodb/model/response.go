@@ -23,6 +23,31 @@ type PekerjaanResponse struct {
 	Meta MetaInfo          `json:"meta"`
 }
 
+// Response untuk daftar file
+type FileResponse struct {
+	Data []File   `json:"data"`
+	Meta MetaInfo `json:"meta"`
+}
+
+// Response untuk daftar klaim data alumni
+type AlumniClaimResponse struct {
+	Data []AlumniClaim `json:"data"`
+	Meta MetaInfo      `json:"meta"`
+}
+
+// Response untuk inbox notifikasi
+type NotificationResponse struct {
+	Data   []Notification `json:"data"`
+	Meta   MetaInfo       `json:"meta"`
+	Unread int64          `json:"unread"`
+}
+
+// Response untuk daftar lowongan kerja
+type LowonganResponse struct {
+	Data []Lowongan `json:"data"`
+	Meta MetaInfo   `json:"meta"`
+}
+
 // Response generik (bisa dipakai kalau butuh custom)
 type BaseResponse struct {
 	Success bool        `json:"success"`
@@ -0,0 +1,164 @@
+package model
+
+import (
+	"strings"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// status kuesioner tracer study
+const (
+	// SurveyDraft: pertanyaan masih bisa diubah, belum bisa diisi alumni
+	SurveyDraft  = "draft"
+	SurveyOpen   = "open"
+	SurveyClosed = "closed"
+)
+
+// tipe pertanyaan kuesioner
+const (
+	QuestionText           = "text"
+	QuestionNumber         = "number"
+	QuestionSingleChoice   = "single_choice"
+	QuestionMultipleChoice = "multiple_choice"
+)
+
+type SurveyQuestion struct {
+	// ID dipakai sebagai key jawaban dan header kolom CSV; otomatis "q1", "q2", ... jika kosong
+	ID       string   `bson:"id" json:"id"`
+	Label    string   `bson:"label" json:"label"`
+	Type     string   `bson:"type" json:"type"`
+	Options  []string `bson:"options,omitempty" json:"options,omitempty"`
+	Required bool     `bson:"required" json:"required"`
+}
+
+// SurveyTarget menentukan angkatan lulusan dan jurusan yang boleh mengisi; list kosong berarti semua
+type SurveyTarget struct {
+	TahunLulus []int    `bson:"tahun_lulus,omitempty" json:"tahun_lulus,omitempty"`
+	Jurusan    []string `bson:"jurusan,omitempty" json:"jurusan,omitempty"`
+}
+
+// Matches melaporkan apakah alumni termasuk target kuesioner (jurusan tidak membedakan huruf besar/kecil)
+func (t SurveyTarget) Matches(a Alumni) bool {
+	if len(t.TahunLulus) > 0 {
+		found := false
+		for _, y := range t.TahunLulus {
+			if y == a.TahunLulus {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	if len(t.Jurusan) > 0 {
+		for _, j := range t.Jurusan {
+			if strings.EqualFold(j, a.Jurusan) {
+				return true
+			}
+		}
+		return false
+	}
+	return true
+}
+
+// Survey adalah kuesioner tracer study yang dibuat admin
+type Survey struct {
+	ID          primitive.ObjectID `bson:"_id,omitempty" json:"id"`
+	Title       string             `bson:"title" json:"title"`
+	Description string             `bson:"description,omitempty" json:"description,omitempty"`
+	Questions   []SurveyQuestion   `bson:"questions" json:"questions"`
+	Target      SurveyTarget       `bson:"target" json:"target"`
+	Status      string             `bson:"status" json:"status"`
+	CreatedBy   primitive.ObjectID `bson:"created_by" json:"created_by"`
+	OpenedAt    *time.Time         `bson:"opened_at,omitempty" json:"opened_at,omitempty"`
+	ClosedAt    *time.Time         `bson:"closed_at,omitempty" json:"closed_at,omitempty"`
+	CreatedAt   time.Time          `bson:"created_at" json:"created_at"`
+	UpdatedAt   time.Time          `bson:"updated_at" json:"updated_at"`
+}
+
+// SurveyAnswer menyimpan jawaban satu pertanyaan; hanya field yang sesuai tipe pertanyaan yang terisi
+type SurveyAnswer struct {
+	QuestionID string   `bson:"question_id" json:"question_id"`
+	Text       string   `bson:"text,omitempty" json:"text,omitempty"`
+	Number     *float64 `bson:"number,omitempty" json:"number,omitempty"`
+	Choices    []string `bson:"choices,omitempty" json:"choices,omitempty"`
+}
+
+// SurveyResponse adalah jawaban satu alumni; satu alumni satu response per kuesioner.
+// NIM, Nama, Jurusan, dan TahunLulus disalin saat submit untuk laporan dan export.
+type SurveyResponse struct {
+	ID          primitive.ObjectID `bson:"_id,omitempty" json:"id"`
+	SurveyID    primitive.ObjectID `bson:"survey_id" json:"survey_id"`
+	AlumniID    primitive.ObjectID `bson:"alumni_id" json:"alumni_id"`
+	UserID      primitive.ObjectID `bson:"user_id" json:"user_id"`
+	NIM         string             `bson:"nim" json:"nim"`
+	Nama        string             `bson:"nama" json:"nama"`
+	Jurusan     string             `bson:"jurusan" json:"jurusan"`
+	TahunLulus  int                `bson:"tahun_lulus" json:"tahun_lulus"`
+	Answers     []SurveyAnswer     `bson:"answers" json:"answers"`
+	SubmittedAt time.Time          `bson:"submitted_at" json:"submitted_at"`
+	UpdatedAt   time.Time          `bson:"updated_at" json:"updated_at"`
+}
+
+type SurveyRequest struct {
+	Title       string           `json:"title"`
+	Description string           `json:"description"`
+	Questions   []SurveyQuestion `json:"questions"`
+	Target      SurveyTarget     `json:"target"`
+}
+
+type SurveyStatusRequest struct {
+	// Status: "open" atau "closed"
+	Status string `json:"status"`
+}
+
+// SubmitSurveyRequest berisi jawaban per ID pertanyaan:
+// string untuk text dan single_choice, angka untuk number, array string untuk multiple_choice
+type SubmitSurveyRequest struct {
+	Answers map[string]interface{} `json:"answers"`
+}
+
+type SurveyRemindRequest struct {
+	// Link halaman pengisian kuesioner, dipakai template survey_reminder sebagai {{.Data.link}}
+	Link string `json:"link"`
+}
+
+// MySurvey adalah kuesioner terbuka untuk alumni login beserta status pengisiannya
+type MySurvey struct {
+	Survey
+	Answered    bool       `json:"answered"`
+	SubmittedAt *time.Time `json:"submitted_at,omitempty"`
+}
+
+// OptionCount adalah jumlah pemilih satu opsi
+type OptionCount struct {
+	Option string `json:"option"`
+	Count  int    `json:"count"`
+}
+
+// QuestionResult adalah rekap jawaban satu pertanyaan
+type QuestionResult struct {
+	ID       string `json:"id"`
+	Label    string `json:"label"`
+	Type     string `json:"type"`
+	Answered int    `json:"answered"`
+	// Options terisi untuk single_choice dan multiple_choice, urut sesuai definisi pertanyaan
+	Options []OptionCount `json:"options,omitempty"`
+	// Min, Max, dan Mean terisi untuk number
+	Min  *float64 `json:"min,omitempty"`
+	Max  *float64 `json:"max,omitempty"`
+	Mean *float64 `json:"mean,omitempty"`
+}
+
+// SurveyResults adalah rekap jawaban kuesioner untuk laporan
+type SurveyResults struct {
+	SurveyID  primitive.ObjectID `json:"survey_id"`
+	Title     string             `json:"title"`
+	Responses int                `json:"responses"`
+	// Targeted adalah jumlah alumni yang termasuk target kuesioner (dan filter laporan)
+	Targeted     int              `json:"targeted"`
+	ResponseRate float64          `json:"response_rate"`
+	Questions    []QuestionResult `json:"questions"`
+}
@@ -0,0 +1,44 @@
+package model
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// status sesi upload bertahap
+const (
+	UploadSessionUploading = "uploading"
+	// UploadSessionFinalizing: chunk sedang digabung oleh satu request finalize; finalize lain ditolak
+	UploadSessionFinalizing = "finalizing"
+)
+
+// UploadSession adalah sesi upload bertahap (resumable) untuk dokumen / sertifikat PDF
+type UploadSession struct {
+	ID        primitive.ObjectID `json:"id" bson:"_id,omitempty"`
+	UserID    primitive.ObjectID `json:"user_id" bson:"user_id"`
+	AlumniID  primitive.ObjectID `json:"alumni_id" bson:"alumni_id"`
+	FileName  string             `json:"file_name" bson:"file_name"`
+	Size      int64              `json:"size" bson:"size"`
+	Offset    int64              `json:"offset" bson:"offset"`
+	Checksum  string             `json:"checksum,omitempty" bson:"checksum,omitempty"` // SHA-256 hex seluruh file (opsional)
+	Status    string             `json:"status" bson:"status"`
+	Chunks    []UploadChunk      `json:"-" bson:"chunks"`
+	ExpiresAt time.Time          `json:"expires_at" bson:"expires_at"`
+	CreatedAt time.Time          `json:"created_at" bson:"created_at"`
+	UpdatedAt time.Time          `json:"updated_at" bson:"updated_at"`
+}
+
+// UploadChunk adalah potongan file yang sudah diterima, disimpan sementara di storage
+type UploadChunk struct {
+	Offset     int64  `bson:"offset"`
+	Size       int64  `bson:"size"`
+	StorageKey string `bson:"storage_key"`
+}
+
+type CreateUploadSessionRequest struct {
+	AlumniID string `json:"alumni_id"`
+	FileName string `json:"file_name"`
+	Size     int64  `json:"size"`
+	Checksum string `json:"checksum"`
+}
@@ -10,9 +10,12 @@ type User struct {
 	ID           primitive.ObjectID `bson:"_id,omitempty" json:"id"`
 	Username     string             `bson:"username" json:"username"`
 	Email        string             `bson:"email" json:"email"`
-	PasswordHash string             `bson:"password_hash" json:"password_hash"`
+	PasswordHash string             `bson:"password_hash" json:"-"`
 	Role         string             `bson:"role" json:"role"`
-	CreatedAt    time.Time          `bson:"created_at" json:"created_at"`
+	// EmailVerified diisi true setelah user membuka link verifikasi dari email
+	EmailVerified   bool       `bson:"email_verified" json:"email_verified"`
+	EmailVerifiedAt *time.Time `bson:"email_verified_at,omitempty" json:"email_verified_at,omitempty"`
+	CreatedAt       time.Time  `bson:"created_at" json:"created_at"`
 }
 
 type LoginRequest struct {
@@ -21,8 +24,23 @@ type LoginRequest struct {
 }
 
 type RegisterRequest struct {
-	Username     string `json:"username"`
-	Email        string `json:"email"`
-	PasswordHash string `json:"password"`
-	Role         string `json:"role,omitempty"`
+	Username     string                 `json:"username"`
+	Email        string                 `json:"email"`
+	PasswordHash string                 `json:"password"`
+	Alumni       *RegisterAlumniRequest `json:"alumni,omitempty"`
+}
+
+// RegisterAlumniRequest adalah profil alumni opsional yang dibuat bersama akun dan langsung terhubung ke user tersebut
+type RegisterAlumniRequest struct {
+	NIM        string `json:"nim"`
+	Nama       string `json:"nama"`
+	Jurusan    string `json:"jurusan"`
+	Angkatan   int    `json:"angkatan"`
+	TahunLulus int    `json:"tahun_lulus"`
+	NoTelepon  string `json:"no_telepon"`
+	Alamat     string `json:"alamat"`
+}
+
+type ResendVerificationRequest struct {
+	Email string `json:"email"`
 }
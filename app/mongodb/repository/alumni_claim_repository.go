@@ -0,0 +1,119 @@
+package repository
+
+import (
+	"alumni-app/app/mongodb/model"
+	"alumni-app/config"
+	"context"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type AlumniClaimRepositoryInterface interface {
+	Create(ctx context.Context, claim *model.AlumniClaim) error
+	GetByID(ctx context.Context, id primitive.ObjectID) (model.AlumniClaim, error)
+	Find(ctx context.Context, userID primitive.ObjectID, status string, page, limit int) ([]model.AlumniClaim, error)
+	Count(ctx context.Context, userID primitive.ObjectID, status string) (int64, error)
+	Decide(ctx context.Context, id primitive.ObjectID, status string, reviewerID primitive.ObjectID, notes string) error
+}
+
+type AlumniClaimRepository struct {
+	Col      *mongo.Collection
+	Timeouts config.DBTimeoutConfig
+}
+
+func NewAlumniClaimRepository(db *mongo.Database, timeouts config.DBTimeoutConfig) AlumniClaimRepositoryInterface {
+	return &AlumniClaimRepository{
+		Col:      db.Collection("alumni_claims"),
+		Timeouts: timeouts,
+	}
+}
+
+// Create menyimpan klaim baru; klaim pending kedua untuk user dan alumni yang sama
+// gagal dengan *ConflictError{Field: "claim"}
+func (r *AlumniClaimRepository) Create(ctx context.Context, claim *model.AlumniClaim) error {
+	ctx, cancel := withTimeout(ctx, r.Timeouts.Write)
+	defer cancel()
+
+	claim.ID = primitive.NewObjectID()
+	claim.CreatedAt = time.Now()
+
+	_, err := r.Col.InsertOne(ctx, claim)
+	return translateDuplicate(err)
+}
+
+func (r *AlumniClaimRepository) GetByID(ctx context.Context, id primitive.ObjectID) (model.AlumniClaim, error) {
+	ctx, cancel := withTimeout(ctx, r.Timeouts.Read)
+	defer cancel()
+
+	var claim model.AlumniClaim
+	err := r.Col.FindOne(ctx, bson.M{"_id": id}).Decode(&claim)
+	return claim, err
+}
+
+// Find mengambil klaim terbaru lebih dulu; userID nol dan status kosong berarti tanpa filter
+func (r *AlumniClaimRepository) Find(ctx context.Context, userID primitive.ObjectID, status string, page, limit int) ([]model.AlumniClaim, error) {
+	ctx, cancel := withTimeout(ctx, r.Timeouts.Read)
+	defer cancel()
+
+	opts := options.Find().
+		SetSort(bson.D{{Key: "created_at", Value: -1}}).
+		SetSkip(int64((page - 1) * limit)).
+		SetLimit(int64(limit))
+
+	cur, err := r.Col.Find(ctx, claimFilter(userID, status), opts)
+	if err != nil {
+		return nil, err
+	}
+	defer cur.Close(ctx)
+
+	list := []model.AlumniClaim{}
+	if err := cur.All(ctx, &list); err != nil {
+		return nil, err
+	}
+	return list, nil
+}
+
+func (r *AlumniClaimRepository) Count(ctx context.Context, userID primitive.ObjectID, status string) (int64, error) {
+	ctx, cancel := withTimeout(ctx, r.Timeouts.Read)
+	defer cancel()
+
+	return r.Col.CountDocuments(ctx, claimFilter(userID, status))
+}
+
+// Decide menyimpan keputusan admin; gagal dengan mongo.ErrNoDocuments jika klaim sudah tidak pending
+func (r *AlumniClaimRepository) Decide(ctx context.Context, id primitive.ObjectID, status string, reviewerID primitive.ObjectID, notes string) error {
+	ctx, cancel := withTimeout(ctx, r.Timeouts.Write)
+	defer cancel()
+
+	res, err := r.Col.UpdateOne(ctx,
+		bson.M{"_id": id, "status": model.ClaimPending},
+		bson.M{"$set": bson.M{
+			"status":      status,
+			"notes":       notes,
+			"reviewer_id": reviewerID,
+			"reviewed_at": time.Now(),
+		}},
+	)
+	if err != nil {
+		return err
+	}
+	if res.MatchedCount == 0 {
+		return mongo.ErrNoDocuments
+	}
+	return nil
+}
+
+func claimFilter(userID primitive.ObjectID, status string) bson.M {
+	filter := bson.M{}
+	if !userID.IsZero() {
+		filter["user_id"] = userID
+	}
+	if status != "" {
+		filter["status"] = status
+	}
+	return filter
+}
@@ -2,7 +2,10 @@ package repository
 
 import (
 	"alumni-app/app/mongodb/model"
+	"alumni-app/config"
 	"context"
+	"fmt"
+	"regexp"
 	"time"
 
 	"go.mongodb.org/mongo-driver/bson"
@@ -12,40 +15,39 @@ import (
 )
 
 type AlumniRepositoryInterface interface {
-	GetAll(search string, sortBy string, order string, page, limit int) ([]model.Alumni, error)
-	Count(search string) (int64, error)
-	GetByID(id primitive.ObjectID) (model.Alumni, error)
-	GetByUserID(userID primitive.ObjectID) (model.Alumni, error)
-	Create(a *model.Alumni) error
-	Update(id primitive.ObjectID, a *model.Alumni) error
-	SoftDelete(id primitive.ObjectID) error
-	GetAllByUserID(userID primitive.ObjectID) ([]model.Alumni, error)
+	GetAll(ctx context.Context, filter model.AlumniFilter, sortBy string, order string, page, limit int) ([]model.Alumni, error)
+	Count(ctx context.Context, filter model.AlumniFilter) (int64, error)
+	FindByFilter(ctx context.Context, filter model.AlumniFilter) ([]model.Alumni, error)
+	FindBySurveyTarget(ctx context.Context, target model.SurveyTarget) ([]model.Alumni, error)
+	GetByID(ctx context.Context, id primitive.ObjectID) (model.Alumni, error)
+	GetByUserID(ctx context.Context, userID primitive.ObjectID) (model.Alumni, error)
+	GetByNIM(ctx context.Context, nim string) (model.Alumni, error)
+	Create(ctx context.Context, a *model.Alumni) error
+	Update(ctx context.Context, id primitive.ObjectID, a *model.Alumni) error
+	SoftDelete(ctx context.Context, id primitive.ObjectID) error
+	Restore(ctx context.Context, id primitive.ObjectID) error
+	GetAllByUserID(ctx context.Context, userID primitive.ObjectID) ([]model.Alumni, error)
+	UpdateFileRef(ctx context.Context, id primitive.ObjectID, field, value string) error
+	FindLegacyFoto(ctx context.Context) ([]model.Alumni, error)
+	LinkUser(ctx context.Context, id, userID, from primitive.ObjectID) error
 }
 
 type AlumniRepository struct{
-	Col *mongo.Collection
+	Col      *mongo.Collection
+	Timeouts config.DBTimeoutConfig
 }
 
-func NewAlumniRepository(db *mongo.Database) AlumniRepositoryInterface {
+func NewAlumniRepository(db *mongo.Database, timeouts config.DBTimeoutConfig) AlumniRepositoryInterface {
 	return &AlumniRepository{
-		Col: db.Collection("alumni"),
+		Col:      db.Collection("alumni"),
+		Timeouts: timeouts,
 	}
 }
 
-func (r *AlumniRepository) GetAll(search string, sortBy string, order string, page, limit int) ([]model.Alumni, error) {
-	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+func (r *AlumniRepository) GetAll(ctx context.Context, filter model.AlumniFilter, sortBy string, order string, page, limit int) ([]model.Alumni, error) {
+	ctx, cancel := withTimeout(ctx, r.Timeouts.Read)
 	defer cancel()
 
-	filter := bson.M{
-		"$or": []bson.M{
-			{"nama": bson.M{"$regex": search, "$options": "i"}},
-			{"jurusan": bson.M{"$regex": search, "$options": "i"}},
-			{"email": bson.M{"$regex": search, "$options": "i"}},
-		},
-	}
-	// exclude soft-deleted
-	filter["$and"] = []bson.M{{"deleted_at": bson.M{"$exists": false}}}
-
 	sortOrder := 1
 	if order == "desc" {
 		sortOrder = -1
@@ -56,7 +58,7 @@ func (r *AlumniRepository) GetAll(search string, sortBy string, order string, pa
 		SetSkip(int64((page - 1) * limit)).
 		SetLimit(int64(limit))
 
-	cur, err := r.Col.Find(ctx, filter, opts)
+	cur, err := r.Col.Find(ctx, alumniFilterQuery(filter), opts)
 	if err != nil {
 		return nil, err
 	}
@@ -69,24 +71,88 @@ func (r *AlumniRepository) GetAll(search string, sortBy string, order string, pa
 	return list, nil
 }
 
-func (r *AlumniRepository) Count(search string) (int64, error) {
-	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+func (r *AlumniRepository) Count(ctx context.Context, filter model.AlumniFilter) (int64, error) {
+	ctx, cancel := withTimeout(ctx, r.Timeouts.Read)
+	defer cancel()
+
+	return r.Col.CountDocuments(ctx, alumniFilterQuery(filter))
+}
+
+// FindByFilter mengambil semua alumni yang cocok tanpa pagination (mis. untuk penerima notifikasi)
+func (r *AlumniRepository) FindByFilter(ctx context.Context, filter model.AlumniFilter) ([]model.Alumni, error) {
+	ctx, cancel := withTimeout(ctx, r.Timeouts.Scan)
+	defer cancel()
+
+	cur, err := r.Col.Find(ctx, alumniFilterQuery(filter))
+	if err != nil {
+		return nil, err
+	}
+	defer cur.Close(ctx)
+
+	list := []model.Alumni{}
+	if err := cur.All(ctx, &list); err != nil {
+		return nil, err
+	}
+	return list, nil
+}
+
+// FindBySurveyTarget mengambil alumni aktif yang termasuk target kuesioner dalam satu query:
+// tahun lulus dengan $in dan jurusan dengan pola ^...$ tanpa membedakan huruf besar/kecil.
+// Query ini hanya penyaring awal; model.SurveyTarget.Matches tetap menjadi definisi target.
+func (r *AlumniRepository) FindBySurveyTarget(ctx context.Context, target model.SurveyTarget) ([]model.Alumni, error) {
+	ctx, cancel := withTimeout(ctx, r.Timeouts.Scan)
 	defer cancel()
 
+	filter := bson.M{"deleted_at": bson.M{"$exists": false}}
+	if len(target.TahunLulus) > 0 {
+		filter["tahun_lulus"] = bson.M{"$in": target.TahunLulus}
+	}
+	if len(target.Jurusan) > 0 {
+		patterns := make([]primitive.Regex, len(target.Jurusan))
+		for i, j := range target.Jurusan {
+			patterns[i] = primitive.Regex{Pattern: "^" + regexp.QuoteMeta(j) + "$", Options: "i"}
+		}
+		filter["jurusan"] = bson.M{"$in": patterns}
+	}
+
+	cur, err := r.Col.Find(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+	defer cur.Close(ctx)
+
+	list := []model.Alumni{}
+	if err := cur.All(ctx, &list); err != nil {
+		return nil, err
+	}
+	return list, nil
+}
+
+func alumniFilterQuery(f model.AlumniFilter) bson.M {
 	filter := bson.M{
 		"$or": []bson.M{
-			{"nama": bson.M{"$regex": search, "$options": "i"}},
-			{"jurusan": bson.M{"$regex": search, "$options": "i"}},
-			{"email": bson.M{"$regex": search, "$options": "i"}},
+			{"nama": bson.M{"$regex": f.Search, "$options": "i"}},
+			{"jurusan": bson.M{"$regex": f.Search, "$options": "i"}},
+			{"email": bson.M{"$regex": f.Search, "$options": "i"}},
 		},
 	}
+	// exclude soft-deleted
 	filter["$and"] = []bson.M{{"deleted_at": bson.M{"$exists": false}}}
 
-	return r.Col.CountDocuments(ctx, filter)
+	if f.Jurusan != "" {
+		filter["jurusan"] = bson.M{"$regex": "^" + regexp.QuoteMeta(f.Jurusan) + "$", "$options": "i"}
+	}
+	if f.Angkatan != 0 {
+		filter["angkatan"] = f.Angkatan
+	}
+	if f.TahunLulus != 0 {
+		filter["tahun_lulus"] = f.TahunLulus
+	}
+	return filter
 }
 
-func (r *AlumniRepository) GetByID(id primitive.ObjectID) (model.Alumni, error) {
-	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+func (r *AlumniRepository) GetByID(ctx context.Context, id primitive.ObjectID) (model.Alumni, error) {
+	ctx, cancel := withTimeout(ctx, r.Timeouts.Read)
 	defer cancel()
 
 	var a model.Alumni
@@ -94,8 +160,8 @@ func (r *AlumniRepository) GetByID(id primitive.ObjectID) (model.Alumni, error)
 	return a, err
 }
 
-func (r *AlumniRepository) GetByUserID(userID primitive.ObjectID) (model.Alumni, error) {
-	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+func (r *AlumniRepository) GetByUserID(ctx context.Context, userID primitive.ObjectID) (model.Alumni, error) {
+	ctx, cancel := withTimeout(ctx, r.Timeouts.Read)
 	defer cancel()
 
 	var a model.Alumni
@@ -103,8 +169,21 @@ func (r *AlumniRepository) GetByUserID(userID primitive.ObjectID) (model.Alumni,
 	return a, err
 }
 
-func (r *AlumniRepository) Create(a *model.Alumni) error {
-	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+// GetByNIM mencari alumni tanpa memperhatikan huruf besar/kecil NIM (memakai index nim_ci)
+func (r *AlumniRepository) GetByNIM(ctx context.Context, nim string) (model.Alumni, error) {
+	ctx, cancel := withTimeout(ctx, r.Timeouts.Read)
+	defer cancel()
+
+	var a model.Alumni
+	err := r.Col.FindOne(ctx,
+		bson.M{"nim": nim, "deleted_at": bson.M{"$exists": false}},
+		options.FindOne().SetCollation(caseInsensitive),
+	).Decode(&a)
+	return a, err
+}
+
+func (r *AlumniRepository) Create(ctx context.Context, a *model.Alumni) error {
+	ctx, cancel := withTimeout(ctx, r.Timeouts.Write)
 	defer cancel()
 
 	a.ID = primitive.NewObjectID()
@@ -113,22 +192,43 @@ func (r *AlumniRepository) Create(a *model.Alumni) error {
 	a.UpdatedAt = now
 
 	_, err := r.Col.InsertOne(ctx, a)
-	return err
+	return translateDuplicate(err)
 }
 
-func (r *AlumniRepository) Update(id primitive.ObjectID, a *model.Alumni) error {
-	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+// Update menyimpan perubahan hanya jika a.Version masih sama dengan versi di database,
+// lalu menaikkan a.Version satu.
+func (r *AlumniRepository) Update(ctx context.Context, id primitive.ObjectID, a *model.Alumni) error {
+	ctx, cancel := withTimeout(ctx, r.Timeouts.Write)
 	defer cancel()
 
+	expected := a.Version
+	filter := bson.M{"_id": id, "version": expected}
+	if expected == 0 {
+		// data lama belum punya field version
+		filter["version"] = bson.M{"$in": bson.A{0, nil}}
+	}
+
+	a.Version = expected + 1
 	a.UpdatedAt = time.Now()
 	update := bson.M{"$set": a}
 
-	_, err := r.Col.UpdateOne(ctx, bson.M{"_id": id}, update)
-	return err
+	res, err := r.Col.UpdateOne(ctx, filter, update)
+	if err != nil {
+		a.Version = expected
+		return translateDuplicate(err)
+	}
+	if res.MatchedCount == 0 {
+		a.Version = expected
+		if n, _ := r.Col.CountDocuments(ctx, bson.M{"_id": id}); n > 0 {
+			return ErrVersionConflict
+		}
+		return mongo.ErrNoDocuments
+	}
+	return nil
 }
 
-func (r *AlumniRepository) SoftDelete(id primitive.ObjectID) error {
-	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+func (r *AlumniRepository) SoftDelete(ctx context.Context, id primitive.ObjectID) error {
+	ctx, cancel := withTimeout(ctx, r.Timeouts.Write)
 	defer cancel()
 
 	_, err := r.Col.UpdateOne(ctx, bson.M{"_id": id}, bson.M{"$set": bson.M{
@@ -137,8 +237,16 @@ func (r *AlumniRepository) SoftDelete(id primitive.ObjectID) error {
 	return err
 }
 
-func (r *AlumniRepository) GetAllByUserID(userID primitive.ObjectID) ([]model.Alumni, error) {
-	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+func (r *AlumniRepository) Restore(ctx context.Context, id primitive.ObjectID) error {
+	ctx, cancel := withTimeout(ctx, r.Timeouts.Write)
+	defer cancel()
+
+	_, err := r.Col.UpdateOne(ctx, bson.M{"_id": id}, bson.M{"$unset": bson.M{"deleted_at": ""}})
+	return err
+}
+
+func (r *AlumniRepository) GetAllByUserID(ctx context.Context, userID primitive.ObjectID) ([]model.Alumni, error) {
+	ctx, cancel := withTimeout(ctx, r.Timeouts.Read)
 	defer cancel()
 
 	cur, err := r.Col.Find(ctx, bson.M{"user_id": userID})
@@ -154,6 +262,80 @@ func (r *AlumniRepository) GetAllByUserID(userID primitive.ObjectID) ([]model.Al
 	return list, nil
 }
 
+// FindLegacyFoto mengembalikan alumni yang field foto-nya masih berisi path statis lama
+// (uploads/foto/...), termasuk yang sudah dihapus, untuk dipindahkan ke URL collection files
+func (r *AlumniRepository) FindLegacyFoto(ctx context.Context) ([]model.Alumni, error) {
+	ctx, cancel := withTimeout(ctx, r.Timeouts.Read)
+	defer cancel()
+
+	cur, err := r.Col.Find(ctx, bson.M{"foto": bson.M{"$regex": "^/?uploads/foto/"}})
+	if err != nil {
+		return nil, err
+	}
+	defer cur.Close(ctx)
+
+	var list []model.Alumni
+	if err := cur.All(ctx, &list); err != nil {
+		return nil, err
+	}
+	return list, nil
+}
+
+// UpdateFileRef mengisi atau mengosongkan (value "") field referensi file:
+// "foto", "sertifikat_path", atau status verifikasinya "sertifikat_status"
+func (r *AlumniRepository) UpdateFileRef(ctx context.Context, id primitive.ObjectID, field, value string) error {
+	if field != "foto" && field != "sertifikat_path" && field != "sertifikat_status" {
+		return fmt.Errorf("invalid file field %q", field)
+	}
+
+	ctx, cancel := withTimeout(ctx, r.Timeouts.Write)
+	defer cancel()
+
+	update := bson.M{
+		"$set": bson.M{"updated_at": time.Now()},
+		"$inc": bson.M{"version": 1},
+	}
+	if value == "" {
+		update["$unset"] = bson.M{field: ""}
+	} else {
+		update["$set"].(bson.M)[field] = value
+	}
+
+	res, err := r.Col.UpdateOne(ctx, bson.M{"_id": id}, update)
+	if err != nil {
+		return err
+	}
+	if res.MatchedCount == 0 {
+		return mongo.ErrNoDocuments
+	}
+	return nil
+}
+
+// LinkUser menghubungkan data alumni ke userID hanya jika user_id saat ini masih from
+// (primitive.NilObjectID untuk data yang belum terhubung). Gagal dengan mongo.ErrNoDocuments
+// jika data sudah berubah, atau *ConflictError{Field: "user_id"} jika user sudah punya data alumni lain.
+func (r *AlumniRepository) LinkUser(ctx context.Context, id, userID, from primitive.ObjectID) error {
+	ctx, cancel := withTimeout(ctx, r.Timeouts.Write)
+	defer cancel()
+
+	filter := bson.M{"_id": id, "user_id": from, "deleted_at": bson.M{"$exists": false}}
+	if from.IsZero() {
+		filter["user_id"] = bson.M{"$in": bson.A{primitive.NilObjectID, nil}}
+	}
+
+	res, err := r.Col.UpdateOne(ctx, filter, bson.M{
+		"$set": bson.M{"user_id": userID, "updated_at": time.Now()},
+		"$inc": bson.M{"version": 1},
+	})
+	if err != nil {
+		return translateDuplicate(err)
+	}
+	if res.MatchedCount == 0 {
+		return mongo.ErrNoDocuments
+	}
+	return nil
+}
+
 // func (r *AlumniRepository) UpdateFieldByID(ctx context.Context, id primitive.ObjectID, field string, value any) error {
 // 	_, err := database.DB.Collection("alumni").UpdateOne(
 // 		ctx,
@@ -0,0 +1,127 @@
+package repository
+
+import (
+	"alumni-app/app/mongodb/model"
+	"alumni-app/config"
+	"context"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type BlobRepositoryInterface interface {
+	FindBySHA256(ctx context.Context, hash string) (model.Blob, error)
+	// Acquire menambah referensi blob hash hanya jika catatannya masih ada dan masih memakai key.
+	// false berarti blob sudah dihapus (atau diganti generasi baru) sehingga object di key tidak boleh dipakai.
+	Acquire(ctx context.Context, hash, key string) (bool, error)
+	// Create mencatat blob baru dengan RefCount 1; ErrConflict jika hash sudah tercatat
+	Create(ctx context.Context, b *model.Blob) error
+	// Release mengurangi referensi blob dan mengembalikan sisa referensinya
+	Release(ctx context.Context, hash string) (int, error)
+	// DeleteIfUnreferenced menghapus blob hanya jika RefCount <= 0
+	DeleteIfUnreferenced(ctx context.Context, hash string) (bool, error)
+	Stats(ctx context.Context) (model.DedupReport, error)
+}
+
+type BlobRepository struct {
+	Col      *mongo.Collection
+	Timeouts config.DBTimeoutConfig
+}
+
+// NewBlobRepository: index unik blobs.sha256 dibuat oleh migrasi (database/mongodb/migrations.go)
+func NewBlobRepository(db *mongo.Database, timeouts config.DBTimeoutConfig) BlobRepositoryInterface {
+	return &BlobRepository{Col: db.Collection("blobs"), Timeouts: timeouts}
+}
+
+func (r *BlobRepository) FindBySHA256(ctx context.Context, hash string) (model.Blob, error) {
+	ctx, cancel := withTimeout(ctx, r.Timeouts.Read)
+	defer cancel()
+
+	var b model.Blob
+	err := r.Col.FindOne(ctx, bson.M{"sha256": hash}).Decode(&b)
+	return b, err
+}
+
+func (r *BlobRepository) Acquire(ctx context.Context, hash, key string) (bool, error) {
+	ctx, cancel := withTimeout(ctx, r.Timeouts.Write)
+	defer cancel()
+
+	// tanpa upsert: blob yang baru saja dihapus tidak boleh dihidupkan lagi dengan key lamanya,
+	// karena object-nya sedang (atau sudah) dihapus dari storage oleh pelepas referensi terakhir
+	res, err := r.Col.UpdateOne(ctx,
+		bson.M{"sha256": hash, "storage_key": key},
+		bson.M{"$inc": bson.M{"ref_count": 1}},
+	)
+	if err != nil {
+		return false, err
+	}
+	return res.MatchedCount == 1, nil
+}
+
+func (r *BlobRepository) Create(ctx context.Context, b *model.Blob) error {
+	ctx, cancel := withTimeout(ctx, r.Timeouts.Write)
+	defer cancel()
+
+	b.RefCount = 1
+	b.CreatedAt = time.Now()
+	_, err := r.Col.InsertOne(ctx, b)
+	return translateDuplicate(err)
+}
+
+func (r *BlobRepository) Release(ctx context.Context, hash string) (int, error) {
+	ctx, cancel := withTimeout(ctx, r.Timeouts.Write)
+	defer cancel()
+
+	var b model.Blob
+	err := r.Col.FindOneAndUpdate(ctx,
+		bson.M{"sha256": hash},
+		bson.M{"$inc": bson.M{"ref_count": -1}},
+		options.FindOneAndUpdate().SetReturnDocument(options.After),
+	).Decode(&b)
+	if err != nil {
+		return 0, err
+	}
+	return b.RefCount, nil
+}
+
+func (r *BlobRepository) DeleteIfUnreferenced(ctx context.Context, hash string) (bool, error) {
+	ctx, cancel := withTimeout(ctx, r.Timeouts.Write)
+	defer cancel()
+
+	res, err := r.Col.DeleteOne(ctx, bson.M{"sha256": hash, "ref_count": bson.M{"$lte": 0}})
+	if err != nil {
+		return false, err
+	}
+	return res.DeletedCount == 1, nil
+}
+
+func (r *BlobRepository) Stats(ctx context.Context) (model.DedupReport, error) {
+	ctx, cancel := withTimeout(ctx, r.Timeouts.Scan)
+	defer cancel()
+
+	cur, err := r.Col.Aggregate(ctx, mongo.Pipeline{
+		{{Key: "$match", Value: bson.M{"ref_count": bson.M{"$gt": 0}}}},
+		{{Key: "$group", Value: bson.M{
+			"_id":           nil,
+			"blobs":         bson.M{"$sum": 1},
+			"references":    bson.M{"$sum": "$ref_count"},
+			"stored_bytes":  bson.M{"$sum": "$size"},
+			"logical_bytes": bson.M{"$sum": bson.M{"$multiply": bson.A{"$size", "$ref_count"}}},
+		}}},
+	})
+	if err != nil {
+		return model.DedupReport{}, err
+	}
+	defer cur.Close(ctx)
+
+	var report model.DedupReport
+	if cur.Next(ctx) {
+		if err := cur.Decode(&report); err != nil {
+			return model.DedupReport{}, err
+		}
+	}
+	report.SavedBytes = report.LogicalBytes - report.StoredBytes
+	return report, cur.Err()
+}
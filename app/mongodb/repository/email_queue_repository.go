@@ -0,0 +1,114 @@
+package repository
+
+import (
+	"alumni-app/app/mongodb/model"
+	"alumni-app/config"
+	"context"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type EmailQueueRepositoryInterface interface {
+	EnqueueMany(ctx context.Context, jobs []model.EmailJob) error
+	ClaimNext(ctx context.Context, now time.Time, lease time.Duration) (model.EmailJob, error)
+	MarkSent(ctx context.Context, id primitive.ObjectID) error
+	MarkRetry(ctx context.Context, id primitive.ObjectID, next time.Time, lastErr string) error
+	MarkFailed(ctx context.Context, id primitive.ObjectID, lastErr string) error
+}
+
+type EmailQueueRepository struct {
+	Col      *mongo.Collection
+	Timeouts config.DBTimeoutConfig
+}
+
+func NewEmailQueueRepository(db *mongo.Database, timeouts config.DBTimeoutConfig) EmailQueueRepositoryInterface {
+	return &EmailQueueRepository{
+		Col:      db.Collection("email_queue"),
+		Timeouts: timeouts,
+	}
+}
+
+func (r *EmailQueueRepository) EnqueueMany(ctx context.Context, jobs []model.EmailJob) error {
+	if len(jobs) == 0 {
+		return nil
+	}
+	ctx, cancel := withTimeout(ctx, r.Timeouts.Scan)
+	defer cancel()
+
+	now := time.Now()
+	docs := make([]interface{}, len(jobs))
+	for i := range jobs {
+		jobs[i].ID = primitive.NewObjectID()
+		jobs[i].Status = model.EmailQueued
+		jobs[i].NextAttemptAt = now
+		jobs[i].CreatedAt = now
+		docs[i] = jobs[i]
+	}
+	_, err := r.Col.InsertMany(ctx, docs)
+	return err
+}
+
+// ClaimNext mengambil satu email yang sudah waktunya dikirim dan menguncinya selama lease.
+// Email berstatus sending yang kuncinya habis (worker mati di tengah jalan) ikut diambil ulang.
+// Mengembalikan mongo.ErrNoDocuments jika antrian kosong.
+func (r *EmailQueueRepository) ClaimNext(ctx context.Context, now time.Time, lease time.Duration) (model.EmailJob, error) {
+	ctx, cancel := withTimeout(ctx, r.Timeouts.Write)
+	defer cancel()
+
+	filter := bson.M{"$or": bson.A{
+		bson.M{"status": model.EmailQueued, "next_attempt_at": bson.M{"$lte": now}},
+		bson.M{"status": model.EmailSending, "locked_until": bson.M{"$lte": now}},
+	}}
+	update := bson.M{
+		"$set": bson.M{"status": model.EmailSending, "locked_until": now.Add(lease)},
+		"$inc": bson.M{"attempts": 1},
+	}
+	opts := options.FindOneAndUpdate().
+		SetSort(bson.D{{Key: "next_attempt_at", Value: 1}}).
+		SetReturnDocument(options.After)
+
+	var job model.EmailJob
+	err := r.Col.FindOneAndUpdate(ctx, filter, update, opts).Decode(&job)
+	return job, err
+}
+
+func (r *EmailQueueRepository) MarkSent(ctx context.Context, id primitive.ObjectID) error {
+	return r.finish(ctx, id, bson.M{
+		"$set":   bson.M{"status": model.EmailSent, "sent_at": time.Now()},
+		"$unset": bson.M{"locked_until": "", "last_error": ""},
+	})
+}
+
+// MarkRetry mengembalikan email ke antrian untuk dicoba lagi pada waktu next
+func (r *EmailQueueRepository) MarkRetry(ctx context.Context, id primitive.ObjectID, next time.Time, lastErr string) error {
+	return r.finish(ctx, id, bson.M{
+		"$set":   bson.M{"status": model.EmailQueued, "next_attempt_at": next, "last_error": lastErr},
+		"$unset": bson.M{"locked_until": ""},
+	})
+}
+
+// MarkFailed menghentikan percobaan kirim setelah batas percobaan habis
+func (r *EmailQueueRepository) MarkFailed(ctx context.Context, id primitive.ObjectID, lastErr string) error {
+	return r.finish(ctx, id, bson.M{
+		"$set":   bson.M{"status": model.EmailFailed, "last_error": lastErr},
+		"$unset": bson.M{"locked_until": ""},
+	})
+}
+
+func (r *EmailQueueRepository) finish(ctx context.Context, id primitive.ObjectID, update bson.M) error {
+	ctx, cancel := withTimeout(ctx, r.Timeouts.Write)
+	defer cancel()
+
+	res, err := r.Col.UpdateOne(ctx, bson.M{"_id": id, "status": model.EmailSending}, update)
+	if err != nil {
+		return err
+	}
+	if res.MatchedCount == 0 {
+		return mongo.ErrNoDocuments
+	}
+	return nil
+}
@@ -0,0 +1,66 @@
+package repository
+
+import (
+	"errors"
+	"regexp"
+
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// ErrVersionConflict dikembalikan Update jika versi data sudah diubah request lain
+var ErrVersionConflict = errors.New("version conflict")
+
+// caseInsensitive adalah collation index unik username, email, dan nim (strength 2 = abaikan huruf besar/kecil).
+// Query harus memakai collation yang sama agar index tersebut terpakai.
+var caseInsensitive = &options.Collation{Locale: "en", Strength: 2}
+
+// ErrConflict adalah induk semua *ConflictError; pakai errors.Is(err, ErrConflict)
+var ErrConflict = errors.New("duplicate value")
+
+// ConflictError dikembalikan Create/Update jika nilai bentrok dengan index unik
+// (username, email, nim, klaim alumni pending, jawaban kuesioner, minat lowongan). Field adalah nama field yang bentrok.
+type ConflictError struct {
+	Field string
+}
+
+func (e *ConflictError) Error() string {
+	return e.Field + " already exists"
+}
+
+func (e *ConflictError) Unwrap() error {
+	return ErrConflict
+}
+
+// uniqueIndexField memetakan nama index unik (lihat database/mongodb/migrations.go) ke field-nya
+var uniqueIndexField = map[string]string{
+	"username_ci":   "username",
+	"email_ci":      "email",
+	"nim_ci":        "nim",
+	"username_1":    "username",
+	"nim_1":         "nim",
+	"user_id_1":     "user_id",
+	"claim_pending": "claim",
+
+	"notification_template_key": "key",
+	"survey_response_alumni":    "response",
+	"lowongan_interest_alumni":  "interest",
+}
+
+var dupKeyIndex = regexp.MustCompile(`index: (\S+) dup key`)
+
+// translateDuplicate mengubah duplicate key error MongoDB (E11000) menjadi *ConflictError;
+// error lain dikembalikan apa adanya
+func translateDuplicate(err error) error {
+	if err == nil || !mongo.IsDuplicateKeyError(err) {
+		return err
+	}
+	field := "unknown"
+	if m := dupKeyIndex.FindStringSubmatch(err.Error()); m != nil {
+		field = m[1]
+		if f, ok := uniqueIndexField[m[1]]; ok {
+			field = f
+		}
+	}
+	return &ConflictError{Field: field}
+}
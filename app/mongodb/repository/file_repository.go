@@ -3,29 +3,37 @@ package repository
 import (
 	"context"
 	"alumni-app/app/mongodb/model"
+	"alumni-app/config"
 	"time"
 	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/bson/primitive"
 	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
 )
 
 type FileRepository interface {
-	Create(file *model.File) error
-	FindAll() ([]model.File, error)
-	FindByID(id string) (*model.File, error)
-	Delete(id string) error
+	Create(ctx context.Context, file *model.File) error
+	FindAll(ctx context.Context) ([]model.File, error)
+	Search(ctx context.Context, filter model.FileFilter, page, limit int) ([]model.File, error)
+	Count(ctx context.Context, filter model.FileFilter) (int64, error)
+	FindByID(ctx context.Context, id string) (*model.File, error)
+	FindByAlumniID(ctx context.Context, alumniID primitive.ObjectID) ([]model.File, error)
+	UpdateStatus(ctx context.Context, id primitive.ObjectID, status, scanResult string) error
+	UpdateReview(ctx context.Context, id primitive.ObjectID, review model.CertificateReview) error
+	Delete(ctx context.Context, id string) error
 }
 
 type fileRepository struct {
 	collection *mongo.Collection
+	timeouts   config.DBTimeoutConfig
 }
 
-func NewFileRepository(db *mongo.Database) FileRepository {
-	return &fileRepository{collection: db.Collection("files")}
+func NewFileRepository(db *mongo.Database, timeouts config.DBTimeoutConfig) FileRepository {
+	return &fileRepository{collection: db.Collection("files"), timeouts: timeouts}
 }
 
-func (r *fileRepository) Create(file *model.File) error {
-	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+func (r *fileRepository) Create(ctx context.Context, file *model.File) error {
+	ctx, cancel := withTimeout(ctx, r.timeouts.Write)
 	defer cancel()
 	file.UploadedAt = time.Now()
 	result, err := r.collection.InsertOne(ctx, file)
@@ -36,8 +44,8 @@ func (r *fileRepository) Create(file *model.File) error {
 	return nil
 }
 
-func (r *fileRepository) FindAll() ([]model.File, error) {
-	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+func (r *fileRepository) FindAll(ctx context.Context) ([]model.File, error) {
+	ctx, cancel := withTimeout(ctx, r.timeouts.Scan)
 	defer cancel()
 
 	var files []model.File
@@ -53,8 +61,82 @@ func (r *fileRepository) FindAll() ([]model.File, error) {
 	return files, nil
 }
 
-func (r *fileRepository) FindByID(id string) (*model.File, error) {
-	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+func (r *fileRepository) Search(ctx context.Context, filter model.FileFilter, page, limit int) ([]model.File, error) {
+	ctx, cancel := withTimeout(ctx, r.timeouts.Read)
+	defer cancel()
+
+	opts := options.Find().
+		SetSort(bson.D{{Key: "uploaded_at", Value: -1}}).
+		SetSkip(int64((page - 1) * limit)).
+		SetLimit(int64(limit))
+
+	cursor, err := r.collection.Find(ctx, fileFilterQuery(filter), opts)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	files := []model.File{}
+	if err := cursor.All(ctx, &files); err != nil {
+		return nil, err
+	}
+	return files, nil
+}
+
+func (r *fileRepository) Count(ctx context.Context, filter model.FileFilter) (int64, error) {
+	ctx, cancel := withTimeout(ctx, r.timeouts.Read)
+	defer cancel()
+	return r.collection.CountDocuments(ctx, fileFilterQuery(filter))
+}
+
+func fileFilterQuery(f model.FileFilter) bson.M {
+	q := bson.M{}
+	if f.AlumniIDs != nil {
+		q["alumni_id"] = bson.M{"$in": f.AlumniIDs}
+	}
+	switch f.Type {
+	case "":
+	case "foto":
+		q["file_type"] = bson.M{"$regex": "^image/"}
+	case "sertifikat":
+		q["file_type"] = "application/pdf"
+	default:
+		q["file_type"] = f.Type
+	}
+
+	if f.Status != "" {
+		q["status"] = f.Status
+	}
+	if f.Review != "" {
+		q["review.status"] = f.Review
+	}
+
+	uploaded := bson.M{}
+	if f.From != nil {
+		uploaded["$gte"] = *f.From
+	}
+	if f.To != nil {
+		uploaded["$lt"] = *f.To
+	}
+	if len(uploaded) > 0 {
+		q["uploaded_at"] = uploaded
+	}
+
+	size := bson.M{}
+	if f.MinSize > 0 {
+		size["$gte"] = f.MinSize
+	}
+	if f.MaxSize > 0 {
+		size["$lte"] = f.MaxSize
+	}
+	if len(size) > 0 {
+		q["file_size"] = size
+	}
+	return q
+}
+
+func (r *fileRepository) FindByID(ctx context.Context, id string) (*model.File, error) {
+	ctx, cancel := withTimeout(ctx, r.timeouts.Read)
 	defer cancel()
 
 	objID, err := primitive.ObjectIDFromHex(id)
@@ -69,8 +151,67 @@ func (r *fileRepository) FindByID(id string) (*model.File, error) {
 	return &file, nil
 }
 
-func (r *fileRepository) Delete(id string) error {
-	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+func (r *fileRepository) FindByAlumniID(ctx context.Context, alumniID primitive.ObjectID) ([]model.File, error) {
+	ctx, cancel := withTimeout(ctx, r.timeouts.Read)
+	defer cancel()
+
+	var files []model.File
+	cursor, err := r.collection.Find(ctx, bson.M{"alumni_id": alumniID})
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	if err := cursor.All(ctx, &files); err != nil {
+		return nil, err
+	}
+	return files, nil
+}
+
+func (r *fileRepository) UpdateStatus(ctx context.Context, id primitive.ObjectID, status, scanResult string) error {
+	ctx, cancel := withTimeout(ctx, r.timeouts.Write)
+	defer cancel()
+
+	update := bson.M{"$set": bson.M{
+		"status":      status,
+		"scan_result": scanResult,
+		"scanned_at":  time.Now(),
+	}}
+	// salinan asli hanya disimpan selama file menunggu pemindaian
+	if status != model.FileStatusQuarantine {
+		update["$unset"] = bson.M{"source_key": ""}
+	}
+	res, err := r.collection.UpdateOne(ctx, bson.M{"_id": id}, update)
+	if err != nil {
+		return err
+	}
+	if res.MatchedCount == 0 {
+		return mongo.ErrNoDocuments
+	}
+	return nil
+}
+
+// UpdateReview menyimpan keputusan verifikasi; hanya berhasil jika sertifikat masih pending
+// sehingga dua reviewer tidak bisa memutus sertifikat yang sama
+func (r *fileRepository) UpdateReview(ctx context.Context, id primitive.ObjectID, review model.CertificateReview) error {
+	ctx, cancel := withTimeout(ctx, r.timeouts.Write)
+	defer cancel()
+
+	res, err := r.collection.UpdateOne(ctx,
+		bson.M{"_id": id, "review.status": model.ReviewPending},
+		bson.M{"$set": bson.M{"review": review}},
+	)
+	if err != nil {
+		return err
+	}
+	if res.MatchedCount == 0 {
+		return mongo.ErrNoDocuments
+	}
+	return nil
+}
+
+func (r *fileRepository) Delete(ctx context.Context, id string) error {
+	ctx, cancel := withTimeout(ctx, r.timeouts.Write)
 	defer cancel()
 
 	objID, err := primitive.ObjectIDFromHex(id)
@@ -0,0 +1,106 @@
+package repository
+
+import (
+	"alumni-app/app/mongodb/model"
+	"alumni-app/config"
+	"context"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type LowonganInterestRepositoryInterface interface {
+	Create(ctx context.Context, interest *model.LowonganInterest) error
+	Delete(ctx context.Context, lowonganID, alumniID primitive.ObjectID) error
+	DeleteByLowongan(ctx context.Context, lowonganID primitive.ObjectID) (int64, error)
+	FindByLowongan(ctx context.Context, lowonganID primitive.ObjectID) ([]model.LowonganInterest, error)
+	FindByAlumni(ctx context.Context, alumniID primitive.ObjectID) ([]model.LowonganInterest, error)
+	CountByLowongan(ctx context.Context, lowonganID primitive.ObjectID) (int64, error)
+}
+
+type LowonganInterestRepository struct {
+	Col      *mongo.Collection
+	Timeouts config.DBTimeoutConfig
+}
+
+func NewLowonganInterestRepository(db *mongo.Database, timeouts config.DBTimeoutConfig) LowonganInterestRepositoryInterface {
+	return &LowonganInterestRepository{
+		Col:      db.Collection("lowongan_interests"),
+		Timeouts: timeouts,
+	}
+}
+
+// Create mencatat minat alumni; minat kedua untuk lowongan yang sama gagal dengan
+// *ConflictError{Field: "interest"}
+func (r *LowonganInterestRepository) Create(ctx context.Context, interest *model.LowonganInterest) error {
+	ctx, cancel := withTimeout(ctx, r.Timeouts.Write)
+	defer cancel()
+
+	interest.ID = primitive.NewObjectID()
+	interest.CreatedAt = time.Now()
+
+	_, err := r.Col.InsertOne(ctx, interest)
+	return translateDuplicate(err)
+}
+
+func (r *LowonganInterestRepository) Delete(ctx context.Context, lowonganID, alumniID primitive.ObjectID) error {
+	ctx, cancel := withTimeout(ctx, r.Timeouts.Write)
+	defer cancel()
+
+	res, err := r.Col.DeleteOne(ctx, bson.M{"lowongan_id": lowonganID, "alumni_id": alumniID})
+	if err != nil {
+		return err
+	}
+	if res.DeletedCount == 0 {
+		return mongo.ErrNoDocuments
+	}
+	return nil
+}
+
+func (r *LowonganInterestRepository) DeleteByLowongan(ctx context.Context, lowonganID primitive.ObjectID) (int64, error) {
+	ctx, cancel := withTimeout(ctx, r.Timeouts.Write)
+	defer cancel()
+
+	res, err := r.Col.DeleteMany(ctx, bson.M{"lowongan_id": lowonganID})
+	if err != nil {
+		return 0, err
+	}
+	return res.DeletedCount, nil
+}
+
+// FindByLowongan mengambil peminat lowongan, yang lebih dulu mendaftar lebih dulu
+func (r *LowonganInterestRepository) FindByLowongan(ctx context.Context, lowonganID primitive.ObjectID) ([]model.LowonganInterest, error) {
+	return r.find(ctx, bson.M{"lowongan_id": lowonganID}, 1)
+}
+
+// FindByAlumni mengambil lowongan yang diminati alumni, terbaru lebih dulu
+func (r *LowonganInterestRepository) FindByAlumni(ctx context.Context, alumniID primitive.ObjectID) ([]model.LowonganInterest, error) {
+	return r.find(ctx, bson.M{"alumni_id": alumniID}, -1)
+}
+
+func (r *LowonganInterestRepository) CountByLowongan(ctx context.Context, lowonganID primitive.ObjectID) (int64, error) {
+	ctx, cancel := withTimeout(ctx, r.Timeouts.Read)
+	defer cancel()
+
+	return r.Col.CountDocuments(ctx, bson.M{"lowongan_id": lowonganID})
+}
+
+func (r *LowonganInterestRepository) find(ctx context.Context, filter bson.M, order int) ([]model.LowonganInterest, error) {
+	ctx, cancel := withTimeout(ctx, r.Timeouts.Read)
+	defer cancel()
+
+	cur, err := r.Col.Find(ctx, filter, options.Find().SetSort(bson.D{{Key: "created_at", Value: order}}))
+	if err != nil {
+		return nil, err
+	}
+	defer cur.Close(ctx)
+
+	list := []model.LowonganInterest{}
+	if err := cur.All(ctx, &list); err != nil {
+		return nil, err
+	}
+	return list, nil
+}
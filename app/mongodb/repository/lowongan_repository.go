@@ -0,0 +1,219 @@
+package repository
+
+import (
+	"alumni-app/app/mongodb/model"
+	"alumni-app/config"
+	"context"
+	"regexp"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type LowonganRepositoryInterface interface {
+	GetAll(ctx context.Context, filter model.LowonganFilter, sortBy, order string, page, limit int) ([]model.Lowongan, error)
+	Count(ctx context.Context, filter model.LowonganFilter) (int64, error)
+	GetByID(ctx context.Context, id primitive.ObjectID) (model.Lowongan, error)
+	Create(ctx context.Context, l *model.Lowongan) error
+	Update(ctx context.Context, id primitive.ObjectID, l *model.Lowongan) error
+	Delete(ctx context.Context, id primitive.ObjectID) error
+	ExpireBefore(ctx context.Context, cutoff time.Time) (int64, error)
+	Distinct(ctx context.Context, field string) ([]string, error)
+}
+
+type LowonganRepository struct {
+	Col      *mongo.Collection
+	Timeouts config.DBTimeoutConfig
+}
+
+func NewLowonganRepository(db *mongo.Database, timeouts config.DBTimeoutConfig) LowonganRepositoryInterface {
+	return &LowonganRepository{
+		Col:      db.Collection("lowongan"),
+		Timeouts: timeouts,
+	}
+}
+
+func (r *LowonganRepository) GetAll(ctx context.Context, filter model.LowonganFilter, sortBy, order string, page, limit int) ([]model.Lowongan, error) {
+	ctx, cancel := withTimeout(ctx, r.Timeouts.Read)
+	defer cancel()
+
+	sortOrder := 1
+	if order == "desc" {
+		sortOrder = -1
+	}
+	opts := options.Find().
+		SetSort(bson.D{{Key: sortBy, Value: sortOrder}, {Key: "_id", Value: sortOrder}}).
+		SetSkip(int64((page - 1) * limit)).
+		SetLimit(int64(limit))
+
+	cur, err := r.Col.Find(ctx, lowonganFilterQuery(filter, time.Now()), opts)
+	if err != nil {
+		return nil, err
+	}
+	defer cur.Close(ctx)
+
+	list := []model.Lowongan{}
+	if err := cur.All(ctx, &list); err != nil {
+		return nil, err
+	}
+	return list, nil
+}
+
+func (r *LowonganRepository) Count(ctx context.Context, filter model.LowonganFilter) (int64, error) {
+	ctx, cancel := withTimeout(ctx, r.Timeouts.Read)
+	defer cancel()
+
+	return r.Col.CountDocuments(ctx, lowonganFilterQuery(filter, time.Now()))
+}
+
+func (r *LowonganRepository) GetByID(ctx context.Context, id primitive.ObjectID) (model.Lowongan, error) {
+	ctx, cancel := withTimeout(ctx, r.Timeouts.Read)
+	defer cancel()
+
+	var l model.Lowongan
+	err := r.Col.FindOne(ctx, bson.M{"_id": id}).Decode(&l)
+	return l, err
+}
+
+func (r *LowonganRepository) Create(ctx context.Context, l *model.Lowongan) error {
+	ctx, cancel := withTimeout(ctx, r.Timeouts.Write)
+	defer cancel()
+
+	l.ID = primitive.NewObjectID()
+	l.CreatedAt = time.Now()
+	l.UpdatedAt = l.CreatedAt
+
+	_, err := r.Col.InsertOne(ctx, l)
+	return err
+}
+
+func (r *LowonganRepository) Update(ctx context.Context, id primitive.ObjectID, l *model.Lowongan) error {
+	ctx, cancel := withTimeout(ctx, r.Timeouts.Write)
+	defer cancel()
+
+	l.UpdatedAt = time.Now()
+	res, err := r.Col.UpdateOne(ctx, bson.M{"_id": id}, bson.M{"$set": bson.M{
+		"nama_perusahaan":     l.NamaPerusahaan,
+		"posisi_jabatan":      l.PosisiJabatan,
+		"bidang_industri":     l.BidangIndustri,
+		"lokasi_kerja":        l.LokasiKerja,
+		"gaji_min":            l.GajiMin,
+		"gaji_max":            l.GajiMax,
+		"deskripsi_pekerjaan": l.DeskripsiPekerjaan,
+		"kontak_lamaran":      l.KontakLamaran,
+		"batas_lamaran":       l.BatasLamaran,
+		"status":              l.Status,
+		"updated_at":          l.UpdatedAt,
+	}})
+	if err != nil {
+		return err
+	}
+	if res.MatchedCount == 0 {
+		return mongo.ErrNoDocuments
+	}
+	return nil
+}
+
+func (r *LowonganRepository) Delete(ctx context.Context, id primitive.ObjectID) error {
+	ctx, cancel := withTimeout(ctx, r.Timeouts.Write)
+	defer cancel()
+
+	res, err := r.Col.DeleteOne(ctx, bson.M{"_id": id})
+	if err != nil {
+		return err
+	}
+	if res.DeletedCount == 0 {
+		return mongo.ErrNoDocuments
+	}
+	return nil
+}
+
+// ExpireBefore menandai lowongan open dengan batas lamaran sebelum cutoff sebagai expired
+func (r *LowonganRepository) ExpireBefore(ctx context.Context, cutoff time.Time) (int64, error) {
+	ctx, cancel := withTimeout(ctx, r.Timeouts.Scan)
+	defer cancel()
+
+	res, err := r.Col.UpdateMany(ctx,
+		bson.M{"status": model.LowonganOpen, "batas_lamaran": bson.M{"$lt": cutoff}},
+		bson.M{"$set": bson.M{"status": model.LowonganExpired, "updated_at": time.Now()}},
+	)
+	if err != nil {
+		return 0, err
+	}
+	return res.ModifiedCount, nil
+}
+
+// Distinct mengambil nilai unik sebuah field (mis. bidang_industri), tanpa nilai kosong
+func (r *LowonganRepository) Distinct(ctx context.Context, field string) ([]string, error) {
+	ctx, cancel := withTimeout(ctx, r.Timeouts.Scan)
+	defer cancel()
+
+	return distinctStrings(ctx, r.Col, field, bson.M{})
+}
+
+// lowonganFilterQuery menerjemahkan filter; status open hanya mencakup lowongan yang batas lamarannya
+// belum lewat, sedangkan expired juga mencakup lowongan open yang belum sempat ditandai worker
+func lowonganFilterQuery(f model.LowonganFilter, now time.Time) bson.M {
+	filter := bson.M{}
+	var and []bson.M
+
+	if f.Search != "" {
+		search := bson.M{"$regex": regexp.QuoteMeta(f.Search), "$options": "i"}
+		and = append(and, bson.M{"$or": []bson.M{
+			{"nama_perusahaan": search},
+			{"posisi_jabatan": search},
+			{"bidang_industri": search},
+			{"deskripsi_pekerjaan": search},
+		}})
+	}
+	if f.BidangIndustri != "" {
+		filter["bidang_industri"] = bson.M{"$regex": "^" + regexp.QuoteMeta(f.BidangIndustri) + "$", "$options": "i"}
+	}
+	if f.LokasiKerja != "" {
+		filter["lokasi_kerja"] = bson.M{"$regex": "^" + regexp.QuoteMeta(f.LokasiKerja) + "$", "$options": "i"}
+	}
+	if f.GajiMin > 0 {
+		filter["gaji_max"] = bson.M{"$gte": f.GajiMin}
+	}
+	if !f.PostedBy.IsZero() {
+		filter["posted_by"] = f.PostedBy
+	}
+
+	today := now.UTC().Truncate(24 * time.Hour)
+	switch f.Status {
+	case "", model.LowonganOpen:
+		filter["status"] = model.LowonganOpen
+		filter["batas_lamaran"] = bson.M{"$gte": today}
+	case model.LowonganExpired:
+		and = append(and, bson.M{"$or": []bson.M{
+			{"status": model.LowonganExpired},
+			{"status": model.LowonganOpen, "batas_lamaran": bson.M{"$lt": today}},
+		}})
+	case "all":
+	default:
+		filter["status"] = f.Status
+	}
+
+	if len(and) > 0 {
+		filter["$and"] = and
+	}
+	return filter
+}
+
+// distinctStrings mengambil nilai string unik field pada dokumen yang cocok dengan filter
+func distinctStrings(ctx context.Context, col *mongo.Collection, field string, filter bson.M) ([]string, error) {
+	values, err := col.Distinct(ctx, field, filter)
+	if err != nil {
+		return nil, err
+	}
+	list := []string{}
+	for _, v := range values {
+		if s, ok := v.(string); ok && s != "" {
+			list = append(list, s)
+		}
+	}
+	return list, nil
+}
@@ -0,0 +1,84 @@
+package repository
+
+import (
+	"alumni-app/app/mongodb/model"
+	realrepo "alumni-app/app/mongodb/repository"
+	"context"
+	"errors"
+	"sort"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+type MockAlumniClaimRepository struct {
+	Data map[string]model.AlumniClaim
+}
+
+func NewMockAlumniClaimRepository() *MockAlumniClaimRepository {
+	return &MockAlumniClaimRepository{
+		Data: make(map[string]model.AlumniClaim),
+	}
+}
+
+func (m *MockAlumniClaimRepository) Create(ctx context.Context, claim *model.AlumniClaim) error {
+	if claim.Status == model.ClaimPending {
+		for _, c := range m.Data {
+			if c.Status == model.ClaimPending && c.UserID == claim.UserID && c.AlumniID == claim.AlumniID {
+				return &realrepo.ConflictError{Field: "claim"}
+			}
+		}
+	}
+	claim.ID = primitive.NewObjectID()
+	claim.CreatedAt = time.Now()
+	m.Data[claim.ID.Hex()] = *claim
+	return nil
+}
+
+func (m *MockAlumniClaimRepository) GetByID(ctx context.Context, id primitive.ObjectID) (model.AlumniClaim, error) {
+	c, ok := m.Data[id.Hex()]
+	if !ok {
+		return model.AlumniClaim{}, errors.New("not found")
+	}
+	return c, nil
+}
+
+func (m *MockAlumniClaimRepository) Find(ctx context.Context, userID primitive.ObjectID, status string, page, limit int) ([]model.AlumniClaim, error) {
+	list := []model.AlumniClaim{}
+	for _, c := range m.Data {
+		if (userID.IsZero() || c.UserID == userID) && (status == "" || c.Status == status) {
+			list = append(list, c)
+		}
+	}
+	sort.Slice(list, func(i, j int) bool { return list[i].CreatedAt.After(list[j].CreatedAt) })
+
+	start := (page - 1) * limit
+	if start >= len(list) {
+		return []model.AlumniClaim{}, nil
+	}
+	end := start + limit
+	if end > len(list) {
+		end = len(list)
+	}
+	return list[start:end], nil
+}
+
+func (m *MockAlumniClaimRepository) Count(ctx context.Context, userID primitive.ObjectID, status string) (int64, error) {
+	list, _ := m.Find(ctx, userID, status, 1, len(m.Data)+1)
+	return int64(len(list)), nil
+}
+
+func (m *MockAlumniClaimRepository) Decide(ctx context.Context, id primitive.ObjectID, status string, reviewerID primitive.ObjectID, notes string) error {
+	c, ok := m.Data[id.Hex()]
+	if !ok || c.Status != model.ClaimPending {
+		return mongo.ErrNoDocuments
+	}
+	now := time.Now()
+	c.Status = status
+	c.Notes = notes
+	c.ReviewerID = &reviewerID
+	c.ReviewedAt = &now
+	m.Data[id.Hex()] = c
+	return nil
+}
@@ -1,10 +1,14 @@
 package repository
 
 import (
+	"context"
 	"errors"
+	"strings"
 	"time"
 	"alumni-app/app/mongodb/model"
+	realrepo "alumni-app/app/mongodb/repository"
 	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
 )
 
 type MockAlumniRepository struct {
@@ -17,19 +21,40 @@ func NewMockAlumniRepository() *MockAlumniRepository {
 	}
 }
 
-func (m *MockAlumniRepository) GetAll(search, sortBy, order string, page, limit int) ([]model.Alumni, error) {
+func (m *MockAlumniRepository) GetAll(ctx context.Context, filter model.AlumniFilter, sortBy, order string, page, limit int) ([]model.Alumni, error) {
+	return m.FindByFilter(ctx, filter)
+}
+
+func (m *MockAlumniRepository) Count(ctx context.Context, filter model.AlumniFilter) (int64, error) {
+	list, _ := m.FindByFilter(ctx, filter)
+	return int64(len(list)), nil
+}
+
+func (m *MockAlumniRepository) FindByFilter(ctx context.Context, filter model.AlumniFilter) ([]model.Alumni, error) {
 	var list []model.Alumni
 	for _, a := range m.Data {
+		if a.DeletedAt != nil ||
+			(filter.Jurusan != "" && !strings.EqualFold(a.Jurusan, filter.Jurusan)) ||
+			(filter.Angkatan != 0 && a.Angkatan != filter.Angkatan) ||
+			(filter.TahunLulus != 0 && a.TahunLulus != filter.TahunLulus) {
+			continue
+		}
 		list = append(list, a)
 	}
 	return list, nil
 }
 
-func (m *MockAlumniRepository) Count(search string) (int64, error) {
-	return int64(len(m.Data)), nil
+func (m *MockAlumniRepository) FindBySurveyTarget(ctx context.Context, target model.SurveyTarget) ([]model.Alumni, error) {
+	var list []model.Alumni
+	for _, a := range m.Data {
+		if a.DeletedAt == nil && target.Matches(a) {
+			list = append(list, a)
+		}
+	}
+	return list, nil
 }
 
-func (m *MockAlumniRepository) GetByID(id primitive.ObjectID) (model.Alumni, error) {
+func (m *MockAlumniRepository) GetByID(ctx context.Context, id primitive.ObjectID) (model.Alumni, error) {
 	a, ok := m.Data[id.Hex()]
 	if !ok {
 		return model.Alumni{}, errors.New("not found")
@@ -37,7 +62,7 @@ func (m *MockAlumniRepository) GetByID(id primitive.ObjectID) (model.Alumni, err
 	return a, nil
 }
 
-func (m *MockAlumniRepository) GetByUserID(userID primitive.ObjectID) (model.Alumni, error) {
+func (m *MockAlumniRepository) GetByUserID(ctx context.Context, userID primitive.ObjectID) (model.Alumni, error) {
 	for _, a := range m.Data {
 		if a.UserID == userID {
 			return a, nil
@@ -46,7 +71,16 @@ func (m *MockAlumniRepository) GetByUserID(userID primitive.ObjectID) (model.Alu
 	return model.Alumni{}, errors.New("not found")
 }
 
-func (m *MockAlumniRepository) GetAllByUserID(userID primitive.ObjectID) ([]model.Alumni, error) {
+func (m *MockAlumniRepository) GetByNIM(ctx context.Context, nim string) (model.Alumni, error) {
+	for _, a := range m.Data {
+		if a.DeletedAt == nil && strings.EqualFold(a.NIM, nim) {
+			return a, nil
+		}
+	}
+	return model.Alumni{}, errors.New("not found")
+}
+
+func (m *MockAlumniRepository) GetAllByUserID(ctx context.Context, userID primitive.ObjectID) ([]model.Alumni, error) {
 	var list []model.Alumni
 	for _, a := range m.Data {
 		if a.UserID == userID {
@@ -56,21 +90,33 @@ func (m *MockAlumniRepository) GetAllByUserID(userID primitive.ObjectID) ([]mode
 	return list, nil
 }
 
-func (m *MockAlumniRepository) Create(a *model.Alumni) error {
+func (m *MockAlumniRepository) Create(ctx context.Context, a *model.Alumni) error {
+	for _, existing := range m.Data {
+		if strings.EqualFold(existing.NIM, a.NIM) {
+			return &realrepo.ConflictError{Field: "nim"}
+		}
+	}
+	if a.ID.IsZero() {
+		a.ID = primitive.NewObjectID()
+	}
 	m.Data[a.ID.Hex()] = *a
 	return nil
 }
 
-func (m *MockAlumniRepository) Update(id primitive.ObjectID, a *model.Alumni) error {
-	_, ok := m.Data[id.Hex()]
+func (m *MockAlumniRepository) Update(ctx context.Context, id primitive.ObjectID, a *model.Alumni) error {
+	before, ok := m.Data[id.Hex()]
 	if !ok {
 		return errors.New("not found")
 	}
+	if before.Version != a.Version {
+		return realrepo.ErrVersionConflict
+	}
+	a.Version++
 	m.Data[id.Hex()] = *a
 	return nil
 }
 
-func (m *MockAlumniRepository) SoftDelete(id primitive.ObjectID) error {
+func (m *MockAlumniRepository) SoftDelete(ctx context.Context, id primitive.ObjectID) error {
 	a, ok := m.Data[id.Hex()]
 	if !ok {
 		return errors.New("not found")
@@ -81,3 +127,59 @@ func (m *MockAlumniRepository) SoftDelete(id primitive.ObjectID) error {
 	m.Data[id.Hex()] = a
 	return nil
 }
+
+func (m *MockAlumniRepository) Restore(ctx context.Context, id primitive.ObjectID) error {
+	a, ok := m.Data[id.Hex()]
+	if !ok {
+		return errors.New("not found")
+	}
+	a.DeletedAt = nil
+	m.Data[id.Hex()] = a
+	return nil
+}
+
+func (m *MockAlumniRepository) FindLegacyFoto(ctx context.Context) ([]model.Alumni, error) {
+	var list []model.Alumni
+	for _, a := range m.Data {
+		if strings.HasPrefix(strings.TrimPrefix(a.Foto, "/"), "uploads/foto/") {
+			list = append(list, a)
+		}
+	}
+	return list, nil
+}
+
+func (m *MockAlumniRepository) UpdateFileRef(ctx context.Context, id primitive.ObjectID, field, value string) error {
+	a, ok := m.Data[id.Hex()]
+	if !ok {
+		return errors.New("not found")
+	}
+	switch field {
+	case "foto":
+		a.Foto = value
+	case "sertifikat_path":
+		a.SertifikatPath = value
+	case "sertifikat_status":
+		a.SertifikatStatus = value
+	default:
+		return errors.New("invalid file field")
+	}
+	a.Version++
+	m.Data[id.Hex()] = a
+	return nil
+}
+
+func (m *MockAlumniRepository) LinkUser(ctx context.Context, id, userID, from primitive.ObjectID) error {
+	a, ok := m.Data[id.Hex()]
+	if !ok || a.DeletedAt != nil || a.UserID != from {
+		return mongo.ErrNoDocuments
+	}
+	for _, other := range m.Data {
+		if other.ID != id && other.UserID == userID {
+			return &realrepo.ConflictError{Field: "user_id"}
+		}
+	}
+	a.UserID = userID
+	a.Version++
+	m.Data[id.Hex()] = a
+	return nil
+}
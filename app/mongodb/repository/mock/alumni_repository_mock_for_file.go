@@ -2,6 +2,7 @@ package repository
 
 import (
 	"alumni-app/app/mongodb/model"
+	"context"
 	"go.mongodb.org/mongo-driver/bson/primitive"
 )
 
@@ -15,6 +16,6 @@ func NewMockAlumniRepoForFile() *MockAlumniRepoForFile {
 	}
 }
 
-func (m *MockAlumniRepoForFile) GetAllByUserID(userID primitive.ObjectID) ([]model.Alumni, error) {
+func (m *MockAlumniRepoForFile) GetAllByUserID(ctx context.Context, userID primitive.ObjectID) ([]model.Alumni, error) {
 	return m.data, nil
 }
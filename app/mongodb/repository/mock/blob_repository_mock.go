@@ -0,0 +1,81 @@
+package repository
+
+import (
+	"alumni-app/app/mongodb/model"
+	realrepo "alumni-app/app/mongodb/repository"
+	"context"
+	"errors"
+	"time"
+)
+
+type MockBlobRepository struct {
+	Data map[string]model.Blob
+}
+
+func NewMockBlobRepository() *MockBlobRepository {
+	return &MockBlobRepository{
+		Data: make(map[string]model.Blob),
+	}
+}
+
+func (m *MockBlobRepository) FindBySHA256(ctx context.Context, hash string) (model.Blob, error) {
+	b, ok := m.Data[hash]
+	if !ok {
+		return model.Blob{}, errors.New("not found")
+	}
+	return b, nil
+}
+
+func (m *MockBlobRepository) Acquire(ctx context.Context, hash, key string) (bool, error) {
+	existing, ok := m.Data[hash]
+	if !ok || existing.StorageKey != key {
+		return false, nil
+	}
+	existing.RefCount++
+	m.Data[hash] = existing
+	return true, nil
+}
+
+func (m *MockBlobRepository) Create(ctx context.Context, b *model.Blob) error {
+	if _, ok := m.Data[b.SHA256]; ok {
+		return &realrepo.ConflictError{Field: "sha256"}
+	}
+	b.RefCount = 1
+	b.CreatedAt = time.Now()
+	m.Data[b.SHA256] = *b
+	return nil
+}
+
+func (m *MockBlobRepository) Release(ctx context.Context, hash string) (int, error) {
+	b, ok := m.Data[hash]
+	if !ok {
+		return 0, errors.New("not found")
+	}
+	b.RefCount--
+	m.Data[hash] = b
+	return b.RefCount, nil
+}
+
+func (m *MockBlobRepository) DeleteIfUnreferenced(ctx context.Context, hash string) (bool, error) {
+	b, ok := m.Data[hash]
+	if !ok || b.RefCount > 0 {
+		return false, nil
+	}
+	delete(m.Data, hash)
+	return true, nil
+}
+
+func (m *MockBlobRepository) Stats(ctx context.Context) (model.DedupReport, error) {
+	var r model.DedupReport
+	for _, b := range m.Data {
+		if b.RefCount <= 0 {
+			continue
+		}
+		r.Blobs++
+		r.References += int64(b.RefCount)
+		r.StoredBytes += b.Size
+		r.LogicalBytes += b.Size * int64(b.RefCount)
+	}
+	r.SavedBytes = r.LogicalBytes - r.StoredBytes
+	return r, nil
+}
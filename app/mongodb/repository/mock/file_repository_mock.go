@@ -1,8 +1,13 @@
 package repository
 
 import (
+	"context"
 	"errors"
+	"strings"
+	"time"
 	"alumni-app/app/mongodb/model"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
 )
 
 type MockFileRepository struct {
@@ -17,23 +22,89 @@ func NewMockFileRepository() *MockFileRepository {
 	}
 }
 
-func (m *MockFileRepository) Create(file *model.File) error {
+func (m *MockFileRepository) Create(ctx context.Context, file *model.File) error {
 	if m.err != nil {
 		return m.err
 	}
-	file.ID = file.ID
+	if file.ID.IsZero() {
+		file.ID = primitive.NewObjectID()
+	}
 	m.files = append(m.files, *file)
 	return nil
 }
 
-func (m *MockFileRepository) FindAll() ([]model.File, error) {
+func (m *MockFileRepository) FindAll(ctx context.Context) ([]model.File, error) {
 	if m.err != nil {
 		return nil, m.err
 	}
 	return m.files, nil
 }
 
-func (m *MockFileRepository) FindByID(id string) (*model.File, error) {
+func (m *MockFileRepository) Search(ctx context.Context, filter model.FileFilter, page, limit int) ([]model.File, error) {
+	if m.err != nil {
+		return nil, m.err
+	}
+	list := m.filter(filter)
+	start := (page - 1) * limit
+	if start >= len(list) {
+		return []model.File{}, nil
+	}
+	end := start + limit
+	if end > len(list) {
+		end = len(list)
+	}
+	return list[start:end], nil
+}
+
+func (m *MockFileRepository) Count(ctx context.Context, filter model.FileFilter) (int64, error) {
+	if m.err != nil {
+		return 0, m.err
+	}
+	return int64(len(m.filter(filter))), nil
+}
+
+func (m *MockFileRepository) filter(f model.FileFilter) []model.File {
+	list := []model.File{}
+	for _, file := range m.files {
+		if f.AlumniIDs != nil && !containsID(f.AlumniIDs, file.AlumniID) {
+			continue
+		}
+		switch {
+		case f.Type == "foto" && !strings.HasPrefix(file.FileType, "image/"),
+			f.Type == "sertifikat" && file.FileType != "application/pdf",
+			f.Type != "" && f.Type != "foto" && f.Type != "sertifikat" && file.FileType != f.Type:
+			continue
+		}
+		if f.Status != "" && file.Status != f.Status {
+			continue
+		}
+		if f.Review != "" && (file.Review == nil || file.Review.Status != f.Review) {
+			continue
+		}
+		if f.From != nil && file.UploadedAt.Before(*f.From) {
+			continue
+		}
+		if f.To != nil && !file.UploadedAt.Before(*f.To) {
+			continue
+		}
+		if (f.MinSize > 0 && file.FileSize < f.MinSize) || (f.MaxSize > 0 && file.FileSize > f.MaxSize) {
+			continue
+		}
+		list = append(list, file)
+	}
+	return list
+}
+
+func containsID(ids []primitive.ObjectID, id primitive.ObjectID) bool {
+	for _, x := range ids {
+		if x == id {
+			return true
+		}
+	}
+	return false
+}
+
+func (m *MockFileRepository) FindByID(ctx context.Context, id string) (*model.File, error) {
 	if m.err != nil {
 		return nil, m.err
 	}
@@ -45,7 +116,52 @@ func (m *MockFileRepository) FindByID(id string) (*model.File, error) {
 	return nil, errors.New("not found")
 }
 
-func (m *MockFileRepository) Delete(id string) error {
+func (m *MockFileRepository) FindByAlumniID(ctx context.Context, alumniID primitive.ObjectID) ([]model.File, error) {
+	if m.err != nil {
+		return nil, m.err
+	}
+	var list []model.File
+	for _, f := range m.files {
+		if f.AlumniID == alumniID {
+			list = append(list, f)
+		}
+	}
+	return list, nil
+}
+
+func (m *MockFileRepository) UpdateStatus(ctx context.Context, id primitive.ObjectID, status, scanResult string) error {
+	if m.err != nil {
+		return m.err
+	}
+	for i, f := range m.files {
+		if f.ID == id {
+			now := time.Now()
+			m.files[i].Status = status
+			m.files[i].ScanResult = scanResult
+			m.files[i].ScannedAt = &now
+			if status != model.FileStatusQuarantine {
+				m.files[i].SourceKey = ""
+			}
+			return nil
+		}
+	}
+	return errors.New("not found")
+}
+
+func (m *MockFileRepository) UpdateReview(ctx context.Context, id primitive.ObjectID, review model.CertificateReview) error {
+	if m.err != nil {
+		return m.err
+	}
+	for i, f := range m.files {
+		if f.ID == id && f.Review != nil && f.Review.Status == model.ReviewPending {
+			m.files[i].Review = &review
+			return nil
+		}
+	}
+	return mongo.ErrNoDocuments
+}
+
+func (m *MockFileRepository) Delete(ctx context.Context, id string) error {
 	if m.err != nil {
 		return m.err
 	}
@@ -0,0 +1,227 @@
+package repository
+
+import (
+	"alumni-app/app/mongodb/model"
+	realrepo "alumni-app/app/mongodb/repository"
+	"context"
+	"sort"
+	"strings"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+type MockLowonganRepository struct {
+	Data map[string]model.Lowongan
+}
+
+func NewMockLowonganRepository() *MockLowonganRepository {
+	return &MockLowonganRepository{
+		Data: make(map[string]model.Lowongan),
+	}
+}
+
+// GetAll hanya mendukung sort created_at / batas_lamaran / gaji_max; cukup untuk pengujian
+func (m *MockLowonganRepository) GetAll(ctx context.Context, filter model.LowonganFilter, sortBy, order string, page, limit int) ([]model.Lowongan, error) {
+	list := m.filter(filter)
+	sort.Slice(list, func(i, j int) bool {
+		a, b := list[i], list[j]
+		var less bool
+		switch sortBy {
+		case "batas_lamaran":
+			less = a.BatasLamaran.Before(b.BatasLamaran)
+		case "gaji_max":
+			less = a.GajiMax < b.GajiMax
+		default:
+			less = a.CreatedAt.Before(b.CreatedAt)
+		}
+		if order == "desc" {
+			return !less
+		}
+		return less
+	})
+
+	start := (page - 1) * limit
+	if start >= len(list) {
+		return []model.Lowongan{}, nil
+	}
+	end := start + limit
+	if end > len(list) {
+		end = len(list)
+	}
+	return list[start:end], nil
+}
+
+func (m *MockLowonganRepository) Count(ctx context.Context, filter model.LowonganFilter) (int64, error) {
+	return int64(len(m.filter(filter))), nil
+}
+
+func (m *MockLowonganRepository) filter(f model.LowonganFilter) []model.Lowongan {
+	now := time.Now()
+	search := strings.ToLower(f.Search)
+	list := []model.Lowongan{}
+	for _, l := range m.Data {
+		if search != "" && !strings.Contains(strings.ToLower(l.NamaPerusahaan+"\n"+l.PosisiJabatan+"\n"+l.BidangIndustri+"\n"+l.DeskripsiPekerjaan), search) {
+			continue
+		}
+		if (f.BidangIndustri != "" && !strings.EqualFold(l.BidangIndustri, f.BidangIndustri)) ||
+			(f.LokasiKerja != "" && !strings.EqualFold(l.LokasiKerja, f.LokasiKerja)) ||
+			(f.GajiMin > 0 && l.GajiMax < f.GajiMin) ||
+			(!f.PostedBy.IsZero() && l.PostedBy != f.PostedBy) {
+			continue
+		}
+		expired := l.Status == model.LowonganExpired || (l.Status == model.LowonganOpen && l.Expired(now))
+		switch f.Status {
+		case "", model.LowonganOpen:
+			if l.Status != model.LowonganOpen || expired {
+				continue
+			}
+		case model.LowonganExpired:
+			if !expired {
+				continue
+			}
+		case "all":
+		default:
+			if l.Status != f.Status {
+				continue
+			}
+		}
+		list = append(list, l)
+	}
+	return list
+}
+
+func (m *MockLowonganRepository) GetByID(ctx context.Context, id primitive.ObjectID) (model.Lowongan, error) {
+	l, ok := m.Data[id.Hex()]
+	if !ok {
+		return model.Lowongan{}, mongo.ErrNoDocuments
+	}
+	return l, nil
+}
+
+func (m *MockLowonganRepository) Create(ctx context.Context, l *model.Lowongan) error {
+	l.ID = primitive.NewObjectID()
+	l.CreatedAt = time.Now()
+	l.UpdatedAt = l.CreatedAt
+	m.Data[l.ID.Hex()] = *l
+	return nil
+}
+
+func (m *MockLowonganRepository) Update(ctx context.Context, id primitive.ObjectID, l *model.Lowongan) error {
+	existing, ok := m.Data[id.Hex()]
+	if !ok {
+		return mongo.ErrNoDocuments
+	}
+	l.ID = existing.ID
+	l.PostedBy = existing.PostedBy
+	l.CreatedAt = existing.CreatedAt
+	l.UpdatedAt = time.Now()
+	m.Data[id.Hex()] = *l
+	return nil
+}
+
+func (m *MockLowonganRepository) Delete(ctx context.Context, id primitive.ObjectID) error {
+	if _, ok := m.Data[id.Hex()]; !ok {
+		return mongo.ErrNoDocuments
+	}
+	delete(m.Data, id.Hex())
+	return nil
+}
+
+func (m *MockLowonganRepository) ExpireBefore(ctx context.Context, cutoff time.Time) (int64, error) {
+	var n int64
+	for k, l := range m.Data {
+		if l.Status == model.LowonganOpen && l.BatasLamaran.Before(cutoff) {
+			l.Status = model.LowonganExpired
+			m.Data[k] = l
+			n++
+		}
+	}
+	return n, nil
+}
+
+func (m *MockLowonganRepository) Distinct(ctx context.Context, field string) ([]string, error) {
+	seen := map[string]bool{}
+	list := []string{}
+	for _, l := range m.Data {
+		v := l.BidangIndustri
+		if field == "lokasi_kerja" {
+			v = l.LokasiKerja
+		}
+		if v == "" || seen[v] {
+			continue
+		}
+		seen[v] = true
+		list = append(list, v)
+	}
+	return list, nil
+}
+
+type MockLowonganInterestRepository struct {
+	Data map[string]model.LowonganInterest // key = lowonganID + alumniID
+}
+
+func NewMockLowonganInterestRepository() *MockLowonganInterestRepository {
+	return &MockLowonganInterestRepository{
+		Data: make(map[string]model.LowonganInterest),
+	}
+}
+
+func (m *MockLowonganInterestRepository) Create(ctx context.Context, interest *model.LowonganInterest) error {
+	key := interest.LowonganID.Hex() + interest.AlumniID.Hex()
+	if _, ok := m.Data[key]; ok {
+		return &realrepo.ConflictError{Field: "interest"}
+	}
+	interest.ID = primitive.NewObjectID()
+	interest.CreatedAt = time.Now()
+	m.Data[key] = *interest
+	return nil
+}
+
+func (m *MockLowonganInterestRepository) Delete(ctx context.Context, lowonganID, alumniID primitive.ObjectID) error {
+	key := lowonganID.Hex() + alumniID.Hex()
+	if _, ok := m.Data[key]; !ok {
+		return mongo.ErrNoDocuments
+	}
+	delete(m.Data, key)
+	return nil
+}
+
+func (m *MockLowonganInterestRepository) DeleteByLowongan(ctx context.Context, lowonganID primitive.ObjectID) (int64, error) {
+	var n int64
+	for k, i := range m.Data {
+		if i.LowonganID == lowonganID {
+			delete(m.Data, k)
+			n++
+		}
+	}
+	return n, nil
+}
+
+func (m *MockLowonganInterestRepository) FindByLowongan(ctx context.Context, lowonganID primitive.ObjectID) ([]model.LowonganInterest, error) {
+	list := []model.LowonganInterest{}
+	for _, i := range m.Data {
+		if i.LowonganID == lowonganID {
+			list = append(list, i)
+		}
+	}
+	sort.Slice(list, func(a, b int) bool { return list[a].CreatedAt.Before(list[b].CreatedAt) })
+	return list, nil
+}
+
+func (m *MockLowonganInterestRepository) FindByAlumni(ctx context.Context, alumniID primitive.ObjectID) ([]model.LowonganInterest, error) {
+	list := []model.LowonganInterest{}
+	for _, i := range m.Data {
+		if i.AlumniID == alumniID {
+			list = append(list, i)
+		}
+	}
+	sort.Slice(list, func(a, b int) bool { return list[a].CreatedAt.After(list[b].CreatedAt) })
+	return list, nil
+}
+
+func (m *MockLowonganInterestRepository) CountByLowongan(ctx context.Context, lowonganID primitive.ObjectID) (int64, error) {
+	list, _ := m.FindByLowongan(ctx, lowonganID)
+	return int64(len(list)), nil
+}
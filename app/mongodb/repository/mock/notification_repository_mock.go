@@ -0,0 +1,229 @@
+package repository
+
+import (
+	"alumni-app/app/mongodb/model"
+	realrepo "alumni-app/app/mongodb/repository"
+	"context"
+	"errors"
+	"sort"
+	"sync"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+type MockNotificationTemplateRepository struct {
+	Data map[string]model.NotificationTemplate // key = template key
+}
+
+func NewMockNotificationTemplateRepository() *MockNotificationTemplateRepository {
+	return &MockNotificationTemplateRepository{
+		Data: make(map[string]model.NotificationTemplate),
+	}
+}
+
+func (m *MockNotificationTemplateRepository) FindAll(ctx context.Context) ([]model.NotificationTemplate, error) {
+	list := []model.NotificationTemplate{}
+	for _, t := range m.Data {
+		list = append(list, t)
+	}
+	sort.Slice(list, func(i, j int) bool { return list[i].Key < list[j].Key })
+	return list, nil
+}
+
+func (m *MockNotificationTemplateRepository) GetByKey(ctx context.Context, key string) (model.NotificationTemplate, error) {
+	t, ok := m.Data[key]
+	if !ok {
+		return model.NotificationTemplate{}, mongo.ErrNoDocuments
+	}
+	return t, nil
+}
+
+func (m *MockNotificationTemplateRepository) Create(ctx context.Context, tpl *model.NotificationTemplate) error {
+	if _, ok := m.Data[tpl.Key]; ok {
+		return &realrepo.ConflictError{Field: "key"}
+	}
+	tpl.ID = primitive.NewObjectID()
+	tpl.CreatedAt = time.Now()
+	tpl.UpdatedAt = tpl.CreatedAt
+	m.Data[tpl.Key] = *tpl
+	return nil
+}
+
+func (m *MockNotificationTemplateRepository) Update(ctx context.Context, key string, tpl *model.NotificationTemplate) error {
+	existing, ok := m.Data[key]
+	if !ok {
+		return mongo.ErrNoDocuments
+	}
+	existing.Title = tpl.Title
+	existing.Body = tpl.Body
+	existing.Channels = tpl.Channels
+	existing.UpdatedAt = time.Now()
+	m.Data[key] = existing
+	return nil
+}
+
+func (m *MockNotificationTemplateRepository) Delete(ctx context.Context, key string) error {
+	if _, ok := m.Data[key]; !ok {
+		return mongo.ErrNoDocuments
+	}
+	delete(m.Data, key)
+	return nil
+}
+
+type MockNotificationRepository struct {
+	Data map[string]model.Notification
+}
+
+func NewMockNotificationRepository() *MockNotificationRepository {
+	return &MockNotificationRepository{
+		Data: make(map[string]model.Notification),
+	}
+}
+
+func (m *MockNotificationRepository) CreateMany(ctx context.Context, list []model.Notification) error {
+	now := time.Now()
+	for i := range list {
+		list[i].ID = primitive.NewObjectID()
+		list[i].CreatedAt = now
+		m.Data[list[i].ID.Hex()] = list[i]
+	}
+	return nil
+}
+
+func (m *MockNotificationRepository) FindByUser(ctx context.Context, userID primitive.ObjectID, unreadOnly bool, page, limit int) ([]model.Notification, error) {
+	list := []model.Notification{}
+	for _, n := range m.Data {
+		if n.UserID == userID && (!unreadOnly || n.ReadAt == nil) {
+			list = append(list, n)
+		}
+	}
+	sort.Slice(list, func(i, j int) bool { return list[i].CreatedAt.After(list[j].CreatedAt) })
+
+	start := (page - 1) * limit
+	if start >= len(list) {
+		return []model.Notification{}, nil
+	}
+	end := start + limit
+	if end > len(list) {
+		end = len(list)
+	}
+	return list[start:end], nil
+}
+
+func (m *MockNotificationRepository) CountByUser(ctx context.Context, userID primitive.ObjectID, unreadOnly bool) (int64, error) {
+	list, _ := m.FindByUser(ctx, userID, unreadOnly, 1, len(m.Data)+1)
+	return int64(len(list)), nil
+}
+
+func (m *MockNotificationRepository) MarkRead(ctx context.Context, id, userID primitive.ObjectID) error {
+	n, ok := m.Data[id.Hex()]
+	if !ok || n.UserID != userID {
+		return mongo.ErrNoDocuments
+	}
+	if n.ReadAt == nil {
+		now := time.Now()
+		n.ReadAt = &now
+		m.Data[id.Hex()] = n
+	}
+	return nil
+}
+
+func (m *MockNotificationRepository) MarkAllRead(ctx context.Context, userID primitive.ObjectID) (int64, error) {
+	var count int64
+	now := time.Now()
+	for k, n := range m.Data {
+		if n.UserID == userID && n.ReadAt == nil {
+			n.ReadAt = &now
+			m.Data[k] = n
+			count++
+		}
+	}
+	return count, nil
+}
+
+type MockEmailQueueRepository struct {
+	mu   sync.Mutex
+	Data map[string]model.EmailJob
+}
+
+func NewMockEmailQueueRepository() *MockEmailQueueRepository {
+	return &MockEmailQueueRepository{
+		Data: make(map[string]model.EmailJob),
+	}
+}
+
+func (m *MockEmailQueueRepository) EnqueueMany(ctx context.Context, jobs []model.EmailJob) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	now := time.Now()
+	for i := range jobs {
+		jobs[i].ID = primitive.NewObjectID()
+		jobs[i].Status = model.EmailQueued
+		jobs[i].NextAttemptAt = now
+		jobs[i].CreatedAt = now
+		m.Data[jobs[i].ID.Hex()] = jobs[i]
+	}
+	return nil
+}
+
+func (m *MockEmailQueueRepository) ClaimNext(ctx context.Context, now time.Time, lease time.Duration) (model.EmailJob, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	var next *model.EmailJob
+	for _, j := range m.Data {
+		due := (j.Status == model.EmailQueued && !j.NextAttemptAt.After(now)) ||
+			(j.Status == model.EmailSending && j.LockedUntil != nil && !j.LockedUntil.After(now))
+		if due && (next == nil || j.NextAttemptAt.Before(next.NextAttemptAt)) {
+			j := j
+			next = &j
+		}
+	}
+	if next == nil {
+		return model.EmailJob{}, mongo.ErrNoDocuments
+	}
+	locked := now.Add(lease)
+	next.Status = model.EmailSending
+	next.LockedUntil = &locked
+	next.Attempts++
+	m.Data[next.ID.Hex()] = *next
+	return *next, nil
+}
+
+func (m *MockEmailQueueRepository) MarkSent(ctx context.Context, id primitive.ObjectID) error {
+	return m.finish(id, func(j *model.EmailJob) {
+		now := time.Now()
+		j.Status = model.EmailSent
+		j.SentAt = &now
+		j.LastError = ""
+	})
+}
+
+func (m *MockEmailQueueRepository) MarkRetry(ctx context.Context, id primitive.ObjectID, next time.Time, lastErr string) error {
+	return m.finish(id, func(j *model.EmailJob) {
+		j.Status = model.EmailQueued
+		j.NextAttemptAt = next
+		j.LastError = lastErr
+	})
+}
+
+func (m *MockEmailQueueRepository) MarkFailed(ctx context.Context, id primitive.ObjectID, lastErr string) error {
+	return m.finish(id, func(j *model.EmailJob) {
+		j.Status = model.EmailFailed
+		j.LastError = lastErr
+	})
+}
+
+func (m *MockEmailQueueRepository) finish(id primitive.ObjectID, apply func(j *model.EmailJob)) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	j, ok := m.Data[id.Hex()]
+	if !ok || j.Status != model.EmailSending {
+		return errors.New("not found")
+	}
+	apply(&j)
+	j.LockedUntil = nil
+	m.Data[id.Hex()] = j
+	return nil
+}
@@ -0,0 +1,56 @@
+package repository
+
+import (
+	"alumni-app/app/mongodb/model"
+	"context"
+	"errors"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type MockPasswordResetRepository struct {
+	Data map[string]model.PasswordReset
+}
+
+func NewMockPasswordResetRepository() *MockPasswordResetRepository {
+	return &MockPasswordResetRepository{
+		Data: make(map[string]model.PasswordReset),
+	}
+}
+
+func (m *MockPasswordResetRepository) Create(ctx context.Context, r *model.PasswordReset) error {
+	r.ID = primitive.NewObjectID()
+	r.CreatedAt = time.Now()
+	m.Data[r.ID.Hex()] = *r
+	return nil
+}
+
+func (m *MockPasswordResetRepository) GetByTokenHash(ctx context.Context, hash string) (model.PasswordReset, error) {
+	for _, r := range m.Data {
+		if r.TokenHash == hash {
+			return r, nil
+		}
+	}
+	return model.PasswordReset{}, errors.New("not found")
+}
+
+func (m *MockPasswordResetRepository) MarkUsed(ctx context.Context, id primitive.ObjectID) error {
+	r, ok := m.Data[id.Hex()]
+	if !ok || r.UsedAt != nil {
+		return errors.New("not found")
+	}
+	now := time.Now()
+	r.UsedAt = &now
+	m.Data[id.Hex()] = r
+	return nil
+}
+
+func (m *MockPasswordResetRepository) DeleteUnusedByUserID(ctx context.Context, userID primitive.ObjectID) error {
+	for k, r := range m.Data {
+		if r.UserID == userID && r.UsedAt == nil {
+			delete(m.Data, k)
+		}
+	}
+	return nil
+}
@@ -1,23 +1,27 @@
 package repository
 
 import (
+	"context"
 	"errors"
 	"time"
 	"alumni-app/app/mongodb/model"
+	realrepo "alumni-app/app/mongodb/repository"
 	"go.mongodb.org/mongo-driver/bson/primitive"
 )
 
 type MockPekerjaanRepository struct {
-	Data map[string]model.PekerjaanAlumni
+	Data    map[string]model.PekerjaanAlumni
+	History map[string][]model.PekerjaanHistory
 }
 
 func NewMockPekerjaanRepository() *MockPekerjaanRepository {
 	return &MockPekerjaanRepository{
-		Data: make(map[string]model.PekerjaanAlumni),
+		Data:    make(map[string]model.PekerjaanAlumni),
+		History: make(map[string][]model.PekerjaanHistory),
 	}
 }
 
-func (m *MockPekerjaanRepository) GetAll(search, sortBy, order string, limit, offset int) ([]model.PekerjaanAlumni, error) {
+func (m *MockPekerjaanRepository) GetAll(ctx context.Context, search, sortBy, order string, limit, offset int) ([]model.PekerjaanAlumni, error) {
 	var list []model.PekerjaanAlumni
 	for _, p := range m.Data {
 		if p.DeletedAt == nil { 
@@ -27,11 +31,11 @@ func (m *MockPekerjaanRepository) GetAll(search, sortBy, order string, limit, of
 	return list, nil
 }
 
-func (m *MockPekerjaanRepository) Count(search string) (int64, error) {
+func (m *MockPekerjaanRepository) Count(ctx context.Context, search string) (int64, error) {
 	return int64(len(m.Data)), nil
 }
 
-func (m *MockPekerjaanRepository) GetByID(id primitive.ObjectID) (model.PekerjaanAlumni, error) {
+func (m *MockPekerjaanRepository) GetByID(ctx context.Context, id primitive.ObjectID) (model.PekerjaanAlumni, error) {
 	p, ok := m.Data[id.Hex()]
 	if !ok || p.DeletedAt != nil {
 		return model.PekerjaanAlumni{}, errors.New("not found")
@@ -39,7 +43,7 @@ func (m *MockPekerjaanRepository) GetByID(id primitive.ObjectID) (model.Pekerjaa
 	return p, nil
 }
 
-func (m *MockPekerjaanRepository) GetByAlumniID(alumniID primitive.ObjectID) ([]model.PekerjaanAlumni, error) {
+func (m *MockPekerjaanRepository) GetByAlumniID(ctx context.Context, alumniID primitive.ObjectID) ([]model.PekerjaanAlumni, error) {
 	var list []model.PekerjaanAlumni
 	for _, p := range m.Data {
 		if p.AlumniID == alumniID && p.DeletedAt == nil {
@@ -49,21 +53,32 @@ func (m *MockPekerjaanRepository) GetByAlumniID(alumniID primitive.ObjectID) ([]
 	return list, nil
 }
 
-func (m *MockPekerjaanRepository) Create(p *model.PekerjaanAlumni) error {
+func (m *MockPekerjaanRepository) Create(ctx context.Context, p *model.PekerjaanAlumni) error {
 	m.Data[p.ID.Hex()] = *p
 	return nil
 }
 
-func (m *MockPekerjaanRepository) Update(id primitive.ObjectID, p *model.PekerjaanAlumni) error {
-	_, ok := m.Data[id.Hex()]
+func (m *MockPekerjaanRepository) Update(ctx context.Context, id primitive.ObjectID, p *model.PekerjaanAlumni) error {
+	before, ok := m.Data[id.Hex()]
 	if !ok {
 		return errors.New("not found")
 	}
+	if before.Version != p.Version {
+		return realrepo.ErrVersionConflict
+	}
+	m.History[id.Hex()] = append(m.History[id.Hex()], model.PekerjaanHistory{
+		ID:          primitive.NewObjectID(),
+		PekerjaanID: id,
+		Version:     before.Version,
+		Snapshot:    before,
+		ArchivedAt:  time.Now(),
+	})
+	p.Version++
 	m.Data[id.Hex()] = *p
 	return nil
 }
 
-func (m *MockPekerjaanRepository) SoftDelete(id primitive.ObjectID) error {
+func (m *MockPekerjaanRepository) SoftDelete(ctx context.Context, id primitive.ObjectID) error {
 	p, ok := m.Data[id.Hex()]
 	if !ok {
 		return errors.New("not found")
@@ -74,7 +89,20 @@ func (m *MockPekerjaanRepository) SoftDelete(id primitive.ObjectID) error {
 	return nil
 }
 
-func (m *MockPekerjaanRepository) GetTrashed() ([]model.PekerjaanAlumni, error) {
+func (m *MockPekerjaanRepository) SoftDeleteByAlumniID(ctx context.Context, alumniID primitive.ObjectID) (int64, error) {
+	var n int64
+	now := time.Now()
+	for id, p := range m.Data {
+		if p.AlumniID == alumniID && p.DeletedAt == nil {
+			p.DeletedAt = &now
+			m.Data[id] = p
+			n++
+		}
+	}
+	return n, nil
+}
+
+func (m *MockPekerjaanRepository) GetTrashed(ctx context.Context) ([]model.PekerjaanAlumni, error) {
 	var list []model.PekerjaanAlumni
 	for _, p := range m.Data {
 		if p.DeletedAt != nil {
@@ -84,7 +112,7 @@ func (m *MockPekerjaanRepository) GetTrashed() ([]model.PekerjaanAlumni, error)
 	return list, nil
 }
 
-func (m *MockPekerjaanRepository) GetTrashedByAlumniIDs(ids []primitive.ObjectID) ([]model.PekerjaanAlumni, error) {
+func (m *MockPekerjaanRepository) GetTrashedByAlumniIDs(ctx context.Context, ids []primitive.ObjectID) ([]model.PekerjaanAlumni, error) {
 	var result []model.PekerjaanAlumni
 	for _, p := range m.Data {
 		for _, id := range ids {
@@ -96,7 +124,7 @@ func (m *MockPekerjaanRepository) GetTrashedByAlumniIDs(ids []primitive.ObjectID
 	return result, nil
 }
 
-func (m *MockPekerjaanRepository) Restore(id primitive.ObjectID) error {
+func (m *MockPekerjaanRepository) Restore(ctx context.Context, id primitive.ObjectID) error {
 	p, ok := m.Data[id.Hex()]
 	if !ok {
 		return errors.New("not found")
@@ -106,11 +134,47 @@ func (m *MockPekerjaanRepository) Restore(id primitive.ObjectID) error {
 	return nil
 }
 
-func (m *MockPekerjaanRepository) HardDelete(id primitive.ObjectID) error {
+func (m *MockPekerjaanRepository) HardDelete(ctx context.Context, id primitive.ObjectID) error {
 	_, ok := m.Data[id.Hex()]
 	if !ok {
 		return errors.New("not found")
 	}
 	delete(m.Data, id.Hex())
+	delete(m.History, id.Hex())
 	return nil
 }
+
+func (m *MockPekerjaanRepository) GetHistory(ctx context.Context, id primitive.ObjectID) ([]model.PekerjaanHistory, error) {
+	var list []model.PekerjaanHistory
+	h := m.History[id.Hex()]
+	for i := len(h) - 1; i >= 0; i-- {
+		list = append(list, h[i])
+	}
+	return list, nil
+}
+
+func (m *MockPekerjaanRepository) GetHistoryVersion(ctx context.Context, id primitive.ObjectID, version int) (model.PekerjaanHistory, error) {
+	for _, h := range m.History[id.Hex()] {
+		if h.Version == version {
+			return h, nil
+		}
+	}
+	return model.PekerjaanHistory{}, errors.New("not found")
+}
+
+func (m *MockPekerjaanRepository) Distinct(ctx context.Context, field string) ([]string, error) {
+	seen := map[string]bool{}
+	list := []string{}
+	for _, p := range m.Data {
+		v := p.BidangIndustri
+		if field == "lokasi_kerja" {
+			v = p.LokasiKerja
+		}
+		if p.DeletedAt != nil || v == "" || seen[v] {
+			continue
+		}
+		seen[v] = true
+		list = append(list, v)
+	}
+	return list, nil
+}
@@ -0,0 +1,150 @@
+package repository
+
+import (
+	"alumni-app/app/mongodb/model"
+	"context"
+	"sort"
+	"strings"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+type MockSurveyRepository struct {
+	Data map[string]model.Survey
+}
+
+func NewMockSurveyRepository() *MockSurveyRepository {
+	return &MockSurveyRepository{
+		Data: make(map[string]model.Survey),
+	}
+}
+
+func (m *MockSurveyRepository) FindAll(ctx context.Context, status string) ([]model.Survey, error) {
+	list := []model.Survey{}
+	for _, s := range m.Data {
+		if status == "" || s.Status == status {
+			list = append(list, s)
+		}
+	}
+	sort.Slice(list, func(i, j int) bool { return list[i].CreatedAt.After(list[j].CreatedAt) })
+	return list, nil
+}
+
+func (m *MockSurveyRepository) GetByID(ctx context.Context, id primitive.ObjectID) (model.Survey, error) {
+	s, ok := m.Data[id.Hex()]
+	if !ok {
+		return model.Survey{}, mongo.ErrNoDocuments
+	}
+	return s, nil
+}
+
+func (m *MockSurveyRepository) Create(ctx context.Context, s *model.Survey) error {
+	s.ID = primitive.NewObjectID()
+	s.Status = model.SurveyDraft
+	s.CreatedAt = time.Now()
+	s.UpdatedAt = s.CreatedAt
+	m.Data[s.ID.Hex()] = *s
+	return nil
+}
+
+func (m *MockSurveyRepository) Update(ctx context.Context, id primitive.ObjectID, s *model.Survey) error {
+	existing, ok := m.Data[id.Hex()]
+	if !ok || existing.Status != model.SurveyDraft {
+		return mongo.ErrNoDocuments
+	}
+	existing.Title = s.Title
+	existing.Description = s.Description
+	existing.Questions = s.Questions
+	existing.Target = s.Target
+	existing.UpdatedAt = time.Now()
+	s.UpdatedAt = existing.UpdatedAt
+	m.Data[id.Hex()] = existing
+	return nil
+}
+
+func (m *MockSurveyRepository) SetStatus(ctx context.Context, id primitive.ObjectID, from, to string) error {
+	existing, ok := m.Data[id.Hex()]
+	if !ok || existing.Status != from {
+		return mongo.ErrNoDocuments
+	}
+	now := time.Now()
+	existing.Status = to
+	existing.UpdatedAt = now
+	switch to {
+	case model.SurveyOpen:
+		existing.OpenedAt = &now
+	case model.SurveyClosed:
+		existing.ClosedAt = &now
+	}
+	m.Data[id.Hex()] = existing
+	return nil
+}
+
+func (m *MockSurveyRepository) Delete(ctx context.Context, id primitive.ObjectID) error {
+	existing, ok := m.Data[id.Hex()]
+	if !ok || existing.Status != model.SurveyDraft {
+		return mongo.ErrNoDocuments
+	}
+	delete(m.Data, id.Hex())
+	return nil
+}
+
+type MockSurveyResponseRepository struct {
+	Data map[string]model.SurveyResponse // key = surveyID + alumniID
+}
+
+func NewMockSurveyResponseRepository() *MockSurveyResponseRepository {
+	return &MockSurveyResponseRepository{
+		Data: make(map[string]model.SurveyResponse),
+	}
+}
+
+func (m *MockSurveyResponseRepository) Upsert(ctx context.Context, resp *model.SurveyResponse) error {
+	key := resp.SurveyID.Hex() + resp.AlumniID.Hex()
+	now := time.Now()
+	if existing, ok := m.Data[key]; ok {
+		resp.ID = existing.ID
+		resp.SubmittedAt = existing.SubmittedAt
+	} else {
+		resp.ID = primitive.NewObjectID()
+		resp.SubmittedAt = now
+	}
+	resp.UpdatedAt = now
+	m.Data[key] = *resp
+	return nil
+}
+
+func (m *MockSurveyResponseRepository) GetByAlumni(ctx context.Context, surveyID, alumniID primitive.ObjectID) (model.SurveyResponse, error) {
+	resp, ok := m.Data[surveyID.Hex()+alumniID.Hex()]
+	if !ok {
+		return model.SurveyResponse{}, mongo.ErrNoDocuments
+	}
+	return resp, nil
+}
+
+func (m *MockSurveyResponseRepository) FindByAlumni(ctx context.Context, alumniID primitive.ObjectID) ([]model.SurveyResponse, error) {
+	list := []model.SurveyResponse{}
+	for _, r := range m.Data {
+		if r.AlumniID == alumniID {
+			r.Answers = nil
+			list = append(list, r)
+		}
+	}
+	return list, nil
+}
+
+func (m *MockSurveyResponseRepository) FindBySurvey(ctx context.Context, surveyID primitive.ObjectID, cohort model.AlumniFilter) ([]model.SurveyResponse, error) {
+	list := []model.SurveyResponse{}
+	for _, r := range m.Data {
+		if r.SurveyID != surveyID ||
+			(cohort.Jurusan != "" && !strings.EqualFold(r.Jurusan, cohort.Jurusan)) ||
+			(cohort.TahunLulus != 0 && r.TahunLulus != cohort.TahunLulus) {
+			continue
+		}
+		list = append(list, r)
+	}
+	sort.Slice(list, func(i, j int) bool { return list[i].SubmittedAt.Before(list[j].SubmittedAt) })
+	return list, nil
+}
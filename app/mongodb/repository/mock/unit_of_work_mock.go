@@ -0,0 +1,31 @@
+package repository
+
+import (
+	realrepo "alumni-app/app/mongodb/repository"
+	"context"
+	"sync"
+)
+
+// MockUnitOfWork menjalankan fn tanpa transaksi; undo dari OnRollback tetap dijalankan saat gagal.
+// CommitErr meniru commit transaksi yang gagal setelah fn selesai.
+type MockUnitOfWork struct {
+	Calls     int
+	CommitErr error
+	mu        sync.Mutex
+}
+
+func NewMockUnitOfWork() *MockUnitOfWork {
+	return &MockUnitOfWork{}
+}
+
+func (m *MockUnitOfWork) Do(ctx context.Context, fn func(ctx context.Context) error) error {
+	m.mu.Lock()
+	m.Calls++
+	m.mu.Unlock()
+	return realrepo.RunWithRollback(ctx, func(ctx context.Context) error {
+		if err := fn(ctx); err != nil {
+			return err
+		}
+		return m.CommitErr
+	})
+}
@@ -0,0 +1,77 @@
+package repository
+
+import (
+	"alumni-app/app/mongodb/model"
+	realrepo "alumni-app/app/mongodb/repository"
+	"context"
+	"errors"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type MockUploadSessionRepository struct {
+	Data map[string]model.UploadSession
+}
+
+func NewMockUploadSessionRepository() *MockUploadSessionRepository {
+	return &MockUploadSessionRepository{
+		Data: make(map[string]model.UploadSession),
+	}
+}
+
+func (m *MockUploadSessionRepository) Create(ctx context.Context, s *model.UploadSession) error {
+	s.ID = primitive.NewObjectID()
+	s.CreatedAt = time.Now()
+	s.UpdatedAt = s.CreatedAt
+	s.Status = model.UploadSessionUploading
+	m.Data[s.ID.Hex()] = *s
+	return nil
+}
+
+func (m *MockUploadSessionRepository) GetByID(ctx context.Context, id primitive.ObjectID) (model.UploadSession, error) {
+	s, ok := m.Data[id.Hex()]
+	if !ok {
+		return model.UploadSession{}, errors.New("not found")
+	}
+	return s, nil
+}
+
+func (m *MockUploadSessionRepository) AppendChunk(ctx context.Context, id primitive.ObjectID, expectedOffset int64, chunk model.UploadChunk, expiresAt time.Time) error {
+	s, ok := m.Data[id.Hex()]
+	if !ok || s.Offset != expectedOffset {
+		return realrepo.ErrVersionConflict
+	}
+	s.Offset += chunk.Size
+	s.Chunks = append(s.Chunks, chunk)
+	s.ExpiresAt = expiresAt
+	s.UpdatedAt = time.Now()
+	m.Data[id.Hex()] = s
+	return nil
+}
+
+func (m *MockUploadSessionRepository) SetStatus(ctx context.Context, id primitive.ObjectID, from, to string) error {
+	s, ok := m.Data[id.Hex()]
+	if !ok || (s.Status != from && !(from == model.UploadSessionUploading && s.Status == "")) {
+		return realrepo.ErrVersionConflict
+	}
+	s.Status = to
+	s.UpdatedAt = time.Now()
+	m.Data[id.Hex()] = s
+	return nil
+}
+
+func (m *MockUploadSessionRepository) Delete(ctx context.Context, id primitive.ObjectID) error {
+	delete(m.Data, id.Hex())
+	return nil
+}
+
+func (m *MockUploadSessionRepository) FindExpired(ctx context.Context, before time.Time) ([]model.UploadSession, error) {
+	var list []model.UploadSession
+	for _, s := range m.Data {
+		if s.ExpiresAt.Before(before) {
+			list = append(list, s)
+		}
+	}
+	return list, nil
+}
@@ -2,13 +2,21 @@ package repository
 
 import (
 	"alumni-app/app/mongodb/model"
+	realrepo "alumni-app/app/mongodb/repository"
+	"context"
 	"errors"
+	"strings"
+	"sync"
+	"time"
+
 	"go.mongodb.org/mongo-driver/bson/primitive"
 )
 
 // ==================== MOCK REPOSITORY ====================
 
+// MockUserRepository meniru index unik username/email case-insensitive milik MongoDB
 type MockUserRepository struct {
+	mu    sync.Mutex
 	users map[string]model.User // pakai string key = hex ID
 }
 
@@ -18,25 +26,46 @@ func NewMockUserRepository() *MockUserRepository {
 	}
 }
 
-func (m *MockUserRepository) Create(user *model.User) error {
+func (m *MockUserRepository) Create(ctx context.Context, user *model.User) error {
 	if user.Username == "" {
 		return errors.New("username cannot be empty")
 	}
 
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for _, u := range m.users {
+		if strings.EqualFold(u.Username, user.Username) {
+			return &realrepo.ConflictError{Field: "username"}
+		}
+		if user.Email != "" && strings.EqualFold(u.Email, user.Email) {
+			return &realrepo.ConflictError{Field: "email"}
+		}
+	}
 	m.users[user.ID.Hex()] = *user
 	return nil
 }
 
-func (m *MockUserRepository) GetByUsername(username string) (model.User, error) {
+func (m *MockUserRepository) Delete(ctx context.Context, id primitive.ObjectID) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	delete(m.users, id.Hex())
+	return nil
+}
+
+func (m *MockUserRepository) GetByUsername(ctx context.Context, username string) (model.User, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
 	for _, u := range m.users {
-		if u.Username == username {
+		if strings.EqualFold(u.Username, username) {
 			return u, nil
 		}
 	}
 	return model.User{}, errors.New("user not found")
 }
 
-func (m *MockUserRepository) GetByID(id interface{}) (model.User, error) {
+func (m *MockUserRepository) GetByID(ctx context.Context, id interface{}) (model.User, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
 	strID := id.(primitive.ObjectID).Hex()
 	user, exists := m.users[strID]
 	if !exists {
@@ -44,3 +73,40 @@ func (m *MockUserRepository) GetByID(id interface{}) (model.User, error) {
 	}
 	return user, nil
 }
+
+func (m *MockUserRepository) GetByEmail(ctx context.Context, email string) (model.User, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for _, u := range m.users {
+		if strings.EqualFold(u.Email, email) {
+			return u, nil
+		}
+	}
+	return model.User{}, errors.New("user not found")
+}
+
+func (m *MockUserRepository) UpdatePassword(ctx context.Context, id primitive.ObjectID, passwordHash string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	user, exists := m.users[id.Hex()]
+	if !exists {
+		return errors.New("user not found")
+	}
+	user.PasswordHash = passwordHash
+	m.users[id.Hex()] = user
+	return nil
+}
+
+func (m *MockUserRepository) MarkEmailVerified(ctx context.Context, id primitive.ObjectID, email string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	user, exists := m.users[id.Hex()]
+	if !exists || !strings.EqualFold(user.Email, email) {
+		return errors.New("user not found")
+	}
+	now := time.Now()
+	user.EmailVerified = true
+	user.EmailVerifiedAt = &now
+	m.users[id.Hex()] = user
+	return nil
+}
@@ -0,0 +1,118 @@
+package repository
+
+import (
+	"alumni-app/app/mongodb/model"
+	"alumni-app/config"
+	"context"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type NotificationRepositoryInterface interface {
+	CreateMany(ctx context.Context, list []model.Notification) error
+	FindByUser(ctx context.Context, userID primitive.ObjectID, unreadOnly bool, page, limit int) ([]model.Notification, error)
+	CountByUser(ctx context.Context, userID primitive.ObjectID, unreadOnly bool) (int64, error)
+	MarkRead(ctx context.Context, id, userID primitive.ObjectID) error
+	MarkAllRead(ctx context.Context, userID primitive.ObjectID) (int64, error)
+}
+
+type NotificationRepository struct {
+	Col      *mongo.Collection
+	Timeouts config.DBTimeoutConfig
+}
+
+func NewNotificationRepository(db *mongo.Database, timeouts config.DBTimeoutConfig) NotificationRepositoryInterface {
+	return &NotificationRepository{
+		Col:      db.Collection("notifications"),
+		Timeouts: timeouts,
+	}
+}
+
+func (r *NotificationRepository) CreateMany(ctx context.Context, list []model.Notification) error {
+	if len(list) == 0 {
+		return nil
+	}
+	ctx, cancel := withTimeout(ctx, r.Timeouts.Scan)
+	defer cancel()
+
+	now := time.Now()
+	docs := make([]interface{}, len(list))
+	for i := range list {
+		list[i].ID = primitive.NewObjectID()
+		list[i].CreatedAt = now
+		docs[i] = list[i]
+	}
+	_, err := r.Col.InsertMany(ctx, docs)
+	return err
+}
+
+func (r *NotificationRepository) FindByUser(ctx context.Context, userID primitive.ObjectID, unreadOnly bool, page, limit int) ([]model.Notification, error) {
+	ctx, cancel := withTimeout(ctx, r.Timeouts.Read)
+	defer cancel()
+
+	opts := options.Find().
+		SetSort(bson.D{{Key: "created_at", Value: -1}}).
+		SetSkip(int64((page - 1) * limit)).
+		SetLimit(int64(limit))
+
+	cur, err := r.Col.Find(ctx, inboxFilter(userID, unreadOnly), opts)
+	if err != nil {
+		return nil, err
+	}
+	defer cur.Close(ctx)
+
+	list := []model.Notification{}
+	if err := cur.All(ctx, &list); err != nil {
+		return nil, err
+	}
+	return list, nil
+}
+
+func (r *NotificationRepository) CountByUser(ctx context.Context, userID primitive.ObjectID, unreadOnly bool) (int64, error) {
+	ctx, cancel := withTimeout(ctx, r.Timeouts.Read)
+	defer cancel()
+
+	return r.Col.CountDocuments(ctx, inboxFilter(userID, unreadOnly))
+}
+
+// MarkRead menandai notifikasi milik userID sudah dibaca; gagal dengan mongo.ErrNoDocuments
+// jika notifikasi tidak ada atau milik user lain. Menandai ulang tidak mengubah waktu baca.
+func (r *NotificationRepository) MarkRead(ctx context.Context, id, userID primitive.ObjectID) error {
+	ctx, cancel := withTimeout(ctx, r.Timeouts.Write)
+	defer cancel()
+
+	res, err := r.Col.UpdateOne(ctx,
+		bson.M{"_id": id, "user_id": userID},
+		bson.A{bson.M{"$set": bson.M{"read_at": bson.M{"$ifNull": bson.A{"$read_at", "$$NOW"}}}}},
+	)
+	if err != nil {
+		return err
+	}
+	if res.MatchedCount == 0 {
+		return mongo.ErrNoDocuments
+	}
+	return nil
+}
+
+func (r *NotificationRepository) MarkAllRead(ctx context.Context, userID primitive.ObjectID) (int64, error) {
+	ctx, cancel := withTimeout(ctx, r.Timeouts.Write)
+	defer cancel()
+
+	res, err := r.Col.UpdateMany(ctx, inboxFilter(userID, true), bson.M{"$set": bson.M{"read_at": time.Now()}})
+	if err != nil {
+		return 0, err
+	}
+	return res.ModifiedCount, nil
+}
+
+func inboxFilter(userID primitive.ObjectID, unreadOnly bool) bson.M {
+	filter := bson.M{"user_id": userID}
+	if unreadOnly {
+		filter["read_at"] = bson.M{"$exists": false}
+	}
+	return filter
+}
@@ -0,0 +1,107 @@
+package repository
+
+import (
+	"alumni-app/app/mongodb/model"
+	"alumni-app/config"
+	"context"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type NotificationTemplateRepositoryInterface interface {
+	FindAll(ctx context.Context) ([]model.NotificationTemplate, error)
+	GetByKey(ctx context.Context, key string) (model.NotificationTemplate, error)
+	Create(ctx context.Context, tpl *model.NotificationTemplate) error
+	Update(ctx context.Context, key string, tpl *model.NotificationTemplate) error
+	Delete(ctx context.Context, key string) error
+}
+
+type NotificationTemplateRepository struct {
+	Col      *mongo.Collection
+	Timeouts config.DBTimeoutConfig
+}
+
+func NewNotificationTemplateRepository(db *mongo.Database, timeouts config.DBTimeoutConfig) NotificationTemplateRepositoryInterface {
+	return &NotificationTemplateRepository{
+		Col:      db.Collection("notification_templates"),
+		Timeouts: timeouts,
+	}
+}
+
+func (r *NotificationTemplateRepository) FindAll(ctx context.Context) ([]model.NotificationTemplate, error) {
+	ctx, cancel := withTimeout(ctx, r.Timeouts.Read)
+	defer cancel()
+
+	cur, err := r.Col.Find(ctx, bson.M{}, options.Find().SetSort(bson.D{{Key: "key", Value: 1}}))
+	if err != nil {
+		return nil, err
+	}
+	defer cur.Close(ctx)
+
+	list := []model.NotificationTemplate{}
+	if err := cur.All(ctx, &list); err != nil {
+		return nil, err
+	}
+	return list, nil
+}
+
+func (r *NotificationTemplateRepository) GetByKey(ctx context.Context, key string) (model.NotificationTemplate, error) {
+	ctx, cancel := withTimeout(ctx, r.Timeouts.Read)
+	defer cancel()
+
+	var tpl model.NotificationTemplate
+	err := r.Col.FindOne(ctx, bson.M{"key": key}).Decode(&tpl)
+	return tpl, err
+}
+
+// Create gagal dengan *ConflictError{Field: "key"} jika key sudah dipakai
+func (r *NotificationTemplateRepository) Create(ctx context.Context, tpl *model.NotificationTemplate) error {
+	ctx, cancel := withTimeout(ctx, r.Timeouts.Write)
+	defer cancel()
+
+	tpl.ID = primitive.NewObjectID()
+	now := time.Now()
+	tpl.CreatedAt = now
+	tpl.UpdatedAt = now
+
+	_, err := r.Col.InsertOne(ctx, tpl)
+	return translateDuplicate(err)
+}
+
+func (r *NotificationTemplateRepository) Update(ctx context.Context, key string, tpl *model.NotificationTemplate) error {
+	ctx, cancel := withTimeout(ctx, r.Timeouts.Write)
+	defer cancel()
+
+	tpl.UpdatedAt = time.Now()
+	res, err := r.Col.UpdateOne(ctx, bson.M{"key": key}, bson.M{"$set": bson.M{
+		"title":      tpl.Title,
+		"body":       tpl.Body,
+		"channels":   tpl.Channels,
+		"updated_at": tpl.UpdatedAt,
+	}})
+	if err != nil {
+		return err
+	}
+	if res.MatchedCount == 0 {
+		return mongo.ErrNoDocuments
+	}
+	return nil
+}
+
+func (r *NotificationTemplateRepository) Delete(ctx context.Context, key string) error {
+	ctx, cancel := withTimeout(ctx, r.Timeouts.Write)
+	defer cancel()
+
+	res, err := r.Col.DeleteOne(ctx, bson.M{"key": key})
+	if err != nil {
+		return err
+	}
+	if res.DeletedCount == 0 {
+		return mongo.ErrNoDocuments
+	}
+	return nil
+}
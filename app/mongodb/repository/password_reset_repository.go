@@ -0,0 +1,77 @@
+package repository
+
+import (
+	"alumni-app/app/mongodb/model"
+	"alumni-app/config"
+	"context"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+type PasswordResetRepositoryInterface interface {
+	Create(ctx context.Context, r *model.PasswordReset) error
+	GetByTokenHash(ctx context.Context, hash string) (model.PasswordReset, error)
+	MarkUsed(ctx context.Context, id primitive.ObjectID) error
+	DeleteUnusedByUserID(ctx context.Context, userID primitive.ObjectID) error
+}
+
+type PasswordResetRepository struct {
+	Col      *mongo.Collection
+	Timeouts config.DBTimeoutConfig
+}
+
+func NewPasswordResetRepository(db *mongo.Database, timeouts config.DBTimeoutConfig) PasswordResetRepositoryInterface {
+	return &PasswordResetRepository{
+		Col:      db.Collection("password_resets"),
+		Timeouts: timeouts,
+	}
+}
+
+func (r *PasswordResetRepository) Create(ctx context.Context, pr *model.PasswordReset) error {
+	ctx, cancel := withTimeout(ctx, r.Timeouts.Write)
+	defer cancel()
+
+	pr.ID = primitive.NewObjectID()
+	pr.CreatedAt = time.Now()
+
+	_, err := r.Col.InsertOne(ctx, pr)
+	return err
+}
+
+func (r *PasswordResetRepository) GetByTokenHash(ctx context.Context, hash string) (model.PasswordReset, error) {
+	ctx, cancel := withTimeout(ctx, r.Timeouts.Read)
+	defer cancel()
+
+	var pr model.PasswordReset
+	err := r.Col.FindOne(ctx, bson.M{"token_hash": hash}).Decode(&pr)
+	return pr, err
+}
+
+// MarkUsed menandai token terpakai; gagal dengan mongo.ErrNoDocuments jika token sudah dipakai
+func (r *PasswordResetRepository) MarkUsed(ctx context.Context, id primitive.ObjectID) error {
+	ctx, cancel := withTimeout(ctx, r.Timeouts.Write)
+	defer cancel()
+
+	res, err := r.Col.UpdateOne(ctx,
+		bson.M{"_id": id, "used_at": bson.M{"$exists": false}},
+		bson.M{"$set": bson.M{"used_at": time.Now()}},
+	)
+	if err != nil {
+		return err
+	}
+	if res.MatchedCount == 0 {
+		return mongo.ErrNoDocuments
+	}
+	return nil
+}
+
+func (r *PasswordResetRepository) DeleteUnusedByUserID(ctx context.Context, userID primitive.ObjectID) error {
+	ctx, cancel := withTimeout(ctx, r.Timeouts.Write)
+	defer cancel()
+
+	_, err := r.Col.DeleteMany(ctx, bson.M{"user_id": userID, "used_at": bson.M{"$exists": false}})
+	return err
+}
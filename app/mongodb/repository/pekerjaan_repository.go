@@ -2,6 +2,7 @@ package repository
 
 import (
 	"alumni-app/app/mongodb/model"
+	"alumni-app/config"
 	"context"
 	"time"
 
@@ -12,31 +13,39 @@ import (
 )
 
 type PekerjaanRepositoryInterface interface {
-	GetAll(search, sortBy, order string, limit, offset int) ([]model.PekerjaanAlumni, error)
-	Count(search string) (int64, error)
-	GetByID(id primitive.ObjectID) (model.PekerjaanAlumni, error)
-	GetByAlumniID(alumniID primitive.ObjectID) ([]model.PekerjaanAlumni, error)
-	Create(p *model.PekerjaanAlumni) error
-	Update(id primitive.ObjectID, p *model.PekerjaanAlumni) error
-	SoftDelete(id primitive.ObjectID) error
-	GetTrashed() ([]model.PekerjaanAlumni, error)
-	GetTrashedByAlumniIDs(alumniIDs []primitive.ObjectID) ([]model.PekerjaanAlumni, error)
-	Restore(id primitive.ObjectID) error
-	HardDelete(id primitive.ObjectID) error
+	GetAll(ctx context.Context, search, sortBy, order string, limit, offset int) ([]model.PekerjaanAlumni, error)
+	Count(ctx context.Context, search string) (int64, error)
+	GetByID(ctx context.Context, id primitive.ObjectID) (model.PekerjaanAlumni, error)
+	GetByAlumniID(ctx context.Context, alumniID primitive.ObjectID) ([]model.PekerjaanAlumni, error)
+	Create(ctx context.Context, p *model.PekerjaanAlumni) error
+	Update(ctx context.Context, id primitive.ObjectID, p *model.PekerjaanAlumni) error
+	SoftDelete(ctx context.Context, id primitive.ObjectID) error
+	SoftDeleteByAlumniID(ctx context.Context, alumniID primitive.ObjectID) (int64, error)
+	GetTrashed(ctx context.Context) ([]model.PekerjaanAlumni, error)
+	GetTrashedByAlumniIDs(ctx context.Context, alumniIDs []primitive.ObjectID) ([]model.PekerjaanAlumni, error)
+	Restore(ctx context.Context, id primitive.ObjectID) error
+	HardDelete(ctx context.Context, id primitive.ObjectID) error
+	GetHistory(ctx context.Context, id primitive.ObjectID) ([]model.PekerjaanHistory, error)
+	GetHistoryVersion(ctx context.Context, id primitive.ObjectID, version int) (model.PekerjaanHistory, error)
+	Distinct(ctx context.Context, field string) ([]string, error)
 }
 
 type PekerjaanRepository struct {
-	Col *mongo.Collection
+	Col        *mongo.Collection
+	HistoryCol *mongo.Collection
+	Timeouts   config.DBTimeoutConfig
 }
 
-func NewPekerjaanRepository(db *mongo.Database) PekerjaanRepositoryInterface {
+func NewPekerjaanRepository(db *mongo.Database, timeouts config.DBTimeoutConfig) PekerjaanRepositoryInterface {
 	return &PekerjaanRepository{
-		Col: db.Collection("pekerjaan"),
+		Col:        db.Collection("pekerjaan"),
+		HistoryCol: db.Collection("pekerjaan_history"),
+		Timeouts:   timeouts,
 	}
 }
 
-func (r *PekerjaanRepository) GetAll(search, sortBy, order string, limit, offset int) ([]model.PekerjaanAlumni, error) {
-	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+func (r *PekerjaanRepository) GetAll(ctx context.Context, search, sortBy, order string, limit, offset int) ([]model.PekerjaanAlumni, error) {
+	ctx, cancel := withTimeout(ctx, r.Timeouts.Read)
 	defer cancel()
 
 	filter := bson.M{
@@ -70,8 +79,8 @@ func (r *PekerjaanRepository) GetAll(search, sortBy, order string, limit, offset
 	return result, nil
 }
 
-func (r *PekerjaanRepository) Count(search string) (int64, error) {
-	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+func (r *PekerjaanRepository) Count(ctx context.Context, search string) (int64, error) {
+	ctx, cancel := withTimeout(ctx, r.Timeouts.Read)
 	defer cancel()
 
 	filter := bson.M{
@@ -86,8 +95,8 @@ func (r *PekerjaanRepository) Count(search string) (int64, error) {
 	return r.Col.CountDocuments(ctx, filter)
 }
 
-func (r *PekerjaanRepository) GetByID(id primitive.ObjectID) (model.PekerjaanAlumni, error) {
-	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+func (r *PekerjaanRepository) GetByID(ctx context.Context, id primitive.ObjectID) (model.PekerjaanAlumni, error) {
+	ctx, cancel := withTimeout(ctx, r.Timeouts.Read)
 	defer cancel()
 
 	var p model.PekerjaanAlumni
@@ -95,8 +104,8 @@ func (r *PekerjaanRepository) GetByID(id primitive.ObjectID) (model.PekerjaanAlu
 	return p, err
 }
 
-func (r *PekerjaanRepository) GetByAlumniID(alumniID primitive.ObjectID) ([]model.PekerjaanAlumni, error) {
-	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+func (r *PekerjaanRepository) GetByAlumniID(ctx context.Context, alumniID primitive.ObjectID) ([]model.PekerjaanAlumni, error) {
+	ctx, cancel := withTimeout(ctx, r.Timeouts.Read)
 	defer cancel()
 
 	cur, err := r.Col.Find(ctx, bson.M{"alumni_id": alumniID, "deleted_at": bson.M{"$exists": false}})
@@ -112,8 +121,8 @@ func (r *PekerjaanRepository) GetByAlumniID(alumniID primitive.ObjectID) ([]mode
 	return list, nil
 }
 
-func (r *PekerjaanRepository) Create(p *model.PekerjaanAlumni) error {
-	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+func (r *PekerjaanRepository) Create(ctx context.Context, p *model.PekerjaanAlumni) error {
+	ctx, cancel := withTimeout(ctx, r.Timeouts.Write)
 	defer cancel()
 
 	p.ID = primitive.NewObjectID()
@@ -125,27 +134,83 @@ func (r *PekerjaanRepository) Create(p *model.PekerjaanAlumni) error {
 	return err
 }
 
-func (r *PekerjaanRepository) Update(id primitive.ObjectID, p *model.PekerjaanAlumni) error {
-	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+// Update menyimpan perubahan hanya jika p.Version masih sama dengan versi di database.
+// Versi lama disimpan ke pekerjaan_history lebih dulu (kunci pekerjaan_id + version) agar
+// tidak ada versi yang naik tanpa riwayat, lalu p.Version dinaikkan satu.
+func (r *PekerjaanRepository) Update(ctx context.Context, id primitive.ObjectID, p *model.PekerjaanAlumni) error {
+	ctx, cancel := withTimeout(ctx, r.Timeouts.Write)
 	defer cancel()
 
-	p.UpdatedAt = time.Now()
-	update := bson.M{"$set": p}
+	var before model.PekerjaanAlumni
+	if err := r.Col.FindOne(ctx, bson.M{"_id": id}).Decode(&before); err != nil {
+		return err
+	}
+	expected := p.Version
+	if before.Version != expected {
+		return ErrVersionConflict
+	}
 
-	_, err := r.Col.UpdateOne(ctx, bson.M{"_id": id}, update)
-	return err
+	// upsert agar percobaan ulang atau update bersamaan pada versi yang sama tidak menggandakan riwayat
+	_, err := r.HistoryCol.UpdateOne(ctx,
+		bson.M{"pekerjaan_id": id, "version": expected},
+		bson.M{"$setOnInsert": model.PekerjaanHistory{
+			PekerjaanID: id,
+			Version:     expected,
+			Snapshot:    before,
+			ArchivedAt:  time.Now(),
+		}},
+		options.Update().SetUpsert(true),
+	)
+	if err != nil {
+		return err
+	}
+
+	filter := bson.M{"_id": id, "version": expected}
+	if expected == 0 {
+		// data lama belum punya field version
+		filter["version"] = bson.M{"$in": bson.A{0, nil}}
+	}
+
+	p.Version = expected + 1
+	p.UpdatedAt = time.Now()
+	res, err := r.Col.UpdateOne(ctx, filter, bson.M{"$set": p})
+	if err != nil {
+		p.Version = expected
+		return err
+	}
+	if res.MatchedCount == 0 {
+		// sudah diubah request lain sejak dibaca; riwayat versi expected tetap valid
+		p.Version = expected
+		return ErrVersionConflict
+	}
+	return nil
 }
 
-func (r *PekerjaanRepository) SoftDelete(id primitive.ObjectID) error {
-	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+func (r *PekerjaanRepository) SoftDelete(ctx context.Context, id primitive.ObjectID) error {
+	ctx, cancel := withTimeout(ctx, r.Timeouts.Write)
 	defer cancel()
 
 	_, err := r.Col.UpdateOne(ctx, bson.M{"_id": id}, bson.M{"$set": bson.M{"deleted_at": time.Now()}})
 	return err
 }
 
-func (r *PekerjaanRepository) GetTrashed() ([]model.PekerjaanAlumni, error) {
-	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+// SoftDeleteByAlumniID memindahkan semua pekerjaan aktif milik alumni ke trash
+func (r *PekerjaanRepository) SoftDeleteByAlumniID(ctx context.Context, alumniID primitive.ObjectID) (int64, error) {
+	ctx, cancel := withTimeout(ctx, r.Timeouts.Write)
+	defer cancel()
+
+	res, err := r.Col.UpdateMany(ctx,
+		bson.M{"alumni_id": alumniID, "deleted_at": bson.M{"$exists": false}},
+		bson.M{"$set": bson.M{"deleted_at": time.Now()}},
+	)
+	if err != nil {
+		return 0, err
+	}
+	return res.ModifiedCount, nil
+}
+
+func (r *PekerjaanRepository) GetTrashed(ctx context.Context) ([]model.PekerjaanAlumni, error) {
+	ctx, cancel := withTimeout(ctx, r.Timeouts.Read)
 	defer cancel()
 
 	cur, err := r.Col.Find(ctx, bson.M{"deleted_at": bson.M{"$exists": true}})
@@ -161,8 +226,8 @@ func (r *PekerjaanRepository) GetTrashed() ([]model.PekerjaanAlumni, error) {
 	return list, nil
 }
 
-func (r *PekerjaanRepository) GetTrashedByAlumniIDs(alumniIDs []primitive.ObjectID) ([]model.PekerjaanAlumni, error) {
-	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+func (r *PekerjaanRepository) GetTrashedByAlumniIDs(ctx context.Context, alumniIDs []primitive.ObjectID) ([]model.PekerjaanAlumni, error) {
+	ctx, cancel := withTimeout(ctx, r.Timeouts.Read)
 	defer cancel()
 
 	filter := bson.M{
@@ -183,8 +248,8 @@ func (r *PekerjaanRepository) GetTrashedByAlumniIDs(alumniIDs []primitive.Object
 	return list, nil
 }
 
-func (r *PekerjaanRepository) Restore(id primitive.ObjectID) error {
-	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+func (r *PekerjaanRepository) Restore(ctx context.Context, id primitive.ObjectID) error {
+	ctx, cancel := withTimeout(ctx, r.Timeouts.Write)
 	defer cancel()
 
 	update := bson.M{"$unset": bson.M{"deleted_at": ""}}
@@ -192,10 +257,48 @@ func (r *PekerjaanRepository) Restore(id primitive.ObjectID) error {
 	return err
 }
 
-func (r *PekerjaanRepository) HardDelete(id primitive.ObjectID) error {
-	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+func (r *PekerjaanRepository) HardDelete(ctx context.Context, id primitive.ObjectID) error {
+	ctx, cancel := withTimeout(ctx, r.Timeouts.Write)
 	defer cancel()
 
-	_, err := r.Col.DeleteOne(ctx, bson.M{"_id": id})
+	if _, err := r.Col.DeleteOne(ctx, bson.M{"_id": id}); err != nil {
+		return err
+	}
+	_, err := r.HistoryCol.DeleteMany(ctx, bson.M{"pekerjaan_id": id})
 	return err
 }
+
+func (r *PekerjaanRepository) GetHistory(ctx context.Context, id primitive.ObjectID) ([]model.PekerjaanHistory, error) {
+	ctx, cancel := withTimeout(ctx, r.Timeouts.Read)
+	defer cancel()
+
+	opts := options.Find().SetSort(bson.D{{Key: "version", Value: -1}})
+	cur, err := r.HistoryCol.Find(ctx, bson.M{"pekerjaan_id": id}, opts)
+	if err != nil {
+		return nil, err
+	}
+	defer cur.Close(ctx)
+
+	var list []model.PekerjaanHistory
+	if err := cur.All(ctx, &list); err != nil {
+		return nil, err
+	}
+	return list, nil
+}
+
+func (r *PekerjaanRepository) GetHistoryVersion(ctx context.Context, id primitive.ObjectID, version int) (model.PekerjaanHistory, error) {
+	ctx, cancel := withTimeout(ctx, r.Timeouts.Read)
+	defer cancel()
+
+	var h model.PekerjaanHistory
+	err := r.HistoryCol.FindOne(ctx, bson.M{"pekerjaan_id": id, "version": version}).Decode(&h)
+	return h, err
+}
+
+// Distinct mengambil nilai unik sebuah field dari pekerjaan yang belum dihapus (mis. bidang_industri)
+func (r *PekerjaanRepository) Distinct(ctx context.Context, field string) ([]string, error) {
+	ctx, cancel := withTimeout(ctx, r.Timeouts.Scan)
+	defer cancel()
+
+	return distinctStrings(ctx, r.Col, field, bson.M{"deleted_at": bson.M{"$exists": false}})
+}
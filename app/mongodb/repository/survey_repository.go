@@ -0,0 +1,144 @@
+package repository
+
+import (
+	"alumni-app/app/mongodb/model"
+	"alumni-app/config"
+	"context"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type SurveyRepositoryInterface interface {
+	FindAll(ctx context.Context, status string) ([]model.Survey, error)
+	GetByID(ctx context.Context, id primitive.ObjectID) (model.Survey, error)
+	Create(ctx context.Context, s *model.Survey) error
+	Update(ctx context.Context, id primitive.ObjectID, s *model.Survey) error
+	SetStatus(ctx context.Context, id primitive.ObjectID, from, to string) error
+	Delete(ctx context.Context, id primitive.ObjectID) error
+}
+
+type SurveyRepository struct {
+	Col      *mongo.Collection
+	Timeouts config.DBTimeoutConfig
+}
+
+func NewSurveyRepository(db *mongo.Database, timeouts config.DBTimeoutConfig) SurveyRepositoryInterface {
+	return &SurveyRepository{
+		Col:      db.Collection("surveys"),
+		Timeouts: timeouts,
+	}
+}
+
+// FindAll mengambil kuesioner terbaru lebih dulu; status kosong berarti tanpa filter
+func (r *SurveyRepository) FindAll(ctx context.Context, status string) ([]model.Survey, error) {
+	ctx, cancel := withTimeout(ctx, r.Timeouts.Read)
+	defer cancel()
+
+	filter := bson.M{}
+	if status != "" {
+		filter["status"] = status
+	}
+	cur, err := r.Col.Find(ctx, filter, options.Find().SetSort(bson.D{{Key: "created_at", Value: -1}}))
+	if err != nil {
+		return nil, err
+	}
+	defer cur.Close(ctx)
+
+	list := []model.Survey{}
+	if err := cur.All(ctx, &list); err != nil {
+		return nil, err
+	}
+	return list, nil
+}
+
+func (r *SurveyRepository) GetByID(ctx context.Context, id primitive.ObjectID) (model.Survey, error) {
+	ctx, cancel := withTimeout(ctx, r.Timeouts.Read)
+	defer cancel()
+
+	var s model.Survey
+	err := r.Col.FindOne(ctx, bson.M{"_id": id}).Decode(&s)
+	return s, err
+}
+
+func (r *SurveyRepository) Create(ctx context.Context, s *model.Survey) error {
+	ctx, cancel := withTimeout(ctx, r.Timeouts.Write)
+	defer cancel()
+
+	s.ID = primitive.NewObjectID()
+	s.Status = model.SurveyDraft
+	s.CreatedAt = time.Now()
+	s.UpdatedAt = s.CreatedAt
+
+	_, err := r.Col.InsertOne(ctx, s)
+	return err
+}
+
+// Update mengganti judul, deskripsi, pertanyaan, dan target; gagal dengan mongo.ErrNoDocuments
+// jika kuesioner sudah tidak berstatus draft
+func (r *SurveyRepository) Update(ctx context.Context, id primitive.ObjectID, s *model.Survey) error {
+	ctx, cancel := withTimeout(ctx, r.Timeouts.Write)
+	defer cancel()
+
+	s.UpdatedAt = time.Now()
+	res, err := r.Col.UpdateOne(ctx,
+		bson.M{"_id": id, "status": model.SurveyDraft},
+		bson.M{"$set": bson.M{
+			"title":       s.Title,
+			"description": s.Description,
+			"questions":   s.Questions,
+			"target":      s.Target,
+			"updated_at":  s.UpdatedAt,
+		}},
+	)
+	if err != nil {
+		return err
+	}
+	if res.MatchedCount == 0 {
+		return mongo.ErrNoDocuments
+	}
+	return nil
+}
+
+// SetStatus memindahkan status dari from ke to; gagal dengan mongo.ErrNoDocuments
+// jika status sudah diubah request lain
+func (r *SurveyRepository) SetStatus(ctx context.Context, id primitive.ObjectID, from, to string) error {
+	ctx, cancel := withTimeout(ctx, r.Timeouts.Write)
+	defer cancel()
+
+	now := time.Now()
+	set := bson.M{"status": to, "updated_at": now}
+	switch to {
+	case model.SurveyOpen:
+		set["opened_at"] = now
+	case model.SurveyClosed:
+		set["closed_at"] = now
+	}
+
+	res, err := r.Col.UpdateOne(ctx, bson.M{"_id": id, "status": from}, bson.M{"$set": set})
+	if err != nil {
+		return err
+	}
+	if res.MatchedCount == 0 {
+		return mongo.ErrNoDocuments
+	}
+	return nil
+}
+
+// Delete hanya menghapus kuesioner draft (yang belum pernah diisi alumni)
+func (r *SurveyRepository) Delete(ctx context.Context, id primitive.ObjectID) error {
+	ctx, cancel := withTimeout(ctx, r.Timeouts.Write)
+	defer cancel()
+
+	res, err := r.Col.DeleteOne(ctx, bson.M{"_id": id, "status": model.SurveyDraft})
+	if err != nil {
+		return err
+	}
+	if res.DeletedCount == 0 {
+		return mongo.ErrNoDocuments
+	}
+	return nil
+}
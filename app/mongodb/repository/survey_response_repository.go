@@ -0,0 +1,113 @@
+package repository
+
+import (
+	"alumni-app/app/mongodb/model"
+	"alumni-app/config"
+	"context"
+	"regexp"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type SurveyResponseRepositoryInterface interface {
+	Upsert(ctx context.Context, resp *model.SurveyResponse) error
+	GetByAlumni(ctx context.Context, surveyID, alumniID primitive.ObjectID) (model.SurveyResponse, error)
+	FindByAlumni(ctx context.Context, alumniID primitive.ObjectID) ([]model.SurveyResponse, error)
+	FindBySurvey(ctx context.Context, surveyID primitive.ObjectID, cohort model.AlumniFilter) ([]model.SurveyResponse, error)
+}
+
+type SurveyResponseRepository struct {
+	Col      *mongo.Collection
+	Timeouts config.DBTimeoutConfig
+}
+
+func NewSurveyResponseRepository(db *mongo.Database, timeouts config.DBTimeoutConfig) SurveyResponseRepositoryInterface {
+	return &SurveyResponseRepository{
+		Col:      db.Collection("survey_responses"),
+		Timeouts: timeouts,
+	}
+}
+
+// Upsert menyimpan jawaban alumni; submit ulang mengganti jawaban sebelumnya tanpa mengubah submitted_at
+func (r *SurveyResponseRepository) Upsert(ctx context.Context, resp *model.SurveyResponse) error {
+	ctx, cancel := withTimeout(ctx, r.Timeouts.Write)
+	defer cancel()
+
+	now := time.Now()
+	opts := options.FindOneAndUpdate().SetUpsert(true).SetReturnDocument(options.After)
+	err := r.Col.FindOneAndUpdate(ctx,
+		bson.M{"survey_id": resp.SurveyID, "alumni_id": resp.AlumniID},
+		bson.M{
+			"$set": bson.M{
+				"user_id":     resp.UserID,
+				"nim":         resp.NIM,
+				"nama":        resp.Nama,
+				"jurusan":     resp.Jurusan,
+				"tahun_lulus": resp.TahunLulus,
+				"answers":     resp.Answers,
+				"updated_at":  now,
+			},
+			"$setOnInsert": bson.M{"submitted_at": now},
+		},
+		opts,
+	).Decode(resp)
+	return translateDuplicate(err)
+}
+
+func (r *SurveyResponseRepository) GetByAlumni(ctx context.Context, surveyID, alumniID primitive.ObjectID) (model.SurveyResponse, error) {
+	ctx, cancel := withTimeout(ctx, r.Timeouts.Read)
+	defer cancel()
+
+	var resp model.SurveyResponse
+	err := r.Col.FindOne(ctx, bson.M{"survey_id": surveyID, "alumni_id": alumniID}).Decode(&resp)
+	return resp, err
+}
+
+// FindByAlumni mengambil semua kuesioner yang sudah diisi alumni (tanpa isi jawaban)
+func (r *SurveyResponseRepository) FindByAlumni(ctx context.Context, alumniID primitive.ObjectID) ([]model.SurveyResponse, error) {
+	ctx, cancel := withTimeout(ctx, r.Timeouts.Read)
+	defer cancel()
+
+	cur, err := r.Col.Find(ctx, bson.M{"alumni_id": alumniID}, options.Find().SetProjection(bson.M{"answers": 0}))
+	if err != nil {
+		return nil, err
+	}
+	defer cur.Close(ctx)
+
+	list := []model.SurveyResponse{}
+	if err := cur.All(ctx, &list); err != nil {
+		return nil, err
+	}
+	return list, nil
+}
+
+// FindBySurvey mengambil semua jawaban kuesioner untuk rekap dan export, urut waktu submit.
+// Jurusan dan TahunLulus di cohort menyaring berdasarkan data yang disalin saat submit.
+func (r *SurveyResponseRepository) FindBySurvey(ctx context.Context, surveyID primitive.ObjectID, cohort model.AlumniFilter) ([]model.SurveyResponse, error) {
+	ctx, cancel := withTimeout(ctx, r.Timeouts.Scan)
+	defer cancel()
+
+	filter := bson.M{"survey_id": surveyID}
+	if cohort.Jurusan != "" {
+		filter["jurusan"] = bson.M{"$regex": "^" + regexp.QuoteMeta(cohort.Jurusan) + "$", "$options": "i"}
+	}
+	if cohort.TahunLulus != 0 {
+		filter["tahun_lulus"] = cohort.TahunLulus
+	}
+
+	cur, err := r.Col.Find(ctx, filter, options.Find().SetSort(bson.D{{Key: "submitted_at", Value: 1}}))
+	if err != nil {
+		return nil, err
+	}
+	defer cur.Close(ctx)
+
+	list := []model.SurveyResponse{}
+	if err := cur.All(ctx, &list); err != nil {
+		return nil, err
+	}
+	return list, nil
+}
@@ -0,0 +1,15 @@
+package repository
+
+import (
+	"context"
+	"time"
+)
+
+// withTimeout membatasi ctx dari pemanggil (biasanya context request) dengan batas waktu d,
+// sehingga query berhenti saat client memutus koneksi atau d terlewati. d <= 0 berarti tanpa batas tambahan.
+func withTimeout(ctx context.Context, d time.Duration) (context.Context, context.CancelFunc) {
+	if d <= 0 {
+		return context.WithCancel(ctx)
+	}
+	return context.WithTimeout(ctx, d)
+}
@@ -0,0 +1,123 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// UnitOfWork menjalankan beberapa penulisan ke banyak collection sebagai satu kesatuan.
+// Repository yang dipanggil dengan ctx milik fn ikut dalam transaksi yang sama;
+// jika fn mengembalikan error semua penulisan dibatalkan.
+type UnitOfWork interface {
+	Do(ctx context.Context, fn func(ctx context.Context) error) error
+}
+
+type mongoUnitOfWork struct {
+	client       *mongo.Client
+	transactions bool
+}
+
+// NewUnitOfWork memakai transaksi MongoDB jika server mendukungnya (replica set atau mongos).
+// Pada server standalone fn tetap dijalankan, tetapi hanya undo dari OnRollback yang bisa membatalkan.
+func NewUnitOfWork(ctx context.Context, db *mongo.Database) UnitOfWork {
+	var hello struct {
+		SetName string `bson:"setName"`
+		Msg     string `bson:"msg"`
+	}
+	if err := db.RunCommand(ctx, bson.D{{Key: "hello", Value: 1}}).Decode(&hello); err != nil {
+		fmt.Println("⚠️ Warning: gagal cek dukungan transaksi MongoDB:", err)
+	}
+
+	uow := &mongoUnitOfWork{client: db.Client(), transactions: hello.SetName != "" || hello.Msg == "isdbgrid"}
+	if !uow.transactions {
+		fmt.Println("⚠️ Warning: MongoDB bukan replica set, operasi multi-dokumen berjalan tanpa transaksi")
+	}
+	return uow
+}
+
+func (u *mongoUnitOfWork) Do(ctx context.Context, fn func(ctx context.Context) error) error {
+	// unit of work bersarang ikut transaksi yang sedang berjalan
+	if rollbackFrom(ctx) != nil {
+		return fn(ctx)
+	}
+	if !u.transactions {
+		return RunWithRollback(ctx, fn)
+	}
+
+	sess, err := u.client.StartSession()
+	if err != nil {
+		return err
+	}
+	defer sess.EndSession(context.WithoutCancel(ctx))
+
+	var log *rollbackLog
+	_, err = sess.WithTransaction(ctx, func(sc mongo.SessionContext) (interface{}, error) {
+		// driver mengulang fn saat transaksi bentrok; efek percobaan sebelumnya dibatalkan dulu
+		log.run(ctx)
+		log = &rollbackLog{}
+		return nil, fn(context.WithValue(sc, rollbackKey{}, log))
+	})
+	if err != nil {
+		log.run(ctx)
+	}
+	return err
+}
+
+// RunWithRollback menjalankan fn tanpa transaksi database; undo yang didaftarkan lewat
+// OnRollback tetap dijalankan jika fn gagal
+func RunWithRollback(ctx context.Context, fn func(ctx context.Context) error) error {
+	log := &rollbackLog{}
+	err := fn(context.WithValue(ctx, rollbackKey{}, log))
+	if err != nil {
+		log.run(ctx)
+	}
+	return err
+}
+
+// OnRollback mendaftarkan undo untuk efek samping di luar database (mis. object di storage)
+// yang dijalankan, urut terbalik, jika unit of work milik ctx gagal. Di luar unit of work undo diabaikan.
+func OnRollback(ctx context.Context, undo func(ctx context.Context)) {
+	if log := rollbackFrom(ctx); log != nil {
+		log.mu.Lock()
+		log.undo = append(log.undo, undo)
+		log.mu.Unlock()
+	}
+}
+
+// InTransaction bernilai true jika ctx membawa transaksi MongoDB, sehingga penulisan
+// database ikut dibatalkan tanpa perlu undo manual
+func InTransaction(ctx context.Context) bool {
+	return mongo.SessionFromContext(ctx) != nil
+}
+
+type rollbackKey struct{}
+
+type rollbackLog struct {
+	mu   sync.Mutex
+	undo []func(ctx context.Context)
+}
+
+func rollbackFrom(ctx context.Context) *rollbackLog {
+	log, _ := ctx.Value(rollbackKey{}).(*rollbackLog)
+	return log
+}
+
+// run menjalankan undo sekali saja; context request boleh sudah dibatalkan
+func (l *rollbackLog) run(ctx context.Context) {
+	if l == nil {
+		return
+	}
+	l.mu.Lock()
+	undo := l.undo
+	l.undo = nil
+	l.mu.Unlock()
+
+	ctx = context.WithoutCancel(ctx)
+	for i := len(undo) - 1; i >= 0; i-- {
+		undo[i](ctx)
+	}
+}
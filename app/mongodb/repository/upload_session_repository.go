@@ -0,0 +1,125 @@
+package repository
+
+import (
+	"alumni-app/app/mongodb/model"
+	"alumni-app/config"
+	"context"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+type UploadSessionRepositoryInterface interface {
+	Create(ctx context.Context, s *model.UploadSession) error
+	GetByID(ctx context.Context, id primitive.ObjectID) (model.UploadSession, error)
+	AppendChunk(ctx context.Context, id primitive.ObjectID, expectedOffset int64, chunk model.UploadChunk, expiresAt time.Time) error
+	SetStatus(ctx context.Context, id primitive.ObjectID, from, to string) error
+	Delete(ctx context.Context, id primitive.ObjectID) error
+	FindExpired(ctx context.Context, before time.Time) ([]model.UploadSession, error)
+}
+
+type UploadSessionRepository struct {
+	Col      *mongo.Collection
+	Timeouts config.DBTimeoutConfig
+}
+
+func NewUploadSessionRepository(db *mongo.Database, timeouts config.DBTimeoutConfig) UploadSessionRepositoryInterface {
+	return &UploadSessionRepository{
+		Col:      db.Collection("upload_sessions"),
+		Timeouts: timeouts,
+	}
+}
+
+func (r *UploadSessionRepository) Create(ctx context.Context, s *model.UploadSession) error {
+	ctx, cancel := withTimeout(ctx, r.Timeouts.Write)
+	defer cancel()
+
+	s.ID = primitive.NewObjectID()
+	s.CreatedAt = time.Now()
+	s.UpdatedAt = s.CreatedAt
+	s.Status = model.UploadSessionUploading
+	if s.Chunks == nil {
+		s.Chunks = []model.UploadChunk{}
+	}
+
+	_, err := r.Col.InsertOne(ctx, s)
+	return err
+}
+
+func (r *UploadSessionRepository) GetByID(ctx context.Context, id primitive.ObjectID) (model.UploadSession, error) {
+	ctx, cancel := withTimeout(ctx, r.Timeouts.Read)
+	defer cancel()
+
+	var s model.UploadSession
+	err := r.Col.FindOne(ctx, bson.M{"_id": id}).Decode(&s)
+	return s, err
+}
+
+// AppendChunk menambah chunk hanya jika offset sesi masih expectedOffset; selain itu ErrVersionConflict
+func (r *UploadSessionRepository) AppendChunk(ctx context.Context, id primitive.ObjectID, expectedOffset int64, chunk model.UploadChunk, expiresAt time.Time) error {
+	ctx, cancel := withTimeout(ctx, r.Timeouts.Write)
+	defer cancel()
+
+	res, err := r.Col.UpdateOne(ctx,
+		bson.M{"_id": id, "offset": expectedOffset},
+		bson.M{
+			"$set":  bson.M{"offset": expectedOffset + chunk.Size, "expires_at": expiresAt, "updated_at": time.Now()},
+			"$push": bson.M{"chunks": chunk},
+		},
+	)
+	if err != nil {
+		return err
+	}
+	if res.MatchedCount == 0 {
+		return ErrVersionConflict
+	}
+	return nil
+}
+
+// SetStatus mengubah status sesi hanya jika statusnya masih from; selain itu ErrVersionConflict.
+// Dipakai untuk mengklaim sesi sebelum finalize agar dua request tidak merakit file yang sama.
+func (r *UploadSessionRepository) SetStatus(ctx context.Context, id primitive.ObjectID, from, to string) error {
+	ctx, cancel := withTimeout(ctx, r.Timeouts.Write)
+	defer cancel()
+
+	filter := bson.M{"_id": id, "status": from}
+	if from == model.UploadSessionUploading {
+		// sesi lama belum punya field status
+		filter["status"] = bson.M{"$in": bson.A{from, nil}}
+	}
+	res, err := r.Col.UpdateOne(ctx, filter, bson.M{"$set": bson.M{"status": to, "updated_at": time.Now()}})
+	if err != nil {
+		return err
+	}
+	if res.MatchedCount == 0 {
+		return ErrVersionConflict
+	}
+	return nil
+}
+
+func (r *UploadSessionRepository) Delete(ctx context.Context, id primitive.ObjectID) error {
+	ctx, cancel := withTimeout(ctx, r.Timeouts.Write)
+	defer cancel()
+
+	_, err := r.Col.DeleteOne(ctx, bson.M{"_id": id})
+	return err
+}
+
+func (r *UploadSessionRepository) FindExpired(ctx context.Context, before time.Time) ([]model.UploadSession, error) {
+	ctx, cancel := withTimeout(ctx, r.Timeouts.Scan)
+	defer cancel()
+
+	cur, err := r.Col.Find(ctx, bson.M{"expires_at": bson.M{"$lt": before}})
+	if err != nil {
+		return nil, err
+	}
+	defer cur.Close(ctx)
+
+	var list []model.UploadSession
+	if err := cur.All(ctx, &list); err != nil {
+		return nil, err
+	}
+	return list, nil
+}
@@ -2,40 +2,49 @@ package repository
 
 import (
 	"alumni-app/app/mongodb/model"
+	"alumni-app/config"
 	"context"
 	"time"
 
 	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
 	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
 )
 
 type UserRepositoryInterface interface {
-	GetByUsername(username string) (model.User, error)
-	GetByID(id interface{}) (model.User, error)
-	Create(user *model.User) error
+	GetByUsername(ctx context.Context, username string) (model.User, error)
+	GetByID(ctx context.Context, id interface{}) (model.User, error)
+	GetByEmail(ctx context.Context, email string) (model.User, error)
+	Create(ctx context.Context, user *model.User) error
+	Delete(ctx context.Context, id primitive.ObjectID) error
+	UpdatePassword(ctx context.Context, id primitive.ObjectID, passwordHash string) error
+	MarkEmailVerified(ctx context.Context, id primitive.ObjectID, email string) error
 }
 
 type UserRepository struct {
-	Col *mongo.Collection
+	Col      *mongo.Collection
+	Timeouts config.DBTimeoutConfig
 }
 
-func NewUserRepository(db *mongo.Database) UserRepositoryInterface {
+func NewUserRepository(db *mongo.Database, timeouts config.DBTimeoutConfig) UserRepositoryInterface {
 	return &UserRepository{
-		Col: db.Collection("users"),
+		Col:      db.Collection("users"),
+		Timeouts: timeouts,
 	}
 }
 
-func (r *UserRepository) GetByUsername(username string) (model.User, error) {
-	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+func (r *UserRepository) GetByUsername(ctx context.Context, username string) (model.User, error) {
+	ctx, cancel := withTimeout(ctx, r.Timeouts.Read)
 	defer cancel()
 
 	var user model.User
-	err := r.Col.FindOne(ctx, bson.M{"username": username}).Decode(&user)
+	err := r.Col.FindOne(ctx, bson.M{"username": username}, options.FindOne().SetCollation(caseInsensitive)).Decode(&user)
 	return user, err
 }
 
-func (r *UserRepository) GetByID(id interface{}) (model.User, error) {
-	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+func (r *UserRepository) GetByID(ctx context.Context, id interface{}) (model.User, error) {
+	ctx, cancel := withTimeout(ctx, r.Timeouts.Read)
 	defer cancel()
 
 	var user model.User
@@ -43,11 +52,64 @@ func (r *UserRepository) GetByID(id interface{}) (model.User, error) {
 	return user, err
 }
 
-func (r *UserRepository) Create(user *model.User) error {
-	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+func (r *UserRepository) GetByEmail(ctx context.Context, email string) (model.User, error) {
+	ctx, cancel := withTimeout(ctx, r.Timeouts.Read)
 	defer cancel()
 
+	var user model.User
+	err := r.Col.FindOne(ctx, bson.M{"email": email}, options.FindOne().SetCollation(caseInsensitive)).Decode(&user)
+	return user, err
+}
+
+func (r *UserRepository) Create(ctx context.Context, user *model.User) error {
+	ctx, cancel := withTimeout(ctx, r.Timeouts.Write)
+	defer cancel()
+
+	// keunikan dijamin index unik (bukan cek-lalu-insert) sehingga aman dari registrasi bersamaan
 	user.CreatedAt = time.Now()
 	_, err := r.Col.InsertOne(ctx, user)
+	return translateDuplicate(err)
+}
+
+// Delete menghapus user secara permanen; dipakai untuk membatalkan registrasi yang gagal
+func (r *UserRepository) Delete(ctx context.Context, id primitive.ObjectID) error {
+	ctx, cancel := withTimeout(ctx, r.Timeouts.Write)
+	defer cancel()
+
+	_, err := r.Col.DeleteOne(ctx, bson.M{"_id": id})
 	return err
 }
+
+func (r *UserRepository) UpdatePassword(ctx context.Context, id primitive.ObjectID, passwordHash string) error {
+	ctx, cancel := withTimeout(ctx, r.Timeouts.Write)
+	defer cancel()
+
+	res, err := r.Col.UpdateOne(ctx, bson.M{"_id": id}, bson.M{"$set": bson.M{"password_hash": passwordHash}})
+	if err != nil {
+		return err
+	}
+	if res.MatchedCount == 0 {
+		return mongo.ErrNoDocuments
+	}
+	return nil
+}
+
+// MarkEmailVerified menandai email terverifikasi hanya jika email user masih sama dengan
+// email yang diverifikasi; gagal dengan mongo.ErrNoDocuments jika tidak cocok
+func (r *UserRepository) MarkEmailVerified(ctx context.Context, id primitive.ObjectID, email string) error {
+	ctx, cancel := withTimeout(ctx, r.Timeouts.Write)
+	defer cancel()
+
+	res, err := r.Col.UpdateOne(ctx,
+		bson.M{"_id": id, "email": email},
+		bson.M{"$set": bson.M{"email_verified": true, "email_verified_at": time.Now()}},
+		options.Update().SetCollation(caseInsensitive),
+	)
+	if err != nil {
+		return err
+	}
+	if res.MatchedCount == 0 {
+		return mongo.ErrNoDocuments
+	}
+	return nil
+}
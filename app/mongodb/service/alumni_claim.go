@@ -0,0 +1,307 @@
+package service
+
+import (
+	"alumni-app/app/mongodb/model"
+	"alumni-app/app/mongodb/repository"
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// Claim godoc
+// @Summary Klaim data alumni berdasarkan NIM
+// @Description Menghubungkan akun login ke data alumni yang sudah ada. Kirim NIM beserta tanggal lahir atau email yang tercatat.
+// @Description Jika tanggal lahir cocok dan data belum terhubung, akun langsung dihubungkan (200). Email tampil di daftar alumni,
+// @Description sehingga klaim yang hanya cocok lewat email selalu diteruskan ke admin. Selain itu responnya selalu 202 dengan pesan yang sama,
+// @Description baik NIM tidak terdaftar, data verifikasi tidak cocok, maupun klaim diteruskan ke admin, agar keberadaan NIM tidak bocor.
+// @Description Klaim gagal dibatasi per user.
+// @Tags Me
+// @Accept json
+// @Produce json
+// @Param body body model.ClaimRequest true "NIM dan faktor verifikasi"
+// @Success 200 {object} fiber.Map
+// @Success 202 {object} fiber.Map
+// @Failure 400 {object} fiber.Map
+// @Failure 409 {object} fiber.Map "Akun sudah terhubung ke data alumni"
+// @Failure 429 {object} fiber.Map
+// @Failure 500 {object} fiber.Map
+// @Security BearerAuth
+// @Router /me/alumni/claim [post]
+func (s *AlumniService) Claim(c *fiber.Ctx) error {
+	userID, _ := c.Locals("user_id").(primitive.ObjectID)
+
+	// klaim gagal dihitung per user agar NIM tidak bisa ditebak satu per satu
+	failKey := "claim_fail:" + userID.Hex()
+	if count, resetAt, _ := s.limiter.Get(failKey); count >= s.rateCfg.ClaimMaxFailures {
+		c.Set(fiber.HeaderRetryAfter, strconv.Itoa(int(time.Until(resetAt).Seconds())+1))
+		return c.Status(429).JSON(fiber.Map{"error": "Terlalu banyak klaim gagal, coba lagi nanti"})
+	}
+
+	var req model.ClaimRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": "Request tidak valid"})
+	}
+	req.NIM = strings.TrimSpace(req.NIM)
+	req.Email = strings.TrimSpace(req.Email)
+	if req.NIM == "" || (req.TanggalLahir == "" && req.Email == "") {
+		return c.Status(400).JSON(fiber.Map{"error": "NIM dan tanggal lahir atau email wajib diisi"})
+	}
+	var lahir *time.Time
+	if req.TanggalLahir != "" {
+		t, err := time.Parse(time.DateOnly, req.TanggalLahir)
+		if err != nil {
+			return c.Status(400).JSON(fiber.Map{"error": "Format tanggal_lahir harus YYYY-MM-DD"})
+		}
+		lahir = &t
+	}
+
+	if _, err := s.repo.GetByUserID(c.UserContext(), userID); err == nil {
+		return c.Status(409).JSON(fiber.Map{"error": "Akun ini sudah terhubung ke data alumni"})
+	}
+
+	// NIM tidak ditemukan, faktor yang salah, dan klaim yang diteruskan ke admin dijawab sama persis
+	alumni, err := s.repo.GetByNIM(c.UserContext(), req.NIM)
+	if err != nil {
+		recordFailure(s.limiter, failKey, s.rateCfg.ClaimMaxFailures, s.rateCfg.ClaimLockout)
+		return claimAccepted(c)
+	}
+	method, checked, ok := verifyClaim(alumni, req.Email, lahir)
+	if checked && !ok {
+		recordFailure(s.limiter, failKey, s.rateCfg.ClaimMaxFailures, s.rateCfg.ClaimLockout)
+		return claimAccepted(c)
+	}
+
+	claim := model.AlumniClaim{UserID: userID, AlumniID: alumni.ID, NIM: alumni.NIM, Method: method}
+
+	// kasus yang tidak bisa diputuskan otomatis diteruskan ke admin; email bisa disalin dari
+	// GET /alumni sehingga tidak cukup untuk menghubungkan akun tanpa pemeriksaan
+	if !checked || !alumni.UserID.IsZero() || method != "tanggal_lahir" {
+		claim.Status = model.ClaimPending
+		claim.Reason = model.ClaimReasonAlreadyLinked
+		if alumni.UserID.IsZero() {
+			claim.Reason = model.ClaimReasonEmailOnly
+		}
+		if !checked {
+			// tetap dihitung gagal: klaim tanpa verifikasi juga bisa dipakai menebak NIM
+			recordFailure(s.limiter, failKey, s.rateCfg.ClaimMaxFailures, s.rateCfg.ClaimLockout)
+			claim.Reason = model.ClaimReasonUnverified
+		}
+		// klaim pending yang sama sudah ada; tetap dijawab seperti klaim baru
+		if err := s.claimRepo.Create(c.UserContext(), &claim); err != nil && !errors.Is(err, repository.ErrConflict) {
+			return c.Status(500).JSON(fiber.Map{"error": err.Error()})
+		}
+		return claimAccepted(c)
+	}
+
+	claim.Status = model.ClaimApproved
+	err = s.uow.Do(c.UserContext(), func(ctx context.Context) error {
+		if err := s.linkUser(ctx, alumni, userID); err != nil {
+			return err
+		}
+		return s.claimRepo.Create(ctx, &claim)
+	})
+	if err != nil {
+		return claimLinkError(c, err)
+	}
+	s.limiter.Reset(failKey)
+
+	alumni.UserID = userID
+	alumni.Version++
+	return c.JSON(fiber.Map{"success": true, "message": "Data alumni berhasil dihubungkan ke akun", "data": alumni, "claim": claim})
+}
+
+// GetMyClaims godoc
+// @Summary Riwayat klaim data alumni milik user login
+// @Tags Me
+// @Produce json
+// @Param page query int false "Halaman saat ini"
+// @Param limit query int false "Jumlah data per halaman (max 100)"
+// @Success 200 {object} model.AlumniClaimResponse
+// @Failure 400 {object} fiber.Map
+// @Failure 500 {object} fiber.Map
+// @Security BearerAuth
+// @Router /me/alumni/claims [get]
+func (s *AlumniService) GetMyClaims(c *fiber.Ctx) error {
+	userID, _ := c.Locals("user_id").(primitive.ObjectID)
+	return s.listClaims(c, userID, "")
+}
+
+// GetClaims godoc
+// @Summary Daftar klaim data alumni (admin)
+// @Description Klaim terbaru lebih dulu; gunakan status=pending untuk antrian persetujuan
+// @Tags Alumni
+// @Produce json
+// @Param status query string false "pending, approved, atau rejected"
+// @Param page query int false "Halaman saat ini"
+// @Param limit query int false "Jumlah data per halaman (max 100)"
+// @Success 200 {object} model.AlumniClaimResponse
+// @Failure 400 {object} fiber.Map
+// @Failure 403 {object} fiber.Map
+// @Failure 500 {object} fiber.Map
+// @Security BearerAuth
+// @Router /alumni/claims [get]
+func (s *AlumniService) GetClaims(c *fiber.Ctx) error {
+	status := c.Query("status")
+	if status != "" && status != model.ClaimPending && status != model.ClaimApproved && status != model.ClaimRejected {
+		return c.Status(400).JSON(fiber.Map{"error": "status harus pending, approved, atau rejected"})
+	}
+	return s.listClaims(c, primitive.NilObjectID, status)
+}
+
+// ReviewClaim godoc
+// @Summary Setujui atau tolak klaim data alumni (admin)
+// @Description Persetujuan menghubungkan data alumni ke akun pengklaim, termasuk memindahkan data yang sudah terhubung ke akun lain. Catatan wajib diisi jika klaim ditolak.
+// @Tags Alumni
+// @Accept json
+// @Produce json
+// @Param id path string true "ID klaim"
+// @Param body body model.ClaimReviewRequest true "Keputusan admin"
+// @Success 200 {object} fiber.Map
+// @Failure 400 {object} fiber.Map
+// @Failure 403 {object} fiber.Map
+// @Failure 404 {object} fiber.Map
+// @Failure 409 {object} fiber.Map
+// @Failure 500 {object} fiber.Map
+// @Security BearerAuth
+// @Router /alumni/claims/{id}/review [post]
+func (s *AlumniService) ReviewClaim(c *fiber.Ctx) error {
+	id, err := primitive.ObjectIDFromHex(c.Params("id"))
+	if err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": "ID tidak valid"})
+	}
+	var req model.ClaimReviewRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": "Request tidak valid"})
+	}
+	req.Notes = strings.TrimSpace(req.Notes)
+	if req.Decision != model.ClaimApproved && req.Decision != model.ClaimRejected {
+		return c.Status(400).JSON(fiber.Map{"error": "decision harus approved atau rejected"})
+	}
+	if req.Decision == model.ClaimRejected && req.Notes == "" {
+		return c.Status(400).JSON(fiber.Map{"error": "Catatan wajib diisi jika klaim ditolak"})
+	}
+
+	claim, err := s.claimRepo.GetByID(c.UserContext(), id)
+	if err != nil {
+		return c.Status(404).JSON(fiber.Map{"error": "Klaim tidak ditemukan"})
+	}
+	if claim.Status != model.ClaimPending {
+		return c.Status(409).JSON(fiber.Map{"error": "Klaim sudah " + claim.Status})
+	}
+	alumni, err := s.repo.GetByID(c.UserContext(), claim.AlumniID)
+	if err != nil && req.Decision == model.ClaimApproved {
+		return c.Status(404).JSON(fiber.Map{"error": "Data alumni tidak ditemukan"})
+	}
+
+	reviewerID, _ := c.Locals("user_id").(primitive.ObjectID)
+	err = s.uow.Do(c.UserContext(), func(ctx context.Context) error {
+		if req.Decision == model.ClaimApproved {
+			if err := s.linkUser(ctx, alumni, claim.UserID); err != nil {
+				return err
+			}
+		}
+		return s.claimRepo.Decide(ctx, claim.ID, req.Decision, reviewerID, req.Notes)
+	})
+	if err != nil {
+		return claimLinkError(c, err)
+	}
+
+	now := time.Now()
+	claim.Status = req.Decision
+	claim.Notes = req.Notes
+	claim.ReviewerID = &reviewerID
+	claim.ReviewedAt = &now
+	return c.JSON(fiber.Map{"success": true, "message": "Klaim " + claim.Status, "data": claim})
+}
+
+// verifyClaim mencocokkan faktor yang dikirim dengan data alumni. checked false berarti
+// tidak ada faktor yang bisa dicocokkan karena datanya kosong; ok true jika semua faktor yang
+// bisa dicocokkan sesuai. method adalah faktor pertama yang cocok.
+func verifyClaim(a model.Alumni, email string, lahir *time.Time) (method string, checked, ok bool) {
+	ok = true
+	if lahir != nil && a.TanggalLahir != nil {
+		checked = true
+		if a.TanggalLahir.UTC().Format(time.DateOnly) == lahir.Format(time.DateOnly) {
+			method = "tanggal_lahir"
+		} else {
+			ok = false
+		}
+	}
+	if email != "" && a.Email != "" {
+		checked = true
+		if !strings.EqualFold(a.Email, email) {
+			ok = false
+		} else if method == "" {
+			method = "email"
+		}
+	}
+	return method, checked, checked && ok
+}
+
+// linkUser menghubungkan alumni ke userID; tanpa transaksi, tautan lama dipulihkan jika unit of work gagal
+func (s *AlumniService) linkUser(ctx context.Context, alumni model.Alumni, userID primitive.ObjectID) error {
+	if err := s.repo.LinkUser(ctx, alumni.ID, userID, alumni.UserID); err != nil {
+		return err
+	}
+	if !repository.InTransaction(ctx) {
+		repository.OnRollback(ctx, func(ctx context.Context) {
+			if err := s.repo.LinkUser(ctx, alumni.ID, alumni.UserID, userID); err != nil {
+				fmt.Println("⚠️ Warning: gagal memulihkan user_id alumni:", err)
+			}
+		})
+	}
+	return nil
+}
+
+// claimAccepted adalah jawaban seragam untuk klaim yang tidak langsung terhubung
+func claimAccepted(c *fiber.Ctx) error {
+	return c.Status(202).JSON(fiber.Map{"success": true, "message": "Klaim diterima dan akan diperiksa"})
+}
+
+// claimLinkError memetakan kegagalan menghubungkan akun ke response
+func claimLinkError(c *fiber.Ctx, err error) error {
+	var conflict *repository.ConflictError
+	switch {
+	case errors.As(err, &conflict) && conflict.Field == "user_id":
+		return c.Status(409).JSON(fiber.Map{"error": "Akun sudah terhubung ke data alumni lain"})
+	case errors.Is(err, mongo.ErrNoDocuments):
+		return c.Status(409).JSON(fiber.Map{"error": "Data alumni atau klaim sudah berubah, muat ulang data terlebih dahulu"})
+	}
+	return c.Status(500).JSON(fiber.Map{"error": err.Error()})
+}
+
+func (s *AlumniService) listClaims(c *fiber.Ctx, userID primitive.ObjectID, status string) error {
+	page := c.QueryInt("page", 1)
+	limit := c.QueryInt("limit", 10)
+	if page < 1 || limit < 1 || limit > 100 {
+		return c.Status(400).JSON(fiber.Map{"error": "page minimal 1 dan limit antara 1 sampai 100"})
+	}
+
+	claims, err := s.claimRepo.Find(c.UserContext(), userID, status, page, limit)
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": err.Error()})
+	}
+	total, err := s.claimRepo.Count(c.UserContext(), userID, status)
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	return c.JSON(model.AlumniClaimResponse{
+		Data: claims,
+		Meta: model.MetaInfo{
+			Page:   page,
+			Limit:  limit,
+			Total:  int(total),
+			Pages:  (int(total) + limit - 1) / limit,
+			SortBy: "created_at",
+			Order:  "desc",
+		},
+	})
+}
@@ -3,19 +3,35 @@ package service
 import (
 	"alumni-app/app/mongodb/model"
 	"alumni-app/app/mongodb/repository"
-	// "fmt"
-	// "time"
+	"alumni-app/config"
+	"alumni-app/utils/mongodb"
+	"context"
+	"errors"
+	"fmt"
+	"time"
 
 	"github.com/gofiber/fiber/v2"
 	"go.mongodb.org/mongo-driver/bson/primitive"
 )
 
 type AlumniService struct {
-	repo repository.AlumniRepositoryInterface
+	repo          repository.AlumniRepositoryInterface
+	pekerjaanRepo repository.PekerjaanRepositoryInterface
+	claimRepo     repository.AlumniClaimRepositoryInterface
+	uow           repository.UnitOfWork
+	limiter       utils.RateLimitStore
+	rateCfg       config.RateLimitConfig
 }
 
-func NewAlumniService(r repository.AlumniRepositoryInterface) *AlumniService {
-	return &AlumniService{repo: r}
+func NewAlumniService(
+	r repository.AlumniRepositoryInterface,
+	pekerjaanRepo repository.PekerjaanRepositoryInterface,
+	claimRepo repository.AlumniClaimRepositoryInterface,
+	uow repository.UnitOfWork,
+	limiter utils.RateLimitStore,
+	rateCfg config.RateLimitConfig,
+) *AlumniService {
+	return &AlumniService{repo: r, pekerjaanRepo: pekerjaanRepo, claimRepo: claimRepo, uow: uow, limiter: limiter, rateCfg: rateCfg}
 }
 
 // GetAll godoc
@@ -27,6 +43,9 @@ func NewAlumniService(r repository.AlumniRepositoryInterface) *AlumniService {
 // @Param page query int false "Halaman saat ini"
 // @Param limit query int false "Jumlah data per halaman"
 // @Param search query string false "Kata kunci pencarian"
+// @Param jurusan query string false "Filter jurusan"
+// @Param angkatan query int false "Filter angkatan"
+// @Param tahun_lulus query int false "Filter tahun lulus"
 // @Param sortBy query string false "Kolom untuk sorting"
 // @Param order query string false "Urutan sorting (asc/desc)"
 // @Success 200 {object} model.AlumniResponse
@@ -39,13 +58,19 @@ func (s *AlumniService) GetAll(c *fiber.Ctx) error {
 	search := c.Query("search", "")
 	sortBy := c.Query("sortBy", "created_at")
 	order := c.Query("order", "asc")
+	filter := model.AlumniFilter{
+		Search:     search,
+		Jurusan:    c.Query("jurusan"),
+		Angkatan:   c.QueryInt("angkatan"),
+		TahunLulus: c.QueryInt("tahun_lulus"),
+	}
 
-	data, err := s.repo.GetAll(search, sortBy, order, page, limit)
+	data, err := s.repo.GetAll(c.UserContext(), filter, sortBy, order, page, limit)
 	if err != nil {
 		return c.Status(500).JSON(fiber.Map{"error": err.Error()})
 	}
 
-	total, _ := s.repo.Count(search)
+	total, _ := s.repo.Count(c.UserContext(), filter)
 	return c.JSON(model.AlumniResponse{
 		Data: data,
 		Meta: model.MetaInfo{
@@ -67,7 +92,9 @@ func (s *AlumniService) GetAll(c *fiber.Ctx) error {
 // @Accept json
 // @Produce json
 // @Param id path string true "ID Alumni"
+// @Param If-None-Match header string false "ETag yang sudah dimiliki klien"
 // @Success 200 {object} model.Alumni
+// @Success 304 "Data tidak berubah"
 // @Failure 400 {object} fiber.Map
 // @Failure 404 {object} fiber.Map
 // @Security BearerAuth
@@ -78,24 +105,178 @@ func (s *AlumniService) GetByID(c *fiber.Ctx) error {
 	if err != nil {
 		return c.Status(400).JSON(fiber.Map{"error": "ID tidak valid"})
 	}
-	data, err := s.repo.GetByID(id)
+	data, err := s.repo.GetByID(c.UserContext(), id)
 	if err != nil {
 		return c.Status(404).JSON(fiber.Map{"error": "Data tidak ditemukan"})
 	}
+
+	etag := utils.ETag(data.ID, data.Version)
+	c.Set(fiber.HeaderETag, etag)
+	if match := c.Get(fiber.HeaderIfNoneMatch); match != "" && utils.MatchWeakETag(match, etag) {
+		return c.SendStatus(fiber.StatusNotModified)
+	}
+	return c.JSON(data)
+}
+
+// Update godoc
+// @Summary Perbarui data alumni
+// @Description Memperbarui profil alumni, hanya admin atau pemilik data yang diizinkan. Kirim If-Match (ETag dari GET) atau field version untuk mencegah menimpa perubahan orang lain.
+// @Tags Alumni
+// @Accept json
+// @Produce json
+// @Param id path string true "ID Alumni"
+// @Param If-Match header string false "ETag dari GET /alumni/{id}"
+// @Param body body model.UpdateAlumniRequest true "Data alumni baru"
+// @Success 200 {object} fiber.Map
+// @Failure 400 {object} fiber.Map
+// @Failure 403 {object} fiber.Map
+// @Failure 404 {object} fiber.Map
+// @Failure 409 {object} fiber.Map
+// @Failure 412 {object} fiber.Map
+// @Failure 500 {object} fiber.Map
+// @Security BearerAuth
+// @Router /alumni/{id} [put]
+func (s *AlumniService) Update(c *fiber.Ctx) error {
+	idStr := c.Params("id")
+	id, err := primitive.ObjectIDFromHex(idStr)
+	if err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": "ID tidak valid"})
+	}
+
+	role, _ := c.Locals("role").(string)
+	userID, _ := c.Locals("user_id").(primitive.ObjectID)
+
+	existing, err := s.repo.GetByID(c.UserContext(), id)
+	if err != nil {
+		return c.Status(404).JSON(fiber.Map{"error": "Data tidak ditemukan"})
+	}
+
+	if role != "admin" && existing.UserID != userID {
+		return c.Status(403).JSON(fiber.Map{"error": "Tidak diizinkan mengubah data alumni milik orang lain"})
+	}
+
+	return s.update(c, existing)
+}
+
+// GetMine godoc
+// @Summary Profil alumni milik user login
+// @Description Mengambil data alumni yang terhubung dengan user dari token JWT
+// @Tags Me
+// @Accept json
+// @Produce json
+// @Success 200 {object} model.Alumni
+// @Success 304 "Data tidak berubah"
+// @Failure 404 {object} fiber.Map
+// @Security BearerAuth
+// @Router /me/alumni [get]
+func (s *AlumniService) GetMine(c *fiber.Ctx) error {
+	userID, _ := c.Locals("user_id").(primitive.ObjectID)
+
+	data, err := s.repo.GetByUserID(c.UserContext(), userID)
+	if err != nil {
+		return c.Status(404).JSON(fiber.Map{"error": "Data alumni untuk user ini tidak ditemukan"})
+	}
+
+	etag := utils.ETag(data.ID, data.Version)
+	c.Set(fiber.HeaderETag, etag)
+	if match := c.Get(fiber.HeaderIfNoneMatch); match != "" && utils.MatchWeakETag(match, etag) {
+		return c.SendStatus(fiber.StatusNotModified)
+	}
 	return c.JSON(data)
 }
 
+// UpdateMine godoc
+// @Summary Perbarui profil alumni milik user login
+// @Description Sama seperti PUT /alumni/{id} tanpa perlu mengetahui ID alumni
+// @Tags Me
+// @Accept json
+// @Produce json
+// @Param If-Match header string false "ETag dari GET /me/alumni"
+// @Param body body model.UpdateAlumniRequest true "Data alumni baru"
+// @Success 200 {object} fiber.Map
+// @Failure 400 {object} fiber.Map
+// @Failure 404 {object} fiber.Map
+// @Failure 409 {object} fiber.Map
+// @Failure 412 {object} fiber.Map
+// @Failure 500 {object} fiber.Map
+// @Security BearerAuth
+// @Router /me/alumni [put]
+func (s *AlumniService) UpdateMine(c *fiber.Ctx) error {
+	userID, _ := c.Locals("user_id").(primitive.ObjectID)
+
+	existing, err := s.repo.GetByUserID(c.UserContext(), userID)
+	if err != nil {
+		return c.Status(404).JSON(fiber.Map{"error": "Data alumni untuk user ini tidak ditemukan"})
+	}
+
+	return s.update(c, existing)
+}
+
+// update menerapkan body request ke data alumni yang sudah lolos cek akses
+func (s *AlumniService) update(c *fiber.Ctx, existing model.Alumni) error {
+	var req model.UpdateAlumniRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": "Request tidak valid"})
+	}
+	if req.Nama == "" || req.Jurusan == "" || req.Email == "" {
+		return c.Status(400).JSON(fiber.Map{"error": "Nama, jurusan, dan email wajib diisi"})
+	}
+
+	a := existing
+	a.Nama = req.Nama
+	a.Jurusan = req.Jurusan
+	a.Email = req.Email
+	a.NoTelepon = req.NoTelepon
+	a.Alamat = req.Alamat
+	a.Angkatan = req.Angkatan
+	a.TahunLulus = req.TahunLulus
+	a.Version = req.Version
+	if req.TanggalLahir != "" {
+		lahir, err := time.Parse(time.DateOnly, req.TanggalLahir)
+		if err != nil {
+			return c.Status(400).JSON(fiber.Map{"error": "Format tanggal_lahir harus YYYY-MM-DD"})
+		}
+		a.TanggalLahir = &lahir
+	}
+
+	if match := c.Get(fiber.HeaderIfMatch); match != "" {
+		if !utils.MatchStrongETag(match, utils.ETag(existing.ID, existing.Version)) {
+			return c.Status(412).JSON(fiber.Map{"error": "Data alumni sudah berubah, muat ulang data terlebih dahulu"})
+		}
+		a.Version = existing.Version
+	}
+
+	if err := s.repo.Update(c.UserContext(), existing.ID, &a); err != nil {
+		if errors.Is(err, repository.ErrVersionConflict) {
+			return c.Status(409).JSON(fiber.Map{
+				"error":           "Data alumni sudah diubah oleh pengguna lain, muat ulang data terlebih dahulu",
+				"current_version": existing.Version,
+			})
+		}
+		var conflict *repository.ConflictError
+		if errors.As(err, &conflict) {
+			return c.Status(409).JSON(fiber.Map{"error": conflict.Field + " sudah digunakan", "field": conflict.Field})
+		}
+		return c.Status(500).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	c.Set(fiber.HeaderETag, utils.ETag(a.ID, a.Version))
+	return c.JSON(fiber.Map{"success": true, "message": "Data alumni berhasil diperbarui", "data": a})
+}
+
 // Delete godoc
 // @Summary Hapus data alumni
-// @Description Menghapus (soft delete) data alumni berdasarkan ID, hanya admin atau pemilik data yang diizinkan
+// @Description Menghapus (soft delete) data alumni beserta riwayat pekerjaannya dalam satu transaksi, hanya admin atau pemilik data yang diizinkan
 // @Tags Alumni
 // @Accept json
 // @Produce json
 // @Param id path string true "ID Alumni"
+// @Param If-Match header string false "ETag dari GET /alumni/{id}"
 // @Success 200 {object} fiber.Map
 // @Failure 400 {object} fiber.Map
 // @Failure 403 {object} fiber.Map
 // @Failure 404 {object} fiber.Map
+// @Failure 412 {object} fiber.Map
 // @Failure 500 {object} fiber.Map
 // @Security BearerAuth
 // @Router /alumni/{id} [delete]
@@ -111,7 +292,7 @@ func (s *AlumniService) Delete(c *fiber.Ctx) error {
 	userID, _ := c.Locals("user_id").(primitive.ObjectID)
 
 	// ambil data alumni yang mau dihapus
-	alumni, err := s.repo.GetByID(id)
+	alumni, err := s.repo.GetByID(c.UserContext(), id)
 	if err != nil {
 		return c.Status(404).JSON(fiber.Map{"error": "Data tidak ditemukan"})
 	}
@@ -121,11 +302,30 @@ func (s *AlumniService) Delete(c *fiber.Ctx) error {
 		return c.Status(403).JSON(fiber.Map{"error": "Tidak diizinkan menghapus data alumni milik orang lain"})
 	}
 
-	// soft delete
-	if err := s.repo.SoftDelete(id); err != nil {
+	if match := c.Get(fiber.HeaderIfMatch); match != "" && !utils.MatchStrongETag(match, utils.ETag(alumni.ID, alumni.Version)) {
+		return c.Status(412).JSON(fiber.Map{"error": "Data alumni sudah berubah, muat ulang data terlebih dahulu"})
+	}
+
+	// soft delete alumni dan pekerjaannya sekaligus; gagal salah satu berarti tidak ada yang terhapus
+	var pekerjaanDeleted int64
+	err = s.uow.Do(c.UserContext(), func(ctx context.Context) error {
+		if err := s.repo.SoftDelete(ctx, id); err != nil {
+			return err
+		}
+		if !repository.InTransaction(ctx) {
+			repository.OnRollback(ctx, func(ctx context.Context) {
+				if err := s.repo.Restore(ctx, id); err != nil {
+					fmt.Println("⚠️ Warning: gagal memulihkan alumni:", err)
+				}
+			})
+		}
+		pekerjaanDeleted, err = s.pekerjaanRepo.SoftDeleteByAlumniID(ctx, id)
+		return err
+	})
+	if err != nil {
 		return c.Status(500).JSON(fiber.Map{"error": err.Error()})
 	}
-	return c.JSON(fiber.Map{"success": true, "message": "Data berhasil dihapus"})
+	return c.JSON(fiber.Map{"success": true, "message": "Data berhasil dihapus", "pekerjaan_deleted": pekerjaanDeleted})
 }
 
 // func (s *AlumniService) UploadFiles(c *fiber.Ctx) error {
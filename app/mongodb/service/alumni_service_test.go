@@ -3,8 +3,17 @@ package service
 import (
 	"alumni-app/app/mongodb/model"
 	"alumni-app/app/mongodb/repository/mock"
+	"alumni-app/config"
+	"alumni-app/utils/mongodb"
+	"context"
+	"encoding/json"
+	"errors"
+	"net"
 	"net/http/httptest"
+	"strconv"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/gofiber/fiber/v2"
 	"go.mongodb.org/mongo-driver/bson/primitive"
@@ -12,7 +21,7 @@ import (
 
 func TestGetAll(t *testing.T) {
 	mockRepo := repository.NewMockAlumniRepository()
-	service := NewAlumniService(mockRepo)
+	service := NewAlumniService(mockRepo, repository.NewMockPekerjaanRepository(), repository.NewMockAlumniClaimRepository(), repository.NewMockUnitOfWork(), utils.NewMemoryRateLimitStore(), testRateCfg)
 
 	app := fiber.New()
 	app.Get("/alumni", service.GetAll)
@@ -36,7 +45,7 @@ func TestGetAll(t *testing.T) {
 
 func TestGetByID(t *testing.T) {
 	mockRepo := repository.NewMockAlumniRepository()
-	service := NewAlumniService(mockRepo)
+	service := NewAlumniService(mockRepo, repository.NewMockPekerjaanRepository(), repository.NewMockAlumniClaimRepository(), repository.NewMockUnitOfWork(), utils.NewMemoryRateLimitStore(), testRateCfg)
 
 	app := fiber.New()
 	app.Get("/alumni/:id", service.GetByID)
@@ -79,7 +88,7 @@ func TestGetByID(t *testing.T) {
 
 func TestDelete(t *testing.T) {
 	mockRepo := repository.NewMockAlumniRepository()
-	service := NewAlumniService(mockRepo)
+	service := NewAlumniService(mockRepo, repository.NewMockPekerjaanRepository(), repository.NewMockAlumniClaimRepository(), repository.NewMockUnitOfWork(), utils.NewMemoryRateLimitStore(), testRateCfg)
 
 	app := fiber.New()
 	app.Delete("/alumni/:id", func(c *fiber.Ctx) error {
@@ -124,3 +133,437 @@ func TestDelete(t *testing.T) {
 	})
 }
 
+func TestAlumniETag(t *testing.T) {
+	mockRepo := repository.NewMockAlumniRepository()
+	service := NewAlumniService(mockRepo, repository.NewMockPekerjaanRepository(), repository.NewMockAlumniClaimRepository(), repository.NewMockUnitOfWork(), utils.NewMemoryRateLimitStore(), testRateCfg)
+
+	app := fiber.New()
+	app.Use(func(c *fiber.Ctx) error {
+		c.Locals("role", "admin")
+		c.Locals("user_id", primitive.NewObjectID())
+		return c.Next()
+	})
+	app.Get("/alumni/:id", service.GetByID)
+	app.Put("/alumni/:id", service.Update)
+
+	// seed
+	id := primitive.NewObjectID()
+	mockRepo.Data[id.Hex()] = model.Alumni{
+		ID:      id,
+		Nama:    "Farid",
+		Jurusan: "TI",
+		Email:   "farid@example.com",
+		Version: 3,
+	}
+	etag := utils.ETag(id, 3)
+	body := `{"nama":"Farid R","jurusan":"TI","email":"farid@example.com"}`
+
+	t.Run("Get Returns ETag", func(t *testing.T) {
+		req := httptest.NewRequest("GET", "/alumni/"+id.Hex(), nil)
+		resp, _ := app.Test(req)
+
+		if resp.Header.Get("ETag") != etag {
+			t.Errorf("expected ETag %s, got %s", etag, resp.Header.Get("ETag"))
+		}
+	})
+
+	t.Run("If-None-Match Not Modified", func(t *testing.T) {
+		req := httptest.NewRequest("GET", "/alumni/"+id.Hex(), nil)
+		req.Header.Set("If-None-Match", etag)
+		resp, _ := app.Test(req)
+
+		if resp.StatusCode != 304 {
+			t.Errorf("expected 304, got %d", resp.StatusCode)
+		}
+	})
+
+	t.Run("Weak If-None-Match Not Modified", func(t *testing.T) {
+		req := httptest.NewRequest("GET", "/alumni/"+id.Hex(), nil)
+		req.Header.Set("If-None-Match", "W/"+etag)
+		resp, _ := app.Test(req)
+
+		if resp.StatusCode != 304 {
+			t.Errorf("expected 304, got %d", resp.StatusCode)
+		}
+	})
+
+	t.Run("Weak If-Match", func(t *testing.T) {
+		// If-Match memakai perbandingan strong: tag weak tidak pernah cocok
+		req := httptest.NewRequest("PUT", "/alumni/"+id.Hex(), strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("If-Match", "W/"+etag)
+		resp, _ := app.Test(req)
+
+		if resp.StatusCode != 412 {
+			t.Errorf("expected 412, got %d", resp.StatusCode)
+		}
+	})
+
+	t.Run("Stale If-Match", func(t *testing.T) {
+		req := httptest.NewRequest("PUT", "/alumni/"+id.Hex(), strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("If-Match", utils.ETag(id, 2))
+		resp, _ := app.Test(req)
+
+		if resp.StatusCode != 412 {
+			t.Errorf("expected 412, got %d", resp.StatusCode)
+		}
+	})
+
+	t.Run("Matching If-Match", func(t *testing.T) {
+		req := httptest.NewRequest("PUT", "/alumni/"+id.Hex(), strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("If-Match", etag)
+		resp, _ := app.Test(req)
+
+		if resp.StatusCode != 200 {
+			t.Errorf("expected 200, got %d", resp.StatusCode)
+		}
+		if resp.Header.Get("ETag") != utils.ETag(id, 4) {
+			t.Errorf("expected new ETag for version 4, got %s", resp.Header.Get("ETag"))
+		}
+	})
+}
+
+func TestAlumniMine(t *testing.T) {
+	mockRepo := repository.NewMockAlumniRepository()
+	service := NewAlumniService(mockRepo, repository.NewMockPekerjaanRepository(), repository.NewMockAlumniClaimRepository(), repository.NewMockUnitOfWork(), utils.NewMemoryRateLimitStore(), testRateCfg)
+
+	ownerID := primitive.NewObjectID()
+	strangerID := primitive.NewObjectID()
+
+	app := fiber.New()
+	app.Use(func(c *fiber.Ctx) error {
+		// user_id diambil dari header agar bisa berganti per request
+		if c.Get("X-Test-User") == "stranger" {
+			c.Locals("user_id", strangerID)
+		} else {
+			c.Locals("user_id", ownerID)
+		}
+		c.Locals("role", "user")
+		return c.Next()
+	})
+	app.Get("/me/alumni", service.GetMine)
+	app.Put("/me/alumni", service.UpdateMine)
+
+	// seed
+	id := primitive.NewObjectID()
+	mockRepo.Data[id.Hex()] = model.Alumni{
+		ID:      id,
+		Nama:    "Farid",
+		Jurusan: "TI",
+		Email:   "farid@example.com",
+		UserID:  ownerID,
+	}
+
+	t.Run("Get Own Profile", func(t *testing.T) {
+		req := httptest.NewRequest("GET", "/me/alumni", nil)
+		resp, _ := app.Test(req)
+
+		if resp.StatusCode != 200 {
+			t.Errorf("expected 200, got %d", resp.StatusCode)
+		}
+	})
+
+	t.Run("No Profile", func(t *testing.T) {
+		req := httptest.NewRequest("GET", "/me/alumni", nil)
+		req.Header.Set("X-Test-User", "stranger")
+		resp, _ := app.Test(req)
+
+		if resp.StatusCode != 404 {
+			t.Errorf("expected 404, got %d", resp.StatusCode)
+		}
+	})
+
+	t.Run("Update Own Profile", func(t *testing.T) {
+		body := `{"nama":"Farid R","jurusan":"TI","email":"farid@example.com","no_telepon":"0812"}`
+		req := httptest.NewRequest("PUT", "/me/alumni", strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		resp, _ := app.Test(req)
+
+		if resp.StatusCode != 200 {
+			t.Errorf("expected 200, got %d", resp.StatusCode)
+		}
+		if got := mockRepo.Data[id.Hex()]; got.NoTelepon != "0812" || got.UserID != ownerID {
+			t.Errorf("unexpected data after update: %+v", got)
+		}
+	})
+}
+
+func TestDeleteAlumniWithPekerjaan(t *testing.T) {
+	mockRepo := repository.NewMockAlumniRepository()
+	pekerjaanRepo := repository.NewMockPekerjaanRepository()
+	uow := repository.NewMockUnitOfWork()
+	service := NewAlumniService(mockRepo, pekerjaanRepo, repository.NewMockAlumniClaimRepository(), uow, utils.NewMemoryRateLimitStore(), testRateCfg)
+
+	app := fiber.New()
+	app.Use(func(c *fiber.Ctx) error {
+		c.Locals("role", "admin")
+		c.Locals("user_id", primitive.NewObjectID())
+		return c.Next()
+	})
+	app.Delete("/alumni/:id", service.Delete)
+
+	id := primitive.NewObjectID()
+	mockRepo.Data[id.Hex()] = model.Alumni{ID: id, NIM: "123", Nama: "Farid"}
+	mine := []primitive.ObjectID{primitive.NewObjectID(), primitive.NewObjectID()}
+	for _, pid := range mine {
+		pekerjaanRepo.Data[pid.Hex()] = model.PekerjaanAlumni{ID: pid, AlumniID: id, NamaPerusahaan: "PT A"}
+	}
+	otherID := primitive.NewObjectID()
+	pekerjaanRepo.Data[otherID.Hex()] = model.PekerjaanAlumni{ID: otherID, AlumniID: primitive.NewObjectID(), NamaPerusahaan: "PT B"}
+
+	resp, _ := app.Test(httptest.NewRequest("DELETE", "/alumni/"+id.Hex(), nil))
+	if resp.StatusCode != 200 {
+		t.Fatalf("expected 200, got %d", resp.StatusCode)
+	}
+	if uow.Calls != 1 {
+		t.Errorf("expected delete to run in one unit of work, got %d", uow.Calls)
+	}
+	for _, pid := range mine {
+		if pekerjaanRepo.Data[pid.Hex()].DeletedAt == nil {
+			t.Errorf("expected pekerjaan %s moved to trash", pid.Hex())
+		}
+	}
+	if pekerjaanRepo.Data[otherID.Hex()].DeletedAt != nil {
+		t.Error("pekerjaan milik alumni lain tidak boleh ikut terhapus")
+	}
+
+	t.Run("Rollback", func(t *testing.T) {
+		failID := primitive.NewObjectID()
+		mockRepo.Data[failID.Hex()] = model.Alumni{ID: failID, NIM: "456", Nama: "Budi"}
+		uow.CommitErr = errors.New("commit gagal")
+		defer func() { uow.CommitErr = nil }()
+
+		resp, _ := app.Test(httptest.NewRequest("DELETE", "/alumni/"+failID.Hex(), nil))
+		if resp.StatusCode != 500 {
+			t.Fatalf("expected 500, got %d", resp.StatusCode)
+		}
+		if mockRepo.Data[failID.Hex()].DeletedAt != nil {
+			t.Error("alumni harus dipulihkan saat penghapusan gagal")
+		}
+	})
+}
+
+func TestClaim(t *testing.T) {
+	mockRepo := repository.NewMockAlumniRepository()
+	claimRepo := repository.NewMockAlumniClaimRepository()
+	service := NewAlumniService(mockRepo, repository.NewMockPekerjaanRepository(), claimRepo, repository.NewMockUnitOfWork(), utils.NewMemoryRateLimitStore(), testRateCfg)
+
+	users := map[string]primitive.ObjectID{}
+	for _, name := range []string{"unknown", "wrong", "unverified", "lockout", "owner", "nodata", "copycat"} {
+		users[name] = primitive.NewObjectID()
+	}
+	app := fiber.New()
+	app.Post("/me/alumni/claim", func(c *fiber.Ctx) error {
+		c.Locals("user_id", users[c.Get("X-Test-User")])
+		c.Locals("role", "user")
+		return service.Claim(c)
+	})
+	app.Get("/alumni", service.GetAll)
+
+	// seed
+	lahir := time.Date(2000, 5, 17, 0, 0, 0, 0, time.UTC)
+	verified := model.Alumni{ID: primitive.NewObjectID(), NIM: "A11.2018.001", Nama: "Farid", Email: "farid@example.com", TanggalLahir: &lahir}
+	noData := model.Alumni{ID: primitive.NewObjectID(), NIM: "A11.2018.002", Nama: "Budi"}
+	mockRepo.Data[verified.ID.Hex()] = verified
+	mockRepo.Data[noData.ID.Hex()] = noData
+	listed := model.Alumni{ID: primitive.NewObjectID(), NIM: "A11.2018.003", Nama: "Sari", Email: "sari@example.com", TanggalLahir: &lahir}
+	mockRepo.Data[listed.ID.Hex()] = listed
+
+	t.Run("Indistinguishable Responses", func(t *testing.T) {
+		// NIM tidak terdaftar, faktor salah, dan klaim yang diteruskan ke admin harus terlihat sama
+		unknownCode, unknownBody := sendAs(app, "POST", "/me/alumni/claim", "unknown", `{"nim":"A11.2018.999","tanggal_lahir":"2000-05-17"}`)
+		if unknownCode != 202 {
+			t.Fatalf("expected 202 for unknown NIM, got %d", unknownCode)
+		}
+		cases := map[string]string{
+			"wrong":      `{"nim":"A11.2018.001","tanggal_lahir":"2000-05-18"}`,
+			"unverified": `{"nim":"A11.2018.002","tanggal_lahir":"1999-01-01"}`,
+		}
+		for user, body := range cases {
+			if code, out := sendAs(app, "POST", "/me/alumni/claim", user, body); code != unknownCode || string(out) != string(unknownBody) {
+				t.Errorf("%s: got %d %s, want %d %s", user, code, out, unknownCode, unknownBody)
+			}
+		}
+	})
+
+	t.Run("Lockout After Failures", func(t *testing.T) {
+		for i := 0; i < testRateCfg.ClaimMaxFailures; i++ {
+			sendAs(app, "POST", "/me/alumni/claim", "lockout", `{"nim":"X`+strconv.Itoa(i)+`","email":"x@example.com"}`)
+		}
+		if code, _ := sendAs(app, "POST", "/me/alumni/claim", "lockout", `{"nim":"A11.2018.001","tanggal_lahir":"2000-05-17"}`); code != 429 {
+			t.Errorf("expected 429, got %d", code)
+		}
+	})
+
+	t.Run("Auto Link", func(t *testing.T) {
+		if code, _ := sendAs(app, "POST", "/me/alumni/claim", "owner", `{"nim":"a11.2018.001","tanggal_lahir":"2000-05-17"}`); code != 200 {
+			t.Fatalf("expected 200, got %d", code)
+		}
+		if mockRepo.Data[verified.ID.Hex()].UserID != users["owner"] {
+			t.Error("expected alumni linked to claiming user")
+		}
+		if code, _ := sendAs(app, "POST", "/me/alumni/claim", "owner", `{"nim":"A11.2018.002","email":"x@example.com"}`); code != 409 {
+			t.Errorf("expected 409 for already linked account, got %d", code)
+		}
+	})
+
+	t.Run("Data Copied From Listing", func(t *testing.T) {
+		// NIM dan email tampil di GET /alumni sehingga tidak boleh langsung menghubungkan akun
+		_, body := sendAs(app, "GET", "/alumni", "copycat", "")
+		var list model.AlumniResponse
+		json.Unmarshal(body, &list)
+		var target model.Alumni
+		for _, a := range list.Data {
+			if a.UserID.IsZero() && a.Email != "" {
+				target = a
+			}
+		}
+		if target.ID != listed.ID {
+			t.Fatalf("expected unlinked record with email in listing, got %s", body)
+		}
+		claim, _ := json.Marshal(model.ClaimRequest{NIM: target.NIM, Email: target.Email})
+		if code, _ := sendAs(app, "POST", "/me/alumni/claim", "copycat", string(claim)); code != 202 {
+			t.Fatalf("expected 202, got %d", code)
+		}
+		if !mockRepo.Data[listed.ID.Hex()].UserID.IsZero() {
+			t.Error("email-only claim must not link automatically")
+		}
+		claims, _ := claimRepo.Find(context.Background(), users["copycat"], model.ClaimPending, 1, 10)
+		if len(claims) != 1 || claims[0].Reason != model.ClaimReasonEmailOnly {
+			t.Errorf("expected one pending email-only claim, got %+v", claims)
+		}
+	})
+
+	t.Run("No Verification Data", func(t *testing.T) {
+		if code, _ := sendAs(app, "POST", "/me/alumni/claim", "nodata", `{"nim":"A11.2018.002","tanggal_lahir":"1999-01-01"}`); code != 202 {
+			t.Fatalf("expected 202, got %d", code)
+		}
+		if !mockRepo.Data[noData.ID.Hex()].UserID.IsZero() {
+			t.Error("unverified claim must not link automatically")
+		}
+		claims, _ := claimRepo.Find(context.Background(), users["nodata"], model.ClaimPending, 1, 10)
+		if len(claims) != 1 || claims[0].Reason != model.ClaimReasonUnverified {
+			t.Errorf("expected one pending unverified claim, got %+v", claims)
+		}
+	})
+}
+
+func TestReviewClaim(t *testing.T) {
+	mockRepo := repository.NewMockAlumniRepository()
+	claimRepo := repository.NewMockAlumniClaimRepository()
+	service := NewAlumniService(mockRepo, repository.NewMockPekerjaanRepository(), claimRepo, repository.NewMockUnitOfWork(), utils.NewMemoryRateLimitStore(), testRateCfg)
+
+	// tanpa header X-Test-User request dikirim sebagai admin
+	users := map[string]primitive.ObjectID{"": primitive.NewObjectID(), "owner": primitive.NewObjectID(), "other": primitive.NewObjectID()}
+	app := fiber.New()
+	app.Use(func(c *fiber.Ctx) error {
+		user := c.Get("X-Test-User")
+		c.Locals("user_id", users[user])
+		c.Locals("role", "user")
+		if user == "" {
+			c.Locals("role", "admin")
+		}
+		return c.Next()
+	})
+	app.Post("/me/alumni/claim", service.Claim)
+	app.Get("/alumni/claims", service.GetClaims)
+	app.Post("/alumni/claims/:id/review", service.ReviewClaim)
+
+	// seed: data alumni yang sudah terhubung ke akun lain
+	linked := model.Alumni{ID: primitive.NewObjectID(), NIM: "A11.2018.001", Nama: "Farid", Email: "farid@example.com", UserID: users["owner"]}
+	mockRepo.Data[linked.ID.Hex()] = linked
+
+	t.Run("Already Linked Goes To Admin", func(t *testing.T) {
+		if code, _ := sendAs(app, "POST", "/me/alumni/claim", "other", `{"nim":"A11.2018.001","email":"FARID@example.com"}`); code != 202 {
+			t.Fatalf("expected 202 for record linked to another user, got %d", code)
+		}
+		if code, _ := sendAs(app, "POST", "/me/alumni/claim", "other", `{"nim":"A11.2018.001","email":"farid@example.com"}`); code != 202 {
+			t.Errorf("expected duplicate pending claim to look like a new one, got %d", code)
+		}
+		if len(claimRepo.Data) != 1 {
+			t.Errorf("expected one pending claim, got %d", len(claimRepo.Data))
+		}
+	})
+
+	t.Run("List Pending", func(t *testing.T) {
+		code, body := sendAs(app, "GET", "/alumni/claims?status=pending", "", "")
+		var res model.AlumniClaimResponse
+		json.Unmarshal(body, &res)
+		if code != 200 || len(res.Data) != 1 || res.Data[0].Reason != model.ClaimReasonAlreadyLinked {
+			t.Errorf("expected one already-linked claim, got %d %s", code, body)
+		}
+	})
+
+	t.Run("Review", func(t *testing.T) {
+		var pending model.AlumniClaim
+		for _, c := range claimRepo.Data {
+			pending = c
+		}
+		path := "/alumni/claims/" + pending.ID.Hex() + "/review"
+		if code, _ := sendAs(app, "POST", path, "", `{"decision":"rejected"}`); code != 400 {
+			t.Errorf("expected 400 without notes, got %d", code)
+		}
+		if code, _ := sendAs(app, "POST", path, "", `{"decision":"approved"}`); code != 200 {
+			t.Fatalf("expected 200, got %d", code)
+		}
+		if mockRepo.Data[linked.ID.Hex()].UserID != users["other"] {
+			t.Error("expected alumni moved to approved claimant")
+		}
+		if code, _ := sendAs(app, "POST", path, "", `{"decision":"approved"}`); code != 409 {
+			t.Errorf("expected 409 for second review, got %d", code)
+		}
+	})
+}
+
+// blockingAlumniRepo menahan GetAll sampai context request dibatalkan
+type blockingAlumniRepo struct {
+	*repository.MockAlumniRepository
+	started chan struct{}
+	done    chan error
+}
+
+func (r *blockingAlumniRepo) GetAll(ctx context.Context, filter model.AlumniFilter, sortBy string, order string, page, limit int) ([]model.Alumni, error) {
+	close(r.started)
+	<-ctx.Done()
+	r.done <- ctx.Err()
+	return nil, ctx.Err()
+}
+
+func TestClientDisconnectCancelsQuery(t *testing.T) {
+	repo := &blockingAlumniRepo{repository.NewMockAlumniRepository(), make(chan struct{}), make(chan error, 1)}
+	service := NewAlumniService(repo, repository.NewMockPekerjaanRepository(), repository.NewMockAlumniClaimRepository(), repository.NewMockUnitOfWork(), utils.NewMemoryRateLimitStore(), testRateCfg)
+
+	app := config.NewApp()
+	app.Get("/alumni", service.GetAll)
+
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	go app.Listener(ln)
+	defer app.ShutdownWithTimeout(time.Second)
+
+	conn, err := net.Dial("tcp", ln.Addr().String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	conn.Write([]byte("GET /alumni HTTP/1.1\r\nHost: test\r\n\r\n"))
+
+	select {
+	case <-repo.started:
+	case <-time.After(5 * time.Second):
+		t.Fatal("query never started")
+	}
+	conn.Close()
+
+	select {
+	case err := <-repo.done:
+		if err != context.Canceled {
+			t.Errorf("expected context.Canceled, got %v", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("query was not cancelled after the client disconnected")
+	}
+}
@@ -0,0 +1,101 @@
+package service
+
+import (
+	"alumni-app/app/mongodb/model"
+	"alumni-app/utils/mongodb"
+	"context"
+	"fmt"
+	"log"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// verifyEmailPurpose membedakan token verifikasi email dari token bertanda tangan lain
+const verifyEmailPurpose = "verify-email"
+
+// VerifyEmail godoc
+// @Summary Verifikasi email akun
+// @Description Membuka link dari email verifikasi. Token hanya berlaku untuk email yang tercatat saat token dibuat.
+// @Tags Users
+// @Produce json
+// @Param token query string true "Token verifikasi dari email"
+// @Success 200 {object} fiber.Map
+// @Failure 400 {object} fiber.Map
+// @Failure 500 {object} fiber.Map
+// @Router /verify-email [get]
+func (s *UserService) VerifyEmail(c *fiber.Ctx) error {
+	invalid := fiber.Map{"error": "Token verifikasi tidak valid atau sudah kadaluarsa"}
+
+	subject, err := utils.VerifySignedToken([]byte(s.verifyCfg.Secret), verifyEmailPurpose, c.Query("token"))
+	if err != nil {
+		return c.Status(400).JSON(invalid)
+	}
+	idHex, email, _ := strings.Cut(subject, ":")
+	id, err := primitive.ObjectIDFromHex(idHex)
+	if err != nil {
+		return c.Status(400).JSON(invalid)
+	}
+
+	user, err := s.repo.GetByID(c.UserContext(), id)
+	if err != nil || !strings.EqualFold(user.Email, email) {
+		return c.Status(400).JSON(invalid)
+	}
+	if user.EmailVerified {
+		return c.JSON(fiber.Map{"success": true, "message": "Email sudah terverifikasi"})
+	}
+	if err := s.repo.MarkEmailVerified(c.UserContext(), user.ID, user.Email); err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	return c.JSON(fiber.Map{"success": true, "message": "Email berhasil diverifikasi"})
+}
+
+// ResendVerification godoc
+// @Summary Kirim ulang email verifikasi
+// @Description Mengirim ulang link verifikasi ke email akun yang belum terverifikasi. Respon selalu sama walaupun email tidak terdaftar.
+// @Tags Users
+// @Accept json
+// @Produce json
+// @Param body body model.ResendVerificationRequest true "Email akun"
+// @Success 200 {object} fiber.Map
+// @Failure 400 {object} fiber.Map
+// @Router /resend-verification [post]
+func (s *UserService) ResendVerification(c *fiber.Ctx) error {
+	var req model.ResendVerificationRequest
+	if err := c.BodyParser(&req); err != nil || strings.TrimSpace(req.Email) == "" {
+		return c.Status(400).JSON(fiber.Map{"error": "Email wajib diisi"})
+	}
+
+	// email dikirim di latar belakang agar waktu respon tidak membocorkan email yang terdaftar
+	user, err := s.repo.GetByEmail(c.UserContext(), strings.TrimSpace(req.Email))
+	if err == nil && !user.EmailVerified {
+		go s.sendVerification(context.Background(), user)
+	}
+
+	return c.JSON(fiber.Map{
+		"success": true,
+		"message": "Jika email terdaftar dan belum diverifikasi, link verifikasi telah dikirim",
+	})
+}
+
+// sendVerification mengirim link verifikasi; kegagalan hanya dicatat karena user bisa meminta kirim ulang
+func (s *UserService) sendVerification(ctx context.Context, user model.User) {
+	expiresAt := time.Now().Add(s.verifyCfg.TokenTTL)
+	token := utils.SignToken([]byte(s.verifyCfg.Secret), verifyEmailPurpose, user.ID.Hex()+":"+strings.ToLower(user.Email), expiresAt)
+
+	msg := utils.Message{
+		To:      user.Email,
+		Subject: "Verifikasi email Alumni-App",
+		Body: fmt.Sprintf(
+			"Halo %s,\n\nBuka link berikut untuk memverifikasi email akun kamu:\n\n%s?token=%s\n\nLink berlaku sampai %s.\nAbaikan pesan ini jika kamu tidak mendaftar di Alumni-App.",
+			user.Username, s.verifyCfg.LinkURL, url.QueryEscape(token), expiresAt.Format("2006-01-02 15:04 MST"),
+		),
+	}
+	if err := s.notifier.Send(msg); err != nil {
+		log.Println("⚠️ Gagal mengirim email verifikasi:", err)
+	}
+}
@@ -0,0 +1,418 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"path"
+	"strconv"
+	"strings"
+	"time"
+
+	"alumni-app/app/mongodb/model"
+	"alumni-app/app/mongodb/repository"
+	"alumni-app/storage"
+	"alumni-app/utils/mongodb"
+	"github.com/gofiber/fiber/v2"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// reconcilePrefixes adalah folder storage yang berisi file milik collection files.
+// tmp/ (chunk upload) dan quarantine/ (hasil rekonsiliasi) sengaja tidak diperiksa.
+var reconcilePrefixes = []string{"foto/", "sertifikat/", "blobs/"}
+
+// quarantinePrefix adalah tujuan object yatim yang dipindahkan, bukan dihapus
+const quarantinePrefix = "quarantine/"
+
+// Reconcile godoc
+// @Summary Rekonsiliasi storage dengan collection files (admin)
+// @Description Mencari object di storage yang tidak punya record (orphans), record yang object-nya hilang (dangling), dan foto alumni yang masih menunjuk path statis /uploads/foto (legacy_refs). Tanpa parameter hanya melaporkan (dry run).
+// @Tags Files
+// @Produce json
+// @Param orphans query string false "Aksi untuk object yatim: report, delete, quarantine, register" default(report)
+// @Param dangling query string false "Aksi untuk record tanpa object: report, delete, quarantine" default(report)
+// @Param legacy query string false "Aksi untuk foto alumni dengan path statis lama: report, relink" default(report)
+// @Param min_age query string false "Lewati object/record yang lebih baru dari durasi ini (mis. 30m); default RECONCILE_MIN_AGE"
+// @Success 200 {object} model.ReconcileReport
+// @Failure 400 {object} model.ErrorResponse
+// @Failure 403 {object} model.ErrorResponse
+// @Failure 500 {object} model.ErrorResponse
+// @Security BearerAuth
+// @Router /files/reconcile [post]
+func (s *fileService) Reconcile(c *fiber.Ctx) error {
+	opts := model.ReconcileOptions{
+		Orphans:  c.Query("orphans", model.ReconcileDryRun),
+		Dangling: c.Query("dangling", model.ReconcileDryRun),
+		Legacy:   c.Query("legacy", model.ReconcileDryRun),
+		MinAge:   s.uploadCfg.ReconcileMinAge,
+	}
+	if v := c.Query("min_age"); v != "" {
+		d, err := time.ParseDuration(v)
+		if err != nil || d < 0 {
+			return c.Status(400).JSON(fiber.Map{"error": "invalid min_age " + strconv.Quote(v) + " (e.g. 30m, 1h)"})
+		}
+		opts.MinAge = d
+	}
+	if err := validateReconcileOptions(opts); err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	report, err := s.ReconcileStorage(c.UserContext(), opts)
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": err.Error()})
+	}
+	return c.JSON(fiber.Map{"success": true, "data": report})
+}
+
+// ReconcileStorage membandingkan isi storage dengan collection files dan menjalankan
+// perbaikan sesuai opts. Dipakai oleh endpoint admin dan subcommand `reconcile`.
+// Object dan record yang lebih baru dari opts.MinAge dilewati: upload yang selesai di antara
+// List dan FindAll, atau record-nya belum di-commit dalam transaksi upload, bukan temuan.
+func (s *fileService) ReconcileStorage(ctx context.Context, opts model.ReconcileOptions) (*model.ReconcileReport, error) {
+	if opts.Orphans == "" {
+		opts.Orphans = model.ReconcileDryRun
+	}
+	if opts.Dangling == "" {
+		opts.Dangling = model.ReconcileDryRun
+	}
+	if opts.Legacy == "" {
+		opts.Legacy = model.ReconcileDryRun
+	}
+	if err := validateReconcileOptions(opts); err != nil {
+		return nil, err
+	}
+
+	cutoff := time.Now().Add(-opts.MinAge)
+
+	var objects []storage.Object
+	for _, prefix := range reconcilePrefixes {
+		list, err := s.store.List(ctx, prefix)
+		if err != nil {
+			return nil, fmt.Errorf("list %s: %w", prefix, err)
+		}
+		objects = append(objects, list...)
+	}
+
+	files, err := s.repo.FindAll(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	report := &model.ReconcileReport{
+		Options:        opts,
+		ScannedObjects: len(objects),
+		ScannedRecords: len(files),
+		Orphans:        []model.ReconcileItem{},
+		Dangling:       []model.ReconcileItem{},
+		LegacyRefs:     []model.ReconcileItem{},
+	}
+
+	existing := make(map[string]bool, len(objects))
+	for _, o := range objects {
+		existing[o.Key] = true
+	}
+
+	// key yang dipakai record; isi file rejected sudah dilepas sehingga tidak dihitung.
+	// fileIDs dipakai untuk mencari record dari path foto lama.
+	referenced := make(map[string]bool)
+	fileIDs := make(map[string]primitive.ObjectID)
+	for i := range files {
+		file := &files[i]
+		if file.Status == model.FileStatusRejected {
+			continue
+		}
+
+		var missing []string
+		for _, key := range fileKeys(file) {
+			referenced[key] = true
+			fileIDs[key] = file.ID
+			if !existing[key] {
+				missing = append(missing, key)
+			}
+		}
+		if len(missing) == 0 {
+			continue
+		}
+		if file.UploadedAt.After(cutoff) {
+			report.SkippedRecent++
+			continue
+		}
+
+		item := model.ReconcileItem{Key: strings.Join(missing, ","), FileID: file.ID.Hex()}
+		if err := s.fixDangling(ctx, file, opts.Dangling); err != nil {
+			item.Error = err.Error()
+		} else if opts.Dangling != model.ReconcileDryRun {
+			item.Action = opts.Dangling
+			if opts.Dangling == model.ReconcileDelete {
+				for _, key := range fileKeys(file) {
+					delete(fileIDs, key)
+				}
+			}
+		}
+		report.Dangling = append(report.Dangling, item)
+	}
+
+	for _, o := range objects {
+		if referenced[o.Key] {
+			continue
+		}
+		if o.ModTime.After(cutoff) {
+			report.SkippedRecent++
+			continue
+		}
+
+		item := model.ReconcileItem{Key: o.Key, Size: o.Size}
+		fileID, err := s.fixOrphan(ctx, o, opts.Orphans)
+		if err != nil {
+			item.Error = err.Error()
+		} else if opts.Orphans != model.ReconcileDryRun {
+			item.Action = opts.Orphans
+			item.FileID = fileID
+			if id, err := primitive.ObjectIDFromHex(fileID); err == nil {
+				fileIDs[o.Key] = id
+			}
+		}
+		report.Orphans = append(report.Orphans, item)
+	}
+
+	if err := s.relinkLegacyFoto(ctx, fileIDs, opts.Legacy, report); err != nil {
+		return nil, err
+	}
+	return report, nil
+}
+
+// relinkLegacyFoto mencari foto alumni yang masih berupa path statis /uploads/foto dan,
+// untuk aksi relink, menggantinya dengan URL record di collection files. Foto tanpa record
+// dilaporkan tanpa file_id; object-nya bisa didaftarkan dulu dengan orphans=register.
+func (s *fileService) relinkLegacyFoto(ctx context.Context, fileIDs map[string]primitive.ObjectID, action string, report *model.ReconcileReport) error {
+	list, err := s.alumniRepo.FindLegacyFoto(ctx)
+	if err != nil {
+		return err
+	}
+	for _, a := range list {
+		key := objectKey("", strings.TrimPrefix(a.Foto, "/"))
+		item := model.ReconcileItem{Key: key, AlumniID: a.ID.Hex()}
+		id, ok := fileIDs[key]
+		if ok {
+			item.FileID = id.Hex()
+		}
+		if ok && action == model.ReconcileRelink {
+			if err := s.alumniRepo.UpdateFileRef(ctx, a.ID, "foto", fileURL(id)); err != nil {
+				item.Error = err.Error()
+			} else {
+				item.Action = action
+			}
+		}
+		report.LegacyRefs = append(report.LegacyRefs, item)
+	}
+	return nil
+}
+
+// fixDangling menangani record yang object-nya tidak ada di storage
+func (s *fileService) fixDangling(ctx context.Context, file *model.File, action string) error {
+	switch action {
+	case model.ReconcileDelete:
+		if err := s.repo.Delete(ctx, file.ID.Hex()); err != nil {
+			return err
+		}
+		// lepas referensi blob agar ref_count tetap akurat; object yang tersisa ikut terhapus
+		s.releaseFile(ctx, file)
+		s.clearAlumniRef(ctx, file)
+	case model.ReconcileQuarantine:
+		// record tetap ada tetapi tidak bisa diunduh sampai admin menindaklanjuti
+		if file.Status == model.FileStatusQuarantine {
+			return nil
+		}
+		return s.repo.UpdateStatus(ctx, file.ID, model.FileStatusQuarantine, "reconcile: object missing from storage")
+	}
+	return nil
+}
+
+// fixOrphan menangani object yang tidak dipakai record mana pun.
+// Untuk aksi register dikembalikan ID record baru.
+func (s *fileService) fixOrphan(ctx context.Context, obj storage.Object, action string) (string, error) {
+	switch action {
+	case model.ReconcileDelete:
+		if err := s.store.Delete(ctx, obj.Key); err != nil && !errors.Is(err, storage.ErrNotFound) {
+			return "", err
+		}
+		s.dropOrphanBlob(ctx, obj.Key)
+	case model.ReconcileQuarantine:
+		if err := s.moveObject(ctx, obj, quarantinePrefix+obj.Key); err != nil {
+			return "", err
+		}
+		s.dropOrphanBlob(ctx, obj.Key)
+	case model.ReconcileRegister:
+		file, err := s.registerOrphan(ctx, obj)
+		if err != nil {
+			return "", err
+		}
+		return file.ID.Hex(), nil
+	}
+	return "", nil
+}
+
+// registerOrphan membuat record File untuk object yatim dengan status quarantine,
+// sehingga file baru bisa dipakai setelah dipindai ulang lewat POST /files/:id/scan
+func (s *fileService) registerOrphan(ctx context.Context, obj storage.Object) (*model.File, error) {
+	rc, _, err := s.store.Get(ctx, obj.Key)
+	if err != nil {
+		return nil, err
+	}
+	head := make([]byte, 512)
+	n, err := io.ReadFull(rc, head)
+	rc.Close()
+	if err != nil && err != io.ErrUnexpectedEOF && err != io.EOF {
+		return nil, err
+	}
+	contentType, err := utils.SniffContentType(bytes.NewReader(head[:n]))
+	if err != nil {
+		return nil, err
+	}
+
+	name := path.Base(obj.Key)
+	file := &model.File{
+		AlumniID:     alumniIDFromName(name),
+		FileName:     name,
+		OriginalName: name,
+		FilePath:     s.localPath(obj.Key),
+		Storage:      s.store.Name(),
+		StorageKey:   obj.Key,
+		FileSize:     obj.Size,
+		FileType:     contentType,
+		Status:       model.FileStatusQuarantine,
+		ScanResult:   "reconcile: registered orphan object",
+		UploadedAt:   obj.ModTime,
+	}
+
+	// blob content-addressed: hash diambil dari nama object dan referensinya dicatat
+	if hash := blobHash(obj.Key); hash != "" {
+		file.SHA256 = hash
+		acquired, err := s.blobRepo.Acquire(ctx, hash, obj.Key)
+		if err != nil {
+			return nil, err
+		}
+		if !acquired {
+			err = s.blobRepo.Create(ctx, &model.Blob{
+				SHA256:      hash,
+				StorageKey:  obj.Key,
+				Size:        obj.Size,
+				ContentType: contentType,
+			})
+			switch {
+			case errors.Is(err, repository.ErrConflict):
+				// isi yang sama sudah tercatat di key lain: object ini didaftarkan sebagai file biasa
+				file.SHA256 = ""
+			case err != nil:
+				return nil, err
+			}
+		}
+	}
+
+	if err := s.repo.Create(ctx, file); err != nil {
+		if file.SHA256 != "" {
+			s.blobRepo.Release(ctx, file.SHA256)
+		}
+		return nil, err
+	}
+	return file, nil
+}
+
+// moveObject menyalin object ke key baru lalu menghapus yang lama
+func (s *fileService) moveObject(ctx context.Context, obj storage.Object, dst string) error {
+	rc, src, err := s.store.Get(ctx, obj.Key)
+	if err != nil {
+		return err
+	}
+	defer rc.Close()
+
+	if err := s.store.Put(ctx, dst, rc, src.Size, src.ContentType); err != nil {
+		return err
+	}
+	return s.store.Delete(ctx, obj.Key)
+}
+
+// dropOrphanBlob menghapus catatan blob yang object-nya sudah dibuang dari storage,
+// supaya upload berikutnya dengan isi yang sama menulis ulang object-nya
+func (s *fileService) dropOrphanBlob(ctx context.Context, key string) {
+	hash := blobHash(key)
+	if hash == "" {
+		return
+	}
+	blob, err := s.blobRepo.FindBySHA256(ctx, hash)
+	if err != nil || blob.StorageKey != key {
+		return
+	}
+	for remaining := blob.RefCount; remaining > 0; {
+		if remaining, err = s.blobRepo.Release(ctx, hash); err != nil {
+			fmt.Println("⚠️ Warning: gagal melepas blob:", err)
+			return
+		}
+	}
+	if _, err := s.blobRepo.DeleteIfUnreferenced(ctx, hash); err != nil {
+		fmt.Println("⚠️ Warning: gagal hapus blob:", err)
+	}
+}
+
+// fileKeys mengembalikan semua key storage milik sebuah record (file tunggal atau varian foto)
+func fileKeys(file *model.File) []string {
+	if len(file.Variants) == 0 {
+		return []string{objectKey(file.StorageKey, file.FilePath)}
+	}
+	keys := make([]string, 0, len(file.Variants))
+	for _, v := range file.Variants {
+		keys = append(keys, objectKey(v.StorageKey, v.FilePath))
+	}
+	return keys
+}
+
+// blobHash mengambil SHA-256 dari key blobs/<xx>/<sha256>[-<generasi>]<ext>; kosong jika bukan blob
+func blobHash(key string) string {
+	if !strings.HasPrefix(key, "blobs/") {
+		return ""
+	}
+	name := path.Base(key)
+	hash := strings.TrimSuffix(name, path.Ext(name))
+	// key generasi: <sha256>-<id>
+	if i := strings.IndexByte(hash, '-'); i >= 0 {
+		hash = hash[:i]
+	}
+	if len(hash) != 64 {
+		return ""
+	}
+	return hash
+}
+
+// alumniIDFromName membaca ID alumni dari nama file lama (FOTO_<id>_... / SERTIF_<id>_...)
+func alumniIDFromName(name string) primitive.ObjectID {
+	parts := strings.SplitN(name, "_", 3)
+	if len(parts) < 3 || (parts[0] != "FOTO" && parts[0] != "SERTIF") {
+		return primitive.NilObjectID
+	}
+	id, err := primitive.ObjectIDFromHex(parts[1])
+	if err != nil {
+		return primitive.NilObjectID
+	}
+	return id
+}
+
+func validateReconcileOptions(opts model.ReconcileOptions) error {
+	switch opts.Orphans {
+	case model.ReconcileDryRun, model.ReconcileDelete, model.ReconcileQuarantine, model.ReconcileRegister:
+	default:
+		return fmt.Errorf("invalid orphans action %q (report, delete, quarantine, register)", opts.Orphans)
+	}
+	switch opts.Dangling {
+	case model.ReconcileDryRun, model.ReconcileDelete, model.ReconcileQuarantine:
+	default:
+		return fmt.Errorf("invalid dangling action %q (report, delete, quarantine)", opts.Dangling)
+	}
+	switch opts.Legacy {
+	case model.ReconcileDryRun, model.ReconcileRelink:
+	default:
+		return fmt.Errorf("invalid legacy action %q (report, relink)", opts.Legacy)
+	}
+	return nil
+}
@@ -0,0 +1,142 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"alumni-app/app/mongodb/model"
+	"github.com/gofiber/fiber/v2"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// GetReviewQueue godoc
+// @Summary Antrian sertifikat yang menunggu verifikasi (staff)
+// @Description Daftar sertifikat berstatus pending yang sudah lolos pemindaian malware
+// @Tags Files
+// @Produce json
+// @Param page query int false "Halaman saat ini"
+// @Param limit query int false "Jumlah data per halaman (max 100)"
+// @Success 200 {object} model.FileResponse
+// @Failure 400 {object} model.ErrorResponse
+// @Failure 403 {object} model.ErrorResponse
+// @Failure 500 {object} model.ErrorResponse
+// @Security BearerAuth
+// @Router /files/reviews [get]
+func (s *fileService) GetReviewQueue(c *fiber.Ctx) error {
+	page := c.QueryInt("page", 1)
+	limit := c.QueryInt("limit", 10)
+	if page < 1 || limit < 1 || limit > 100 {
+		return c.Status(400).JSON(fiber.Map{"error": "page must be >= 1 and limit between 1 and 100"})
+	}
+
+	filter := model.FileFilter{
+		Type:   "sertifikat",
+		Status: model.FileStatusAvailable,
+		Review: model.ReviewPending,
+	}
+	files, err := s.repo.Search(c.UserContext(), filter, page, limit)
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": err.Error()})
+	}
+	total, err := s.repo.Count(c.UserContext(), filter)
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	return c.JSON(model.FileResponse{
+		Data: files,
+		Meta: model.MetaInfo{
+			Page:   page,
+			Limit:  limit,
+			Total:  int(total),
+			Pages:  (int(total) + limit - 1) / limit,
+			SortBy: "uploaded_at",
+			Order:  "desc",
+		},
+	})
+}
+
+// ReviewCertificate godoc
+// @Summary Setujui atau tolak sertifikat (staff)
+// @Description Menyimpan keputusan verifikasi beserta catatan, reviewer, dan waktunya. Catatan wajib diisi jika sertifikat ditolak.
+// @Tags Files
+// @Accept json
+// @Produce json
+// @Param id path string true "File ID"
+// @Param body body model.ReviewRequest true "Keputusan verifikasi"
+// @Success 200 {object} model.File
+// @Failure 400 {object} model.ErrorResponse
+// @Failure 403 {object} model.ErrorResponse
+// @Failure 404 {object} model.ErrorResponse
+// @Failure 409 {object} model.ErrorResponse
+// @Failure 500 {object} model.ErrorResponse
+// @Security BearerAuth
+// @Router /files/{id}/review [post]
+func (s *fileService) ReviewCertificate(c *fiber.Ctx) error {
+	var req model.ReviewRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": "Invalid request body"})
+	}
+	req.Notes = strings.TrimSpace(req.Notes)
+	if req.Decision != model.ReviewApproved && req.Decision != model.ReviewRejected {
+		return c.Status(400).JSON(fiber.Map{"error": "decision must be approved or rejected"})
+	}
+	if req.Decision == model.ReviewRejected && req.Notes == "" {
+		return c.Status(400).JSON(fiber.Map{"error": "notes are required when rejecting a certificate"})
+	}
+
+	file, err := s.repo.FindByID(c.UserContext(), c.Params("id"))
+	if err != nil {
+		return c.Status(404).JSON(fiber.Map{"error": "File not found"})
+	}
+	if file.Review == nil {
+		return c.Status(400).JSON(fiber.Map{"error": "File is not a certificate under review"})
+	}
+	if !file.IsAvailable() {
+		return c.Status(409).JSON(fiber.Map{"error": "File is not available (status: " + file.Status + ")"})
+	}
+	if file.Review.Status != model.ReviewPending {
+		return c.Status(409).JSON(fiber.Map{"error": "Certificate already " + file.Review.Status})
+	}
+
+	reviewerID, _ := c.Locals("user_id").(primitive.ObjectID)
+	now := time.Now()
+	review := *file.Review
+	review.Status = req.Decision
+	review.Notes = req.Notes
+	review.ReviewerID = &reviewerID
+	review.ReviewedAt = &now
+
+	if err := s.repo.UpdateReview(c.UserContext(), file.ID, review); err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return c.Status(409).JSON(fiber.Map{"error": "Certificate was reviewed by someone else"})
+		}
+		return c.Status(500).JSON(fiber.Map{"error": err.Error()})
+	}
+	file.Review = &review
+
+	if alumni, err := s.alumniRepo.GetByID(c.UserContext(), file.AlumniID); err == nil {
+		// status di profil alumni hanya diubah jika sertifikat ini yang sedang dipakai
+		if alumni.SertifikatPath == fileURL(file.ID) {
+			if err := s.alumniRepo.UpdateFileRef(c.UserContext(), alumni.ID, "sertifikat_status", review.Status); err != nil {
+				fmt.Println("⚠️ Warning: gagal update status sertifikat alumni:", err)
+			}
+		}
+		if s.notifier != nil {
+			data := map[string]string{"file_name": file.OriginalName, "status": review.Status, "notes": review.Notes}
+			if err := s.notifier.NotifyAlumni(c.UserContext(), []model.Alumni{alumni}, "certificate_reviewed", data); err != nil {
+				fmt.Println("⚠️ Warning: gagal mengirim notifikasi hasil verifikasi sertifikat:", err)
+			}
+		}
+	}
+
+	return c.JSON(fiber.Map{"success": true, "message": "Certificate " + review.Status, "data": file})
+}
+
+// newCertificateReview adalah status awal sertifikat yang baru diunggah
+func newCertificateReview() *model.CertificateReview {
+	return &model.CertificateReview{Status: model.ReviewPending, SubmittedAt: time.Now()}
+}
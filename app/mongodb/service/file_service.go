@@ -1,15 +1,28 @@
 package service
 
 import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
 	"fmt"
-	"os"
+	"io"
+	"mime"
+	"net/http"
 	"path/filepath"
+	"strings"
 	"time"
 	"alumni-app/app/mongodb/model"
 	"alumni-app/app/mongodb/repository"
+	"alumni-app/config"
+	"alumni-app/scanner"
+	"alumni-app/storage"
+	"alumni-app/utils/mongodb"
 	"github.com/gofiber/fiber/v2"
 	"github.com/google/uuid"
 	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
 )
 
 type FileService interface {
@@ -17,30 +30,68 @@ type FileService interface {
 	UploadSertifikat(c *fiber.Ctx) error
 	GetAllFiles(c *fiber.Ctx) error
 	GetFileByID(c *fiber.Ctx) error
+	GetMyFiles(c *fiber.Ctx) error
+	GetThumbnail(c *fiber.Ctx) error
+	Download(c *fiber.Ctx) error
+	GetSignedURL(c *fiber.Ctx) error
+	ServeSigned(c *fiber.Ctx) error
 	DeleteFile(c *fiber.Ctx) error
+	GetDedupReport(c *fiber.Ctx) error
+	ScanFile(c *fiber.Ctx) error
+	GetReviewQueue(c *fiber.Ctx) error
+	ReviewCertificate(c *fiber.Ctx) error
+	Reconcile(c *fiber.Ctx) error
+	// ReconcileStorage mencocokkan isi storage dengan collection files (dipakai juga oleh CLI)
+	ReconcileStorage(ctx context.Context, opts model.ReconcileOptions) (*model.ReconcileReport, error)
+
+	CreateUpload(c *fiber.Ctx) error
+	GetUpload(c *fiber.Ctx) error
+	PatchUpload(c *fiber.Ctx) error
+	FinalizeUpload(c *fiber.Ctx) error
+	AbortUpload(c *fiber.Ctx) error
+	// PurgeExpiredUploads menghapus sesi upload bertahap yang sudah kedaluwarsa beserta chunk-nya
+	PurgeExpiredUploads(ctx context.Context) (int, error)
 }
 
 type fileService struct {
 	repo       repository.FileRepository
 	alumniRepo repository.AlumniRepositoryInterface
-	uploadPath string
+	store      storage.Storage
+	blobRepo   repository.BlobRepositoryInterface
+	scanner    scanner.Scanner
+	uploadRepo repository.UploadSessionRepositoryInterface
+	uploadCfg  config.UploadConfig
+	uow        repository.UnitOfWork
+	notifier   AlumniNotifier
 }
 
 func NewFileService(
 	fileRepo repository.FileRepository,
 	alumniRepo repository.AlumniRepositoryInterface,
-	uploadPath string,
+	store storage.Storage,
+	blobRepo repository.BlobRepositoryInterface,
+	scan scanner.Scanner,
+	uploadRepo repository.UploadSessionRepositoryInterface,
+	uploadCfg config.UploadConfig,
+	uow repository.UnitOfWork,
+	notifier AlumniNotifier,
 ) FileService {
 	return &fileService{
 		repo:       fileRepo,
 		alumniRepo: alumniRepo,
-		uploadPath: uploadPath,
+		store:      store,
+		blobRepo:   blobRepo,
+		scanner:    scan,
+		uploadRepo: uploadRepo,
+		uploadCfg:  uploadCfg,
+		uow:        uow,
+		notifier:   notifier,
 	}
 }
 
 // UploadFoto godoc
 // @Summary Upload foto alumni (JPG/PNG, max 1MB)
-// @Description Hanya admin atau pemilik data yang boleh upload foto ke profil alumni
+// @Description Hanya admin atau pemilik data yang boleh upload foto ke profil alumni. Foto diputar sesuai EXIF, metadata EXIF dibuang, dan disimpan dalam ukuran thumb, medium, dan original.
 // @Tags Files
 // @Accept multipart/form-data
 // @Produce json
@@ -56,12 +107,16 @@ func NewFileService(
 // @Router /files/upload-foto/{alumni_id} [post]
 func (s *fileService) UploadFoto(c *fiber.Ctx) error {
 	alumniID := c.Params("alumni_id")
+	alumniObjID, err := primitive.ObjectIDFromHex(alumniID)
+	if err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": "Invalid alumni ID"})
+	}
 	role, _ := c.Locals("role").(string)
 	userID, _ := c.Locals("user_id").(primitive.ObjectID)
 
 	// Role check: user hanya bisa upload miliknya sendiri
 	if role != "admin" {
-		alumnis, err := s.alumniRepo.GetAllByUserID(userID)
+		alumnis, err := s.alumniRepo.GetAllByUserID(c.UserContext(), userID)
 		if err != nil {
 			return c.Status(500).JSON(fiber.Map{"error": "Failed to check ownership"})
 		}
@@ -88,39 +143,95 @@ func (s *fileService) UploadFoto(c *fiber.Ctx) error {
 		return c.Status(400).JSON(fiber.Map{"error": "Max photo size is 1MB"})
 	}
 
-	contentType := fileHeader.Header.Get("Content-Type")
-	if contentType != "image/jpeg" && contentType != "image/png" && contentType != "image/jpg" {
-		return c.Status(400).JSON(fiber.Map{"error": "Invalid file type, only JPG/PNG allowed"})
+	// tipe file ditentukan dari isi file, bukan dari Content-Type / ekstensi kiriman klien
+	src, err := fileHeader.Open()
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": err.Error()})
 	}
+	defer src.Close()
 
-	newName := fmt.Sprintf("FOTO_%s_%s%s", alumniID, uuid.New().String(), filepath.Ext(fileHeader.Filename))
-	folder := filepath.Join(s.uploadPath, "foto")
-	os.MkdirAll(folder, os.ModePerm)
-	filePath := filepath.Join(folder, newName)
+	contentType, err := utils.ValidateImage(src)
+	if err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": "Invalid file type: " + err.Error()})
+	}
 
-	if err := c.SaveFile(fileHeader, filePath); err != nil {
-		return c.Status(500).JSON(fiber.Map{"error": err.Error()})
+	images, err := utils.ProcessPhoto(src)
+	if err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": "Invalid image: " + err.Error()})
 	}
 
+	baseName := fmt.Sprintf("FOTO_%s_%s", alumniID, uuid.New().String())
+	ext := utils.ExtensionFor(contentType)
+
 	fileModel := &model.File{
-		FileName:     newName,
+		AlumniID:     alumniObjID,
 		OriginalName: fileHeader.Filename,
-		FilePath:     filePath,
-		FileSize:     fileHeader.Size,
 		FileType:     contentType,
+		Storage:      s.store.Name(),
+		Status:       model.FileStatusQuarantine,
 		UploadedAt:   time.Now(),
 	}
 
-	if err := s.repo.Create(fileModel); err != nil {
-		os.Remove(filePath)
+	// semua varian, salinan asli untuk pindai ulang, dan record file disimpan bersama;
+	// jika gagal, object yang sudah ditulis ikut dibuang
+	err = s.uow.Do(c.UserContext(), func(ctx context.Context) error {
+		fileModel.Variants = nil
+		for _, img := range images {
+			if err := s.addVariant(ctx, fileModel, img, baseName, ext); err != nil {
+				return err
+			}
+		}
+		fileModel.SourceKey = "quarantine/source/" + baseName + ext
+		if err := s.putObject(ctx, fileModel.SourceKey, src, fileHeader.Size, contentType); err != nil {
+			return err
+		}
+		repository.OnRollback(ctx, func(ctx context.Context) {
+			if err := s.store.Delete(ctx, fileModel.SourceKey); err != nil && !errors.Is(err, storage.ErrNotFound) {
+				fmt.Println("⚠️ Warning: gagal hapus file fisik:", err)
+			}
+		})
+		return s.repo.Create(ctx, fileModel)
+	})
+	if err != nil {
 		return c.Status(500).JSON(fiber.Map{"error": err.Error()})
 	}
 
-	return c.JSON(fiber.Map{
-		"success": true,
-		"message": "Foto uploaded successfully",
-		"data":    fileModel,
-	})
+	// yang dipindai adalah file asli kiriman klien, bukan hasil olahan
+	return s.acceptFile(c, fileModel, src, "foto", "Foto uploaded successfully")
+}
+
+// addVariant menyimpan satu ukuran foto ke storage dan menambahkannya ke fileModel
+func (s *fileService) addVariant(ctx context.Context, fileModel *model.File, img utils.ProcessedImage, baseName, ext string) error {
+	name := baseName + "_" + img.Variant + ext
+	if img.Variant == "original" {
+		name = baseName + ext
+	}
+
+	key, hash, err := s.putBlob(ctx, bytes.NewReader(img.Data), int64(len(img.Data)), img.ContentType)
+	if err != nil {
+		return err
+	}
+
+	variant := model.FileVariant{
+		Size:       img.Variant,
+		FileName:   name,
+		FilePath:   s.localPath(key),
+		StorageKey: key,
+		SHA256:     hash,
+		FileSize:   int64(len(img.Data)),
+		Width:      img.Width,
+		Height:     img.Height,
+	}
+	fileModel.Variants = append(fileModel.Variants, variant)
+
+	if img.Variant == "original" {
+		fileModel.FileName = variant.FileName
+		fileModel.FilePath = variant.FilePath
+		fileModel.StorageKey = variant.StorageKey
+		fileModel.SHA256 = variant.SHA256
+		fileModel.FileSize = variant.FileSize
+	}
+	return nil
 }
 
 // UploadSertifikat godoc
@@ -141,11 +252,15 @@ func (s *fileService) UploadFoto(c *fiber.Ctx) error {
 // @Router /files/upload-sertifikat/{alumni_id} [post]
 func (s *fileService) UploadSertifikat(c *fiber.Ctx) error {
 	alumniID := c.Params("alumni_id")
+	alumniObjID, err := primitive.ObjectIDFromHex(alumniID)
+	if err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": "Invalid alumni ID"})
+	}
 	role, _ := c.Locals("role").(string)
 	userID, _ := c.Locals("user_id").(primitive.ObjectID)
 
 	if role != "admin" {
-		alumnis, err := s.alumniRepo.GetAllByUserID(userID)
+		alumnis, err := s.alumniRepo.GetAllByUserID(c.UserContext(), userID)
 		if err != nil {
 			return c.Status(500).JSON(fiber.Map{"error": "Failed to check ownership"})
 		}
@@ -168,104 +283,797 @@ func (s *fileService) UploadSertifikat(c *fiber.Ctx) error {
 		return c.Status(400).JSON(fiber.Map{"error": "No file uploaded"})
 	}
 
-	if fileHeader.Header.Get("Content-Type") != "application/pdf" {
-		return c.Status(400).JSON(fiber.Map{"error": "Invalid file type, only PDF allowed"})
-	}
 	if fileHeader.Size > 2*1024*1024 {
 		return c.Status(400).JSON(fiber.Map{"error": "Max certificate size is 2MB"})
 	}
 
-	newName := fmt.Sprintf("SERTIF_%s_%s%s", alumniID, uuid.New().String(), filepath.Ext(fileHeader.Filename))
-	folder := filepath.Join(s.uploadPath, "sertifikat")
-	os.MkdirAll(folder, os.ModePerm)
-	filePath := filepath.Join(folder, newName)
+	src, err := fileHeader.Open()
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": err.Error()})
+	}
+	defer src.Close()
 
-	if err := c.SaveFile(fileHeader, filePath); err != nil {
+	contentType, err := utils.SniffContentType(src)
+	if err != nil {
 		return c.Status(500).JSON(fiber.Map{"error": err.Error()})
 	}
+	if contentType != "application/pdf" {
+		return c.Status(400).JSON(fiber.Map{"error": "Invalid file type: detected " + contentType + ", only PDF allowed"})
+	}
+	if err := utils.ValidatePDF(src, fileHeader.Size); err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": "Invalid PDF file: " + err.Error()})
+	}
+
+	newName := fmt.Sprintf("SERTIF_%s_%s%s", alumniID, uuid.New().String(), utils.ExtensionFor(contentType))
 
 	fileModel := &model.File{
+		AlumniID:     alumniObjID,
 		FileName:     newName,
 		OriginalName: fileHeader.Filename,
-		FilePath:     filePath,
+		Storage:      s.store.Name(),
 		FileSize:     fileHeader.Size,
-		FileType:     "application/pdf",
+		FileType:     contentType,
+		Status:       model.FileStatusQuarantine,
+		Review:       newCertificateReview(),
 		UploadedAt:   time.Now(),
 	}
-
-	if err := s.repo.Create(fileModel); err != nil {
-		os.Remove(filePath)
+	if err := s.storeDocument(c.UserContext(), fileModel, src); err != nil {
 		return c.Status(500).JSON(fiber.Map{"error": err.Error()})
 	}
 
-	return c.JSON(fiber.Map{"success": true, "message": "Certificate uploaded successfully", "data": fileModel})
+	return s.acceptFile(c, fileModel, src, "sertifikat_path", "Certificate uploaded successfully")
 }
 
 // GetAllFiles godoc
-// @Summary Dapatkan semua file yang diunggah
-// @Description Mengambil daftar seluruh file dari database
+// @Summary Daftar file yang diunggah
+// @Description Admin melihat semua file dengan filter; user biasa hanya melihat file milik alumni yang terhubung dengan akunnya
 // @Tags Files
 // @Accept json
 // @Produce json
-// @Success 200 {array} model.File
+// @Param page query int false "Halaman saat ini"
+// @Param limit query int false "Jumlah data per halaman (max 100)"
+// @Param type query string false "foto, sertifikat, atau MIME type"
+// @Param status query string false "quarantine, available, atau rejected"
+// @Param review query string false "Status verifikasi sertifikat: pending, approved, atau rejected"
+// @Param alumni_id query string false "Filter alumni (khusus admin)"
+// @Param from query string false "Tanggal upload mulai (YYYY-MM-DD)"
+// @Param to query string false "Tanggal upload sampai (YYYY-MM-DD, inklusif)"
+// @Param min_size query int false "Ukuran minimal (byte)"
+// @Param max_size query int false "Ukuran maksimal (byte)"
+// @Success 200 {object} model.FileResponse
+// @Failure 400 {object} model.ErrorResponse
 // @Failure 500 {object} model.ErrorResponse
 // @Security BearerAuth
 // @Router /files [get]
 func (s *fileService) GetAllFiles(c *fiber.Ctx) error {
-	files, err := s.repo.FindAll()
+	page := c.QueryInt("page", 1)
+	limit := c.QueryInt("limit", 10)
+	if page < 1 || limit < 1 || limit > 100 {
+		return c.Status(400).JSON(fiber.Map{"error": "page must be >= 1 and limit between 1 and 100"})
+	}
+
+	filter := model.FileFilter{
+		Type:    c.Query("type"),
+		Status:  c.Query("status"),
+		Review:  c.Query("review"),
+		MinSize: int64(c.QueryInt("min_size", 0)),
+		MaxSize: int64(c.QueryInt("max_size", 0)),
+	}
+
+	if v := c.Query("from"); v != "" {
+		from, err := time.Parse("2006-01-02", v)
+		if err != nil {
+			return c.Status(400).JSON(fiber.Map{"error": "Invalid from date, use YYYY-MM-DD"})
+		}
+		filter.From = &from
+	}
+	if v := c.Query("to"); v != "" {
+		to, err := time.Parse("2006-01-02", v)
+		if err != nil {
+			return c.Status(400).JSON(fiber.Map{"error": "Invalid to date, use YYYY-MM-DD"})
+		}
+		to = to.AddDate(0, 0, 1)
+		filter.To = &to
+	}
+
+	role, _ := c.Locals("role").(string)
+	if role == "admin" {
+		if v := c.Query("alumni_id"); v != "" {
+			alumniID, err := primitive.ObjectIDFromHex(v)
+			if err != nil {
+				return c.Status(400).JSON(fiber.Map{"error": "Invalid alumni ID"})
+			}
+			filter.AlumniIDs = []primitive.ObjectID{alumniID}
+		}
+	} else {
+		userID, _ := c.Locals("user_id").(primitive.ObjectID)
+		alumnis, err := s.alumniRepo.GetAllByUserID(c.UserContext(), userID)
+		if err != nil {
+			return c.Status(500).JSON(fiber.Map{"error": "Failed to check ownership"})
+		}
+		filter.AlumniIDs = []primitive.ObjectID{}
+		for _, a := range alumnis {
+			filter.AlumniIDs = append(filter.AlumniIDs, a.ID)
+		}
+	}
+
+	files, err := s.repo.Search(c.UserContext(), filter, page, limit)
 	if err != nil {
 		return c.Status(500).JSON(fiber.Map{"error": err.Error()})
 	}
-	return c.JSON(fiber.Map{"success": true, "data": files})
+	total, err := s.repo.Count(c.UserContext(), filter)
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	return c.JSON(model.FileResponse{
+		Data: files,
+		Meta: model.MetaInfo{
+			Page:   page,
+			Limit:  limit,
+			Total:  int(total),
+			Pages:  (int(total) + limit - 1) / limit,
+			SortBy: "uploaded_at",
+			Order:  "desc",
+		},
+	})
 }
 
 // GetFileByID godoc
 // @Summary Dapatkan file berdasarkan ID
-// @Description Mengambil metadata file dari database berdasarkan ID
+// @Description Mengambil metadata file berdasarkan ID; hanya admin, pemilik data alumni, atau staff untuk sertifikat
 // @Tags Files
 // @Accept json
 // @Produce json
 // @Param id path string true "File ID"
 // @Success 200 {object} model.File
+// @Failure 403 {object} model.ErrorResponse
 // @Failure 404 {object} model.ErrorResponse
 // @Failure 500 {object} model.ErrorResponse
 // @Security BearerAuth
 // @Router /files/{id} [get]
 func (s *fileService) GetFileByID(c *fiber.Ctx) error {
 	id := c.Params("id")
-	file, err := s.repo.FindByID(id)
+	file, err := s.repo.FindByID(c.UserContext(), id)
+	if err != nil {
+		return c.Status(404).JSON(fiber.Map{"error": "File not found"})
+	}
+
+	allowed, err := s.canView(c, file)
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": "Failed to check ownership"})
+	}
+	if !allowed {
+		return c.Status(403).JSON(fiber.Map{"error": "You can only view your own files"})
+	}
+	return c.JSON(fiber.Map{"success": true, "data": file})
+}
+
+// GetMyFiles godoc
+// @Summary Daftar file milik user login
+// @Description Mengambil foto dan sertifikat dari alumni yang terhubung dengan user di token JWT
+// @Tags Me
+// @Accept json
+// @Produce json
+// @Success 200 {array} model.File
+// @Failure 404 {object} model.ErrorResponse
+// @Failure 500 {object} model.ErrorResponse
+// @Security BearerAuth
+// @Router /me/files [get]
+func (s *fileService) GetMyFiles(c *fiber.Ctx) error {
+	userID, _ := c.Locals("user_id").(primitive.ObjectID)
+
+	alumni, err := s.alumniRepo.GetByUserID(c.UserContext(), userID)
+	if err != nil {
+		return c.Status(404).JSON(fiber.Map{"error": "Alumni not found for this user"})
+	}
+
+	files, err := s.repo.FindByAlumniID(c.UserContext(), alumni.ID)
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": err.Error()})
+	}
+	return c.JSON(fiber.Map{"success": true, "data": files})
+}
+
+// GetThumbnail godoc
+// @Summary Ambil foto dalam ukuran tertentu
+// @Description Mengirim varian foto (thumb 150px, medium 600px, atau original max 2048px)
+// @Tags Files
+// @Produce image/jpeg
+// @Produce image/png
+// @Param id path string true "File ID"
+// @Param size query string false "thumb (default), medium, atau original"
+// @Success 200 {file} binary
+// @Failure 403 {object} model.ErrorResponse
+// @Failure 404 {object} model.ErrorResponse
+// @Security BearerAuth
+// @Router /files/{id}/thumb [get]
+func (s *fileService) GetThumbnail(c *fiber.Ctx) error {
+	id := c.Params("id")
+	size := c.Query("size", "thumb")
+
+	file, err := s.repo.FindByID(c.UserContext(), id)
+	if err != nil {
+		return c.Status(404).JSON(fiber.Map{"error": "File not found"})
+	}
+
+	allowed, err := s.canView(c, file)
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": "Failed to check ownership"})
+	}
+	if !allowed {
+		return c.Status(403).JSON(fiber.Map{"error": "You can only view your own files"})
+	}
+	if !file.IsAvailable() {
+		return c.Status(409).JSON(fiber.Map{"error": "File is not available (status: " + file.Status + ")"})
+	}
+
+	for _, v := range file.Variants {
+		if v.Size == size {
+			rc, obj, err := s.store.Get(c.UserContext(), objectKey(v.StorageKey, v.FilePath))
+			if err != nil {
+				return c.Status(404).JSON(fiber.Map{"error": "File not found in storage"})
+			}
+			c.Set(fiber.HeaderContentType, obj.ContentType)
+			return config.SendStream(c, rc, int(obj.Size))
+		}
+	}
+	return c.Status(404).JSON(fiber.Map{"error": "Variant '" + size + "' not available for this file"})
+}
+
+// Download godoc
+// @Summary Unduh file (foto / sertifikat)
+// @Description Hanya admin, pemilik data alumni, atau staff (khusus sertifikat) yang boleh mengunduh. Mendukung header Range untuk unduhan sebagian.
+// @Tags Files
+// @Produce application/octet-stream
+// @Param id path string true "File ID"
+// @Param Range header string false "Rentang byte, mis. bytes=0-1023"
+// @Success 200 {file} binary
+// @Success 206 {file} binary
+// @Failure 403 {object} model.ErrorResponse
+// @Failure 404 {object} model.ErrorResponse
+// @Failure 416 {object} model.ErrorResponse
+// @Security BearerAuth
+// @Router /files/{id}/download [get]
+func (s *fileService) Download(c *fiber.Ctx) error {
+	file, err := s.repo.FindByID(c.UserContext(), c.Params("id"))
+	if err != nil {
+		return c.Status(404).JSON(fiber.Map{"error": "File not found"})
+	}
+
+	allowed, err := s.canView(c, file)
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": "Failed to check ownership"})
+	}
+	if !allowed {
+		return c.Status(403).JSON(fiber.Map{"error": "You can only download your own files"})
+	}
+	if !file.IsAvailable() {
+		return c.Status(409).JSON(fiber.Map{"error": "File is not available (status: " + file.Status + ")"})
+	}
+
+	return s.serveObject(c, objectKey(file.StorageKey, file.FilePath), file.OriginalName)
+}
+
+// GetSignedURL godoc
+// @Summary Buat URL unduhan sementara
+// @Description URL bisa dipakai tanpa token login sampai kedaluwarsa (default 300 detik, max 3600). Hanya admin atau pemilik data.
+// @Tags Files
+// @Produce json
+// @Param id path string true "File ID"
+// @Param expires_in query int false "Masa berlaku dalam detik"
+// @Success 200 {object} map[string]interface{}
+// @Failure 403 {object} model.ErrorResponse
+// @Failure 404 {object} model.ErrorResponse
+// @Failure 500 {object} model.ErrorResponse
+// @Security BearerAuth
+// @Router /files/{id}/signed-url [get]
+func (s *fileService) GetSignedURL(c *fiber.Ctx) error {
+	file, err := s.repo.FindByID(c.UserContext(), c.Params("id"))
+	if err != nil {
+		return c.Status(404).JSON(fiber.Map{"error": "File not found"})
+	}
+
+	allowed, err := s.canAccess(c, file)
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": "Failed to check ownership"})
+	}
+	if !allowed {
+		return c.Status(403).JSON(fiber.Map{"error": "You can only share your own files"})
+	}
+	if !file.IsAvailable() {
+		return c.Status(409).JSON(fiber.Map{"error": "File is not available (status: " + file.Status + ")"})
+	}
+
+	expiresIn := c.QueryInt("expires_in", 300)
+	if expiresIn <= 0 || expiresIn > 3600 {
+		return c.Status(400).JSON(fiber.Map{"error": "expires_in must be between 1 and 3600 seconds"})
+	}
+	ttl := time.Duration(expiresIn) * time.Second
+
+	url, err := s.store.SignedURL(c.UserContext(), objectKey(file.StorageKey, file.FilePath), ttl)
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": err.Error()})
+	}
+	return c.JSON(fiber.Map{"success": true, "url": url, "expires_at": time.Now().Add(ttl)})
+}
+
+// ServeSigned godoc
+// @Summary Unduh file lewat URL sementara
+// @Description Endpoint tujuan URL dari /files/{id}/signed-url untuk backend local; tidak memerlukan token login
+// @Tags Files
+// @Produce application/octet-stream
+// @Param key path string true "Storage key"
+// @Param expires query int true "Unix timestamp kedaluwarsa"
+// @Param signature query string true "Tanda tangan URL"
+// @Success 200 {file} binary
+// @Failure 403 {object} model.ErrorResponse
+// @Failure 404 {object} model.ErrorResponse
+// @Router /files/signed/{key} [get]
+func (s *fileService) ServeSigned(c *fiber.Ctx) error {
+	verifier, ok := s.store.(interface {
+		Verify(key, expires, signature string) error
+	})
+	if !ok {
+		return c.Status(404).JSON(fiber.Map{"error": "Signed URLs are served by the storage backend"})
+	}
+
+	key := c.Params("*")
+	if err := verifier.Verify(key, c.Query("expires"), c.Query("signature")); err != nil {
+		return c.Status(403).JSON(fiber.Map{"error": "Invalid or expired link"})
+	}
+	return s.serveObject(c, key, filepath.Base(key))
+}
+
+// GetDedupReport godoc
+// @Summary Laporan penghematan storage dari deduplikasi (admin)
+// @Description Menghitung jumlah blob unik, total referensi, ukuran logis (seolah tanpa dedup), ukuran yang benar-benar tersimpan, dan selisihnya
+// @Tags Files
+// @Produce json
+// @Success 200 {object} model.DedupReport
+// @Failure 403 {object} model.ErrorResponse
+// @Failure 500 {object} model.ErrorResponse
+// @Security BearerAuth
+// @Router /files/dedup-report [get]
+func (s *fileService) GetDedupReport(c *fiber.Ctx) error {
+	report, err := s.blobRepo.Stats(c.UserContext())
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": err.Error()})
+	}
+	return c.JSON(fiber.Map{"success": true, "data": report})
+}
+
+// ScanFile godoc
+// @Summary Pindai ulang file di quarantine (admin)
+// @Description Dipakai jika scanner sempat tidak tersedia saat upload. File menjadi available atau rejected sesuai hasil pemindaian.
+// @Description Foto dipindai dari salinan asli kiriman klien; foto lama tanpa salinan asli dipindai dari varian original hasil olahan.
+// @Tags Files
+// @Produce json
+// @Param id path string true "File ID"
+// @Success 200 {object} model.File
+// @Failure 404 {object} model.ErrorResponse
+// @Failure 409 {object} model.ErrorResponse
+// @Failure 422 {object} model.ErrorResponse
+// @Failure 503 {object} model.ErrorResponse
+// @Security BearerAuth
+// @Router /files/{id}/scan [post]
+func (s *fileService) ScanFile(c *fiber.Ctx) error {
+	file, err := s.repo.FindByID(c.UserContext(), c.Params("id"))
 	if err != nil {
 		return c.Status(404).JSON(fiber.Map{"error": "File not found"})
 	}
+	if file.Status != model.FileStatusQuarantine {
+		return c.Status(409).JSON(fiber.Map{"error": "Only quarantined files can be rescanned"})
+	}
+
+	key := objectKey(file.StorageKey, file.FilePath)
+	if file.SourceKey != "" {
+		key = file.SourceKey
+	}
+	rc, _, err := s.store.Get(c.UserContext(), key)
+	if err != nil {
+		return c.Status(404).JSON(fiber.Map{"error": "File not found in storage"})
+	}
+	defer rc.Close()
+
+	if err := s.scanFile(c.UserContext(), file, rc); err != nil {
+		return c.Status(503).JSON(fiber.Map{"error": "Scanner unavailable: " + err.Error()})
+	}
+	if file.Status == model.FileStatusRejected {
+		s.releaseFile(c.UserContext(), file)
+		return c.Status(422).JSON(fiber.Map{"error": "File rejected by malware scan: " + file.ScanResult, "data": file})
+	}
+
+	field := "sertifikat_path"
+	if len(file.Variants) > 0 {
+		field = "foto"
+	}
+	s.linkAlumniRef(c.UserContext(), file, field)
 	return c.JSON(fiber.Map{"success": true, "data": file})
 }
 
 // DeleteFile godoc
 // @Summary Hapus file berdasarkan ID
-// @Description Menghapus file dari sistem dan database
+// @Description Menghapus file dari storage dan database; hanya admin atau pemilik data. Field foto / sertifikat alumni ikut dikosongkan.
 // @Tags Files
 // @Accept json
 // @Produce json
 // @Param id path string true "File ID"
 // @Success 200 {object} model.FileUploadResponse
+// @Failure 403 {object} model.ErrorResponse
 // @Failure 404 {object} model.ErrorResponse
 // @Failure 500 {object} model.ErrorResponse
 // @Security BearerAuth
 // @Router /files/{id} [delete]
 func (s *fileService) DeleteFile(c *fiber.Ctx) error {
 	id := c.Params("id")
-	file, err := s.repo.FindByID(id)
+	file, err := s.repo.FindByID(c.UserContext(), id)
 	if err != nil {
 		return c.Status(404).JSON(fiber.Map{"error": "File not found"})
 	}
 
-	if err := os.Remove(file.FilePath); err != nil {
-		fmt.Println("⚠️ Warning: gagal hapus file fisik:", err)
+	allowed, err := s.canAccess(c, file)
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": "Failed to check ownership"})
+	}
+	if !allowed {
+		return c.Status(403).JSON(fiber.Map{"error": "You can only delete your own files"})
 	}
 
-	if err := s.repo.Delete(id); err != nil {
+	// hapus record dulu; kalau gagal, file fisik masih utuh
+	if err := s.repo.Delete(c.UserContext(), id); err != nil {
 		return c.Status(500).JSON(fiber.Map{"error": err.Error()})
 	}
 
+	// isi file yang ditolak scanner sudah dilepas saat pemindaian
+	if file.Status != model.FileStatusRejected {
+		s.releaseFile(c.UserContext(), file)
+	}
+	s.clearAlumniRef(c.UserContext(), file)
+
 	return c.JSON(fiber.Map{"success": true, "message": "File deleted successfully"})
 }
+
+// acceptFile memindai file baru (status quarantine) dan menulis respons sesuai hasilnya.
+// refField adalah field alumni ("foto" / "sertifikat_path") yang diisi jika file lolos.
+func (s *fileService) acceptFile(c *fiber.Ctx, file *model.File, content io.ReadSeeker, refField, message string) error {
+	if _, err := content.Seek(0, io.SeekStart); err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	if err := s.scanFile(c.UserContext(), file, content); err != nil {
+		// scanner tidak tersedia: file tetap di quarantine sampai dipindai ulang admin
+		fmt.Println("⚠️ Warning: gagal memindai file:", err)
+		return c.Status(202).JSON(fiber.Map{
+			"success": true,
+			"message": "File received and held in quarantine until it can be scanned",
+			"data":    file,
+		})
+	}
+	if file.Status == model.FileStatusRejected {
+		s.releaseFile(c.UserContext(), file)
+		return c.Status(422).JSON(fiber.Map{"error": "File rejected by malware scan: " + file.ScanResult, "data": file})
+	}
+
+	s.linkAlumniRef(c.UserContext(), file, refField)
+	return c.JSON(fiber.Map{"success": true, "message": message, "data": file})
+}
+
+// scanFile menjalankan scanner dan menyimpan status available / rejected.
+// Setelah ada hasil, salinan asli foto tidak diperlukan lagi dan dihapus.
+func (s *fileService) scanFile(ctx context.Context, file *model.File, content io.Reader) error {
+	res, err := s.scanner.Scan(ctx, content)
+	if err != nil {
+		return err
+	}
+
+	file.Status = model.FileStatusAvailable
+	file.ScanResult = s.scanner.Name() + ": OK"
+	if !res.Clean {
+		file.Status = model.FileStatusRejected
+		file.ScanResult = res.Signature
+	}
+	now := time.Now()
+	file.ScannedAt = &now
+	if err := s.repo.UpdateStatus(ctx, file.ID, file.Status, file.ScanResult); err != nil {
+		return err
+	}
+	s.dropSource(ctx, file)
+	return nil
+}
+
+// dropSource menghapus salinan asli foto yang disimpan untuk pindai ulang
+func (s *fileService) dropSource(ctx context.Context, file *model.File) {
+	if file.SourceKey == "" {
+		return
+	}
+	if err := s.store.Delete(ctx, file.SourceKey); err != nil && !errors.Is(err, storage.ErrNotFound) {
+		fmt.Println("⚠️ Warning: gagal hapus file fisik:", err)
+	}
+	file.SourceKey = ""
+}
+
+// storeDocument menyimpan isi dokumen dan record-nya dalam satu unit of work
+func (s *fileService) storeDocument(ctx context.Context, fileModel *model.File, content io.ReadSeeker) error {
+	return s.uow.Do(ctx, func(ctx context.Context) error {
+		if _, err := content.Seek(0, io.SeekStart); err != nil {
+			return err
+		}
+		key, hash, err := s.putBlob(ctx, content, fileModel.FileSize, fileModel.FileType)
+		if err != nil {
+			return err
+		}
+		fileModel.FilePath = s.localPath(key)
+		fileModel.StorageKey = key
+		fileModel.SHA256 = hash
+		return s.repo.Create(ctx, fileModel)
+	})
+}
+
+// putBlob menyimpan isi file secara content-addressed (blobs/<sha256>-<generasi>); isi yang sudah
+// pernah diunggah tidak ditulis ulang, cukup ditambah referensinya. Setiap kali catatan blob harus
+// dibuat ulang, object ditulis ke key generasi baru agar tidak ikut terhapus oleh pelepas referensi
+// terakhir yang masih menghapus object generasi lama.
+func (s *fileService) putBlob(ctx context.Context, r io.ReadSeeker, size int64, contentType string) (string, string, error) {
+	h := sha256.New()
+	if _, err := io.Copy(h, r); err != nil {
+		return "", "", err
+	}
+	hash := hex.EncodeToString(h.Sum(nil))
+
+	for attempt := 0; attempt < 3; attempt++ {
+		if existing, err := s.blobRepo.FindBySHA256(ctx, hash); err == nil {
+			if _, err := s.store.Stat(ctx, existing.StorageKey); err != nil {
+				// object hilang dari storage (mis. dihapus manual): tulis ulang ke key yang sama
+				if err := s.putObject(ctx, existing.StorageKey, r, size, contentType); err != nil {
+					return "", "", err
+				}
+			}
+			acquired, err := s.blobRepo.Acquire(ctx, hash, existing.StorageKey)
+			if err != nil {
+				return "", "", err
+			}
+			if acquired {
+				s.undoBlob(ctx, hash, existing.StorageKey)
+				return existing.StorageKey, hash, nil
+			}
+			// referensi terakhir dilepas di antara FindBySHA256 dan Acquire; object lama akan dihapus
+			continue
+		}
+
+		blob := &model.Blob{
+			SHA256:      hash,
+			StorageKey:  "blobs/" + hash[:2] + "/" + hash + "-" + uuid.New().String()[:8] + utils.ExtensionFor(contentType),
+			Size:        size,
+			ContentType: contentType,
+		}
+		if err := s.putObject(ctx, blob.StorageKey, r, size, contentType); err != nil {
+			return "", "", err
+		}
+		if err := s.blobRepo.Create(ctx, blob); err != nil {
+			s.store.Delete(ctx, blob.StorageKey)
+			// upload lain dengan isi yang sama lebih dulu tercatat; dalam transaksi error ini
+			// sudah membatalkan transaksi sehingga tidak bisa diulang di sini
+			if errors.Is(err, repository.ErrConflict) && !repository.InTransaction(ctx) {
+				continue
+			}
+			return "", "", err
+		}
+		s.undoBlob(ctx, hash, blob.StorageKey)
+		return blob.StorageKey, hash, nil
+	}
+	return "", "", fmt.Errorf("blob %s berubah terus saat diunggah, coba lagi", hash)
+}
+
+// putObject menulis ulang r dari awal ke key
+func (s *fileService) putObject(ctx context.Context, key string, r io.ReadSeeker, size int64, contentType string) error {
+	if _, err := r.Seek(0, io.SeekStart); err != nil {
+		return err
+	}
+	return s.store.Put(ctx, key, r, size, contentType)
+}
+
+// undoBlob membatalkan putBlob jika unit of work gagal. Dalam transaksi, Acquire/Create sudah ikut
+// dibatalkan sehingga object cukup dibuang bila key-nya tidak lagi tercatat di blobs; tanpa transaksi
+// referensinya dilepas seperti saat file dihapus.
+func (s *fileService) undoBlob(ctx context.Context, hash, key string) {
+	inTx := repository.InTransaction(ctx)
+	repository.OnRollback(ctx, func(ctx context.Context) {
+		if !inTx {
+			s.releaseObject(ctx, hash, key)
+			return
+		}
+		blob, err := s.blobRepo.FindBySHA256(ctx, hash)
+		if err == nil && blob.StorageKey == key {
+			return
+		}
+		if err != nil && !errors.Is(err, mongo.ErrNoDocuments) {
+			return
+		}
+		if err := s.store.Delete(ctx, key); err != nil && !errors.Is(err, storage.ErrNotFound) {
+			fmt.Println("⚠️ Warning: gagal hapus file fisik:", err)
+		}
+	})
+}
+
+// releaseFile melepas semua object milik file (varian foto atau file tunggal)
+func (s *fileService) releaseFile(ctx context.Context, file *model.File) {
+	s.dropSource(ctx, file)
+	if len(file.Variants) == 0 {
+		s.releaseObject(ctx, file.SHA256, objectKey(file.StorageKey, file.FilePath))
+		return
+	}
+	for _, v := range file.Variants {
+		s.releaseObject(ctx, v.SHA256, objectKey(v.StorageKey, v.FilePath))
+	}
+}
+
+// releaseObject mengurangi referensi blob dan menghapus object saat tidak ada lagi yang memakai.
+// File lama tanpa hash langsung dihapus dari storage.
+func (s *fileService) releaseObject(ctx context.Context, hash, key string) {
+	if hash == "" {
+		if err := s.store.Delete(ctx, key); err != nil {
+			fmt.Println("⚠️ Warning: gagal hapus file fisik:", err)
+		}
+		return
+	}
+
+	remaining, err := s.blobRepo.Release(ctx, hash)
+	if err != nil {
+		fmt.Println("⚠️ Warning: gagal melepas blob:", err)
+		return
+	}
+	if remaining > 0 {
+		return
+	}
+	if deleted, err := s.blobRepo.DeleteIfUnreferenced(ctx, hash); err == nil && deleted {
+		if err := s.store.Delete(ctx, key); err != nil {
+			fmt.Println("⚠️ Warning: gagal hapus file fisik:", err)
+		}
+	}
+}
+
+// canAccess: admin boleh semua file, user hanya file milik alumni yang terhubung dengan akunnya
+func (s *fileService) canAccess(c *fiber.Ctx, file *model.File) (bool, error) {
+	role, _ := c.Locals("role").(string)
+	if role == "admin" {
+		return true, nil
+	}
+	if file.AlumniID.IsZero() {
+		return false, nil
+	}
+	return s.ownsAlumni(c, file.AlumniID)
+}
+
+// canView seperti canAccess, ditambah staff yang perlu membuka sertifikat untuk diverifikasi
+func (s *fileService) canView(c *fiber.Ctx, file *model.File) (bool, error) {
+	if role, _ := c.Locals("role").(string); role == "staff" && file.Review != nil {
+		return true, nil
+	}
+	return s.canAccess(c, file)
+}
+
+// ownsAlumni mengecek apakah alumni tersebut terhubung dengan user login
+func (s *fileService) ownsAlumni(c *fiber.Ctx, alumniID primitive.ObjectID) (bool, error) {
+	userID, _ := c.Locals("user_id").(primitive.ObjectID)
+	alumnis, err := s.alumniRepo.GetAllByUserID(c.UserContext(), userID)
+	if err != nil {
+		return false, err
+	}
+	for _, a := range alumnis {
+		if a.ID == alumniID {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// serveObject mengirim object dari storage dengan Content-Disposition dan dukungan Range satu rentang
+func (s *fileService) serveObject(c *fiber.Ctx, key, downloadName string) error {
+	rc, obj, err := s.store.Get(c.UserContext(), key)
+	if errors.Is(err, storage.ErrNotFound) {
+		return c.Status(404).JSON(fiber.Map{"error": "File not found in storage"})
+	}
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	c.Set(fiber.HeaderContentType, obj.ContentType)
+	c.Set(fiber.HeaderContentDisposition, mime.FormatMediaType("attachment", map[string]string{"filename": downloadName}))
+	c.Set(fiber.HeaderAcceptRanges, "bytes")
+	c.Set(fiber.HeaderCacheControl, "private, no-store")
+	if !obj.ModTime.IsZero() {
+		c.Set(fiber.HeaderLastModified, obj.ModTime.UTC().Format(http.TimeFormat))
+	}
+
+	start, end, partial, err := utils.ParseRange(c.Get(fiber.HeaderRange), obj.Size)
+	if err != nil {
+		rc.Close()
+		c.Set(fiber.HeaderContentRange, fmt.Sprintf("bytes */%d", obj.Size))
+		return c.Status(416).JSON(fiber.Map{"error": "Requested range not satisfiable"})
+	}
+	if !partial {
+		return config.SendStream(c, rc, int(obj.Size))
+	}
+
+	if seeker, ok := rc.(io.Seeker); ok {
+		_, err = seeker.Seek(start, io.SeekStart)
+	} else {
+		_, err = io.CopyN(io.Discard, rc, start)
+	}
+	if err != nil {
+		rc.Close()
+		return c.Status(500).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	length := end - start + 1
+	c.Set(fiber.HeaderContentRange, fmt.Sprintf("bytes %d-%d/%d", start, end, obj.Size))
+	c.Status(206)
+	return config.SendStream(c, struct {
+		io.Reader
+		io.Closer
+	}{io.LimitReader(rc, length), rc}, int(length))
+}
+
+// clearAlumniRef mengosongkan foto / sertifikat_path alumni yang menunjuk ke file yang dihapus
+func (s *fileService) clearAlumniRef(ctx context.Context, file *model.File) {
+	if file.AlumniID.IsZero() {
+		return
+	}
+	alumni, err := s.alumniRepo.GetByID(ctx, file.AlumniID)
+	if err != nil {
+		return
+	}
+
+	ref := fileURL(file.ID)
+	if alumni.Foto == ref {
+		if err := s.alumniRepo.UpdateFileRef(ctx, alumni.ID, "foto", ""); err != nil {
+			fmt.Println("⚠️ Warning: gagal kosongkan foto alumni:", err)
+		}
+	}
+	if alumni.SertifikatPath == ref {
+		if err := s.alumniRepo.UpdateFileRef(ctx, alumni.ID, "sertifikat_path", ""); err != nil {
+			fmt.Println("⚠️ Warning: gagal kosongkan sertifikat alumni:", err)
+		}
+		if err := s.alumniRepo.UpdateFileRef(ctx, alumni.ID, "sertifikat_status", ""); err != nil {
+			fmt.Println("⚠️ Warning: gagal kosongkan status sertifikat alumni:", err)
+		}
+	}
+}
+
+// linkAlumniRef mengisi foto / sertifikat_path alumni dengan file yang lolos pemindaian.
+// Untuk sertifikat, status verifikasinya ikut ditampilkan di profil alumni.
+func (s *fileService) linkAlumniRef(ctx context.Context, file *model.File, field string) {
+	if err := s.alumniRepo.UpdateFileRef(ctx, file.AlumniID, field, fileURL(file.ID)); err != nil {
+		fmt.Println("⚠️ Warning: gagal update file alumni:", err)
+		return
+	}
+	if field == "sertifikat_path" && file.Review != nil {
+		if err := s.alumniRepo.UpdateFileRef(ctx, file.AlumniID, "sertifikat_status", file.Review.Status); err != nil {
+			fmt.Println("⚠️ Warning: gagal update status sertifikat alumni:", err)
+		}
+	}
+}
+
+// fileURL adalah alamat unduhan file yang disimpan di field foto / sertifikat_path alumni
+func fileURL(id primitive.ObjectID) string {
+	return "/api/v1/files/" + id.Hex() + "/download"
+}
+
+// localPath mengisi FilePath (lokasi di disk) hanya untuk backend local
+func (s *fileService) localPath(key string) string {
+	if l, ok := s.store.(*storage.Local); ok {
+		return l.Path(key)
+	}
+	return ""
+}
+
+// objectKey mengembalikan key storage; data lama belum punya storage_key sehingga
+// key diturunkan dari file_path relatif terhadap folder ./uploads
+func objectKey(key, filePath string) string {
+	if key != "" {
+		return key
+	}
+	p := filepath.ToSlash(filepath.Clean(filePath))
+	return strings.TrimPrefix(p, "uploads/")
+}
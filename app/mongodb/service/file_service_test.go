@@ -3,8 +3,32 @@ package service
 import (
 	"alumni-app/app/mongodb/model"
 	"alumni-app/app/mongodb/repository/mock"
+	"alumni-app/config"
+	"alumni-app/scanner"
+	"alumni-app/storage"
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"encoding/binary"
+	"hash/crc32"
+	"image"
+	"image/jpeg"
+	"image/png"
+	"io"
+	"mime/multipart"
+	"net/http"
 	"net/http/httptest"
+	"net/textproto"
+	"path/filepath"
+	"strconv"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/gofiber/fiber/v2"
 	"go.mongodb.org/mongo-driver/bson/primitive"
@@ -12,12 +36,13 @@ import (
 
 func TestGetAllFiles(t *testing.T) {
 	mockRepo := repository.NewMockFileRepository()
-	service := NewFileService(mockRepo, nil, "uploads")
+	service := NewFileService(mockRepo, nil, storage.NewMemory(), repository.NewMockBlobRepository(), scanner.Noop{}, repository.NewMockUploadSessionRepository(), testUploadCfg, repository.NewMockUnitOfWork(), nil)
 
 	app := fiber.New()
+	app.Use(asAdmin)
 	app.Get("/files", service.GetAllFiles)
 
-	mockRepo.Create(&model.File{
+	mockRepo.Create(context.Background(), &model.File{
 		ID:       primitive.NewObjectID(),
 		FileName: "test.jpg",
 	})
@@ -32,16 +57,17 @@ func TestGetAllFiles(t *testing.T) {
 
 func TestGetFileByID(t *testing.T) {
 	mockRepo := repository.NewMockFileRepository()
-	service := NewFileService(mockRepo, nil, "uploads")
+	service := NewFileService(mockRepo, nil, storage.NewMemory(), repository.NewMockBlobRepository(), scanner.Noop{}, repository.NewMockUploadSessionRepository(), testUploadCfg, repository.NewMockUnitOfWork(), nil)
 
 	app := fiber.New()
+	app.Use(asAdmin)
 	app.Get("/files/:id", service.GetFileByID)
 
 	file := model.File{
 		ID:       primitive.NewObjectID(),
 		FileName: "foto.jpg",
 	}
-	mockRepo.Create(&file)
+	mockRepo.Create(context.Background(), &file)
 
 	t.Run("Valid ID", func(t *testing.T) {
 		req := httptest.NewRequest("GET", "/files/"+file.ID.Hex(), nil)
@@ -64,9 +90,10 @@ func TestGetFileByID(t *testing.T) {
 
 func TestDeleteFile(t *testing.T) {
 	mockRepo := repository.NewMockFileRepository()
-	service := NewFileService(mockRepo, nil, "uploads")
+	service := NewFileService(mockRepo, nil, storage.NewMemory(), repository.NewMockBlobRepository(), scanner.Noop{}, repository.NewMockUploadSessionRepository(), testUploadCfg, repository.NewMockUnitOfWork(), nil)
 
 	app := fiber.New()
+	app.Use(asAdmin)
 	app.Delete("/files/:id", service.DeleteFile)
 
 	file := model.File{
@@ -74,7 +101,7 @@ func TestDeleteFile(t *testing.T) {
 		FileName: "foto.jpg",
 		FilePath: "uploads/foto.jpg",
 	}
-	mockRepo.Create(&file)
+	mockRepo.Create(context.Background(), &file)
 
 	t.Run("Valid Delete", func(t *testing.T) {
 		req := httptest.NewRequest("DELETE", "/files/"+file.ID.Hex(), nil)
@@ -94,3 +121,1240 @@ func TestDeleteFile(t *testing.T) {
 		}
 	})
 }
+
+var testUploadCfg = config.UploadConfig{
+	MaxDocumentSize: 1024 * 1024,
+	MaxChunkSize:    64,
+	SessionTTL:      time.Hour,
+}
+
+// asAdmin mensimulasikan AuthRequired untuk user admin
+func asAdmin(c *fiber.Ctx) error {
+	c.Locals("role", "admin")
+	c.Locals("user_id", primitive.NewObjectID())
+	return c.Next()
+}
+
+func TestFileOwnership(t *testing.T) {
+	mockRepo := repository.NewMockFileRepository()
+	alumniRepo := repository.NewMockAlumniRepository()
+	store := storage.NewMemory()
+	service := NewFileService(mockRepo, alumniRepo, store, repository.NewMockBlobRepository(), scanner.Noop{}, repository.NewMockUploadSessionRepository(), testUploadCfg, repository.NewMockUnitOfWork(), nil)
+
+	ownerID := primitive.NewObjectID()
+	alumniID := primitive.NewObjectID()
+	otherAlumniID := primitive.NewObjectID()
+
+	now := time.Now()
+	mine := model.File{ID: primitive.NewObjectID(), AlumniID: alumniID, FileType: "application/pdf", FileSize: 1000, StorageKey: "sertifikat/mine.pdf", UploadedAt: now}
+	photo := model.File{ID: primitive.NewObjectID(), AlumniID: alumniID, FileType: "image/jpeg", FileSize: 50000, UploadedAt: now.AddDate(0, 0, -10)}
+	other := model.File{ID: primitive.NewObjectID(), AlumniID: otherAlumniID, FileType: "application/pdf", FileSize: 2000, UploadedAt: now}
+	for _, f := range []model.File{mine, photo, other} {
+		mockRepo.Create(context.Background(), &f)
+	}
+	store.Put(context.Background(), mine.StorageKey, bytes.NewReader([]byte("%PDF")), 4, "application/pdf")
+	alumniRepo.Data[alumniID.Hex()] = model.Alumni{ID: alumniID, UserID: ownerID, SertifikatPath: fileURL(mine.ID), Foto: fileURL(photo.ID)}
+	alumniRepo.Data[otherAlumniID.Hex()] = model.Alumni{ID: otherAlumniID, UserID: primitive.NewObjectID()}
+
+	app := fiber.New()
+	app.Use(func(c *fiber.Ctx) error {
+		if c.Get("X-Test-Role") == "admin" {
+			return asAdmin(c)
+		}
+		c.Locals("role", "user")
+		c.Locals("user_id", ownerID)
+		return c.Next()
+	})
+	app.Get("/files", service.GetAllFiles)
+	app.Get("/files/:id", service.GetFileByID)
+	app.Delete("/files/:id", service.DeleteFile)
+
+	list := func(query, role string) model.FileResponse {
+		req := httptest.NewRequest("GET", "/files"+query, nil)
+		req.Header.Set("X-Test-Role", role)
+		resp, _ := app.Test(req, -1)
+		if resp.StatusCode != 200 {
+			t.Fatalf("GET /files%s: expected 200, got %d", query, resp.StatusCode)
+		}
+		var out model.FileResponse
+		json.NewDecoder(resp.Body).Decode(&out)
+		return out
+	}
+
+	t.Run("User Listing Is Scoped", func(t *testing.T) {
+		out := list("?alumni_id="+otherAlumniID.Hex(), "user")
+		if out.Meta.Total != 2 {
+			t.Errorf("Expected 2 own files, got %d", out.Meta.Total)
+		}
+	})
+
+	t.Run("Admin Filters", func(t *testing.T) {
+		tests := []struct {
+			query string
+			want  int
+		}{
+			{"", 3},
+			{"?type=sertifikat", 2},
+			{"?type=foto", 1},
+			{"?alumni_id=" + otherAlumniID.Hex(), 1},
+			{"?min_size=1500", 2},
+			{"?max_size=1500", 1},
+			{"?from=" + now.AddDate(0, 0, -1).Format("2006-01-02"), 2},
+			{"?to=" + now.AddDate(0, 0, -5).Format("2006-01-02"), 1},
+			{"?limit=2&page=2", 3},
+		}
+		for _, tt := range tests {
+			if out := list(tt.query, "admin"); out.Meta.Total != tt.want {
+				t.Errorf("%q: expected total %d, got %d", tt.query, tt.want, out.Meta.Total)
+			}
+		}
+		if out := list("?limit=2&page=2", "admin"); len(out.Data) != 1 || out.Meta.Pages != 2 {
+			t.Errorf("Expected 1 item on page 2 of 2, got %d items, %d pages", len(out.Data), out.Meta.Pages)
+		}
+	})
+
+	t.Run("Other User's File", func(t *testing.T) {
+		for _, method := range []string{"GET", "DELETE"} {
+			resp, _ := app.Test(httptest.NewRequest(method, "/files/"+other.ID.Hex(), nil), -1)
+			if resp.StatusCode != 403 {
+				t.Errorf("%s: expected 403, got %d", method, resp.StatusCode)
+			}
+		}
+	})
+
+	t.Run("Delete Clears Alumni Field", func(t *testing.T) {
+		resp, _ := app.Test(httptest.NewRequest("DELETE", "/files/"+mine.ID.Hex(), nil), -1)
+		if resp.StatusCode != 200 {
+			t.Fatalf("Expected 200, got %d", resp.StatusCode)
+		}
+		a := alumniRepo.Data[alumniID.Hex()]
+		if a.SertifikatPath != "" || a.Foto != fileURL(photo.ID) {
+			t.Errorf("Expected only sertifikat_path cleared, got foto=%q sertifikat=%q", a.Foto, a.SertifikatPath)
+		}
+		if _, err := store.Stat(context.Background(), mine.StorageKey); err == nil {
+			t.Error("Expected stored object to be removed")
+		}
+	})
+}
+
+func TestGetMyFiles(t *testing.T) {
+	mockRepo := repository.NewMockFileRepository()
+	alumniRepo := repository.NewMockAlumniRepository()
+	service := NewFileService(mockRepo, alumniRepo, storage.NewMemory(), repository.NewMockBlobRepository(), scanner.Noop{}, repository.NewMockUploadSessionRepository(), testUploadCfg, repository.NewMockUnitOfWork(), nil)
+
+	userID := primitive.NewObjectID()
+	alumniID := primitive.NewObjectID()
+	alumniRepo.Data[alumniID.Hex()] = model.Alumni{ID: alumniID, UserID: userID}
+
+	mockRepo.Create(context.Background(), &model.File{ID: primitive.NewObjectID(), AlumniID: alumniID, FileName: "mine.jpg"})
+	mockRepo.Create(context.Background(), &model.File{ID: primitive.NewObjectID(), AlumniID: primitive.NewObjectID(), FileName: "other.jpg"})
+
+	app := fiber.New()
+	app.Get("/me/files", func(c *fiber.Ctx) error {
+		c.Locals("user_id", userID)
+		return service.GetMyFiles(c)
+	})
+
+	req := httptest.NewRequest("GET", "/me/files", nil)
+	resp, _ := app.Test(req, -1)
+
+	if resp.StatusCode != 200 {
+		t.Fatalf("Expected 200, got %d", resp.StatusCode)
+	}
+
+	var body struct {
+		Data []model.File `json:"data"`
+	}
+	json.NewDecoder(resp.Body).Decode(&body)
+	if len(body.Data) != 1 || body.Data[0].FileName != "mine.jpg" {
+		t.Errorf("Expected only mine.jpg, got %+v", body.Data)
+	}
+}
+
+func TestDownloadFile(t *testing.T) {
+	mockRepo := repository.NewMockFileRepository()
+	alumniRepo := repository.NewMockAlumniRepository()
+	store := storage.NewMemory()
+	service := NewFileService(mockRepo, alumniRepo, store, repository.NewMockBlobRepository(), scanner.Noop{}, repository.NewMockUploadSessionRepository(), testUploadCfg, repository.NewMockUnitOfWork(), nil)
+
+	ownerID := primitive.NewObjectID()
+	alumniID := primitive.NewObjectID()
+	alumniRepo.Data[alumniID.Hex()] = model.Alumni{ID: alumniID, UserID: ownerID}
+
+	content := []byte("%PDF-1.4 sertifikat alumni")
+	store.Put(context.Background(), "sertifikat/a.pdf", bytes.NewReader(content), int64(len(content)), "application/pdf")
+	fileID := primitive.NewObjectID()
+	mockRepo.Create(context.Background(), &model.File{ID: fileID, AlumniID: alumniID, OriginalName: "ijazah.pdf", StorageKey: "sertifikat/a.pdf"})
+
+	app := fiber.New()
+	app.Use(func(c *fiber.Ctx) error {
+		c.Locals("role", c.Get("X-Test-Role", "user"))
+		if c.Get("X-Test-User") == "owner" {
+			c.Locals("user_id", ownerID)
+		} else {
+			c.Locals("user_id", primitive.NewObjectID())
+		}
+		return c.Next()
+	})
+	app.Get("/files/:id/download", service.Download)
+
+	tests := []struct {
+		name        string
+		user        string
+		role        string
+		rangeHeader string
+		wantStatus  int
+		wantBody    string
+	}{
+		{"Owner", "owner", "user", "", 200, string(content)},
+		{"Admin", "", "admin", "", 200, string(content)},
+		{"Other User", "", "user", "", 403, ""},
+		{"Range", "owner", "user", "bytes=0-3", 206, "%PDF"},
+		{"Suffix Range", "owner", "user", "bytes=-8", 206, "t alumni"},
+		{"Unsatisfiable Range", "owner", "user", "bytes=999-", 416, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest("GET", "/files/"+fileID.Hex()+"/download", nil)
+			req.Header.Set("X-Test-User", tt.user)
+			req.Header.Set("X-Test-Role", tt.role)
+			if tt.rangeHeader != "" {
+				req.Header.Set("Range", tt.rangeHeader)
+			}
+			resp, _ := app.Test(req, -1)
+
+			if resp.StatusCode != tt.wantStatus {
+				t.Fatalf("Expected %d, got %d", tt.wantStatus, resp.StatusCode)
+			}
+			if tt.wantBody == "" {
+				return
+			}
+			body, _ := io.ReadAll(resp.Body)
+			if string(body) != tt.wantBody {
+				t.Errorf("Expected body %q, got %q", tt.wantBody, body)
+			}
+			if cd := resp.Header.Get("Content-Disposition"); cd != `attachment; filename=ijazah.pdf` {
+				t.Errorf("Unexpected Content-Disposition %q", cd)
+			}
+		})
+	}
+}
+
+// ctxStore mencatat context yang dipakai membaca object dari storage
+type ctxStore struct {
+	storage.Storage
+	ctx chan context.Context
+}
+
+func (s *ctxStore) Get(ctx context.Context, key string) (io.ReadCloser, storage.Object, error) {
+	s.ctx <- ctx
+	return s.Storage.Get(ctx, key)
+}
+
+func TestDownloadCancelsStreamContext(t *testing.T) {
+	mockRepo := repository.NewMockFileRepository()
+	store := &ctxStore{storage.NewMemory(), make(chan context.Context, 1)}
+	service := NewFileService(mockRepo, repository.NewMockAlumniRepository(), store, repository.NewMockBlobRepository(), scanner.Noop{}, repository.NewMockUploadSessionRepository(), testUploadCfg, repository.NewMockUnitOfWork(), nil)
+
+	content := []byte("%PDF-1.4 sertifikat alumni")
+	store.Put(context.Background(), "sertifikat/a.pdf", bytes.NewReader(content), int64(len(content)), "application/pdf")
+	fileID := primitive.NewObjectID()
+	mockRepo.Create(context.Background(), &model.File{ID: fileID, OriginalName: "ijazah.pdf", StorageKey: "sertifikat/a.pdf"})
+
+	app := config.NewApp()
+	app.Use(func(c *fiber.Ctx) error {
+		c.Locals("role", "admin")
+		c.Locals("user_id", primitive.NewObjectID())
+		return c.Next()
+	})
+	app.Get("/files/:id/download", service.Download)
+
+	resp, _ := app.Test(httptest.NewRequest("GET", "/files/"+fileID.Hex()+"/download", nil), -1)
+	body, _ := io.ReadAll(resp.Body)
+	if resp.StatusCode != 200 || string(body) != string(content) {
+		t.Fatalf("Expected 200 with content, got %d %q", resp.StatusCode, body)
+	}
+
+	ctx := <-store.ctx
+	select {
+	case <-ctx.Done():
+	case <-time.After(time.Second):
+		t.Fatal("request context was not cancelled after the body stream was closed")
+	}
+}
+
+func TestSignedURL(t *testing.T) {
+	mockRepo := repository.NewMockFileRepository()
+	store := storage.NewLocal(t.TempDir(), "/files/signed", []byte("secret"))
+	service := NewFileService(mockRepo, repository.NewMockAlumniRepository(), store, repository.NewMockBlobRepository(), scanner.Noop{}, repository.NewMockUploadSessionRepository(), testUploadCfg, repository.NewMockUnitOfWork(), nil)
+
+	store.Put(context.Background(), "sertifikat/a.pdf", bytes.NewReader([]byte("%PDF-1.4")), 8, "application/pdf")
+	fileID := primitive.NewObjectID()
+	mockRepo.Create(context.Background(), &model.File{ID: fileID, OriginalName: "a.pdf", StorageKey: "sertifikat/a.pdf"})
+
+	app := fiber.New()
+	app.Get("/files/signed/*", service.ServeSigned)
+	app.Get("/files/:id/signed-url", func(c *fiber.Ctx) error {
+		c.Locals("role", "admin")
+		return service.GetSignedURL(c)
+	})
+
+	req := httptest.NewRequest("GET", "/files/"+fileID.Hex()+"/signed-url?expires_in=60", nil)
+	resp, _ := app.Test(req, -1)
+	if resp.StatusCode != 200 {
+		t.Fatalf("Expected 200, got %d", resp.StatusCode)
+	}
+	var out struct {
+		URL string `json:"url"`
+	}
+	json.NewDecoder(resp.Body).Decode(&out)
+
+	t.Run("Valid Link", func(t *testing.T) {
+		resp, _ := app.Test(httptest.NewRequest("GET", out.URL, nil), -1)
+		if resp.StatusCode != 200 {
+			t.Errorf("Expected 200, got %d", resp.StatusCode)
+		}
+	})
+
+	t.Run("Tampered Link", func(t *testing.T) {
+		tampered := strings.Replace(out.URL, "sertifikat/a.pdf", "sertifikat/b.pdf", 1)
+		resp, _ := app.Test(httptest.NewRequest("GET", tampered, nil), -1)
+		if resp.StatusCode != 403 {
+			t.Errorf("Expected 403, got %d", resp.StatusCode)
+		}
+	})
+}
+
+func TestResumableUpload(t *testing.T) {
+	mockRepo := repository.NewMockFileRepository()
+	alumniRepo := repository.NewMockAlumniRepository()
+	uploadRepo := repository.NewMockUploadSessionRepository()
+	store := storage.NewMemory()
+	service := NewFileService(mockRepo, alumniRepo, store, repository.NewMockBlobRepository(), scanner.Noop{}, uploadRepo, testUploadCfg, repository.NewMockUnitOfWork(), nil)
+
+	ownerID := primitive.NewObjectID()
+	alumniID := primitive.NewObjectID()
+	alumniRepo.Data[alumniID.Hex()] = model.Alumni{ID: alumniID, UserID: ownerID}
+
+	app := fiber.New()
+	app.Use(func(c *fiber.Ctx) error {
+		c.Locals("role", "user")
+		if c.Get("X-Test-User") == "other" {
+			c.Locals("user_id", primitive.NewObjectID())
+		} else {
+			c.Locals("user_id", ownerID)
+		}
+		return c.Next()
+	})
+	app.Post("/files/uploads", service.CreateUpload)
+	app.Get("/files/uploads/:id", service.GetUpload)
+	app.Patch("/files/uploads/:id", service.PatchUpload)
+	app.Post("/files/uploads/:id/finalize", service.FinalizeUpload)
+
+	pdf := []byte("%PDF-1.4\n1 0 obj\n<< /Type /Catalog >>\nendobj\ntrailer\n<< /Root 1 0 R >>\nstartxref\n0\n%%EOF\n")
+	sum := sha256.Sum256(pdf)
+
+	create := func(size int, checksum string) string {
+		body := fmt.Sprintf(`{"alumni_id":"%s","file_name":"transkrip.pdf","size":%d,"checksum":"%s"}`, alumniID.Hex(), size, checksum)
+		req := httptest.NewRequest("POST", "/files/uploads", strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		resp, _ := app.Test(req, -1)
+		if resp.StatusCode != 201 {
+			t.Fatalf("create: expected 201, got %d", resp.StatusCode)
+		}
+		return filepath.Base(resp.Header.Get("Location"))
+	}
+	patch := func(id string, offset int, chunk []byte, checksum string) *http.Response {
+		req := httptest.NewRequest("PATCH", "/files/uploads/"+id, bytes.NewReader(chunk))
+		req.Header.Set("Content-Type", "application/offset+octet-stream")
+		req.Header.Set("Upload-Offset", strconv.Itoa(offset))
+		if checksum != "" {
+			req.Header.Set("Upload-Checksum", checksum)
+		}
+		resp, _ := app.Test(req, -1)
+		return resp
+	}
+	chunkSum := func(b []byte) string {
+		s := sha256.Sum256(b)
+		return "sha256 " + base64.StdEncoding.EncodeToString(s[:])
+	}
+
+	t.Run("Complete Upload", func(t *testing.T) {
+		id := create(len(pdf), hex.EncodeToString(sum[:]))
+
+		if resp := patch(id, 0, pdf[:40], chunkSum(pdf[:40])); resp.StatusCode != 204 || resp.Header.Get("Upload-Offset") != "40" {
+			t.Fatalf("first chunk: got %d offset %s", resp.StatusCode, resp.Header.Get("Upload-Offset"))
+		}
+		if resp := patch(id, 0, pdf[:40], ""); resp.StatusCode != 409 {
+			t.Errorf("stale offset: expected 409, got %d", resp.StatusCode)
+		}
+		if resp := patch(id, 40, pdf[40:], chunkSum([]byte("other"))); resp.StatusCode != 460 {
+			t.Errorf("bad chunk checksum: expected 460, got %d", resp.StatusCode)
+		}
+
+		req := httptest.NewRequest("POST", "/files/uploads/"+id+"/finalize", nil)
+		if resp, _ := app.Test(req, -1); resp.StatusCode != 409 {
+			t.Errorf("incomplete finalize: expected 409, got %d", resp.StatusCode)
+		}
+
+		// resume: klien menanyakan offset lalu melanjutkan
+		resp, _ := app.Test(httptest.NewRequest("GET", "/files/uploads/"+id, nil), -1)
+		offset, _ := strconv.Atoi(resp.Header.Get("Upload-Offset"))
+		if resp := patch(id, offset, pdf[offset:], chunkSum(pdf[offset:])); resp.StatusCode != 204 {
+			t.Fatalf("second chunk: expected 204, got %d", resp.StatusCode)
+		}
+
+		req = httptest.NewRequest("POST", "/files/uploads/"+id+"/finalize", nil)
+		resp, _ = app.Test(req, -1)
+		if resp.StatusCode != 200 {
+			t.Fatalf("finalize: expected 200, got %d", resp.StatusCode)
+		}
+		var out struct {
+			Data model.File `json:"data"`
+		}
+		json.NewDecoder(resp.Body).Decode(&out)
+
+		rc, _, err := store.Get(context.Background(), out.Data.StorageKey)
+		if err != nil {
+			t.Fatalf("final object missing: %v", err)
+		}
+		stored, _ := io.ReadAll(rc)
+		if !bytes.Equal(stored, pdf) {
+			t.Error("assembled file differs from upload")
+		}
+		if store.Len() != 1 || len(uploadRepo.Data) != 0 {
+			t.Errorf("expected chunks and session cleaned up, got %d objects, %d sessions", store.Len(), len(uploadRepo.Data))
+		}
+		if alumniRepo.Data[alumniID.Hex()].SertifikatPath != fileURL(out.Data.ID) {
+			t.Error("expected alumni sertifikat_path to point to the new file")
+		}
+	})
+
+	t.Run("Final Checksum Mismatch", func(t *testing.T) {
+		other := sha256.Sum256([]byte("other"))
+		id := create(len(pdf), hex.EncodeToString(other[:]))
+		patch(id, 0, pdf[:60], "")
+		patch(id, 60, pdf[60:], "")
+
+		resp, _ := app.Test(httptest.NewRequest("POST", "/files/uploads/"+id+"/finalize", nil), -1)
+		if resp.StatusCode != 422 {
+			t.Errorf("expected 422, got %d", resp.StatusCode)
+		}
+	})
+
+	t.Run("Concurrent Finalize", func(t *testing.T) {
+		id := create(len(pdf), hex.EncodeToString(sum[:]))
+		patch(id, 0, pdf, "")
+		// request finalize lain sudah mengklaim sesi ini
+		sess := uploadRepo.Data[id]
+		sess.Status = model.UploadSessionFinalizing
+		uploadRepo.Data[id] = sess
+		all, _ := mockRepo.FindAll(context.Background())
+
+		resp, _ := app.Test(httptest.NewRequest("POST", "/files/uploads/"+id+"/finalize", nil), -1)
+		if resp.StatusCode != 409 {
+			t.Errorf("expected 409, got %d", resp.StatusCode)
+		}
+		if after, _ := mockRepo.FindAll(context.Background()); len(after) != len(all) {
+			t.Error("expected no file record from the losing finalize")
+		}
+		if uploadRepo.Data[id].Status != model.UploadSessionFinalizing {
+			t.Error("expected the winner's claim to be left untouched")
+		}
+	})
+
+	t.Run("Other User", func(t *testing.T) {
+		id := create(10, "")
+		req := httptest.NewRequest("GET", "/files/uploads/"+id, nil)
+		req.Header.Set("X-Test-User", "other")
+		if resp, _ := app.Test(req, -1); resp.StatusCode != 404 {
+			t.Errorf("expected 404, got %d", resp.StatusCode)
+		}
+	})
+
+	t.Run("Expired Session", func(t *testing.T) {
+		id := create(10, "")
+		patch(id, 0, []byte("%PDF-"), "")
+		oid, _ := primitive.ObjectIDFromHex(id)
+		sess := uploadRepo.Data[id]
+		sess.ExpiresAt = time.Now().Add(-time.Minute)
+		uploadRepo.Data[id] = sess
+
+		if resp := patch(id, 5, []byte("12345"), ""); resp.StatusCode != 410 {
+			t.Errorf("expected 410, got %d", resp.StatusCode)
+		}
+		before := store.Len()
+		n, err := service.PurgeExpiredUploads(context.Background())
+		if err != nil || n != 1 {
+			t.Fatalf("purge: n=%d err=%v", n, err)
+		}
+		if _, err := uploadRepo.GetByID(context.Background(), oid); err == nil || store.Len() != before-1 {
+			t.Error("expected expired session and its chunk to be removed")
+		}
+	})
+}
+
+// multipartFile membuat body multipart dengan field "file" dan Content-Type yang bisa dipalsukan
+func multipartFile(filename, contentType string, content []byte) (*bytes.Buffer, string) {
+	body := &bytes.Buffer{}
+	w := multipart.NewWriter(body)
+	h := make(textproto.MIMEHeader)
+	h.Set("Content-Disposition", `form-data; name="file"; filename="`+filename+`"`)
+	h.Set("Content-Type", contentType)
+	part, _ := w.CreatePart(h)
+	part.Write(content)
+	w.Close()
+	return body, w.FormDataContentType()
+}
+
+func samplePNG() []byte {
+	buf := &bytes.Buffer{}
+	png.Encode(buf, image.NewRGBA(image.Rect(0, 0, 4, 4)))
+	return buf.Bytes()
+}
+
+// pngWithDimensions mengubah ukuran di header IHDR PNG kecil (CRC dihitung ulang) tanpa menambah data pixel,
+// meniru decompression bomb yang kecil di disk tapi raksasa setelah di-decode
+func pngWithDimensions(w, h uint32) []byte {
+	raw := samplePNG()
+	binary.BigEndian.PutUint32(raw[16:], w)
+	binary.BigEndian.PutUint32(raw[20:], h)
+	binary.BigEndian.PutUint32(raw[29:], crc32.ChecksumIEEE(raw[12:29]))
+	return raw
+}
+
+func TestUploadContentSniffing(t *testing.T) {
+	mockRepo := repository.NewMockFileRepository()
+	service := NewFileService(mockRepo, repository.NewMockAlumniRepository(), storage.NewMemory(), repository.NewMockBlobRepository(), scanner.Noop{}, repository.NewMockUploadSessionRepository(), testUploadCfg, repository.NewMockUnitOfWork(), nil)
+
+	app := fiber.New()
+	app.Use(func(c *fiber.Ctx) error {
+		c.Locals("role", "admin")
+		c.Locals("user_id", primitive.NewObjectID())
+		return c.Next()
+	})
+	app.Post("/files/upload-foto/:alumni_id", service.UploadFoto)
+	app.Post("/files/upload-sertifikat/:alumni_id", service.UploadSertifikat)
+
+	alumniID := primitive.NewObjectID().Hex()
+	pdf := []byte("%PDF-1.4\n1 0 obj\n<< /Type /Catalog >>\nendobj\ntrailer\n<< /Root 1 0 R >>\nstartxref\n0\n%%EOF\n")
+
+	tests := []struct {
+		name        string
+		path        string
+		filename    string
+		contentType string
+		content     []byte
+		wantStatus  int
+		wantType    string
+		wantExt     string
+	}{
+		{"HTML Disguised As PNG", "/files/upload-foto/", "foto.png", "image/png", []byte("<html><script>alert(1)</script></html>"), 400, "", ""},
+		{"Truncated PNG", "/files/upload-foto/", "foto.png", "image/png", samplePNG()[:20], 400, "", ""},
+		{"Oversized Dimensions", "/files/upload-foto/", "foto.png", "image/png", pngWithDimensions(100000, 100000), 400, "", ""},
+		{"Real PNG With Wrong Name", "/files/upload-foto/", "foto.exe", "application/octet-stream", samplePNG(), 200, "image/png", ".png"},
+		{"Executable Disguised As PDF", "/files/upload-sertifikat/", "cert.pdf", "application/pdf", []byte("MZ\x90\x00 this is not a pdf"), 400, "", ""},
+		{"PDF Without Trailer", "/files/upload-sertifikat/", "cert.pdf", "application/pdf", []byte("%PDF-1.4\n1 0 obj\nendobj\n"), 400, "", ""},
+		{"Valid PDF", "/files/upload-sertifikat/", "cert.txt", "text/plain", pdf, 200, "application/pdf", ".pdf"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			body, ct := multipartFile(tt.filename, tt.contentType, tt.content)
+			req := httptest.NewRequest("POST", tt.path+alumniID, body)
+			req.Header.Set("Content-Type", ct)
+			resp, _ := app.Test(req, -1)
+
+			if resp.StatusCode != tt.wantStatus {
+				t.Fatalf("Expected %d, got %d", tt.wantStatus, resp.StatusCode)
+			}
+			if tt.wantType == "" {
+				return
+			}
+
+			var out struct {
+				Data model.File `json:"data"`
+			}
+			json.NewDecoder(resp.Body).Decode(&out)
+			if out.Data.FileType != tt.wantType {
+				t.Errorf("Expected file type %s, got %s", tt.wantType, out.Data.FileType)
+			}
+			if filepath.Ext(out.Data.FileName) != tt.wantExt {
+				t.Errorf("Expected extension %s, got %s", tt.wantExt, out.Data.FileName)
+			}
+		})
+	}
+}
+
+// jpegWithOrientation membuat JPEG w x h dengan segmen EXIF berisi tag Orientation dan data "GPS" palsu
+func jpegWithOrientation(w, h int, orientation uint16) []byte {
+	buf := &bytes.Buffer{}
+	jpeg.Encode(buf, image.NewRGBA(image.Rect(0, 0, w, h)), nil)
+	raw := buf.Bytes()
+
+	tiff := &bytes.Buffer{}
+	tiff.WriteString("II")
+	binary.Write(tiff, binary.LittleEndian, uint16(42))
+	binary.Write(tiff, binary.LittleEndian, uint32(8))
+	binary.Write(tiff, binary.LittleEndian, uint16(1))
+	binary.Write(tiff, binary.LittleEndian, []uint16{0x0112, 3})
+	binary.Write(tiff, binary.LittleEndian, uint32(1))
+	binary.Write(tiff, binary.LittleEndian, []uint16{orientation, 0})
+	binary.Write(tiff, binary.LittleEndian, uint32(0))
+	tiff.WriteString("GPS-7.2575,112.7521")
+
+	payload := append([]byte("Exif\x00\x00"), tiff.Bytes()...)
+	app1 := []byte{0xFF, 0xE1, 0, 0}
+	binary.BigEndian.PutUint16(app1[2:], uint16(len(payload)+2))
+	app1 = append(app1, payload...)
+
+	out := append([]byte{}, raw[:2]...)
+	out = append(out, app1...)
+	return append(out, raw[2:]...)
+}
+
+func TestUploadFotoVariants(t *testing.T) {
+	mockRepo := repository.NewMockFileRepository()
+	store := storage.NewMemory()
+	service := NewFileService(mockRepo, repository.NewMockAlumniRepository(), store, repository.NewMockBlobRepository(), scanner.Noop{}, repository.NewMockUploadSessionRepository(), testUploadCfg, repository.NewMockUnitOfWork(), nil)
+
+	app := fiber.New()
+	app.Use(func(c *fiber.Ctx) error {
+		c.Locals("role", "admin")
+		c.Locals("user_id", primitive.NewObjectID())
+		return c.Next()
+	})
+	app.Post("/files/upload-foto/:alumni_id", service.UploadFoto)
+	app.Get("/files/:id/thumb", service.GetThumbnail)
+
+	// 400x200 dengan orientation 6 (harus diputar 90 derajat menjadi potret)
+	body, ct := multipartFile("foto.jpg", "image/jpeg", jpegWithOrientation(400, 200, 6))
+	req := httptest.NewRequest("POST", "/files/upload-foto/"+primitive.NewObjectID().Hex(), body)
+	req.Header.Set("Content-Type", ct)
+	resp, _ := app.Test(req, -1)
+	if resp.StatusCode != 200 {
+		t.Fatalf("Expected 200, got %d", resp.StatusCode)
+	}
+
+	var out struct {
+		Data model.File `json:"data"`
+	}
+	json.NewDecoder(resp.Body).Decode(&out)
+
+	want := map[string][2]int{"thumb": {75, 150}, "medium": {200, 400}, "original": {200, 400}}
+	if len(out.Data.Variants) != len(want) {
+		t.Fatalf("Expected %d variants, got %d", len(want), len(out.Data.Variants))
+	}
+	for _, v := range out.Data.Variants {
+		if dim := want[v.Size]; v.Width != dim[0] || v.Height != dim[1] {
+			t.Errorf("%s: expected %dx%d, got %dx%d", v.Size, dim[0], dim[1], v.Width, v.Height)
+		}
+		if v.StorageKey == "" || v.FilePath != "" {
+			t.Errorf("%s: expected storage key only, got key=%q path=%q", v.Size, v.StorageKey, v.FilePath)
+		}
+		rc, _, err := store.Get(context.Background(), v.StorageKey)
+		if err != nil {
+			t.Fatalf("%s: not stored: %v", v.Size, err)
+		}
+		stored, _ := io.ReadAll(rc)
+		if bytes.Contains(stored, []byte("Exif")) || bytes.Contains(stored, []byte("GPS-7.2575")) {
+			t.Errorf("%s: EXIF metadata was not stripped", v.Size)
+		}
+	}
+
+	t.Run("Serve Thumb", func(t *testing.T) {
+		req := httptest.NewRequest("GET", "/files/"+out.Data.ID.Hex()+"/thumb", nil)
+		resp, _ := app.Test(req, -1)
+
+		if resp.StatusCode != 200 {
+			t.Errorf("Expected 200, got %d", resp.StatusCode)
+		}
+		if resp.Header.Get("Content-Type") != "image/jpeg" {
+			t.Errorf("Expected image/jpeg, got %s", resp.Header.Get("Content-Type"))
+		}
+	})
+
+	t.Run("Unknown Size", func(t *testing.T) {
+		req := httptest.NewRequest("GET", "/files/"+out.Data.ID.Hex()+"/thumb?size=huge", nil)
+		resp, _ := app.Test(req, -1)
+
+		if resp.StatusCode != 404 {
+			t.Errorf("Expected 404, got %d", resp.StatusCode)
+		}
+	})
+
+	t.Run("Other User", func(t *testing.T) {
+		other := fiber.New()
+		other.Use(func(c *fiber.Ctx) error {
+			c.Locals("role", "user")
+			c.Locals("user_id", primitive.NewObjectID())
+			return c.Next()
+		})
+		other.Get("/files/:id/thumb", service.GetThumbnail)
+
+		req := httptest.NewRequest("GET", "/files/"+out.Data.ID.Hex()+"/thumb?size=original", nil)
+		resp, _ := other.Test(req, -1)
+
+		if resp.StatusCode != 403 {
+			t.Errorf("Expected 403, got %d", resp.StatusCode)
+		}
+	})
+}
+
+func TestDedupUploads(t *testing.T) {
+	mockRepo := repository.NewMockFileRepository()
+	blobRepo := repository.NewMockBlobRepository()
+	store := storage.NewMemory()
+	service := NewFileService(mockRepo, repository.NewMockAlumniRepository(), store, blobRepo, scanner.Noop{}, repository.NewMockUploadSessionRepository(), testUploadCfg, repository.NewMockUnitOfWork(), nil)
+
+	app := fiber.New()
+	app.Use(asAdmin)
+	app.Post("/files/upload-sertifikat/:alumni_id", service.UploadSertifikat)
+	app.Get("/files/dedup-report", service.GetDedupReport)
+	app.Delete("/files/:id", service.DeleteFile)
+
+	pdf := []byte("%PDF-1.4\n1 0 obj\n<< /Type /Catalog >>\nendobj\ntrailer\n<< /Root 1 0 R >>\nstartxref\n0\n%%EOF\n")
+	var uploaded []model.File
+	for i := 0; i < 2; i++ {
+		body, ct := multipartFile("cert.pdf", "application/pdf", pdf)
+		req := httptest.NewRequest("POST", "/files/upload-sertifikat/"+primitive.NewObjectID().Hex(), body)
+		req.Header.Set("Content-Type", ct)
+		resp, _ := app.Test(req, -1)
+		if resp.StatusCode != 200 {
+			t.Fatalf("upload %d: expected 200, got %d", i, resp.StatusCode)
+		}
+		var out struct {
+			Data model.File `json:"data"`
+		}
+		json.NewDecoder(resp.Body).Decode(&out)
+		uploaded = append(uploaded, out.Data)
+	}
+
+	sum := sha256.Sum256(pdf)
+	if uploaded[0].SHA256 != hex.EncodeToString(sum[:]) {
+		t.Errorf("Expected sha256 %x, got %s", sum, uploaded[0].SHA256)
+	}
+	if uploaded[0].StorageKey != uploaded[1].StorageKey || store.Len() != 1 {
+		t.Fatalf("Expected one shared blob, got keys %q %q and %d objects", uploaded[0].StorageKey, uploaded[1].StorageKey, store.Len())
+	}
+
+	report := func() model.DedupReport {
+		resp, _ := app.Test(httptest.NewRequest("GET", "/files/dedup-report", nil), -1)
+		var out struct {
+			Data model.DedupReport `json:"data"`
+		}
+		json.NewDecoder(resp.Body).Decode(&out)
+		return out.Data
+	}
+	if r := report(); r.Blobs != 1 || r.References != 2 || r.SavedBytes != int64(len(pdf)) {
+		t.Errorf("Unexpected report %+v", r)
+	}
+
+	app.Test(httptest.NewRequest("DELETE", "/files/"+uploaded[0].ID.Hex(), nil), -1)
+	if store.Len() != 1 {
+		t.Error("Blob must stay while another file references it")
+	}
+	app.Test(httptest.NewRequest("DELETE", "/files/"+uploaded[1].ID.Hex(), nil), -1)
+	if store.Len() != 0 || len(blobRepo.Data) != 0 {
+		t.Errorf("Expected blob removed after last reference, got %d objects, %d blobs", store.Len(), len(blobRepo.Data))
+	}
+}
+
+func TestDedupConcurrentRelease(t *testing.T) {
+	blobRepo := repository.NewMockBlobRepository()
+	store := storage.NewMemory()
+	service := NewFileService(repository.NewMockFileRepository(), repository.NewMockAlumniRepository(), store, blobRepo, scanner.Noop{}, repository.NewMockUploadSessionRepository(), testUploadCfg, repository.NewMockUnitOfWork(), nil)
+
+	app := fiber.New()
+	app.Use(asAdmin)
+	app.Post("/files/upload-sertifikat/:alumni_id", service.UploadSertifikat)
+
+	pdf := []byte("%PDF-1.4\n1 0 obj\n<< /Type /Catalog >>\nendobj\ntrailer\n<< /Root 1 0 R >>\nstartxref\n0\n%%EOF\n")
+	upload := func() model.File {
+		body, ct := multipartFile("cert.pdf", "application/pdf", pdf)
+		req := httptest.NewRequest("POST", "/files/upload-sertifikat/"+primitive.NewObjectID().Hex(), body)
+		req.Header.Set("Content-Type", ct)
+		resp, _ := app.Test(req, -1)
+		if resp.StatusCode != 200 {
+			t.Fatalf("upload: expected 200, got %d", resp.StatusCode)
+		}
+		var out struct {
+			Data model.File `json:"data"`
+		}
+		json.NewDecoder(resp.Body).Decode(&out)
+		return out.Data
+	}
+
+	first := upload()
+	// pelepas referensi terakhir sudah menghapus catatan blob, tetapi object-nya belum terhapus
+	delete(blobRepo.Data, first.SHA256)
+
+	second := upload()
+	if second.StorageKey == first.StorageKey {
+		t.Fatalf("Expected a new blob generation, got the releasing key %q", second.StorageKey)
+	}
+	if blobRepo.Data[second.SHA256].StorageKey != second.StorageKey {
+		t.Errorf("Expected blob record to point at %q, got %q", second.StorageKey, blobRepo.Data[second.SHA256].StorageKey)
+	}
+
+	// pelepas referensi menyelesaikan penghapusan object lama
+	store.Delete(context.Background(), first.StorageKey)
+	if _, err := store.Stat(context.Background(), second.StorageKey); err != nil {
+		t.Errorf("Expected new upload content to survive the release: %v", err)
+	}
+}
+
+func TestUploadRollback(t *testing.T) {
+	blobRepo := repository.NewMockBlobRepository()
+	store := storage.NewMemory()
+	uow := repository.NewMockUnitOfWork()
+	service := NewFileService(repository.NewMockFileRepository(), repository.NewMockAlumniRepository(), store, blobRepo, scanner.Noop{}, repository.NewMockUploadSessionRepository(), testUploadCfg, uow, nil)
+
+	app := fiber.New()
+	app.Use(asAdmin)
+	app.Post("/files/upload-sertifikat/:alumni_id", service.UploadSertifikat)
+	app.Post("/files/upload-foto/:alumni_id", service.UploadFoto)
+
+	upload := func(path, name, contentType string, content []byte) int {
+		body, ct := multipartFile(name, contentType, content)
+		req := httptest.NewRequest("POST", path+primitive.NewObjectID().Hex(), body)
+		req.Header.Set("Content-Type", ct)
+		resp, _ := app.Test(req, -1)
+		return resp.StatusCode
+	}
+
+	pdf := []byte("%PDF-1.4\n1 0 obj\n<< /Type /Catalog >>\nendobj\ntrailer\n<< /Root 1 0 R >>\nstartxref\n0\n%%EOF\n")
+	if status := upload("/files/upload-sertifikat/", "cert.pdf", "application/pdf", pdf); status != 200 {
+		t.Fatalf("Expected 200, got %d", status)
+	}
+	sum := sha256.Sum256(pdf)
+	hash := hex.EncodeToString(sum[:])
+
+	uow.CommitErr = errors.New("commit failed")
+
+	t.Run("Shared Blob Keeps Reference Count", func(t *testing.T) {
+		if status := upload("/files/upload-sertifikat/", "cert.pdf", "application/pdf", pdf); status != 500 {
+			t.Fatalf("Expected 500, got %d", status)
+		}
+		if blobRepo.Data[hash].RefCount != 1 || store.Len() != 1 {
+			t.Errorf("Expected original blob untouched, got ref_count %d and %d objects", blobRepo.Data[hash].RefCount, store.Len())
+		}
+	})
+
+	t.Run("New Document Removed From Storage", func(t *testing.T) {
+		other := append([]byte("%PDF-1.4\n% other\n"), pdf[9:]...)
+		if status := upload("/files/upload-sertifikat/", "other.pdf", "application/pdf", other); status != 500 {
+			t.Fatalf("Expected 500, got %d", status)
+		}
+		if store.Len() != 1 || len(blobRepo.Data) != 1 {
+			t.Errorf("Expected rolled back upload to leave no object, got %d objects and %d blobs", store.Len(), len(blobRepo.Data))
+		}
+	})
+
+	t.Run("Photo Variants Removed From Storage", func(t *testing.T) {
+		if status := upload("/files/upload-foto/", "foto.png", "image/png", samplePNG()); status != 500 {
+			t.Fatalf("Expected 500, got %d", status)
+		}
+		if store.Len() != 1 || len(blobRepo.Data) != 1 {
+			t.Errorf("Expected no photo variants left, got %d objects and %d blobs", store.Len(), len(blobRepo.Data))
+		}
+	})
+}
+
+// fakeScanner menolak isi yang mengandung "EICAR"; err != nil mensimulasikan scanner mati
+type fakeScanner struct {
+	err error
+}
+
+func (f *fakeScanner) Name() string { return "fake" }
+
+func (f *fakeScanner) Scan(ctx context.Context, r io.Reader) (scanner.Result, error) {
+	if f.err != nil {
+		return scanner.Result{}, f.err
+	}
+	data, _ := io.ReadAll(r)
+	if bytes.Contains(data, []byte("EICAR")) {
+		return scanner.Result{Clean: false, Signature: "Eicar-Test-Signature"}, nil
+	}
+	return scanner.Result{Clean: true}, nil
+}
+
+func TestUploadScanning(t *testing.T) {
+	mockRepo := repository.NewMockFileRepository()
+	store := storage.NewMemory()
+	scan := &fakeScanner{}
+	service := NewFileService(mockRepo, repository.NewMockAlumniRepository(), store, repository.NewMockBlobRepository(), scan, repository.NewMockUploadSessionRepository(), testUploadCfg, repository.NewMockUnitOfWork(), nil)
+
+	app := fiber.New()
+	app.Use(asAdmin)
+	app.Post("/files/upload-sertifikat/:alumni_id", service.UploadSertifikat)
+	app.Post("/files/upload-foto/:alumni_id", service.UploadFoto)
+	app.Get("/files/:id/download", service.Download)
+	app.Post("/files/:id/scan", service.ScanFile)
+
+	upload := func(pdf []byte) (int, model.File) {
+		body, ct := multipartFile("cert.pdf", "application/pdf", pdf)
+		req := httptest.NewRequest("POST", "/files/upload-sertifikat/"+primitive.NewObjectID().Hex(), body)
+		req.Header.Set("Content-Type", ct)
+		resp, _ := app.Test(req, -1)
+		var out struct {
+			Data model.File `json:"data"`
+		}
+		json.NewDecoder(resp.Body).Decode(&out)
+		return resp.StatusCode, out.Data
+	}
+	download := func(id primitive.ObjectID) int {
+		resp, _ := app.Test(httptest.NewRequest("GET", "/files/"+id.Hex()+"/download", nil), -1)
+		return resp.StatusCode
+	}
+	pdf := func(extra string) []byte {
+		return []byte("%PDF-1.4\n1 0 obj\n<< /Type /Catalog >>\nendobj\n" + extra + "\ntrailer\n<< /Root 1 0 R >>\nstartxref\n0\n%%EOF\n")
+	}
+
+	t.Run("Clean", func(t *testing.T) {
+		status, file := upload(pdf("% clean"))
+		if status != 200 || file.Status != model.FileStatusAvailable {
+			t.Fatalf("Expected 200 available, got %d %q", status, file.Status)
+		}
+		if code := download(file.ID); code != 200 {
+			t.Errorf("Expected download 200, got %d", code)
+		}
+	})
+
+	t.Run("Infected", func(t *testing.T) {
+		before := store.Len()
+		status, file := upload(pdf("% EICAR"))
+		if status != 422 || file.Status != model.FileStatusRejected || file.ScanResult != "Eicar-Test-Signature" {
+			t.Fatalf("Expected 422 rejected, got %d %q %q", status, file.Status, file.ScanResult)
+		}
+		if store.Len() != before {
+			t.Error("Rejected content must be removed from storage")
+		}
+		if code := download(file.ID); code != 409 {
+			t.Errorf("Expected download 409, got %d", code)
+		}
+	})
+
+	t.Run("Scanner Down Then Rescan", func(t *testing.T) {
+		scan.err = errors.New("connection refused")
+		status, file := upload(pdf("% later"))
+		if status != 202 || file.Status != model.FileStatusQuarantine {
+			t.Fatalf("Expected 202 quarantine, got %d %q", status, file.Status)
+		}
+		if code := download(file.ID); code != 409 {
+			t.Errorf("Expected download 409 while quarantined, got %d", code)
+		}
+
+		resp, _ := app.Test(httptest.NewRequest("POST", "/files/"+file.ID.Hex()+"/scan", nil), -1)
+		if resp.StatusCode != 503 {
+			t.Errorf("Expected rescan 503 while scanner down, got %d", resp.StatusCode)
+		}
+
+		scan.err = nil
+		resp, _ = app.Test(httptest.NewRequest("POST", "/files/"+file.ID.Hex()+"/scan", nil), -1)
+		if resp.StatusCode != 200 {
+			t.Fatalf("Expected rescan 200, got %d", resp.StatusCode)
+		}
+		if code := download(file.ID); code != 200 {
+			t.Errorf("Expected download 200 after rescan, got %d", code)
+		}
+	})
+
+	t.Run("Photo Rescan Scans Original Upload", func(t *testing.T) {
+		// data setelah IEND tidak ikut ke varian hasil olahan, jadi hanya salinan asli yang berisi EICAR
+		scan.err = errors.New("connection refused")
+		body, ct := multipartFile("foto.png", "image/png", append(samplePNG(), []byte("EICAR")...))
+		req := httptest.NewRequest("POST", "/files/upload-foto/"+primitive.NewObjectID().Hex(), body)
+		req.Header.Set("Content-Type", ct)
+		resp, _ := app.Test(req, -1)
+		var out struct {
+			Data model.File `json:"data"`
+		}
+		json.NewDecoder(resp.Body).Decode(&out)
+		if resp.StatusCode != 202 {
+			t.Fatalf("Expected 202 quarantine, got %d", resp.StatusCode)
+		}
+
+		scan.err = nil
+		resp, _ = app.Test(httptest.NewRequest("POST", "/files/"+out.Data.ID.Hex()+"/scan", nil), -1)
+		if resp.StatusCode != 422 {
+			t.Fatalf("Expected rescan of the original upload to be rejected, got %d", resp.StatusCode)
+		}
+		if keys, _ := store.List(context.Background(), "quarantine/source/"); len(keys) != 0 {
+			t.Errorf("Expected original upload removed after rescan, got %v", keys)
+		}
+	})
+}
+
+func TestReconcileFiles(t *testing.T) {
+	mockRepo := repository.NewMockFileRepository()
+	store := storage.NewMemory()
+	service := NewFileService(mockRepo, repository.NewMockAlumniRepository(), store, repository.NewMockBlobRepository(), scanner.Noop{}, repository.NewMockUploadSessionRepository(), testUploadCfg, repository.NewMockUnitOfWork(), nil)
+
+	app := fiber.New()
+	app.Use(asAdmin)
+	app.Post("/files/upload-sertifikat/:alumni_id", service.UploadSertifikat)
+	app.Post("/files/reconcile", service.Reconcile)
+
+	pdf := []byte("%PDF-1.4\n1 0 obj\n<< /Type /Catalog >>\nendobj\ntrailer\n<< /Root 1 0 R >>\nstartxref\n0\n%%EOF\n")
+	body, ct := multipartFile("cert.pdf", "application/pdf", pdf)
+	req := httptest.NewRequest("POST", "/files/upload-sertifikat/"+primitive.NewObjectID().Hex(), body)
+	req.Header.Set("Content-Type", ct)
+	if resp, _ := app.Test(req, -1); resp.StatusCode != 200 {
+		t.Fatalf("upload: expected 200, got %d", resp.StatusCode)
+	}
+
+	ctx := context.Background()
+	alumniID := primitive.NewObjectID()
+	legacyKey := "sertifikat/SERTIF_" + alumniID.Hex() + "_old.pdf"
+	store.Put(ctx, legacyKey, bytes.NewReader(pdf), int64(len(pdf)), "application/pdf")
+	stray := samplePNG()
+	store.Put(ctx, "foto/stray.png", bytes.NewReader(stray), int64(len(stray)), "image/png")
+	store.Put(ctx, "tmp/uploads/abc/00000000000000000000_x", strings.NewReader("chunk"), 5, "")
+
+	dangling := &model.File{FileName: "missing.pdf", StorageKey: "sertifikat/missing.pdf", FileType: "application/pdf", UploadedAt: time.Now()}
+	mockRepo.Create(context.Background(), dangling)
+
+	reconcile := func(query string) (int, model.ReconcileReport) {
+		resp, _ := app.Test(httptest.NewRequest("POST", "/files/reconcile"+query, nil), -1)
+		var out struct {
+			Data model.ReconcileReport `json:"data"`
+		}
+		json.NewDecoder(resp.Body).Decode(&out)
+		return resp.StatusCode, out.Data
+	}
+
+	// semua temuan masih baru: bisa jadi upload yang sedang berjalan, jadi dilewati
+	status, report := reconcile("?min_age=1h&orphans=delete&dangling=delete")
+	if status != 200 || len(report.Orphans) != 0 || len(report.Dangling) != 0 || report.SkippedRecent != 3 {
+		t.Fatalf("Expected recent objects and records skipped, got %d %+v", status, report)
+	}
+	if all, _ := mockRepo.FindAll(context.Background()); len(all) != 2 || store.Len() != 4 {
+		t.Errorf("Recent items must not be touched, got %d records and %d objects", len(all), store.Len())
+	}
+	if status, _ := reconcile("?min_age=soon"); status != 400 {
+		t.Errorf("Expected 400 for invalid min_age, got %d", status)
+	}
+
+	// dry run: hanya laporan, tmp/ tidak ikut diperiksa
+	status, report = reconcile("")
+	if status != 200 || len(report.Orphans) != 2 || len(report.Dangling) != 1 {
+		t.Fatalf("Expected 2 orphans and 1 dangling, got %d %+v", status, report)
+	}
+	if report.Dangling[0].FileID != dangling.ID.Hex() || report.Dangling[0].Action != "" {
+		t.Errorf("Unexpected dangling item %+v", report.Dangling[0])
+	}
+	if all, _ := mockRepo.FindAll(context.Background()); len(all) != 2 || store.Len() != 4 {
+		t.Errorf("Dry run must not change anything, got %d records and %d objects", len(all), store.Len())
+	}
+
+	if status, _ := reconcile("?orphans=purge"); status != 400 {
+		t.Errorf("Expected 400 for invalid action, got %d", status)
+	}
+
+	// quarantine: object yatim dipindah ke quarantine/, record dangling tidak bisa diunduh
+	status, report = reconcile("?orphans=quarantine&dangling=quarantine")
+	if status != 200 || report.Orphans[0].Action != "quarantine" || report.Dangling[0].Action != "quarantine" {
+		t.Fatalf("Unexpected report %d %+v", status, report)
+	}
+	if _, err := store.Stat(ctx, "quarantine/"+legacyKey); err != nil {
+		t.Errorf("Expected orphan moved to quarantine/: %v", err)
+	}
+	if _, err := store.Stat(ctx, legacyKey); !errors.Is(err, storage.ErrNotFound) {
+		t.Error("Expected orphan removed from its original key")
+	}
+	if f, _ := mockRepo.FindByID(context.Background(), dangling.ID.Hex()); f.Status != model.FileStatusQuarantine {
+		t.Errorf("Expected dangling record quarantined, got %q", f.Status)
+	}
+
+	// register: object yatim dicatat sebagai file quarantine dengan alumni dari nama file lama
+	store.Put(ctx, legacyKey, bytes.NewReader(pdf), int64(len(pdf)), "application/pdf")
+	status, report = reconcile("?orphans=register&dangling=delete")
+	if status != 200 || len(report.Orphans) != 1 || report.Orphans[0].FileID == "" {
+		t.Fatalf("Unexpected report %d %+v", status, report)
+	}
+	registered, err := mockRepo.FindByID(context.Background(), report.Orphans[0].FileID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if registered.AlumniID != alumniID || registered.Status != model.FileStatusQuarantine || registered.FileType != "application/pdf" {
+		t.Errorf("Unexpected registered file %+v", registered)
+	}
+	if _, err := mockRepo.FindByID(context.Background(), dangling.ID.Hex()); err == nil {
+		t.Error("Expected dangling record deleted")
+	}
+
+	status, report = reconcile("")
+	if status != 200 || len(report.Orphans) != 0 || len(report.Dangling) != 0 {
+		t.Errorf("Expected storage and records in sync, got %+v", report)
+	}
+}
+
+func TestReconcileLegacyFoto(t *testing.T) {
+	mockRepo := repository.NewMockFileRepository()
+	alumniRepo := repository.NewMockAlumniRepository()
+	store := storage.NewMemory()
+	service := NewFileService(mockRepo, alumniRepo, store, repository.NewMockBlobRepository(), scanner.Noop{}, repository.NewMockUploadSessionRepository(), testUploadCfg, repository.NewMockUnitOfWork(), nil)
+
+	app := fiber.New()
+	app.Use(asAdmin)
+	app.Post("/files/reconcile", service.Reconcile)
+
+	// seed: foto dari mount statis lama, dengan record lama, tanpa record, dan object yang sudah hilang
+	ctx := context.Background()
+	png := samplePNG()
+	withRecord := model.Alumni{ID: primitive.NewObjectID(), Foto: "uploads/foto/FOTO_lama.png"}
+	withoutRecord := model.Alumni{ID: primitive.NewObjectID()}
+	withoutRecord.Foto = "/uploads/foto/FOTO_" + withoutRecord.ID.Hex() + "_baru.png"
+	missing := model.Alumni{ID: primitive.NewObjectID(), Foto: "/uploads/foto/hilang.png"}
+	for _, a := range []model.Alumni{withRecord, withoutRecord, missing} {
+		alumniRepo.Data[a.ID.Hex()] = a
+	}
+	store.Put(ctx, "foto/FOTO_lama.png", bytes.NewReader(png), int64(len(png)), "image/png")
+	store.Put(ctx, "foto/FOTO_"+withoutRecord.ID.Hex()+"_baru.png", bytes.NewReader(png), int64(len(png)), "image/png")
+	legacy := &model.File{AlumniID: withRecord.ID, FileName: "FOTO_lama.png", FilePath: "uploads/foto/FOTO_lama.png", FileType: "image/png", UploadedAt: time.Now()}
+	mockRepo.Create(ctx, legacy)
+
+	reconcile := func(query string) (int, model.ReconcileReport) {
+		resp, _ := app.Test(httptest.NewRequest("POST", "/files/reconcile"+query, nil), -1)
+		var out struct {
+			Data model.ReconcileReport `json:"data"`
+		}
+		json.NewDecoder(resp.Body).Decode(&out)
+		return resp.StatusCode, out.Data
+	}
+
+	if status, _ := reconcile("?legacy=move"); status != 400 {
+		t.Errorf("Expected 400 for invalid legacy action, got %d", status)
+	}
+
+	// dry run: foto lama dilaporkan beserta record yang cocok, tanpa mengubah alumni
+	status, report := reconcile("")
+	if status != 200 || len(report.LegacyRefs) != 3 {
+		t.Fatalf("Expected 3 legacy foto, got %d %+v", status, report)
+	}
+	for _, item := range report.LegacyRefs {
+		if item.AlumniID == withRecord.ID.Hex() && (item.FileID != legacy.ID.Hex() || item.Action != "") {
+			t.Errorf("Unexpected legacy item %+v", item)
+		}
+	}
+	if alumniRepo.Data[withRecord.ID.Hex()].Foto != withRecord.Foto {
+		t.Error("Dry run must not change alumni foto")
+	}
+
+	// relink bersama register: object tanpa record didaftarkan lalu fotonya ikut dipindahkan
+	status, report = reconcile("?orphans=register&legacy=relink")
+	if status != 200 || len(report.Orphans) != 1 {
+		t.Fatalf("Unexpected report %d %+v", status, report)
+	}
+	if got := alumniRepo.Data[withRecord.ID.Hex()].Foto; got != fileURL(legacy.ID) {
+		t.Errorf("Expected foto relinked to %s, got %s", fileURL(legacy.ID), got)
+	}
+	if got := alumniRepo.Data[withoutRecord.ID.Hex()].Foto; got != "/api/v1/files/"+report.Orphans[0].FileID+"/download" {
+		t.Errorf("Expected foto relinked to the registered file, got %s", got)
+	}
+
+	// foto yang object-nya hilang tetap dilaporkan tanpa file_id
+	status, report = reconcile("?legacy=relink")
+	if status != 200 || len(report.LegacyRefs) != 1 || report.LegacyRefs[0].AlumniID != missing.ID.Hex() || report.LegacyRefs[0].FileID != "" {
+		t.Errorf("Expected only the missing foto left, got %+v", report.LegacyRefs)
+	}
+}
+
+func TestCertificateReview(t *testing.T) {
+	mockRepo := repository.NewMockFileRepository()
+	alumniRepo := repository.NewMockAlumniRepository()
+	service := NewFileService(mockRepo, alumniRepo, storage.NewMemory(), repository.NewMockBlobRepository(), scanner.Noop{}, repository.NewMockUploadSessionRepository(), testUploadCfg, repository.NewMockUnitOfWork(), nil)
+
+	alumniID := primitive.NewObjectID()
+	alumniRepo.Data[alumniID.Hex()] = model.Alumni{ID: alumniID, UserID: primitive.NewObjectID()}
+	staffID := primitive.NewObjectID()
+
+	app := fiber.New()
+	app.Use(func(c *fiber.Ctx) error {
+		if c.Get("X-Test-Role") == "staff" {
+			c.Locals("role", "staff")
+			c.Locals("user_id", staffID)
+			return c.Next()
+		}
+		return asAdmin(c)
+	})
+	app.Post("/files/upload-sertifikat/:alumni_id", service.UploadSertifikat)
+	app.Get("/files/reviews", service.GetReviewQueue)
+	app.Get("/files/:id/download", service.Download)
+	app.Post("/files/:id/review", service.ReviewCertificate)
+
+	pdf := []byte("%PDF-1.4\n1 0 obj\n<< /Type /Catalog >>\nendobj\ntrailer\n<< /Root 1 0 R >>\nstartxref\n0\n%%EOF\n")
+	body, ct := multipartFile("cert.pdf", "application/pdf", pdf)
+	req := httptest.NewRequest("POST", "/files/upload-sertifikat/"+alumniID.Hex(), body)
+	req.Header.Set("Content-Type", ct)
+	resp, _ := app.Test(req, -1)
+	var uploaded struct {
+		Data model.File `json:"data"`
+	}
+	json.NewDecoder(resp.Body).Decode(&uploaded)
+	file := uploaded.Data
+	if resp.StatusCode != 200 || file.Review == nil || file.Review.Status != model.ReviewPending {
+		t.Fatalf("Expected pending certificate, got %d %+v", resp.StatusCode, file.Review)
+	}
+	if a := alumniRepo.Data[alumniID.Hex()]; a.SertifikatStatus != model.ReviewPending {
+		t.Errorf("Expected alumni sertifikat_status pending, got %q", a.SertifikatStatus)
+	}
+
+	queue := func() model.FileResponse {
+		req := httptest.NewRequest("GET", "/files/reviews", nil)
+		req.Header.Set("X-Test-Role", "staff")
+		resp, _ := app.Test(req, -1)
+		var out model.FileResponse
+		json.NewDecoder(resp.Body).Decode(&out)
+		return out
+	}
+	if q := queue(); q.Meta.Total != 1 || q.Data[0].ID != file.ID {
+		t.Fatalf("Expected certificate in review queue, got %+v", q)
+	}
+
+	req = httptest.NewRequest("GET", "/files/"+file.ID.Hex()+"/download", nil)
+	req.Header.Set("X-Test-Role", "staff")
+	if resp, _ := app.Test(req, -1); resp.StatusCode != 200 {
+		t.Errorf("Staff must be able to open certificates under review, got %d", resp.StatusCode)
+	}
+
+	review := func(payload string) *http.Response {
+		req := httptest.NewRequest("POST", "/files/"+file.ID.Hex()+"/review", strings.NewReader(payload))
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("X-Test-Role", "staff")
+		resp, _ := app.Test(req, -1)
+		return resp
+	}
+
+	tests := []struct {
+		name    string
+		payload string
+		want    int
+	}{
+		{"Invalid Decision", `{"decision":"maybe"}`, 400},
+		{"Reject Without Notes", `{"decision":"rejected","notes":"  "}`, 400},
+		{"Approve", `{"decision":"approved","notes":"Sesuai data akademik"}`, 200},
+		{"Already Reviewed", `{"decision":"rejected","notes":"x"}`, 409},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if resp := review(tt.payload); resp.StatusCode != tt.want {
+				t.Errorf("Expected %d, got %d", tt.want, resp.StatusCode)
+			}
+		})
+	}
+
+	stored, _ := mockRepo.FindByID(context.Background(), file.ID.Hex())
+	r := stored.Review
+	if r.Status != model.ReviewApproved || r.Notes != "Sesuai data akademik" || r.ReviewerID == nil || *r.ReviewerID != staffID || r.ReviewedAt == nil {
+		t.Errorf("Unexpected review %+v", r)
+	}
+	if a := alumniRepo.Data[alumniID.Hex()]; a.SertifikatStatus != model.ReviewApproved {
+		t.Errorf("Expected alumni sertifikat_status approved, got %q", a.SertifikatStatus)
+	}
+	if q := queue(); q.Meta.Total != 0 {
+		t.Errorf("Expected empty queue after review, got %d", q.Meta.Total)
+	}
+}
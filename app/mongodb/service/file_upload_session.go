@@ -0,0 +1,373 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"alumni-app/app/mongodb/model"
+	"alumni-app/app/mongodb/repository"
+	"alumni-app/utils/mongodb"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Upload bertahap mengikuti pola protokol tus: buat sesi, kirim chunk dengan PATCH + Upload-Offset,
+// lalu finalize. Chunk disimpan sementara di storage dengan prefix tmp/uploads/<session>/.
+
+// CreateUpload godoc
+// @Summary Mulai upload bertahap (resumable) dokumen PDF
+// @Description Membuat sesi upload untuk dokumen / sertifikat besar. Hanya admin atau pemilik data alumni. checksum (opsional) adalah SHA-256 hex seluruh file.
+// @Tags Files
+// @Accept json
+// @Produce json
+// @Param body body model.CreateUploadSessionRequest true "Data sesi upload"
+// @Success 201 {object} model.UploadSession
+// @Failure 400 {object} model.ErrorResponse
+// @Failure 403 {object} model.ErrorResponse
+// @Failure 413 {object} model.ErrorResponse
+// @Security BearerAuth
+// @Router /files/uploads [post]
+func (s *fileService) CreateUpload(c *fiber.Ctx) error {
+	var req model.CreateUploadSessionRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": "Invalid request body"})
+	}
+
+	alumniID, err := primitive.ObjectIDFromHex(req.AlumniID)
+	if err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": "Invalid alumni ID"})
+	}
+	if strings.TrimSpace(req.FileName) == "" {
+		return c.Status(400).JSON(fiber.Map{"error": "file_name is required"})
+	}
+	if req.Size <= 0 {
+		return c.Status(400).JSON(fiber.Map{"error": "size must be greater than 0"})
+	}
+	if req.Size > s.uploadCfg.MaxDocumentSize {
+		return c.Status(413).JSON(fiber.Map{"error": fmt.Sprintf("Max document size is %d bytes", s.uploadCfg.MaxDocumentSize)})
+	}
+	if req.Checksum != "" {
+		if b, err := hex.DecodeString(req.Checksum); err != nil || len(b) != sha256.Size {
+			return c.Status(400).JSON(fiber.Map{"error": "checksum must be a SHA-256 hex digest"})
+		}
+	}
+
+	role, _ := c.Locals("role").(string)
+	userID, _ := c.Locals("user_id").(primitive.ObjectID)
+	if role != "admin" {
+		owner, err := s.ownsAlumni(c, alumniID)
+		if err != nil {
+			return c.Status(500).JSON(fiber.Map{"error": "Failed to check ownership"})
+		}
+		if !owner {
+			return c.Status(403).JSON(fiber.Map{"error": "You can only upload your own documents"})
+		}
+	}
+
+	sess := &model.UploadSession{
+		UserID:    userID,
+		AlumniID:  alumniID,
+		FileName:  req.FileName,
+		Size:      req.Size,
+		Checksum:  strings.ToLower(req.Checksum),
+		ExpiresAt: time.Now().Add(s.uploadCfg.SessionTTL),
+	}
+	if err := s.uploadRepo.Create(c.UserContext(), sess); err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	c.Location("/api/v1/files/uploads/" + sess.ID.Hex())
+	setUploadHeaders(c, sess)
+	return c.Status(201).JSON(fiber.Map{"success": true, "data": sess})
+}
+
+// GetUpload godoc
+// @Summary Status upload bertahap
+// @Description Mengembalikan offset yang sudah diterima (juga di header Upload-Offset) agar klien bisa melanjutkan upload
+// @Tags Files
+// @Produce json
+// @Param id path string true "Upload session ID"
+// @Success 200 {object} model.UploadSession
+// @Failure 404 {object} model.ErrorResponse
+// @Failure 410 {object} model.ErrorResponse
+// @Security BearerAuth
+// @Router /files/uploads/{id} [get]
+func (s *fileService) GetUpload(c *fiber.Ctx) error {
+	sess, status, msg := s.findUpload(c)
+	if status != 0 {
+		return c.Status(status).JSON(fiber.Map{"error": msg})
+	}
+
+	setUploadHeaders(c, &sess)
+	c.Set(fiber.HeaderCacheControl, "no-store")
+	return c.JSON(fiber.Map{"success": true, "data": sess})
+}
+
+// PatchUpload godoc
+// @Summary Kirim satu chunk upload bertahap
+// @Description Body berisi byte mentah (Content-Type application/offset+octet-stream). Upload-Offset harus sama dengan offset server. Upload-Checksum opsional dengan format "sha256 <base64>".
+// @Tags Files
+// @Accept application/offset+octet-stream
+// @Param id path string true "Upload session ID"
+// @Param Upload-Offset header int true "Offset chunk"
+// @Param Upload-Checksum header string false "sha256 <digest base64>"
+// @Success 204 "Chunk diterima"
+// @Failure 400 {object} model.ErrorResponse
+// @Failure 409 {object} model.ErrorResponse
+// @Failure 410 {object} model.ErrorResponse
+// @Failure 413 {object} model.ErrorResponse
+// @Failure 415 {object} model.ErrorResponse
+// @Failure 460 {object} model.ErrorResponse
+// @Security BearerAuth
+// @Router /files/uploads/{id} [patch]
+func (s *fileService) PatchUpload(c *fiber.Ctx) error {
+	sess, status, msg := s.findUpload(c)
+	if status != 0 {
+		return c.Status(status).JSON(fiber.Map{"error": msg})
+	}
+
+	if c.Get(fiber.HeaderContentType) != "application/offset+octet-stream" {
+		return c.Status(415).JSON(fiber.Map{"error": "Content-Type must be application/offset+octet-stream"})
+	}
+	offset, err := strconv.ParseInt(c.Get("Upload-Offset"), 10, 64)
+	if err != nil || offset < 0 {
+		return c.Status(400).JSON(fiber.Map{"error": "Invalid Upload-Offset header"})
+	}
+	if offset != sess.Offset {
+		c.Set("Upload-Offset", strconv.FormatInt(sess.Offset, 10))
+		return c.Status(409).JSON(fiber.Map{"error": "Upload-Offset does not match", "offset": sess.Offset})
+	}
+
+	chunk := c.Body()
+	if len(chunk) == 0 {
+		return c.Status(400).JSON(fiber.Map{"error": "Empty chunk"})
+	}
+	if int64(len(chunk)) > s.uploadCfg.MaxChunkSize {
+		return c.Status(413).JSON(fiber.Map{"error": fmt.Sprintf("Max chunk size is %d bytes", s.uploadCfg.MaxChunkSize)})
+	}
+	if offset+int64(len(chunk)) > sess.Size {
+		return c.Status(400).JSON(fiber.Map{"error": "Chunk exceeds declared upload size"})
+	}
+
+	if header := c.Get("Upload-Checksum"); header != "" {
+		algo, digest, _ := strings.Cut(header, " ")
+		if algo != "sha256" {
+			return c.Status(400).JSON(fiber.Map{"error": "Unsupported checksum algorithm, use sha256"})
+		}
+		want, err := base64.StdEncoding.DecodeString(digest)
+		sum := sha256.Sum256(chunk)
+		if err != nil || string(want) != string(sum[:]) {
+			return c.Status(460).JSON(fiber.Map{"error": "Checksum mismatch"})
+		}
+	}
+
+	// key unik per percobaan supaya PATCH ganda di offset yang sama tidak saling menimpa
+	key := fmt.Sprintf("tmp/uploads/%s/%020d_%s", sess.ID.Hex(), offset, uuid.New().String())
+	if err := s.store.Put(c.UserContext(), key, bytes.NewReader(chunk), int64(len(chunk)), "application/octet-stream"); err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	part := model.UploadChunk{Offset: offset, Size: int64(len(chunk)), StorageKey: key}
+	expiresAt := time.Now().Add(s.uploadCfg.SessionTTL)
+	if err := s.uploadRepo.AppendChunk(c.UserContext(), sess.ID, offset, part, expiresAt); err != nil {
+		// chunk lain dengan offset yang sama sudah lebih dulu diterima
+		s.store.Delete(c.UserContext(), key)
+		if errors.Is(err, repository.ErrVersionConflict) {
+			return c.Status(409).JSON(fiber.Map{"error": "Upload-Offset does not match"})
+		}
+		return c.Status(500).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	sess.Offset = offset + part.Size
+	sess.ExpiresAt = expiresAt
+	setUploadHeaders(c, &sess)
+	return c.SendStatus(204)
+}
+
+// FinalizeUpload godoc
+// @Summary Selesaikan upload bertahap
+// @Description Menggabungkan semua chunk, memeriksa checksum dan validasi PDF, lalu membuat record file
+// @Tags Files
+// @Produce json
+// @Param id path string true "Upload session ID"
+// @Success 200 {object} model.File
+// @Failure 400 {object} model.ErrorResponse
+// @Failure 409 {object} model.ErrorResponse
+// @Failure 410 {object} model.ErrorResponse
+// @Failure 422 {object} model.ErrorResponse
+// @Security BearerAuth
+// @Router /files/uploads/{id}/finalize [post]
+func (s *fileService) FinalizeUpload(c *fiber.Ctx) error {
+	sess, status, msg := s.findUpload(c)
+	if status != 0 {
+		return c.Status(status).JSON(fiber.Map{"error": msg})
+	}
+	if sess.Offset != sess.Size {
+		c.Set("Upload-Offset", strconv.FormatInt(sess.Offset, 10))
+		return c.Status(409).JSON(fiber.Map{"error": "Upload is not complete", "offset": sess.Offset, "size": sess.Size})
+	}
+
+	ctx := c.UserContext()
+	// klaim sesi lebih dulu: hanya satu request finalize yang boleh membuat record file
+	if err := s.uploadRepo.SetStatus(ctx, sess.ID, model.UploadSessionUploading, model.UploadSessionFinalizing); err != nil {
+		if errors.Is(err, repository.ErrVersionConflict) {
+			return c.Status(409).JSON(fiber.Map{"error": "Upload is already being finalized"})
+		}
+		return c.Status(500).JSON(fiber.Map{"error": err.Error()})
+	}
+	finalized := false
+	defer func() {
+		// gagal di tengah jalan (bukan karena file tidak valid): sesi dilepas agar finalize bisa diulang
+		if !finalized {
+			s.uploadRepo.SetStatus(ctx, sess.ID, model.UploadSessionFinalizing, model.UploadSessionUploading)
+		}
+	}()
+
+	tmp, err := os.CreateTemp("", "alumni-upload-*")
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": err.Error()})
+	}
+	defer os.Remove(tmp.Name())
+	defer tmp.Close()
+
+	hash := sha256.New()
+	var written int64
+	for _, part := range sess.Chunks {
+		rc, _, err := s.store.Get(ctx, part.StorageKey)
+		if err != nil {
+			return c.Status(500).JSON(fiber.Map{"error": "Missing upload chunk: " + err.Error()})
+		}
+		n, err := io.Copy(io.MultiWriter(tmp, hash), rc)
+		rc.Close()
+		if err != nil {
+			return c.Status(500).JSON(fiber.Map{"error": err.Error()})
+		}
+		written += n
+	}
+	if written != sess.Size {
+		return c.Status(500).JSON(fiber.Map{"error": "Assembled file size does not match upload size"})
+	}
+
+	// file tidak valid: sesi dibuang, klien harus mulai ulang
+	if sess.Checksum != "" && hex.EncodeToString(hash.Sum(nil)) != sess.Checksum {
+		s.discardUpload(ctx, sess)
+		return c.Status(422).JSON(fiber.Map{"error": "Checksum mismatch for assembled file"})
+	}
+
+	if _, err := tmp.Seek(0, io.SeekStart); err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": err.Error()})
+	}
+	contentType, err := utils.SniffContentType(tmp)
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": err.Error()})
+	}
+	if contentType != "application/pdf" {
+		s.discardUpload(ctx, sess)
+		return c.Status(400).JSON(fiber.Map{"error": "Invalid file type: detected " + contentType + ", only PDF allowed"})
+	}
+	if err := utils.ValidatePDF(tmp, sess.Size); err != nil {
+		s.discardUpload(ctx, sess)
+		return c.Status(400).JSON(fiber.Map{"error": "Invalid PDF file: " + err.Error()})
+	}
+
+	newName := fmt.Sprintf("SERTIF_%s_%s%s", sess.AlumniID.Hex(), uuid.New().String(), utils.ExtensionFor(contentType))
+	fileModel := &model.File{
+		AlumniID:     sess.AlumniID,
+		FileName:     newName,
+		OriginalName: sess.FileName,
+		Storage:      s.store.Name(),
+		FileSize:     sess.Size,
+		FileType:     contentType,
+		Status:       model.FileStatusQuarantine,
+		Review:       newCertificateReview(),
+		UploadedAt:   time.Now(),
+	}
+	if err := s.storeDocument(ctx, fileModel, tmp); err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": err.Error()})
+	}
+	finalized = true
+
+	s.discardUpload(ctx, sess)
+	return s.acceptFile(c, fileModel, tmp, "sertifikat_path", "Document uploaded successfully")
+}
+
+// AbortUpload godoc
+// @Summary Batalkan upload bertahap
+// @Description Menghapus sesi upload dan semua chunk yang sudah diterima
+// @Tags Files
+// @Param id path string true "Upload session ID"
+// @Success 204 "Sesi dihapus"
+// @Failure 404 {object} model.ErrorResponse
+// @Security BearerAuth
+// @Router /files/uploads/{id} [delete]
+func (s *fileService) AbortUpload(c *fiber.Ctx) error {
+	sess, status, msg := s.findUpload(c)
+	if status != 0 && status != 410 {
+		return c.Status(status).JSON(fiber.Map{"error": msg})
+	}
+
+	s.discardUpload(c.UserContext(), sess)
+	return c.SendStatus(204)
+}
+
+func (s *fileService) PurgeExpiredUploads(ctx context.Context) (int, error) {
+	expired, err := s.uploadRepo.FindExpired(ctx, time.Now())
+	if err != nil {
+		return 0, err
+	}
+	for _, sess := range expired {
+		s.discardUpload(ctx, sess)
+	}
+	return len(expired), nil
+}
+
+// findUpload memuat sesi milik user login; status != 0 berarti request harus dihentikan
+func (s *fileService) findUpload(c *fiber.Ctx) (model.UploadSession, int, string) {
+	id, err := primitive.ObjectIDFromHex(c.Params("id"))
+	if err != nil {
+		return model.UploadSession{}, 400, "Invalid upload ID"
+	}
+	sess, err := s.uploadRepo.GetByID(c.UserContext(), id)
+	if err != nil {
+		return model.UploadSession{}, 404, "Upload not found"
+	}
+
+	role, _ := c.Locals("role").(string)
+	userID, _ := c.Locals("user_id").(primitive.ObjectID)
+	if role != "admin" && sess.UserID != userID {
+		return model.UploadSession{}, 404, "Upload not found"
+	}
+	if time.Now().After(sess.ExpiresAt) {
+		return sess, 410, "Upload session has expired"
+	}
+	return sess, 0, ""
+}
+
+// discardUpload menghapus chunk sementara dan sesi upload
+func (s *fileService) discardUpload(ctx context.Context, sess model.UploadSession) {
+	for _, part := range sess.Chunks {
+		s.store.Delete(ctx, part.StorageKey)
+	}
+	if err := s.uploadRepo.Delete(ctx, sess.ID); err != nil {
+		fmt.Println("⚠️ Warning: gagal hapus sesi upload:", err)
+	}
+}
+
+func setUploadHeaders(c *fiber.Ctx, sess *model.UploadSession) {
+	c.Set("Upload-Offset", strconv.FormatInt(sess.Offset, 10))
+	c.Set("Upload-Length", strconv.FormatInt(sess.Size, 10))
+	c.Set("Upload-Expires", sess.ExpiresAt.UTC().Format(http.TimeFormat))
+}
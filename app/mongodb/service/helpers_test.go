@@ -0,0 +1,20 @@
+package service
+
+import (
+	"io"
+	"net/http/httptest"
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// sendAs mengirim request JSON dengan header X-Test-User dan mengembalikan status serta body response.
+// Setiap test memasang sendiri handler yang membaca header tersebut ke c.Locals.
+func sendAs(app *fiber.App, method, path, user, body string) (int, []byte) {
+	req := httptest.NewRequest(method, path, strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("X-Test-User", user)
+	resp, _ := app.Test(req)
+	data, _ := io.ReadAll(resp.Body)
+	return resp.StatusCode, data
+}
@@ -0,0 +1,169 @@
+package service
+
+import (
+	"alumni-app/app/mongodb/model"
+	"alumni-app/app/mongodb/repository"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// Interest godoc
+// @Summary Nyatakan minat / lamar lowongan
+// @Description Menghubungkan alumni login ke lowongan; NIM, nama, dan email alumni diteruskan ke pemasang lowongan.
+// @Description Hanya untuk lowongan yang masih dibuka.
+// @Tags Lowongan
+// @Accept json
+// @Produce json
+// @Param id path string true "ID lowongan"
+// @Param body body model.LowonganInterestRequest false "Catatan untuk pemasang lowongan"
+// @Success 201 {object} model.LowonganInterest
+// @Failure 400 {object} fiber.Map
+// @Failure 403 {object} fiber.Map
+// @Failure 404 {object} fiber.Map
+// @Failure 409 {object} fiber.Map
+// @Failure 500 {object} fiber.Map
+// @Security BearerAuth
+// @Router /lowongan/{id}/interest [post]
+func (s *LowonganService) Interest(c *fiber.Ctx) error {
+	userID, _ := c.Locals("user_id").(primitive.ObjectID)
+	l, status, err := s.loadLowongan(c)
+	if err != nil {
+		return c.Status(status).JSON(fiber.Map{"error": err.Error()})
+	}
+	alumni, err := s.alumniRepo.GetByUserID(c.UserContext(), userID)
+	if err != nil {
+		return c.Status(403).JSON(fiber.Map{"error": "Hanya akun yang terhubung ke data alumni yang bisa melamar"})
+	}
+	if l.PostedBy == userID {
+		return c.Status(400).JSON(fiber.Map{"error": "Tidak bisa melamar lowongan sendiri"})
+	}
+	if l.Status != model.LowonganOpen {
+		return c.Status(409).JSON(fiber.Map{"error": "Lowongan sudah tidak menerima pelamar", "status": l.Status})
+	}
+
+	var req model.LowonganInterestRequest
+	if len(c.Body()) > 0 {
+		if err := c.BodyParser(&req); err != nil {
+			return c.Status(400).JSON(fiber.Map{"error": "Request tidak valid"})
+		}
+	}
+
+	interest := model.LowonganInterest{
+		LowonganID: l.ID,
+		AlumniID:   alumni.ID,
+		UserID:     userID,
+		NIM:        alumni.NIM,
+		Nama:       alumni.Nama,
+		Email:      alumni.Email,
+		Catatan:    strings.TrimSpace(req.Catatan),
+	}
+	if err := s.interestRepo.Create(c.UserContext(), &interest); err != nil {
+		if errors.Is(err, repository.ErrConflict) {
+			return c.Status(409).JSON(fiber.Map{"error": "Anda sudah melamar lowongan ini", "field": "interest"})
+		}
+		return c.Status(500).JSON(fiber.Map{"error": err.Error()})
+	}
+	return c.Status(201).JSON(fiber.Map{"success": true, "message": "Minat berhasil dikirim", "data": interest})
+}
+
+// Withdraw godoc
+// @Summary Batalkan minat pada lowongan
+// @Tags Lowongan
+// @Produce json
+// @Param id path string true "ID lowongan"
+// @Success 200 {object} fiber.Map
+// @Failure 400 {object} fiber.Map
+// @Failure 404 {object} fiber.Map
+// @Failure 500 {object} fiber.Map
+// @Security BearerAuth
+// @Router /lowongan/{id}/interest [delete]
+func (s *LowonganService) Withdraw(c *fiber.Ctx) error {
+	userID, _ := c.Locals("user_id").(primitive.ObjectID)
+	id, err := primitive.ObjectIDFromHex(c.Params("id"))
+	if err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": "ID tidak valid"})
+	}
+	alumni, err := s.alumniRepo.GetByUserID(c.UserContext(), userID)
+	if err != nil {
+		return c.Status(404).JSON(fiber.Map{"error": "Data alumni untuk user ini tidak ditemukan"})
+	}
+
+	if err := s.interestRepo.Delete(c.UserContext(), id, alumni.ID); err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return c.Status(404).JSON(fiber.Map{"error": "Anda belum melamar lowongan ini"})
+		}
+		return c.Status(500).JSON(fiber.Map{"error": err.Error()})
+	}
+	return c.JSON(fiber.Map{"success": true, "message": "Minat berhasil dibatalkan"})
+}
+
+// GetInterests godoc
+// @Summary Daftar peminat lowongan
+// @Description Hanya admin dan pemasang lowongan
+// @Tags Lowongan
+// @Produce json
+// @Param id path string true "ID lowongan"
+// @Success 200 {array} model.LowonganInterest
+// @Failure 400 {object} fiber.Map
+// @Failure 403 {object} fiber.Map
+// @Failure 404 {object} fiber.Map
+// @Failure 500 {object} fiber.Map
+// @Security BearerAuth
+// @Router /lowongan/{id}/interests [get]
+func (s *LowonganService) GetInterests(c *fiber.Ctx) error {
+	l, status, err := s.loadLowongan(c)
+	if err != nil {
+		return c.Status(status).JSON(fiber.Map{"error": err.Error()})
+	}
+	if !canManageLowongan(c, l) {
+		return c.Status(403).JSON(fiber.Map{"error": "Tidak diizinkan"})
+	}
+
+	list, err := s.interestRepo.FindByLowongan(c.UserContext(), l.ID)
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": err.Error()})
+	}
+	return c.JSON(fiber.Map{"success": true, "count": len(list), "data": list})
+}
+
+// GetMyInterests godoc
+// @Summary Lowongan yang dilamar alumni login
+// @Tags Me
+// @Produce json
+// @Success 200 {array} model.MyLowonganInterest
+// @Failure 404 {object} fiber.Map
+// @Failure 500 {object} fiber.Map
+// @Security BearerAuth
+// @Router /me/lowongan/interests [get]
+func (s *LowonganService) GetMyInterests(c *fiber.Ctx) error {
+	userID, _ := c.Locals("user_id").(primitive.ObjectID)
+	alumni, err := s.alumniRepo.GetByUserID(c.UserContext(), userID)
+	if err != nil {
+		return c.Status(404).JSON(fiber.Map{"error": "Data alumni untuk user ini tidak ditemukan"})
+	}
+
+	interests, err := s.interestRepo.FindByAlumni(c.UserContext(), alumni.ID)
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": err.Error()})
+	}
+	now := time.Now()
+	list := make([]model.MyLowonganInterest, 0, len(interests))
+	for _, i := range interests {
+		item := model.MyLowonganInterest{LowonganInterest: i}
+		l, err := s.repo.GetByID(c.UserContext(), i.LowonganID)
+		switch {
+		case err == nil:
+			markExpired(&l, now)
+			item.Lowongan = &l
+		case !errors.Is(err, mongo.ErrNoDocuments):
+			return c.Status(500).JSON(fiber.Map{"error": err.Error()})
+		}
+		list = append(list, item)
+	}
+	return c.JSON(fiber.Map{"success": true, "count": len(list), "data": list})
+}
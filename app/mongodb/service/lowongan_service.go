@@ -0,0 +1,463 @@
+package service
+
+import (
+	"alumni-app/app/mongodb/model"
+	"alumni-app/app/mongodb/repository"
+	"context"
+	"errors"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// lowonganSortFields adalah kolom yang boleh dipakai sortBy pada GET /lowongan
+var lowonganSortFields = map[string]bool{
+	"created_at":     true,
+	"batas_lamaran":  true,
+	"gaji_max":       true,
+	"posisi_jabatan": true,
+}
+
+type LowonganService struct {
+	repo          repository.LowonganRepositoryInterface
+	interestRepo  repository.LowonganInterestRepositoryInterface
+	pekerjaanRepo repository.PekerjaanRepositoryInterface
+	alumniRepo    repository.AlumniRepositoryInterface
+	userRepo      repository.UserRepositoryInterface
+	uow           repository.UnitOfWork
+}
+
+func NewLowonganService(
+	repo repository.LowonganRepositoryInterface,
+	interestRepo repository.LowonganInterestRepositoryInterface,
+	pekerjaanRepo repository.PekerjaanRepositoryInterface,
+	alumniRepo repository.AlumniRepositoryInterface,
+	userRepo repository.UserRepositoryInterface,
+	uow repository.UnitOfWork,
+) *LowonganService {
+	return &LowonganService{
+		repo:          repo,
+		interestRepo:  interestRepo,
+		pekerjaanRepo: pekerjaanRepo,
+		alumniRepo:    alumniRepo,
+		userRepo:      userRepo,
+		uow:           uow,
+	}
+}
+
+// GetAll godoc
+// @Summary Cari lowongan kerja
+// @Description Default hanya lowongan yang masih dibuka dan batas lamarannya belum lewat
+// @Tags Lowongan
+// @Produce json
+// @Param page query int false "Halaman saat ini"
+// @Param limit query int false "Jumlah data per halaman (max 100)"
+// @Param search query string false "Cari di perusahaan, posisi, bidang industri, dan deskripsi"
+// @Param bidang_industri query string false "Filter bidang industri"
+// @Param lokasi_kerja query string false "Filter lokasi kerja"
+// @Param gaji_min query int false "Gaji maksimum minimal"
+// @Param status query string false "open (default), closed, expired, atau all"
+// @Param sortBy query string false "created_at, batas_lamaran, gaji_max, atau posisi_jabatan"
+// @Param order query string false "Urutan sorting (asc/desc)"
+// @Success 200 {object} model.LowonganResponse
+// @Failure 400 {object} fiber.Map
+// @Failure 500 {object} fiber.Map
+// @Security BearerAuth
+// @Router /lowongan [get]
+func (s *LowonganService) GetAll(c *fiber.Ctx) error {
+	filter := model.LowonganFilter{
+		Search:         c.Query("search"),
+		BidangIndustri: c.Query("bidang_industri"),
+		LokasiKerja:    c.Query("lokasi_kerja"),
+		GajiMin:        int64(c.QueryInt("gaji_min")),
+		Status:         c.Query("status", model.LowonganOpen),
+	}
+	return s.list(c, filter)
+}
+
+// GetMine godoc
+// @Summary Lowongan yang dipasang user login
+// @Description Semua status, termasuk yang sudah ditutup atau kedaluwarsa
+// @Tags Me
+// @Produce json
+// @Param page query int false "Halaman saat ini"
+// @Param limit query int false "Jumlah data per halaman (max 100)"
+// @Success 200 {object} model.LowonganResponse
+// @Failure 400 {object} fiber.Map
+// @Failure 500 {object} fiber.Map
+// @Security BearerAuth
+// @Router /me/lowongan [get]
+func (s *LowonganService) GetMine(c *fiber.Ctx) error {
+	userID, _ := c.Locals("user_id").(primitive.ObjectID)
+	return s.list(c, model.LowonganFilter{Status: "all", PostedBy: userID})
+}
+
+// GetByID godoc
+// @Summary Detail lowongan kerja
+// @Tags Lowongan
+// @Produce json
+// @Param id path string true "ID lowongan"
+// @Success 200 {object} model.Lowongan
+// @Failure 400 {object} fiber.Map
+// @Failure 404 {object} fiber.Map
+// @Failure 500 {object} fiber.Map
+// @Security BearerAuth
+// @Router /lowongan/{id} [get]
+func (s *LowonganService) GetByID(c *fiber.Ctx) error {
+	l, status, err := s.loadLowongan(c)
+	if err != nil {
+		return c.Status(status).JSON(fiber.Map{"error": err.Error()})
+	}
+	if l.InterestCount, err = s.interestRepo.CountByLowongan(c.UserContext(), l.ID); err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": err.Error()})
+	}
+	return c.JSON(fiber.Map{"success": true, "data": l})
+}
+
+// GetVocabulary godoc
+// @Summary Daftar bidang industri dan lokasi kerja
+// @Description Nilai yang sudah dipakai di data pekerjaan alumni dan lowongan, untuk pilihan filter dan isian form
+// @Tags Lowongan
+// @Produce json
+// @Success 200 {object} model.LowonganVocabulary
+// @Failure 500 {object} fiber.Map
+// @Security BearerAuth
+// @Router /lowongan/vocabulary [get]
+func (s *LowonganService) GetVocabulary(c *fiber.Ctx) error {
+	bidang, err := s.vocabulary(c.UserContext(), "bidang_industri")
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": err.Error()})
+	}
+	lokasi, err := s.vocabulary(c.UserContext(), "lokasi_kerja")
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": err.Error()})
+	}
+	return c.JSON(fiber.Map{"success": true, "data": model.LowonganVocabulary{BidangIndustri: bidang, LokasiKerja: lokasi}})
+}
+
+// Create godoc
+// @Summary Pasang lowongan kerja
+// @Description Bisa dilakukan admin (mis. untuk perusahaan mitra) dan alumni terverifikasi
+// @Description (email terverifikasi dan akun terhubung ke data alumni). Bidang industri dan lokasi kerja
+// @Description disamakan penulisannya dengan nilai yang sudah ada (lihat GET /lowongan/vocabulary).
+// @Tags Lowongan
+// @Accept json
+// @Produce json
+// @Param body body model.LowonganRequest true "Data lowongan"
+// @Success 201 {object} model.Lowongan
+// @Failure 400 {object} fiber.Map
+// @Failure 403 {object} fiber.Map
+// @Failure 500 {object} fiber.Map
+// @Security BearerAuth
+// @Router /lowongan [post]
+func (s *LowonganService) Create(c *fiber.Ctx) error {
+	userID, _ := c.Locals("user_id").(primitive.ObjectID)
+	allowed, err := s.canPost(c)
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": err.Error()})
+	}
+	if !allowed {
+		return c.Status(403).JSON(fiber.Map{"error": "Hanya admin dan alumni terverifikasi yang bisa memasang lowongan"})
+	}
+
+	var req model.LowonganRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": "Request tidak valid"})
+	}
+	l, err := s.lowonganFromRequest(c.UserContext(), req)
+	if err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": err.Error()})
+	}
+	if l.Expired(time.Now()) {
+		return c.Status(400).JSON(fiber.Map{"error": "Batas lamaran tidak boleh sebelum hari ini"})
+	}
+	l.Status = model.LowonganOpen
+	l.PostedBy = userID
+
+	if err := s.repo.Create(c.UserContext(), &l); err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": err.Error()})
+	}
+	return c.Status(201).JSON(fiber.Map{"success": true, "message": "Lowongan berhasil dipasang", "data": l})
+}
+
+// Update godoc
+// @Summary Perbarui lowongan kerja
+// @Description Hanya admin dan pemasang lowongan. Status "closed" menutup lowongan; lowongan kedaluwarsa
+// @Description dibuka kembali dengan memperpanjang batas lamaran.
+// @Tags Lowongan
+// @Accept json
+// @Produce json
+// @Param id path string true "ID lowongan"
+// @Param body body model.LowonganRequest true "Data lowongan"
+// @Success 200 {object} model.Lowongan
+// @Failure 400 {object} fiber.Map
+// @Failure 403 {object} fiber.Map
+// @Failure 404 {object} fiber.Map
+// @Failure 500 {object} fiber.Map
+// @Security BearerAuth
+// @Router /lowongan/{id} [put]
+func (s *LowonganService) Update(c *fiber.Ctx) error {
+	existing, status, err := s.loadLowongan(c)
+	if err != nil {
+		return c.Status(status).JSON(fiber.Map{"error": err.Error()})
+	}
+	if !canManageLowongan(c, existing) {
+		return c.Status(403).JSON(fiber.Map{"error": "Tidak diizinkan"})
+	}
+
+	var req model.LowonganRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": "Request tidak valid"})
+	}
+	l, err := s.lowonganFromRequest(c.UserContext(), req)
+	if err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	l.Status = req.Status
+	if l.Status == "" {
+		// lowongan kedaluwarsa otomatis dibuka kembali jika batas lamaran diperpanjang
+		l.Status = existing.Status
+		if l.Status == model.LowonganExpired {
+			l.Status = model.LowonganOpen
+		}
+	} else if l.Status != model.LowonganOpen && l.Status != model.LowonganClosed {
+		return c.Status(400).JSON(fiber.Map{"error": "Status harus open atau closed"})
+	}
+	if l.Status == model.LowonganOpen && l.Expired(time.Now()) {
+		if req.Status == model.LowonganOpen {
+			return c.Status(400).JSON(fiber.Map{"error": "Batas lamaran sudah lewat; perpanjang batas lamaran untuk membuka lowongan"})
+		}
+		l.Status = model.LowonganExpired
+	}
+
+	if err := s.repo.Update(c.UserContext(), existing.ID, &l); err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return c.Status(404).JSON(fiber.Map{"error": "Lowongan tidak ditemukan"})
+		}
+		return c.Status(500).JSON(fiber.Map{"error": err.Error()})
+	}
+	l.ID = existing.ID
+	l.PostedBy = existing.PostedBy
+	l.CreatedAt = existing.CreatedAt
+	return c.JSON(fiber.Map{"success": true, "message": "Lowongan berhasil diperbarui", "data": l})
+}
+
+// Delete godoc
+// @Summary Hapus lowongan kerja
+// @Description Hanya admin dan pemasang lowongan; data peminat ikut terhapus
+// @Tags Lowongan
+// @Produce json
+// @Param id path string true "ID lowongan"
+// @Success 200 {object} fiber.Map
+// @Failure 400 {object} fiber.Map
+// @Failure 403 {object} fiber.Map
+// @Failure 404 {object} fiber.Map
+// @Failure 500 {object} fiber.Map
+// @Security BearerAuth
+// @Router /lowongan/{id} [delete]
+func (s *LowonganService) Delete(c *fiber.Ctx) error {
+	l, status, err := s.loadLowongan(c)
+	if err != nil {
+		return c.Status(status).JSON(fiber.Map{"error": err.Error()})
+	}
+	if !canManageLowongan(c, l) {
+		return c.Status(403).JSON(fiber.Map{"error": "Tidak diizinkan"})
+	}
+
+	var interestsDeleted int64
+	err = s.uow.Do(c.UserContext(), func(ctx context.Context) error {
+		if err := s.repo.Delete(ctx, l.ID); err != nil {
+			return err
+		}
+		interestsDeleted, err = s.interestRepo.DeleteByLowongan(ctx, l.ID)
+		return err
+	})
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": err.Error()})
+	}
+	return c.JSON(fiber.Map{"success": true, "message": "Lowongan berhasil dihapus", "interests_deleted": interestsDeleted})
+}
+
+// ExpirePostings menandai lowongan open yang batas lamarannya sudah lewat sebagai expired
+func (s *LowonganService) ExpirePostings(ctx context.Context) (int64, error) {
+	return s.repo.ExpireBefore(ctx, time.Now().UTC().Truncate(24*time.Hour))
+}
+
+func (s *LowonganService) list(c *fiber.Ctx, filter model.LowonganFilter) error {
+	page := c.QueryInt("page", 1)
+	limit := c.QueryInt("limit", 10)
+	if page < 1 || limit < 1 || limit > 100 {
+		return c.Status(400).JSON(fiber.Map{"error": "page minimal 1 dan limit antara 1 sampai 100"})
+	}
+	sortBy := c.Query("sortBy", "created_at")
+	order := c.Query("order", "desc")
+	if !lowonganSortFields[sortBy] {
+		return c.Status(400).JSON(fiber.Map{"error": "sortBy tidak valid"})
+	}
+	switch filter.Status {
+	case model.LowonganOpen, model.LowonganClosed, model.LowonganExpired, "all":
+	default:
+		return c.Status(400).JSON(fiber.Map{"error": "Status harus open, closed, expired, atau all"})
+	}
+
+	data, err := s.repo.GetAll(c.UserContext(), filter, sortBy, order, page, limit)
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": err.Error()})
+	}
+	total, err := s.repo.Count(c.UserContext(), filter)
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": err.Error()})
+	}
+	now := time.Now()
+	for i := range data {
+		markExpired(&data[i], now)
+	}
+
+	return c.JSON(model.LowonganResponse{
+		Data: data,
+		Meta: model.MetaInfo{
+			Page:   page,
+			Limit:  limit,
+			Total:  int(total),
+			Pages:  (int(total) + limit - 1) / limit,
+			SortBy: sortBy,
+			Order:  order,
+			Search: filter.Search,
+		},
+	})
+}
+
+// loadLowongan mengambil lowongan dari parameter :id; status adalah kode HTTP jika gagal
+func (s *LowonganService) loadLowongan(c *fiber.Ctx) (model.Lowongan, int, error) {
+	id, err := primitive.ObjectIDFromHex(c.Params("id"))
+	if err != nil {
+		return model.Lowongan{}, 400, errors.New("ID tidak valid")
+	}
+	l, err := s.repo.GetByID(c.UserContext(), id)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return model.Lowongan{}, 404, errors.New("Lowongan tidak ditemukan")
+	}
+	if err != nil {
+		return model.Lowongan{}, 500, err
+	}
+	markExpired(&l, time.Now())
+	return l, 0, nil
+}
+
+// canPost: admin, atau alumni terverifikasi (email terverifikasi dan akun terhubung ke data alumni)
+func (s *LowonganService) canPost(c *fiber.Ctx) (bool, error) {
+	if role, _ := c.Locals("role").(string); role == "admin" {
+		return true, nil
+	}
+	userID, _ := c.Locals("user_id").(primitive.ObjectID)
+	user, err := s.userRepo.GetByID(c.UserContext(), userID)
+	if err != nil || !user.EmailVerified {
+		return false, nil
+	}
+	if _, err := s.alumniRepo.GetByUserID(c.UserContext(), userID); err != nil {
+		return false, nil
+	}
+	return true, nil
+}
+
+func canManageLowongan(c *fiber.Ctx, l model.Lowongan) bool {
+	role, _ := c.Locals("role").(string)
+	userID, _ := c.Locals("user_id").(primitive.ObjectID)
+	return role == "admin" || l.PostedBy == userID
+}
+
+// lowonganFromRequest memvalidasi isian lowongan; status diisi pemanggil
+func (s *LowonganService) lowonganFromRequest(ctx context.Context, req model.LowonganRequest) (model.Lowongan, error) {
+	req.NamaPerusahaan = strings.TrimSpace(req.NamaPerusahaan)
+	req.PosisiJabatan = strings.TrimSpace(req.PosisiJabatan)
+	if req.NamaPerusahaan == "" || req.PosisiJabatan == "" {
+		return model.Lowongan{}, errors.New("Nama perusahaan dan posisi jabatan wajib diisi")
+	}
+	if req.GajiMin < 0 || req.GajiMax < 0 || (req.GajiMax > 0 && req.GajiMin > req.GajiMax) {
+		return model.Lowongan{}, errors.New("Rentang gaji tidak valid")
+	}
+	batas, err := time.Parse(time.DateOnly, req.BatasLamaran)
+	if err != nil {
+		return model.Lowongan{}, errors.New("Format batas lamaran harus YYYY-MM-DD")
+	}
+
+	bidang, err := s.canonical(ctx, "bidang_industri", req.BidangIndustri)
+	if err != nil {
+		return model.Lowongan{}, err
+	}
+	lokasi, err := s.canonical(ctx, "lokasi_kerja", req.LokasiKerja)
+	if err != nil {
+		return model.Lowongan{}, err
+	}
+
+	return model.Lowongan{
+		NamaPerusahaan:     req.NamaPerusahaan,
+		PosisiJabatan:      req.PosisiJabatan,
+		BidangIndustri:     bidang,
+		LokasiKerja:        lokasi,
+		GajiMin:            req.GajiMin,
+		GajiMax:            req.GajiMax,
+		DeskripsiPekerjaan: strings.TrimSpace(req.DeskripsiPekerjaan),
+		KontakLamaran:      strings.TrimSpace(req.KontakLamaran),
+		BatasLamaran:       batas,
+	}, nil
+}
+
+// vocabulary menggabungkan nilai field dari pekerjaan alumni dan lowongan tanpa membedakan
+// huruf besar/kecil; penulisan di data pekerjaan alumni didahulukan
+func (s *LowonganService) vocabulary(ctx context.Context, field string) ([]string, error) {
+	fromPekerjaan, err := s.pekerjaanRepo.Distinct(ctx, field)
+	if err != nil {
+		return nil, err
+	}
+	fromLowongan, err := s.repo.Distinct(ctx, field)
+	if err != nil {
+		return nil, err
+	}
+
+	seen := make(map[string]bool)
+	list := []string{}
+	for _, v := range append(fromPekerjaan, fromLowongan...) {
+		v = strings.TrimSpace(v)
+		key := strings.ToLower(v)
+		if v == "" || seen[key] {
+			continue
+		}
+		seen[key] = true
+		list = append(list, v)
+	}
+	sort.Slice(list, func(i, j int) bool { return strings.ToLower(list[i]) < strings.ToLower(list[j]) })
+	return list, nil
+}
+
+// canonical menyamakan penulisan value dengan kosakata yang sudah ada, mis. "teknologi informasi"
+// menjadi "Teknologi Informasi"; nilai baru disimpan apa adanya
+func (s *LowonganService) canonical(ctx context.Context, field, value string) (string, error) {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return "", nil
+	}
+	vocab, err := s.vocabulary(ctx, field)
+	if err != nil {
+		return "", err
+	}
+	for _, v := range vocab {
+		if strings.EqualFold(v, value) {
+			return v, nil
+		}
+	}
+	return value, nil
+}
+
+// markExpired menampilkan lowongan open yang batas lamarannya lewat sebagai expired
+// meskipun worker belum sempat memperbaruinya
+func markExpired(l *model.Lowongan, now time.Time) {
+	if l.Status == model.LowonganOpen && l.Expired(now) {
+		l.Status = model.LowonganExpired
+	}
+}
@@ -0,0 +1,340 @@
+package service
+
+import (
+	"alumni-app/app/mongodb/model"
+	"alumni-app/app/mongodb/repository/mock"
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func TestCreateLowongan(t *testing.T) {
+	alumniRepo := repository.NewMockAlumniRepository()
+	userRepo := repository.NewMockUserRepository()
+	pekerjaanRepo := repository.NewMockPekerjaanRepository()
+	service := NewLowonganService(repository.NewMockLowonganRepository(), repository.NewMockLowonganInterestRepository(), pekerjaanRepo, alumniRepo, userRepo, repository.NewMockUnitOfWork())
+
+	// farid: alumni terverifikasi, sari: alumni dengan email belum terverifikasi, admin: tanpa data alumni
+	admin, farid, sari := primitive.NewObjectID(), primitive.NewObjectID(), primitive.NewObjectID()
+	users := map[string]primitive.ObjectID{"admin": admin, "farid": farid, "sari": sari}
+	userRepo.Create(context.Background(), &model.User{ID: farid, Username: "farid", EmailVerified: true})
+	userRepo.Create(context.Background(), &model.User{ID: sari, Username: "sari"})
+	alumniRepo.Create(context.Background(), &model.Alumni{NIM: "1", Nama: "Farid", UserID: farid})
+	alumniRepo.Create(context.Background(), &model.Alumni{NIM: "2", Nama: "Sari", UserID: sari})
+	pekerjaanRepo.Data["p1"] = model.PekerjaanAlumni{BidangIndustri: "Teknologi Informasi", LokasiKerja: "Jakarta"}
+
+	app := fiber.New()
+	app.Post("/lowongan", func(c *fiber.Ctx) error {
+		user := c.Get("X-Test-User")
+		c.Locals("user_id", users[user])
+		c.Locals("role", "user")
+		if user == "admin" {
+			c.Locals("role", "admin")
+		}
+		return service.Create(c)
+	})
+
+	tomorrow := time.Now().UTC().AddDate(0, 0, 1).Format(time.DateOnly)
+	body := func(posisi, bidang string, gajiMax int64, batas string) string {
+		b, _ := json.Marshal(model.LowonganRequest{
+			NamaPerusahaan: "PT Mitra", PosisiJabatan: posisi, BidangIndustri: bidang, LokasiKerja: "jakarta",
+			GajiMin: 5000000, GajiMax: gajiMax, BatasLamaran: batas,
+		})
+		return string(b)
+	}
+
+	t.Run("Who Can Post", func(t *testing.T) {
+		if code, _ := sendAs(app, "POST", "/lowongan", "sari", body("Backend", "IT", 0, tomorrow)); code != 403 {
+			t.Errorf("expected 403 for unverified alumni, got %d", code)
+		}
+		if code, _ := sendAs(app, "POST", "/lowongan", "admin", body("Akuntan", "Keuangan", 8000000, tomorrow)); code != 201 {
+			t.Errorf("expected 201 for admin, got %d", code)
+		}
+	})
+
+	t.Run("Validation", func(t *testing.T) {
+		if code, _ := sendAs(app, "POST", "/lowongan", "farid", body("Backend", "IT", 1000, tomorrow)); code != 400 {
+			t.Errorf("expected 400 for gaji_min > gaji_max, got %d", code)
+		}
+		if code, _ := sendAs(app, "POST", "/lowongan", "farid", body("Backend", "IT", 0, "2000-01-01")); code != 400 {
+			t.Errorf("expected 400 for past deadline, got %d", code)
+		}
+	})
+
+	t.Run("Vocabulary Spelling", func(t *testing.T) {
+		code, data := sendAs(app, "POST", "/lowongan", "farid", body("Backend Engineer", "teknologi informasi", 12000000, tomorrow))
+		if code != 201 {
+			t.Fatalf("expected 201 for verified alumni, got %d", code)
+		}
+		var res struct{ Data model.Lowongan }
+		json.Unmarshal(data, &res)
+		if res.Data.BidangIndustri != "Teknologi Informasi" || res.Data.LokasiKerja != "Jakarta" {
+			t.Errorf("expected vocabulary spelling, got %q / %q", res.Data.BidangIndustri, res.Data.LokasiKerja)
+		}
+	})
+}
+
+func TestGetAllLowongan(t *testing.T) {
+	repo := repository.NewMockLowonganRepository()
+	pekerjaanRepo := repository.NewMockPekerjaanRepository()
+	service := NewLowonganService(repo, repository.NewMockLowonganInterestRepository(), pekerjaanRepo, repository.NewMockAlumniRepository(), repository.NewMockUserRepository(), repository.NewMockUnitOfWork())
+
+	app := fiber.New()
+	app.Get("/lowongan", service.GetAll)
+	app.Get("/lowongan/vocabulary", service.GetVocabulary)
+
+	// seed: dua lowongan terbuka dan satu yang batas lamarannya lewat tapi belum ditandai worker
+	tomorrow := time.Now().UTC().AddDate(0, 0, 1).Truncate(24 * time.Hour)
+	for _, l := range []model.Lowongan{
+		{PosisiJabatan: "Backend Engineer", BidangIndustri: "Teknologi Informasi", LokasiKerja: "Jakarta", GajiMin: 5000000, GajiMax: 12000000, BatasLamaran: tomorrow},
+		{PosisiJabatan: "Akuntan", BidangIndustri: "Keuangan", LokasiKerja: "Jakarta", GajiMin: 5000000, GajiMax: 8000000, BatasLamaran: tomorrow},
+		{PosisiJabatan: "Operator", BatasLamaran: tomorrow.AddDate(0, 0, -4)},
+	} {
+		l.NamaPerusahaan = "PT Mitra"
+		l.Status = model.LowonganOpen
+		repo.Create(context.Background(), &l)
+	}
+	pekerjaanRepo.Data["p1"] = model.PekerjaanAlumni{BidangIndustri: "Teknologi Informasi", LokasiKerja: "Jakarta"}
+
+	t.Run("Search And Filter", func(t *testing.T) {
+		cases := map[string]int{
+			"/lowongan":                                 2,
+			"/lowongan?search=backend":                  1,
+			"/lowongan?bidang_industri=keuangan":        1,
+			"/lowongan?lokasi_kerja=JAKARTA":            2,
+			"/lowongan?gaji_min=10000000":               1,
+			"/lowongan?status=expired":                  1,
+			"/lowongan?status=all&sortBy=batas_lamaran": 3,
+		}
+		for path, want := range cases {
+			code, data := sendAs(app, "GET", path, "", "")
+			var res model.LowonganResponse
+			json.Unmarshal(data, &res)
+			if code != 200 || res.Meta.Total != want {
+				t.Errorf("%s: expected %d results, got %d (%d)", path, want, res.Meta.Total, code)
+			}
+		}
+	})
+
+	t.Run("Unknown Sort", func(t *testing.T) {
+		if code, _ := sendAs(app, "GET", "/lowongan?sortBy=password", "", ""); code != 400 {
+			t.Errorf("expected 400 for unknown sortBy, got %d", code)
+		}
+	})
+
+	t.Run("Vocabulary", func(t *testing.T) {
+		_, data := sendAs(app, "GET", "/lowongan/vocabulary", "", "")
+		var vocab struct{ Data model.LowonganVocabulary }
+		json.Unmarshal(data, &vocab)
+		if strings.Join(vocab.Data.BidangIndustri, ",") != "Keuangan,Teknologi Informasi" {
+			t.Errorf("unexpected vocabulary: %+v", vocab.Data)
+		}
+	})
+}
+
+func TestLowonganInterest(t *testing.T) {
+	repo := repository.NewMockLowonganRepository()
+	alumniRepo := repository.NewMockAlumniRepository()
+	service := NewLowonganService(repo, repository.NewMockLowonganInterestRepository(), repository.NewMockPekerjaanRepository(), alumniRepo, repository.NewMockUserRepository(), repository.NewMockUnitOfWork())
+
+	admin, farid, sari := primitive.NewObjectID(), primitive.NewObjectID(), primitive.NewObjectID()
+	users := map[string]primitive.ObjectID{"admin": admin, "farid": farid, "sari": sari}
+	alumniRepo.Create(context.Background(), &model.Alumni{NIM: "1", Nama: "Farid", Email: "farid@example.com", UserID: farid})
+	alumniRepo.Create(context.Background(), &model.Alumni{NIM: "2", Nama: "Sari", Email: "sari@example.com", UserID: sari})
+
+	app := fiber.New()
+	app.Use(func(c *fiber.Ctx) error {
+		user := c.Get("X-Test-User")
+		c.Locals("user_id", users[user])
+		c.Locals("role", "user")
+		if user == "admin" {
+			c.Locals("role", "admin")
+		}
+		return c.Next()
+	})
+	app.Get("/lowongan/:id", service.GetByID)
+	app.Post("/lowongan/:id/interest", service.Interest)
+	app.Get("/lowongan/:id/interests", service.GetInterests)
+	app.Get("/me/lowongan/interests", service.GetMyInterests)
+
+	tomorrow := time.Now().UTC().AddDate(0, 0, 1).Truncate(24 * time.Hour)
+	backend := model.Lowongan{NamaPerusahaan: "PT Mitra", PosisiJabatan: "Backend Engineer", Status: model.LowonganOpen, PostedBy: farid, BatasLamaran: tomorrow}
+	old := model.Lowongan{NamaPerusahaan: "PT Lama", PosisiJabatan: "Operator", Status: model.LowonganOpen, PostedBy: admin, BatasLamaran: tomorrow.AddDate(0, 0, -4)}
+	repo.Create(context.Background(), &backend)
+	repo.Create(context.Background(), &old)
+	path := "/lowongan/" + backend.ID.Hex()
+
+	t.Run("Not Allowed", func(t *testing.T) {
+		if code, _ := sendAs(app, "POST", path+"/interest", "farid", ""); code != 400 {
+			t.Errorf("expected 400 applying to own posting, got %d", code)
+		}
+		if code, _ := sendAs(app, "POST", path+"/interest", "admin", ""); code != 403 {
+			t.Errorf("expected 403 for account without alumni record, got %d", code)
+		}
+		if code, _ := sendAs(app, "POST", "/lowongan/"+old.ID.Hex()+"/interest", "sari", ""); code != 409 {
+			t.Errorf("expected 409 applying to expired posting, got %d", code)
+		}
+	})
+
+	t.Run("Apply Once", func(t *testing.T) {
+		if code, _ := sendAs(app, "POST", path+"/interest", "sari", `{"catatan":"Siap WFO"}`); code != 201 {
+			t.Fatalf("expected 201, got %d", code)
+		}
+		if code, _ := sendAs(app, "POST", path+"/interest", "sari", ""); code != 409 {
+			t.Errorf("expected 409 applying twice, got %d", code)
+		}
+	})
+
+	t.Run("List Interests", func(t *testing.T) {
+		if code, _ := sendAs(app, "GET", path+"/interests", "sari", ""); code != 403 {
+			t.Errorf("expected 403 listing interests of someone else's posting, got %d", code)
+		}
+		_, data := sendAs(app, "GET", path+"/interests", "farid", "")
+		var res struct{ Data []model.LowonganInterest }
+		json.Unmarshal(data, &res)
+		if len(res.Data) != 1 || res.Data[0].Email != "sari@example.com" || res.Data[0].Catatan != "Siap WFO" {
+			t.Errorf("unexpected interests: %+v", res.Data)
+		}
+	})
+
+	t.Run("Interest Count", func(t *testing.T) {
+		_, data := sendAs(app, "GET", path, "sari", "")
+		var detail struct{ Data model.Lowongan }
+		json.Unmarshal(data, &detail)
+		if detail.Data.InterestCount != 1 {
+			t.Errorf("expected interest_count 1, got %d", detail.Data.InterestCount)
+		}
+	})
+
+	t.Run("My Interests", func(t *testing.T) {
+		_, data := sendAs(app, "GET", "/me/lowongan/interests", "sari", "")
+		var mine struct{ Data []model.MyLowonganInterest }
+		json.Unmarshal(data, &mine)
+		if len(mine.Data) != 1 || mine.Data[0].Lowongan == nil || mine.Data[0].Lowongan.PosisiJabatan != "Backend Engineer" {
+			t.Errorf("unexpected my interests: %+v", mine.Data)
+		}
+	})
+}
+
+func TestUpdateLowongan(t *testing.T) {
+	repo := repository.NewMockLowonganRepository()
+	alumniRepo := repository.NewMockAlumniRepository()
+	service := NewLowonganService(repo, repository.NewMockLowonganInterestRepository(), repository.NewMockPekerjaanRepository(), alumniRepo, repository.NewMockUserRepository(), repository.NewMockUnitOfWork())
+
+	admin, farid, sari := primitive.NewObjectID(), primitive.NewObjectID(), primitive.NewObjectID()
+	users := map[string]primitive.ObjectID{"admin": admin, "farid": farid, "sari": sari}
+	alumniRepo.Create(context.Background(), &model.Alumni{NIM: "2", Nama: "Sari", UserID: sari})
+
+	app := fiber.New()
+	app.Use(func(c *fiber.Ctx) error {
+		user := c.Get("X-Test-User")
+		c.Locals("user_id", users[user])
+		c.Locals("role", "user")
+		if user == "admin" {
+			c.Locals("role", "admin")
+		}
+		return c.Next()
+	})
+	app.Put("/lowongan/:id", service.Update)
+	app.Post("/lowongan/:id/interest", service.Interest)
+
+	deadline := time.Now().UTC().AddDate(0, 0, 1).Truncate(24 * time.Hour)
+	backend := model.Lowongan{NamaPerusahaan: "PT Mitra", PosisiJabatan: "Backend Engineer", Status: model.LowonganOpen, PostedBy: farid, BatasLamaran: deadline}
+	akuntan := model.Lowongan{NamaPerusahaan: "PT Mitra", PosisiJabatan: "Akuntan", Status: model.LowonganOpen, PostedBy: admin, BatasLamaran: deadline}
+	old := model.Lowongan{NamaPerusahaan: "PT Lama", PosisiJabatan: "Operator", Status: model.LowonganExpired, PostedBy: admin, BatasLamaran: deadline.AddDate(0, 0, -4)}
+	for _, l := range []*model.Lowongan{&backend, &akuntan, &old} {
+		repo.Create(context.Background(), l)
+	}
+	tomorrow := deadline.Format(time.DateOnly)
+
+	t.Run("Someone Else's Posting", func(t *testing.T) {
+		body := `{"nama_perusahaan":"PT Mitra","posisi_jabatan":"Akuntan","batas_lamaran":"` + tomorrow + `"}`
+		if code, _ := sendAs(app, "PUT", "/lowongan/"+akuntan.ID.Hex(), "farid", body); code != 403 {
+			t.Errorf("expected 403 editing someone else's posting, got %d", code)
+		}
+	})
+
+	t.Run("Close", func(t *testing.T) {
+		body := `{"nama_perusahaan":"PT Mitra","posisi_jabatan":"Backend Engineer","batas_lamaran":"` + tomorrow + `","status":"closed"}`
+		if code, _ := sendAs(app, "PUT", "/lowongan/"+backend.ID.Hex(), "farid", body); code != 200 {
+			t.Fatalf("expected 200 closing posting, got %d", code)
+		}
+		if code, _ := sendAs(app, "POST", "/lowongan/"+backend.ID.Hex()+"/interest", "sari", ""); code != 409 {
+			t.Errorf("expected 409 applying to closed posting, got %d", code)
+		}
+	})
+
+	t.Run("Extend Deadline", func(t *testing.T) {
+		// memperpanjang batas lamaran membuka kembali lowongan kedaluwarsa
+		body := `{"nama_perusahaan":"PT Lama","posisi_jabatan":"Operator","batas_lamaran":"` + tomorrow + `"}`
+		if code, _ := sendAs(app, "PUT", "/lowongan/"+old.ID.Hex(), "admin", body); code != 200 {
+			t.Fatalf("expected 200 extending deadline, got %d", code)
+		}
+		if got := repo.Data[old.ID.Hex()].Status; got != model.LowonganOpen {
+			t.Errorf("expected reopened posting, got %s", got)
+		}
+	})
+}
+
+func TestExpirePostings(t *testing.T) {
+	repo := repository.NewMockLowonganRepository()
+	service := NewLowonganService(repo, repository.NewMockLowonganInterestRepository(), repository.NewMockPekerjaanRepository(), repository.NewMockAlumniRepository(), repository.NewMockUserRepository(), repository.NewMockUnitOfWork())
+
+	today := time.Now().UTC().Truncate(24 * time.Hour)
+	open := model.Lowongan{PosisiJabatan: "Akuntan", Status: model.LowonganOpen, BatasLamaran: today.AddDate(0, 0, 1)}
+	stale := model.Lowongan{PosisiJabatan: "Admin", Status: model.LowonganOpen, BatasLamaran: today.AddDate(0, 0, -1)}
+	repo.Create(context.Background(), &open)
+	repo.Create(context.Background(), &stale)
+
+	n, err := service.ExpirePostings(context.Background())
+	if err != nil || n != 1 || repo.Data[stale.ID.Hex()].Status != model.LowonganExpired {
+		t.Errorf("expected one posting expired, got n=%d err=%v status=%s", n, err, repo.Data[stale.ID.Hex()].Status)
+	}
+	if repo.Data[open.ID.Hex()].Status != model.LowonganOpen {
+		t.Error("posting before its deadline must stay open")
+	}
+}
+
+func TestDeleteLowongan(t *testing.T) {
+	repo := repository.NewMockLowonganRepository()
+	interestRepo := repository.NewMockLowonganInterestRepository()
+	service := NewLowonganService(repo, interestRepo, repository.NewMockPekerjaanRepository(), repository.NewMockAlumniRepository(), repository.NewMockUserRepository(), repository.NewMockUnitOfWork())
+
+	admin, farid, sari := primitive.NewObjectID(), primitive.NewObjectID(), primitive.NewObjectID()
+	users := map[string]primitive.ObjectID{"admin": admin, "sari": sari}
+
+	app := fiber.New()
+	app.Delete("/lowongan/:id", func(c *fiber.Ctx) error {
+		user := c.Get("X-Test-User")
+		c.Locals("user_id", users[user])
+		c.Locals("role", "user")
+		if user == "admin" {
+			c.Locals("role", "admin")
+		}
+		return service.Delete(c)
+	})
+
+	backend := model.Lowongan{PosisiJabatan: "Backend Engineer", Status: model.LowonganOpen, PostedBy: farid}
+	repo.Create(context.Background(), &backend)
+	interestRepo.Create(context.Background(), &model.LowonganInterest{LowonganID: backend.ID, AlumniID: primitive.NewObjectID(), UserID: sari})
+
+	t.Run("Not Owner", func(t *testing.T) {
+		if code, _ := sendAs(app, "DELETE", "/lowongan/"+backend.ID.Hex(), "sari", ""); code != 403 {
+			t.Errorf("expected 403, got %d", code)
+		}
+	})
+
+	t.Run("Admin", func(t *testing.T) {
+		if code, _ := sendAs(app, "DELETE", "/lowongan/"+backend.ID.Hex(), "admin", ""); code != 200 {
+			t.Fatalf("expected 200, got %d", code)
+		}
+		if len(interestRepo.Data) != 0 {
+			t.Errorf("expected interests removed with posting, got %d", len(interestRepo.Data))
+		}
+	})
+}
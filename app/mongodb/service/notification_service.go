@@ -0,0 +1,442 @@
+package service
+
+import (
+	"alumni-app/app/mongodb/model"
+	"alumni-app/app/mongodb/repository"
+	"alumni-app/config"
+	"alumni-app/utils/mongodb"
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"text/template"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// AlumniNotifier mengirim notifikasi berbasis template ke alumni; dipakai service lain
+// untuk kejadian sistem seperti hasil verifikasi sertifikat dan pengingat tracer study
+type AlumniNotifier interface {
+	NotifyAlumni(ctx context.Context, recipients []model.Alumni, key string, data map[string]string) error
+}
+
+type NotificationService struct {
+	templateRepo     repository.NotificationTemplateRepositoryInterface
+	notificationRepo repository.NotificationRepositoryInterface
+	queueRepo        repository.EmailQueueRepositoryInterface
+	alumniRepo       repository.AlumniRepositoryInterface
+	sender           utils.Notifier
+	cfg              config.NotificationConfig
+}
+
+func NewNotificationService(
+	templateRepo repository.NotificationTemplateRepositoryInterface,
+	notificationRepo repository.NotificationRepositoryInterface,
+	queueRepo repository.EmailQueueRepositoryInterface,
+	alumniRepo repository.AlumniRepositoryInterface,
+	sender utils.Notifier,
+	cfg config.NotificationConfig,
+) *NotificationService {
+	return &NotificationService{
+		templateRepo:     templateRepo,
+		notificationRepo: notificationRepo,
+		queueRepo:        queueRepo,
+		alumniRepo:       alumniRepo,
+		sender:           sender,
+		cfg:              cfg,
+	}
+}
+
+// GetTemplates godoc
+// @Summary Daftar template notifikasi (staff)
+// @Tags Notifications
+// @Produce json
+// @Success 200 {array} model.NotificationTemplate
+// @Failure 403 {object} fiber.Map
+// @Failure 500 {object} fiber.Map
+// @Security BearerAuth
+// @Router /notifications/templates [get]
+func (s *NotificationService) GetTemplates(c *fiber.Ctx) error {
+	list, err := s.templateRepo.FindAll(c.UserContext())
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": err.Error()})
+	}
+	return c.JSON(fiber.Map{"success": true, "data": list})
+}
+
+// CreateTemplate godoc
+// @Summary Buat template notifikasi (staff)
+// @Description Title dan body memakai sintaks text/template, mis. "Halo {{.Nama}}, {{.Data.link}}"
+// @Tags Notifications
+// @Accept json
+// @Produce json
+// @Param body body model.NotificationTemplateRequest true "Template baru"
+// @Success 201 {object} model.NotificationTemplate
+// @Failure 400 {object} fiber.Map
+// @Failure 403 {object} fiber.Map
+// @Failure 409 {object} fiber.Map
+// @Failure 500 {object} fiber.Map
+// @Security BearerAuth
+// @Router /notifications/templates [post]
+func (s *NotificationService) CreateTemplate(c *fiber.Ctx) error {
+	var req model.NotificationTemplateRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": "Request tidak valid"})
+	}
+	req.Key = strings.TrimSpace(req.Key)
+	if req.Key == "" {
+		return c.Status(400).JSON(fiber.Map{"error": "Key template wajib diisi"})
+	}
+	tpl, err := templateFromRequest(req)
+	if err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	if err := s.templateRepo.Create(c.UserContext(), &tpl); err != nil {
+		if errors.Is(err, repository.ErrConflict) {
+			return c.Status(409).JSON(fiber.Map{"error": "Key template sudah digunakan", "field": "key"})
+		}
+		return c.Status(500).JSON(fiber.Map{"error": err.Error()})
+	}
+	return c.Status(201).JSON(fiber.Map{"success": true, "message": "Template berhasil dibuat", "data": tpl})
+}
+
+// UpdateTemplate godoc
+// @Summary Perbarui template notifikasi (staff)
+// @Tags Notifications
+// @Accept json
+// @Produce json
+// @Param key path string true "Key template"
+// @Param body body model.NotificationTemplateRequest true "Isi template"
+// @Success 200 {object} model.NotificationTemplate
+// @Failure 400 {object} fiber.Map
+// @Failure 403 {object} fiber.Map
+// @Failure 404 {object} fiber.Map
+// @Failure 500 {object} fiber.Map
+// @Security BearerAuth
+// @Router /notifications/templates/{key} [put]
+func (s *NotificationService) UpdateTemplate(c *fiber.Ctx) error {
+	var req model.NotificationTemplateRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": "Request tidak valid"})
+	}
+	req.Key = c.Params("key")
+	tpl, err := templateFromRequest(req)
+	if err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	if err := s.templateRepo.Update(c.UserContext(), req.Key, &tpl); err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return c.Status(404).JSON(fiber.Map{"error": "Template tidak ditemukan"})
+		}
+		return c.Status(500).JSON(fiber.Map{"error": err.Error()})
+	}
+	return c.JSON(fiber.Map{"success": true, "message": "Template berhasil diperbarui", "data": tpl})
+}
+
+// DeleteTemplate godoc
+// @Summary Hapus template notifikasi (staff)
+// @Description Notifikasi yang sudah terkirim tidak ikut terhapus
+// @Tags Notifications
+// @Produce json
+// @Param key path string true "Key template"
+// @Success 200 {object} fiber.Map
+// @Failure 403 {object} fiber.Map
+// @Failure 404 {object} fiber.Map
+// @Failure 500 {object} fiber.Map
+// @Security BearerAuth
+// @Router /notifications/templates/{key} [delete]
+func (s *NotificationService) DeleteTemplate(c *fiber.Ctx) error {
+	if err := s.templateRepo.Delete(c.UserContext(), c.Params("key")); err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return c.Status(404).JSON(fiber.Map{"error": "Template tidak ditemukan"})
+		}
+		return c.Status(500).JSON(fiber.Map{"error": err.Error()})
+	}
+	return c.JSON(fiber.Map{"success": true, "message": "Template berhasil dihapus"})
+}
+
+// Send godoc
+// @Summary Kirim notifikasi ke alumni (staff)
+// @Description Mengirim template ke semua alumni yang cocok dengan target (filter sama seperti GET /alumni).
+// @Description Inbox in-app hanya untuk alumni yang sudah terhubung ke akun; email dimasukkan ke antrian dan dikirim bertahap.
+// @Tags Notifications
+// @Accept json
+// @Produce json
+// @Param body body model.SendNotificationRequest true "Template, data, dan target"
+// @Success 202 {object} fiber.Map
+// @Failure 400 {object} fiber.Map
+// @Failure 403 {object} fiber.Map
+// @Failure 404 {object} fiber.Map
+// @Failure 500 {object} fiber.Map
+// @Security BearerAuth
+// @Router /notifications/send [post]
+func (s *NotificationService) Send(c *fiber.Ctx) error {
+	var req model.SendNotificationRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": "Request tidak valid"})
+	}
+	if err := validateChannels(req.Channels); err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	tpl, err := s.templateRepo.GetByKey(c.UserContext(), req.TemplateKey)
+	if err != nil {
+		return c.Status(404).JSON(fiber.Map{"error": "Template tidak ditemukan"})
+	}
+	recipients, err := s.alumniRepo.FindByFilter(c.UserContext(), req.Target)
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	inApp, emails, err := s.deliver(c.UserContext(), tpl, recipients, req.Data, req.Channels)
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": err.Error()})
+	}
+	return c.Status(202).JSON(fiber.Map{
+		"success":      true,
+		"message":      "Notifikasi diproses",
+		"recipients":   len(recipients),
+		"in_app":       inApp,
+		"email_queued": emails,
+	})
+}
+
+// GetMine godoc
+// @Summary Inbox notifikasi user login
+// @Tags Me
+// @Produce json
+// @Param unread query bool false "Hanya yang belum dibaca"
+// @Param page query int false "Halaman saat ini"
+// @Param limit query int false "Jumlah data per halaman (max 100)"
+// @Success 200 {object} model.NotificationResponse
+// @Failure 400 {object} fiber.Map
+// @Failure 500 {object} fiber.Map
+// @Security BearerAuth
+// @Router /me/notifications [get]
+func (s *NotificationService) GetMine(c *fiber.Ctx) error {
+	userID, _ := c.Locals("user_id").(primitive.ObjectID)
+	page := c.QueryInt("page", 1)
+	limit := c.QueryInt("limit", 10)
+	if page < 1 || limit < 1 || limit > 100 {
+		return c.Status(400).JSON(fiber.Map{"error": "page minimal 1 dan limit antara 1 sampai 100"})
+	}
+	unreadOnly := c.QueryBool("unread")
+
+	list, err := s.notificationRepo.FindByUser(c.UserContext(), userID, unreadOnly, page, limit)
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": err.Error()})
+	}
+	total, err := s.notificationRepo.CountByUser(c.UserContext(), userID, unreadOnly)
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": err.Error()})
+	}
+	unread := total
+	if !unreadOnly {
+		if unread, err = s.notificationRepo.CountByUser(c.UserContext(), userID, true); err != nil {
+			return c.Status(500).JSON(fiber.Map{"error": err.Error()})
+		}
+	}
+
+	return c.JSON(model.NotificationResponse{
+		Data: list,
+		Meta: model.MetaInfo{
+			Page:   page,
+			Limit:  limit,
+			Total:  int(total),
+			Pages:  (int(total) + limit - 1) / limit,
+			SortBy: "created_at",
+			Order:  "desc",
+		},
+		Unread: unread,
+	})
+}
+
+// MarkRead godoc
+// @Summary Tandai notifikasi sudah dibaca
+// @Tags Me
+// @Produce json
+// @Param id path string true "ID notifikasi"
+// @Success 200 {object} fiber.Map
+// @Failure 400 {object} fiber.Map
+// @Failure 404 {object} fiber.Map
+// @Failure 500 {object} fiber.Map
+// @Security BearerAuth
+// @Router /me/notifications/{id}/read [put]
+func (s *NotificationService) MarkRead(c *fiber.Ctx) error {
+	userID, _ := c.Locals("user_id").(primitive.ObjectID)
+	id, err := primitive.ObjectIDFromHex(c.Params("id"))
+	if err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": "ID tidak valid"})
+	}
+
+	if err := s.notificationRepo.MarkRead(c.UserContext(), id, userID); err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return c.Status(404).JSON(fiber.Map{"error": "Notifikasi tidak ditemukan"})
+		}
+		return c.Status(500).JSON(fiber.Map{"error": err.Error()})
+	}
+	return c.JSON(fiber.Map{"success": true, "message": "Notifikasi ditandai sudah dibaca"})
+}
+
+// MarkAllRead godoc
+// @Summary Tandai semua notifikasi sudah dibaca
+// @Tags Me
+// @Produce json
+// @Success 200 {object} fiber.Map
+// @Failure 500 {object} fiber.Map
+// @Security BearerAuth
+// @Router /me/notifications/read-all [put]
+func (s *NotificationService) MarkAllRead(c *fiber.Ctx) error {
+	userID, _ := c.Locals("user_id").(primitive.ObjectID)
+
+	n, err := s.notificationRepo.MarkAllRead(c.UserContext(), userID)
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": err.Error()})
+	}
+	return c.JSON(fiber.Map{"success": true, "message": "Semua notifikasi ditandai sudah dibaca", "updated": n})
+}
+
+// NotifyAlumni mengirim template key ke alumni penerima lewat channel default template.
+// Template yang belum dibuat admin dilewati tanpa error.
+func (s *NotificationService) NotifyAlumni(ctx context.Context, recipients []model.Alumni, key string, data map[string]string) error {
+	tpl, err := s.templateRepo.GetByKey(ctx, key)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	_, _, err = s.deliver(ctx, tpl, recipients, data, nil)
+	return err
+}
+
+// DeliverEmails mengirim email di antrian yang sudah waktunya sampai antrian kosong.
+// Email yang gagal dijadwalkan ulang dengan jeda berlipat, dan ditandai failed setelah MaxAttempts.
+func (s *NotificationService) DeliverEmails(ctx context.Context) (sent, failed int, err error) {
+	for {
+		job, err := s.queueRepo.ClaimNext(ctx, time.Now(), s.cfg.Lease)
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return sent, failed, nil
+		}
+		if err != nil {
+			return sent, failed, err
+		}
+
+		sendErr := s.sender.Send(utils.Message{To: job.To, Subject: job.Subject, Body: job.Body})
+		switch {
+		case sendErr == nil:
+			err = s.queueRepo.MarkSent(ctx, job.ID)
+			sent++
+		case job.Attempts >= s.cfg.MaxAttempts:
+			err = s.queueRepo.MarkFailed(ctx, job.ID, sendErr.Error())
+			failed++
+		default:
+			backoff := s.cfg.RetryBackoff << (job.Attempts - 1)
+			err = s.queueRepo.MarkRetry(ctx, job.ID, time.Now().Add(backoff), sendErr.Error())
+		}
+		if err != nil {
+			return sent, failed, err
+		}
+	}
+}
+
+// deliver merender template untuk setiap penerima lalu menyimpan inbox dan antrian email
+func (s *NotificationService) deliver(ctx context.Context, tpl model.NotificationTemplate, recipients []model.Alumni, data map[string]string, channels []string) (inApp, emails int, err error) {
+	if len(channels) == 0 {
+		channels = tpl.Channels
+	}
+	title, body, err := parseTemplate(tpl.Title, tpl.Body)
+	if err != nil {
+		return 0, 0, err
+	}
+
+	var notifications []model.Notification
+	var jobs []model.EmailJob
+	for _, a := range recipients {
+		vars := templateVars{Nama: a.Nama, NIM: a.NIM, Jurusan: a.Jurusan, Angkatan: a.Angkatan, TahunLulus: a.TahunLulus, Data: data}
+		var t, b bytes.Buffer
+		if err := title.Execute(&t, vars); err != nil {
+			return 0, 0, err
+		}
+		if err := body.Execute(&b, vars); err != nil {
+			return 0, 0, err
+		}
+
+		if hasChannel(channels, model.ChannelInApp) && !a.UserID.IsZero() {
+			notifications = append(notifications, model.Notification{UserID: a.UserID, TemplateKey: tpl.Key, Title: t.String(), Body: b.String()})
+		}
+		if hasChannel(channels, model.ChannelEmail) && a.Email != "" {
+			jobs = append(jobs, model.EmailJob{TemplateKey: tpl.Key, To: a.Email, Subject: t.String(), Body: b.String()})
+		}
+	}
+
+	if err := s.notificationRepo.CreateMany(ctx, notifications); err != nil {
+		return 0, 0, err
+	}
+	if err := s.queueRepo.EnqueueMany(ctx, jobs); err != nil {
+		return len(notifications), 0, err
+	}
+	return len(notifications), len(jobs), nil
+}
+
+// templateVars adalah field yang bisa dipakai di template notifikasi
+type templateVars struct {
+	Nama       string
+	NIM        string
+	Jurusan    string
+	Angkatan   int
+	TahunLulus int
+	Data       map[string]string
+}
+
+func templateFromRequest(req model.NotificationTemplateRequest) (model.NotificationTemplate, error) {
+	if strings.TrimSpace(req.Title) == "" || strings.TrimSpace(req.Body) == "" {
+		return model.NotificationTemplate{}, errors.New("Title dan body template wajib diisi")
+	}
+	if len(req.Channels) == 0 {
+		req.Channels = []string{model.ChannelInApp}
+	}
+	if err := validateChannels(req.Channels); err != nil {
+		return model.NotificationTemplate{}, err
+	}
+	if _, _, err := parseTemplate(req.Title, req.Body); err != nil {
+		return model.NotificationTemplate{}, err
+	}
+	return model.NotificationTemplate{Key: req.Key, Title: req.Title, Body: req.Body, Channels: req.Channels}, nil
+}
+
+func parseTemplate(title, body string) (*template.Template, *template.Template, error) {
+	t, err := template.New("title").Option("missingkey=zero").Parse(title)
+	if err != nil {
+		return nil, nil, fmt.Errorf("Template title tidak valid: %w", err)
+	}
+	b, err := template.New("body").Option("missingkey=zero").Parse(body)
+	if err != nil {
+		return nil, nil, fmt.Errorf("Template body tidak valid: %w", err)
+	}
+	return t, b, nil
+}
+
+func validateChannels(channels []string) error {
+	for _, ch := range channels {
+		if ch != model.ChannelInApp && ch != model.ChannelEmail {
+			return fmt.Errorf("Channel %q tidak dikenal (in_app atau email)", ch)
+		}
+	}
+	return nil
+}
+
+func hasChannel(channels []string, ch string) bool {
+	for _, c := range channels {
+		if c == ch {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,193 @@
+package service
+
+import (
+	"alumni-app/app/mongodb/model"
+	"alumni-app/app/mongodb/repository/mock"
+	"alumni-app/config"
+	"alumni-app/utils/mongodb"
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// flakySender gagal sebanyak failures kali sebelum berhasil
+type flakySender struct {
+	failures int
+	captureNotifier
+}
+
+func (f *flakySender) Send(msg utils.Message) error {
+	if f.failures > 0 {
+		f.failures--
+		return errors.New("smtp down")
+	}
+	return f.captureNotifier.Send(msg)
+}
+
+var testNotificationCfg = config.NotificationConfig{
+	MaxAttempts:  2,
+	RetryBackoff: -time.Second, // percobaan ulang langsung jatuh tempo
+	Lease:        time.Minute,
+}
+
+func TestCreateTemplate(t *testing.T) {
+	service := NewNotificationService(repository.NewMockNotificationTemplateRepository(), repository.NewMockNotificationRepository(), repository.NewMockEmailQueueRepository(), repository.NewMockAlumniRepository(), &captureNotifier{}, testNotificationCfg)
+
+	app := fiber.New()
+	app.Post("/notifications/templates", service.CreateTemplate)
+
+	t.Run("Invalid Template", func(t *testing.T) {
+		if code, _ := sendAs(app, "POST", "/notifications/templates", "", `{"key":"rusak","title":"Halo {{.Nama","body":"x"}`); code != 400 {
+			t.Errorf("expected 400 for invalid template, got %d", code)
+		}
+	})
+
+	t.Run("Unknown Channel", func(t *testing.T) {
+		if code, _ := sendAs(app, "POST", "/notifications/templates", "", `{"key":"x","title":"a","body":"b","channels":["sms"]}`); code != 400 {
+			t.Errorf("expected 400 for unknown channel, got %d", code)
+		}
+	})
+
+	t.Run("Duplicate Key", func(t *testing.T) {
+		body := `{"key":"survey_reminder","title":"Tracer study {{.Jurusan}}","body":"Halo {{.Nama}}, isi di {{.Data.link}}","channels":["in_app","email"]}`
+		if code, _ := sendAs(app, "POST", "/notifications/templates", "", body); code != 201 {
+			t.Fatalf("expected 201, got %d", code)
+		}
+		if code, _ := sendAs(app, "POST", "/notifications/templates", "", body); code != 409 {
+			t.Errorf("expected 409 for duplicate key, got %d", code)
+		}
+	})
+}
+
+func TestSendNotification(t *testing.T) {
+	templateRepo := repository.NewMockNotificationTemplateRepository()
+	notificationRepo := repository.NewMockNotificationRepository()
+	queueRepo := repository.NewMockEmailQueueRepository()
+	alumniRepo := repository.NewMockAlumniRepository()
+	service := NewNotificationService(templateRepo, notificationRepo, queueRepo, alumniRepo, &captureNotifier{}, testNotificationCfg)
+
+	app := fiber.New()
+	app.Post("/notifications/send", service.Send)
+
+	// seed: dua alumni TI 2018 (satu belum punya akun) dan satu alumni SI
+	farid := primitive.NewObjectID()
+	for _, a := range []model.Alumni{
+		{NIM: "1", Nama: "Farid", Jurusan: "TI", Angkatan: 2018, Email: "farid@example.com", UserID: farid},
+		{NIM: "2", Nama: "Tanpa Akun", Jurusan: "TI", Angkatan: 2018, Email: "anon@example.com"},
+		{NIM: "3", Nama: "Budi", Jurusan: "SI", Angkatan: 2018, Email: "budi@example.com", UserID: primitive.NewObjectID()},
+	} {
+		alumniRepo.Create(context.Background(), &a)
+	}
+	templateRepo.Create(context.Background(), &model.NotificationTemplate{
+		Key: "survey_reminder", Title: "Tracer study {{.Jurusan}}", Body: "Halo {{.Nama}}, isi di {{.Data.link}}",
+		Channels: []string{model.ChannelInApp, model.ChannelEmail},
+	})
+
+	t.Run("Send To Target", func(t *testing.T) {
+		body := `{"template_key":"survey_reminder","data":{"link":"https://example.com/s"},"target":{"jurusan":"ti","angkatan":2018}}`
+		if code, _ := sendAs(app, "POST", "/notifications/send", "", body); code != 202 {
+			t.Fatalf("expected 202, got %d", code)
+		}
+		if len(notificationRepo.Data) != 1 {
+			t.Fatalf("expected one inbox item (only linked TI alumni), got %d", len(notificationRepo.Data))
+		}
+		for _, n := range notificationRepo.Data {
+			if n.UserID != farid || n.Title != "Tracer study TI" || n.Body != "Halo Farid, isi di https://example.com/s" {
+				t.Errorf("unexpected notification: %+v", n)
+			}
+		}
+		if len(queueRepo.Data) != 2 {
+			t.Errorf("expected two queued emails, got %d", len(queueRepo.Data))
+		}
+	})
+
+	t.Run("Missing Template Is Skipped", func(t *testing.T) {
+		if err := service.NotifyAlumni(context.Background(), []model.Alumni{{UserID: farid}}, "tidak_ada", nil); err != nil {
+			t.Errorf("expected missing template to be ignored, got %v", err)
+		}
+	})
+}
+
+func TestMarkRead(t *testing.T) {
+	notificationRepo := repository.NewMockNotificationRepository()
+	service := NewNotificationService(repository.NewMockNotificationTemplateRepository(), notificationRepo, repository.NewMockEmailQueueRepository(), repository.NewMockAlumniRepository(), &captureNotifier{}, testNotificationCfg)
+
+	farid, budi := primitive.NewObjectID(), primitive.NewObjectID()
+	app := fiber.New()
+	app.Use(func(c *fiber.Ctx) error {
+		// tanpa header X-Test-User request dikirim sebagai farid
+		c.Locals("user_id", farid)
+		if c.Get("X-Test-User") == "budi" {
+			c.Locals("user_id", budi)
+		}
+		c.Locals("role", "user")
+		return c.Next()
+	})
+	app.Get("/me/notifications", service.GetMine)
+	app.Put("/me/notifications/:id/read", service.MarkRead)
+
+	list := []model.Notification{{UserID: farid, Title: "Halo", Body: "Farid"}}
+	notificationRepo.CreateMany(context.Background(), list)
+	id := list[0].ID.Hex()
+
+	t.Run("Unread Inbox", func(t *testing.T) {
+		code, body := sendAs(app, "GET", "/me/notifications?unread=true", "", "")
+		var res model.NotificationResponse
+		json.Unmarshal(body, &res)
+		if code != 200 || len(res.Data) != 1 || res.Unread != 1 {
+			t.Errorf("expected one unread notification, got %d %s", code, body)
+		}
+	})
+
+	t.Run("Someone Else's Notification", func(t *testing.T) {
+		if code, _ := sendAs(app, "PUT", "/me/notifications/"+id+"/read", "budi", ""); code != 404 {
+			t.Errorf("expected 404 when marking someone else's notification, got %d", code)
+		}
+	})
+
+	t.Run("Own Notification", func(t *testing.T) {
+		if code, _ := sendAs(app, "PUT", "/me/notifications/"+id+"/read", "", ""); code != 200 {
+			t.Errorf("expected 200, got %d", code)
+		}
+		if n, _ := notificationRepo.CountByUser(context.Background(), farid, true); n != 0 {
+			t.Errorf("expected no unread notifications, got %d", n)
+		}
+	})
+}
+
+func TestDeliverEmails(t *testing.T) {
+	queueRepo := repository.NewMockEmailQueueRepository()
+	sender := &flakySender{}
+	service := NewNotificationService(repository.NewMockNotificationTemplateRepository(), repository.NewMockNotificationRepository(), queueRepo, repository.NewMockAlumniRepository(), sender, testNotificationCfg)
+
+	t.Run("Retry Then Sent", func(t *testing.T) {
+		queueRepo.EnqueueMany(context.Background(), []model.EmailJob{
+			{To: "farid@example.com", Subject: "s", Body: "b"},
+			{To: "anon@example.com", Subject: "s", Body: "b"},
+		})
+		// percobaan pertama satu email gagal lalu dijadwalkan ulang dan terkirim pada putaran yang sama
+		sender.failures = 1
+		sent, failed, err := service.DeliverEmails(context.Background())
+		if err != nil || sent != 2 || failed != 0 {
+			t.Fatalf("expected 2 sent, got sent=%d failed=%d err=%v", sent, failed, err)
+		}
+		for _, j := range queueRepo.Data {
+			if j.Status != model.EmailSent {
+				t.Errorf("expected all emails sent, got %+v", j)
+			}
+		}
+	})
+
+	t.Run("Failed After Max Attempts", func(t *testing.T) {
+		queueRepo.EnqueueMany(context.Background(), []model.EmailJob{{To: "x@example.com", Subject: "s", Body: "b"}})
+		sender.failures = testNotificationCfg.MaxAttempts
+		if _, failed, _ := service.DeliverEmails(context.Background()); failed != 1 {
+			t.Errorf("expected email marked failed after %d attempts, got failed=%d", testNotificationCfg.MaxAttempts, failed)
+		}
+	})
+}
@@ -0,0 +1,188 @@
+package service
+
+import (
+	"alumni-app/app/mongodb/model"
+	"alumni-app/app/mongodb/repository"
+	"alumni-app/config"
+	"alumni-app/utils/mongodb"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type PasswordService struct {
+	userRepo  repository.UserRepositoryInterface
+	resetRepo repository.PasswordResetRepositoryInterface
+	notifier  utils.Notifier
+	cfg       config.PasswordConfig
+}
+
+func NewPasswordService(
+	userRepo repository.UserRepositoryInterface,
+	resetRepo repository.PasswordResetRepositoryInterface,
+	notifier utils.Notifier,
+	cfg config.PasswordConfig,
+) *PasswordService {
+	return &PasswordService{userRepo: userRepo, resetRepo: resetRepo, notifier: notifier, cfg: cfg}
+}
+
+// ChangePassword godoc
+// @Summary Ganti password
+// @Description Mengganti password user yang sedang login, wajib menyertakan password lama
+// @Tags Users
+// @Accept json
+// @Produce json
+// @Param body body model.ChangePasswordRequest true "Password lama dan baru"
+// @Success 200 {object} fiber.Map
+// @Failure 400 {object} fiber.Map
+// @Failure 401 {object} fiber.Map
+// @Failure 500 {object} fiber.Map
+// @Security BearerAuth
+// @Router /me/password [put]
+func (s *PasswordService) ChangePassword(c *fiber.Ctx) error {
+	userID, _ := c.Locals("user_id").(primitive.ObjectID)
+
+	var req model.ChangePasswordRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": "Request tidak valid"})
+	}
+	if req.OldPassword == "" || req.NewPassword == "" {
+		return c.Status(400).JSON(fiber.Map{"error": "Password lama dan password baru wajib diisi"})
+	}
+
+	user, err := s.userRepo.GetByID(c.UserContext(), userID)
+	if err != nil {
+		return c.Status(401).JSON(fiber.Map{"error": "User tidak ditemukan"})
+	}
+	if !utils.CheckPassword(user.PasswordHash, req.OldPassword) {
+		return c.Status(400).JSON(fiber.Map{"error": "Password lama salah"})
+	}
+	if req.OldPassword == req.NewPassword {
+		return c.Status(400).JSON(fiber.Map{"error": "Password baru harus berbeda dari password lama"})
+	}
+	if err := utils.ValidatePassword(req.NewPassword, s.cfg); err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	hashed, err := utils.HashPassword(req.NewPassword)
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": "Gagal enkripsi password"})
+	}
+	if err := s.userRepo.UpdatePassword(c.UserContext(), user.ID, hashed); err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	return c.JSON(fiber.Map{"success": true, "message": "Password berhasil diganti"})
+}
+
+// ForgotPassword godoc
+// @Summary Minta token reset password
+// @Description Mengirim token reset password ke email user. Respon selalu sama walaupun email tidak terdaftar.
+// @Tags Users
+// @Accept json
+// @Produce json
+// @Param body body model.ForgotPasswordRequest true "Email akun"
+// @Success 200 {object} fiber.Map
+// @Failure 400 {object} fiber.Map
+// @Router /forgot-password [post]
+func (s *PasswordService) ForgotPassword(c *fiber.Ctx) error {
+	var req model.ForgotPasswordRequest
+	if err := c.BodyParser(&req); err != nil || req.Email == "" {
+		return c.Status(400).JSON(fiber.Map{"error": "Email wajib diisi"})
+	}
+
+	resp := fiber.Map{
+		"success": true,
+		"message": "Jika email terdaftar, token reset password telah dikirim",
+	}
+
+	user, err := s.userRepo.GetByEmail(c.UserContext(), req.Email)
+	if err != nil {
+		return c.JSON(resp)
+	}
+
+	plain, hash, err := utils.NewRandomToken()
+	if err != nil {
+		log.Println("⚠️ Gagal membuat token reset:", err)
+		return c.JSON(resp)
+	}
+
+	// token lama yang belum dipakai tidak berlaku lagi
+	if err := s.resetRepo.DeleteUnusedByUserID(c.UserContext(), user.ID); err != nil {
+		log.Println("⚠️ Gagal menghapus token reset lama:", err)
+	}
+
+	expiresAt := time.Now().Add(s.cfg.ResetTokenTTL)
+	if err := s.resetRepo.Create(c.UserContext(), &model.PasswordReset{
+		UserID:    user.ID,
+		TokenHash: hash,
+		ExpiresAt: expiresAt,
+	}); err != nil {
+		log.Println("⚠️ Gagal menyimpan token reset:", err)
+		return c.JSON(resp)
+	}
+
+	msg := utils.Message{
+		To:      user.Email,
+		Subject: "Reset password Alumni-App",
+		Body: fmt.Sprintf(
+			"Halo %s,\n\nGunakan token berikut untuk reset password melalui POST /api/v1/reset-password:\n\n%s\n\nToken berlaku sampai %s dan hanya bisa dipakai sekali.\nAbaikan pesan ini jika kamu tidak meminta reset password.",
+			user.Username, plain, expiresAt.Format("2006-01-02 15:04 MST"),
+		),
+	}
+	// dikirim di latar belakang agar waktu respon tidak membedakan email terdaftar dan tidak
+	go func() {
+		if err := s.notifier.Send(msg); err != nil {
+			log.Println("⚠️ Gagal mengirim token reset:", err)
+		}
+	}()
+
+	return c.JSON(resp)
+}
+
+// ResetPassword godoc
+// @Summary Reset password dengan token
+// @Description Mengganti password menggunakan token dari /forgot-password (sekali pakai, ada masa berlaku)
+// @Tags Users
+// @Accept json
+// @Produce json
+// @Param body body model.ResetPasswordRequest true "Token dan password baru"
+// @Success 200 {object} fiber.Map
+// @Failure 400 {object} fiber.Map
+// @Failure 500 {object} fiber.Map
+// @Router /reset-password [post]
+func (s *PasswordService) ResetPassword(c *fiber.Ctx) error {
+	var req model.ResetPasswordRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": "Request tidak valid"})
+	}
+	if req.Token == "" || req.NewPassword == "" {
+		return c.Status(400).JSON(fiber.Map{"error": "Token dan password baru wajib diisi"})
+	}
+
+	pr, err := s.resetRepo.GetByTokenHash(c.UserContext(), utils.HashToken(req.Token))
+	if err != nil || pr.UsedAt != nil || time.Now().After(pr.ExpiresAt) {
+		return c.Status(400).JSON(fiber.Map{"error": "Token reset tidak valid atau sudah kadaluarsa"})
+	}
+	if err := utils.ValidatePassword(req.NewPassword, s.cfg); err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	hashed, err := utils.HashPassword(req.NewPassword)
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": "Gagal enkripsi password"})
+	}
+
+	// tandai terpakai lebih dulu supaya token tidak bisa dipakai dua request sekaligus
+	if err := s.resetRepo.MarkUsed(c.UserContext(), pr.ID); err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": "Token reset tidak valid atau sudah kadaluarsa"})
+	}
+	if err := s.userRepo.UpdatePassword(c.UserContext(), pr.UserID, hashed); err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	return c.JSON(fiber.Map{"success": true, "message": "Password berhasil direset, silakan login kembali"})
+}
@@ -0,0 +1,161 @@
+package service
+
+import (
+	"alumni-app/app/mongodb/model"
+	"alumni-app/app/mongodb/repository/mock"
+	"alumni-app/utils/mongodb"
+	"context"
+	"net/http/httptest"
+	"regexp"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"golang.org/x/crypto/bcrypt"
+)
+
+// captureNotifier menyimpan pesan terakhir yang dikirim
+type captureNotifier struct {
+	mu   sync.Mutex
+	sent []utils.Message
+}
+
+func (n *captureNotifier) Send(msg utils.Message) error {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	n.sent = append(n.sent, msg)
+	return nil
+}
+
+// waitSent menunggu sampai minimal n pesan terkirim, untuk pengiriman yang berjalan di latar belakang
+func (n *captureNotifier) waitSent(count int) []utils.Message {
+	deadline := time.Now().Add(2 * time.Second)
+	for {
+		n.mu.Lock()
+		sent := append([]utils.Message(nil), n.sent...)
+		n.mu.Unlock()
+		if len(sent) >= count || time.Now().After(deadline) {
+			return sent
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
+
+func seedUser(repo *repository.MockUserRepository, password string) model.User {
+	hashed, _ := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+	user := model.User{
+		ID:           primitive.NewObjectID(),
+		Username:     "shendy",
+		Email:        "s@example.com",
+		PasswordHash: string(hashed),
+		Role:         "user",
+	}
+	repo.Create(context.Background(), &user)
+	return user
+}
+
+func TestChangePassword(t *testing.T) {
+	userRepo := repository.NewMockUserRepository()
+	service := NewPasswordService(userRepo, repository.NewMockPasswordResetRepository(), &captureNotifier{}, testPasswordCfg)
+	user := seedUser(userRepo, "lama12345")
+
+	app := fiber.New()
+	app.Put("/me/password", func(c *fiber.Ctx) error {
+		c.Locals("user_id", user.ID)
+		return service.ChangePassword(c)
+	})
+
+	tests := []struct {
+		name       string
+		body       string
+		wantStatus int
+	}{
+		{
+			name:       "Wrong Old Password",
+			body:       `{"old_password":"salah","new_password":"baru12345"}`,
+			wantStatus: 400,
+		},
+		{
+			name:       "Weak New Password",
+			body:       `{"old_password":"lama12345","new_password":"pendek"}`,
+			wantStatus: 400,
+		},
+		{
+			name:       "Valid Change",
+			body:       `{"old_password":"lama12345","new_password":"baru12345"}`,
+			wantStatus: 200,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest("PUT", "/me/password", strings.NewReader(tt.body))
+			req.Header.Set("Content-Type", "application/json")
+			resp, _ := app.Test(req, -1)
+
+			if resp.StatusCode != tt.wantStatus {
+				t.Errorf("got %d, want %d", resp.StatusCode, tt.wantStatus)
+			}
+		})
+	}
+
+	updated, _ := userRepo.GetByID(context.Background(), user.ID)
+	if !utils.CheckPassword(updated.PasswordHash, "baru12345") {
+		t.Error("expected password to be changed")
+	}
+}
+
+func TestForgotAndResetPassword(t *testing.T) {
+	userRepo := repository.NewMockUserRepository()
+	resetRepo := repository.NewMockPasswordResetRepository()
+	notifier := &captureNotifier{}
+	service := NewPasswordService(userRepo, resetRepo, notifier, testPasswordCfg)
+	user := seedUser(userRepo, "lama12345")
+
+	app := fiber.New()
+	app.Post("/forgot-password", service.ForgotPassword)
+	app.Post("/reset-password", service.ResetPassword)
+
+	post := func(path, body string) int {
+		req := httptest.NewRequest("POST", path, strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		resp, _ := app.Test(req, -1)
+		return resp.StatusCode
+	}
+
+	if got := post("/forgot-password", `{"email":"unknown@example.com"}`); got != 200 {
+		t.Errorf("unknown email: got %d, want 200", got)
+	}
+
+	if got := post("/forgot-password", `{"email":"s@example.com"}`); got != 200 {
+		t.Fatalf("got %d, want 200", got)
+	}
+	// pesan dikirim di latar belakang; email tidak terdaftar tidak boleh menambah pesan
+	sent := notifier.waitSent(1)
+	if len(sent) != 1 || sent[0].To != "s@example.com" {
+		t.Fatalf("expected 1 message to s@example.com, got %+v", sent)
+	}
+	token := regexp.MustCompile(`[0-9a-f]{64}`).FindString(sent[0].Body)
+
+	for _, r := range resetRepo.Data {
+		if r.TokenHash == token {
+			t.Error("token must be stored hashed")
+		}
+	}
+
+	body := `{"token":"` + token + `","new_password":"baru12345"}`
+	if got := post("/reset-password", body); got != 200 {
+		t.Errorf("valid reset: got %d, want 200", got)
+	}
+	if got := post("/reset-password", body); got != 400 {
+		t.Errorf("reused token: got %d, want 400", got)
+	}
+
+	updated, _ := userRepo.GetByID(context.Background(), user.ID)
+	if !utils.CheckPassword(updated.PasswordHash, "baru12345") {
+		t.Error("expected password to be reset")
+	}
+}
@@ -3,6 +3,8 @@ package service
 import (
 	"alumni-app/app/mongodb/model"
 	"alumni-app/app/mongodb/repository"
+	"alumni-app/utils/mongodb"
+	"errors"
 	"time"
 
 	"github.com/gofiber/fiber/v2"
@@ -43,11 +45,11 @@ func (s *PekerjaanService) GetAll(c *fiber.Ctx) error {
 	sortBy := c.Query("sortBy", "created_at")
 	order := c.Query("order", "asc")
 
-	data, err := s.repo.GetAll(search, sortBy, order, limit, (page-1)*limit)
+	data, err := s.repo.GetAll(c.UserContext(), search, sortBy, order, limit, (page-1)*limit)
 	if err != nil {
 		return c.Status(500).JSON(fiber.Map{"error": err.Error()})
 	}
-	total, _ := s.repo.Count(search)
+	total, _ := s.repo.Count(c.UserContext(), search)
 
 	return c.JSON(model.PekerjaanResponse{
 		Data: data,
@@ -70,7 +72,9 @@ func (s *PekerjaanService) GetAll(c *fiber.Ctx) error {
 // @Accept json
 // @Produce json
 // @Param id path string true "ID Pekerjaan"
+// @Param If-None-Match header string false "ETag yang sudah dimiliki klien"
 // @Success 200 {object} model.PekerjaanAlumni
+// @Success 304 "Data tidak berubah"
 // @Failure 400 {object} fiber.Map
 // @Failure 404 {object} fiber.Map
 // @Security BearerAuth
@@ -82,14 +86,47 @@ func (s *PekerjaanService) GetByID(c *fiber.Ctx) error {
 		return c.Status(400).JSON(fiber.Map{"error": "ID tidak valid"})
 	}
 
-	data, err := s.repo.GetByID(id)
+	data, err := s.repo.GetByID(c.UserContext(), id)
 	if err != nil {
 		return c.Status(404).JSON(fiber.Map{"error": "Data pekerjaan tidak ditemukan"})
 	}
 
+	etag := utils.ETag(data.ID, data.Version)
+	c.Set(fiber.HeaderETag, etag)
+	if match := c.Get(fiber.HeaderIfNoneMatch); match != "" && utils.MatchWeakETag(match, etag) {
+		return c.SendStatus(fiber.StatusNotModified)
+	}
+
 	return c.JSON(fiber.Map{"success": true, "data": data})
 }
 
+// GetMine godoc
+// @Summary Daftar pekerjaan milik user login
+// @Description Mengambil semua pekerjaan dari alumni yang terhubung dengan user di token JWT
+// @Tags Me
+// @Accept json
+// @Produce json
+// @Success 200 {object} fiber.Map
+// @Failure 404 {object} fiber.Map
+// @Failure 500 {object} fiber.Map
+// @Security BearerAuth
+// @Router /me/pekerjaan [get]
+func (s *PekerjaanService) GetMine(c *fiber.Ctx) error {
+	userID, _ := c.Locals("user_id").(primitive.ObjectID)
+
+	alumni, err := s.alumniRepo.GetByUserID(c.UserContext(), userID)
+	if err != nil {
+		return c.Status(404).JSON(fiber.Map{"error": "Data alumni untuk user ini tidak ditemukan"})
+	}
+
+	data, err := s.repo.GetByAlumniID(c.UserContext(), alumni.ID)
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	return c.JSON(fiber.Map{"success": true, "count": len(data), "data": data})
+}
+
 func (s *PekerjaanService) GetByAlumniID(c *fiber.Ctx) error {
 	alumniStr := c.Params("alumni_id")
 	alumniID, err := primitive.ObjectIDFromHex(alumniStr)
@@ -97,7 +134,7 @@ func (s *PekerjaanService) GetByAlumniID(c *fiber.Ctx) error {
 		return c.Status(400).JSON(fiber.Map{"error": "Alumni ID tidak valid"})
 	}
 
-	data, err := s.repo.GetByAlumniID(alumniID)
+	data, err := s.repo.GetByAlumniID(c.UserContext(), alumniID)
 	if err != nil {
 		return c.Status(500).JSON(fiber.Map{"error": err.Error()})
 	}
@@ -129,7 +166,7 @@ func (s *PekerjaanService) Create(c *fiber.Ctx) error {
 	userID, _ := c.Locals("user_id").(primitive.ObjectID)
 
 	if role != "admin" {
-		alumni, err := s.alumniRepo.GetByID(req.AlumniID)
+		alumni, err := s.alumniRepo.GetByID(c.UserContext(), req.AlumniID)
 		if err != nil {
 			return c.Status(404).JSON(fiber.Map{"error": "Alumni tidak ditemukan"})
 		}
@@ -169,7 +206,7 @@ func (s *PekerjaanService) Create(c *fiber.Ctx) error {
 		DeskripsiPekerjaan:  req.DeskripsiPekerjaan,
 	}
 
-	if err := s.repo.Create(&p); err != nil {
+	if err := s.repo.Create(c.UserContext(), &p); err != nil {
 		return c.Status(500).JSON(fiber.Map{"error": "Gagal menambah pekerjaan: " + err.Error()})
 	}
 
@@ -183,11 +220,14 @@ func (s *PekerjaanService) Create(c *fiber.Ctx) error {
 // @Accept json
 // @Produce json
 // @Param id path string true "ID Pekerjaan"
+// @Param If-Match header string false "ETag dari GET /pekerjaan/{id}"
 // @Param body body model.UpdatePekerjaanRequest true "Data pekerjaan baru"
 // @Success 200 {object} fiber.Map
 // @Failure 400 {object} fiber.Map
 // @Failure 403 {object} fiber.Map
 // @Failure 404 {object} fiber.Map
+// @Failure 409 {object} fiber.Map
+// @Failure 412 {object} fiber.Map
 // @Failure 500 {object} fiber.Map
 // @Security BearerAuth
 // @Router /pekerjaan/{id} [put]
@@ -201,13 +241,13 @@ func (s *PekerjaanService) Update(c *fiber.Ctx) error {
 	role, _ := c.Locals("role").(string)
 	userID, _ := c.Locals("user_id").(primitive.ObjectID)
 
-	existing, err := s.repo.GetByID(id)
+	existing, err := s.repo.GetByID(c.UserContext(), id)
 	if err != nil {
 		return c.Status(404).JSON(fiber.Map{"error": "Data pekerjaan tidak ditemukan"})
 	}
 
 	if role != "admin" {
-		alumni, err := s.alumniRepo.GetByID(existing.AlumniID)
+		alumni, err := s.alumniRepo.GetByID(c.UserContext(), existing.AlumniID)
 		if err != nil {
 			return c.Status(404).JSON(fiber.Map{"error": "Alumni tidak ditemukan"})
 		}
@@ -235,23 +275,162 @@ func (s *PekerjaanService) Update(c *fiber.Ctx) error {
 		selesai = &t
 	}
 
-	p := model.PekerjaanAlumni{
-		NamaPerusahaan:      req.NamaPerusahaan,
-		PosisiJabatan:       req.PosisiJabatan,
-		BidangIndustri:      req.BidangIndustri,
-		LokasiKerja:         req.LokasiKerja,
-		GajiRange:           req.GajiRange,
-		TanggalMulaiKerja:   mulai,
-		TanggalSelesaiKerja: selesai,
-		StatusPekerjaan:     req.StatusPekerjaan,
-		DeskripsiPekerjaan:  req.DeskripsiPekerjaan,
+	p := existing
+	p.NamaPerusahaan = req.NamaPerusahaan
+	p.PosisiJabatan = req.PosisiJabatan
+	p.BidangIndustri = req.BidangIndustri
+	p.LokasiKerja = req.LokasiKerja
+	p.GajiRange = req.GajiRange
+	p.TanggalMulaiKerja = mulai
+	p.TanggalSelesaiKerja = selesai
+	p.StatusPekerjaan = req.StatusPekerjaan
+	p.DeskripsiPekerjaan = req.DeskripsiPekerjaan
+	p.Version = req.Version
+
+	if match := c.Get(fiber.HeaderIfMatch); match != "" {
+		if !utils.MatchStrongETag(match, utils.ETag(existing.ID, existing.Version)) {
+			return c.Status(412).JSON(fiber.Map{"error": "Data pekerjaan sudah berubah, muat ulang data terlebih dahulu"})
+		}
+		p.Version = existing.Version
 	}
 
-	if err := s.repo.Update(id, &p); err != nil {
+	if err := s.repo.Update(c.UserContext(), id, &p); err != nil {
+		if errors.Is(err, repository.ErrVersionConflict) {
+			return c.Status(409).JSON(fiber.Map{
+				"error":           "Data pekerjaan sudah diubah oleh pengguna lain, muat ulang data terlebih dahulu",
+				"current_version": existing.Version,
+			})
+		}
 		return c.Status(500).JSON(fiber.Map{"error": err.Error()})
 	}
 
-	return c.JSON(fiber.Map{"success": true, "message": "Pekerjaan berhasil diperbarui"})
+	c.Set(fiber.HeaderETag, utils.ETag(p.ID, p.Version))
+	return c.JSON(fiber.Map{"success": true, "message": "Pekerjaan berhasil diperbarui", "data": p})
+}
+
+// GetHistory godoc
+// @Summary Riwayat perubahan pekerjaan
+// @Description Mengambil snapshot versi-versi sebelumnya dari data pekerjaan (terbaru lebih dulu)
+// @Tags Pekerjaan
+// @Accept json
+// @Produce json
+// @Param id path string true "ID Pekerjaan"
+// @Success 200 {object} fiber.Map
+// @Failure 400 {object} fiber.Map
+// @Failure 403 {object} fiber.Map
+// @Failure 404 {object} fiber.Map
+// @Failure 500 {object} fiber.Map
+// @Security BearerAuth
+// @Router /pekerjaan/{id}/history [get]
+func (s *PekerjaanService) GetHistory(c *fiber.Ctx) error {
+	idStr := c.Params("id")
+	id, err := primitive.ObjectIDFromHex(idStr)
+	if err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": "ID tidak valid"})
+	}
+
+	role, _ := c.Locals("role").(string)
+	userID, _ := c.Locals("user_id").(primitive.ObjectID)
+
+	existing, err := s.repo.GetByID(c.UserContext(), id)
+	if err != nil {
+		return c.Status(404).JSON(fiber.Map{"error": "Data pekerjaan tidak ditemukan"})
+	}
+
+	if role != "admin" {
+		alumni, err := s.alumniRepo.GetByID(c.UserContext(), existing.AlumniID)
+		if err != nil {
+			return c.Status(404).JSON(fiber.Map{"error": "Alumni tidak ditemukan"})
+		}
+		if alumni.UserID != userID {
+			return c.Status(403).JSON(fiber.Map{"error": "Tidak diizinkan"})
+		}
+	}
+
+	data, err := s.repo.GetHistory(c.UserContext(), id)
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	return c.JSON(fiber.Map{
+		"success":         true,
+		"current_version": existing.Version,
+		"count":           len(data),
+		"data":            data,
+	})
+}
+
+// Revert godoc
+// @Summary Kembalikan pekerjaan ke versi sebelumnya
+// @Description Menerapkan ulang snapshot versi tertentu sebagai versi baru (riwayat tetap tersimpan)
+// @Tags Pekerjaan
+// @Accept json
+// @Produce json
+// @Param id path string true "ID Pekerjaan"
+// @Param version path int true "Versi tujuan"
+// @Success 200 {object} fiber.Map
+// @Failure 400 {object} fiber.Map
+// @Failure 403 {object} fiber.Map
+// @Failure 404 {object} fiber.Map
+// @Failure 409 {object} fiber.Map
+// @Failure 500 {object} fiber.Map
+// @Security BearerAuth
+// @Router /pekerjaan/{id}/revert/{version} [post]
+func (s *PekerjaanService) Revert(c *fiber.Ctx) error {
+	idStr := c.Params("id")
+	id, err := primitive.ObjectIDFromHex(idStr)
+	if err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": "ID tidak valid"})
+	}
+
+	version, err := c.ParamsInt("version")
+	if err != nil || version < 0 {
+		return c.Status(400).JSON(fiber.Map{"error": "Versi tidak valid"})
+	}
+
+	role, _ := c.Locals("role").(string)
+	userID, _ := c.Locals("user_id").(primitive.ObjectID)
+
+	existing, err := s.repo.GetByID(c.UserContext(), id)
+	if err != nil {
+		return c.Status(404).JSON(fiber.Map{"error": "Data pekerjaan tidak ditemukan"})
+	}
+
+	if role != "admin" {
+		alumni, err := s.alumniRepo.GetByID(c.UserContext(), existing.AlumniID)
+		if err != nil {
+			return c.Status(404).JSON(fiber.Map{"error": "Alumni tidak ditemukan"})
+		}
+		if alumni.UserID != userID {
+			return c.Status(403).JSON(fiber.Map{"error": "Tidak diizinkan"})
+		}
+	}
+
+	if version == existing.Version {
+		return c.Status(400).JSON(fiber.Map{"error": "Data sudah berada pada versi tersebut"})
+	}
+
+	h, err := s.repo.GetHistoryVersion(c.UserContext(), id, version)
+	if err != nil {
+		return c.Status(404).JSON(fiber.Map{"error": "Versi pekerjaan tidak ditemukan"})
+	}
+
+	// identitas, pemilik dan status trash tetap mengikuti data saat ini
+	p := h.Snapshot
+	p.ID = existing.ID
+	p.AlumniID = existing.AlumniID
+	p.CreatedAt = existing.CreatedAt
+	p.DeletedAt = existing.DeletedAt
+	p.Version = existing.Version
+
+	if err := s.repo.Update(c.UserContext(), id, &p); err != nil {
+		if errors.Is(err, repository.ErrVersionConflict) {
+			return c.Status(409).JSON(fiber.Map{"error": "Data pekerjaan sudah diubah oleh pengguna lain, coba lagi"})
+		}
+		return c.Status(500).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	return c.JSON(fiber.Map{"success": true, "message": "Pekerjaan berhasil dikembalikan ke versi sebelumnya", "data": p})
 }
 
 // Delete godoc
@@ -261,10 +440,12 @@ func (s *PekerjaanService) Update(c *fiber.Ctx) error {
 // @Accept json
 // @Produce json
 // @Param id path string true "ID Pekerjaan"
+// @Param If-Match header string false "ETag dari GET /pekerjaan/{id}"
 // @Success 200 {object} fiber.Map
 // @Failure 400 {object} fiber.Map
 // @Failure 403 {object} fiber.Map
 // @Failure 404 {object} fiber.Map
+// @Failure 412 {object} fiber.Map
 // @Failure 500 {object} fiber.Map
 // @Security BearerAuth
 // @Router /pekerjaan/{id} [delete]
@@ -278,13 +459,13 @@ func (s *PekerjaanService) Delete(c *fiber.Ctx) error {
 	role, _ := c.Locals("role").(string)
 	userID, _ := c.Locals("user_id").(primitive.ObjectID)
 
-	existing, err := s.repo.GetByID(id)
+	existing, err := s.repo.GetByID(c.UserContext(), id)
 	if err != nil {
 		return c.Status(404).JSON(fiber.Map{"error": "Data tidak ditemukan"})
 	}
 
 	if role != "admin" {
-		alumni, err := s.alumniRepo.GetByID(existing.AlumniID)
+		alumni, err := s.alumniRepo.GetByID(c.UserContext(), existing.AlumniID)
 		if err != nil {
 			return c.Status(500).JSON(fiber.Map{"error": "Validasi gagal"})
 		}
@@ -293,7 +474,11 @@ func (s *PekerjaanService) Delete(c *fiber.Ctx) error {
 		}
 	}
 
-	if err := s.repo.SoftDelete(id); err != nil {
+	if match := c.Get(fiber.HeaderIfMatch); match != "" && !utils.MatchStrongETag(match, utils.ETag(existing.ID, existing.Version)) {
+		return c.Status(412).JSON(fiber.Map{"error": "Data pekerjaan sudah berubah, muat ulang data terlebih dahulu"})
+	}
+
+	if err := s.repo.SoftDelete(c.UserContext(), id); err != nil {
 		return c.Status(500).JSON(fiber.Map{"error": err.Error()})
 	}
 	return c.JSON(fiber.Map{"success": true, "message": "Pekerjaan berhasil dihapus (soft delete)"})
@@ -309,13 +494,13 @@ func (s *PekerjaanService) Restore(c *fiber.Ctx) error {
 	role, _ := c.Locals("role").(string)
 	userID, _ := c.Locals("user_id").(primitive.ObjectID)
 
-	existing, err := s.repo.GetByID(id)
+	existing, err := s.repo.GetByID(c.UserContext(), id)
 	if err != nil {
 		return c.Status(404).JSON(fiber.Map{"error": "Data pekerjaan tidak ditemukan"})
 	}
 
 	if role != "admin" {
-		alumni, err := s.alumniRepo.GetByID(existing.AlumniID)
+		alumni, err := s.alumniRepo.GetByID(c.UserContext(), existing.AlumniID)
 		if err != nil {
 			return c.Status(404).JSON(fiber.Map{"error": "Alumni tidak ditemukan"})
 		}
@@ -324,7 +509,7 @@ func (s *PekerjaanService) Restore(c *fiber.Ctx) error {
 		}
 	}
 
-	if err := s.repo.Restore(id); err != nil {
+	if err := s.repo.Restore(c.UserContext(), id); err != nil {
 		return c.Status(500).JSON(fiber.Map{"error": err.Error()})
 	}
 	return c.JSON(fiber.Map{"success": true, "message": "Pekerjaan berhasil direstore"})
@@ -340,13 +525,13 @@ func (s *PekerjaanService) HardDelete(c *fiber.Ctx) error {
 	role, _ := c.Locals("role").(string)
 	userID, _ := c.Locals("user_id").(primitive.ObjectID)
 
-	existing, err := s.repo.GetByID(id)
+	existing, err := s.repo.GetByID(c.UserContext(), id)
 	if err != nil {
 		return c.Status(404).JSON(fiber.Map{"error": "Data pekerjaan tidak ditemukan"})
 	}
 
 	if role != "admin" {
-		alumni, err := s.alumniRepo.GetByID(existing.AlumniID)
+		alumni, err := s.alumniRepo.GetByID(c.UserContext(), existing.AlumniID)
 		if err != nil {
 			return c.Status(404).JSON(fiber.Map{"error": "Alumni tidak ditemukan"})
 		}
@@ -355,7 +540,11 @@ func (s *PekerjaanService) HardDelete(c *fiber.Ctx) error {
 		}
 	}
 
-	if err := s.repo.HardDelete(id); err != nil {
+	if match := c.Get(fiber.HeaderIfMatch); match != "" && !utils.MatchStrongETag(match, utils.ETag(existing.ID, existing.Version)) {
+		return c.Status(412).JSON(fiber.Map{"error": "Data pekerjaan sudah berubah, muat ulang data terlebih dahulu"})
+	}
+
+	if err := s.repo.HardDelete(c.UserContext(), id); err != nil {
 		return c.Status(500).JSON(fiber.Map{"error": err.Error()})
 	}
 	return c.JSON(fiber.Map{"success": true, "message": "Pekerjaan berhasil dihapus permanen"})
@@ -365,7 +554,7 @@ func (s *PekerjaanService) GetTrashed(c *fiber.Ctx) error {
 	role, _ := c.Locals("role").(string)
 
 	if role == "admin" {
-		data, err := s.repo.GetTrashed()
+		data, err := s.repo.GetTrashed(c.UserContext())
 		if err != nil {
 			return c.Status(500).JSON(fiber.Map{"error": err.Error()})
 		}
@@ -379,7 +568,7 @@ func (s *PekerjaanService) GetTrashed(c *fiber.Ctx) error {
 	userID, _ := c.Locals("user_id").(primitive.ObjectID)
 
 	// Ambil semua alumni milik user ini
-	alumnis, err := s.alumniRepo.GetAllByUserID(userID)
+	alumnis, err := s.alumniRepo.GetAllByUserID(c.UserContext(), userID)
 	if err != nil || len(alumnis) == 0 {
 		return c.Status(404).JSON(fiber.Map{"error": "Alumni untuk user ini tidak ditemukan"})
 	}
@@ -391,7 +580,7 @@ func (s *PekerjaanService) GetTrashed(c *fiber.Ctx) error {
 	}
 
 	// Ambil semua pekerjaan yang dihapus dari alumni tersebut
-	data, err := s.repo.GetTrashedByAlumniIDs(alumniIDs)
+	data, err := s.repo.GetTrashedByAlumniIDs(c.UserContext(), alumniIDs)
 	if err != nil {
 		return c.Status(500).JSON(fiber.Map{"error": err.Error()})
 	}
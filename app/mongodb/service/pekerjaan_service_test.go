@@ -3,7 +3,9 @@ package service
 import (
 	"alumni-app/app/mongodb/model"
 	"alumni-app/app/mongodb/repository/mock"
+	"context"
 	"net/http/httptest"
+	"strings"
 	"testing"
 
 	"github.com/gofiber/fiber/v2"
@@ -122,3 +124,115 @@ func TestPekerjaanGetAll(t *testing.T) {
 		t.Errorf("expected 200, got %d", resp.StatusCode)
 	}
 }
+
+func TestPekerjaanUpdateVersionConflict(t *testing.T) {
+	mockRepo := repository.NewMockPekerjaanRepository()
+	alumniRepo := repository.NewMockAlumniRepository()
+	service := NewPekerjaanService(mockRepo, alumniRepo)
+
+	app := fiber.New()
+	app.Put("/pekerjaan/:id", func(c *fiber.Ctx) error {
+		c.Locals("role", "admin")
+		c.Locals("user_id", primitive.NewObjectID())
+		return service.Update(c)
+	})
+
+	// seed
+	id := primitive.NewObjectID()
+	mockRepo.Data[id.Hex()] = model.PekerjaanAlumni{
+		ID:             id,
+		AlumniID:       primitive.NewObjectID(),
+		NamaPerusahaan: "PT Lama",
+		Version:        1,
+	}
+
+	tests := []struct {
+		name       string
+		body       string
+		wantStatus int
+	}{
+		{
+			name:       "Stale Version",
+			body:       `{"nama_perusahaan":"PT A","posisi_jabatan":"Dev","tanggal_mulai_kerja":"2024-01-01","version":0}`,
+			wantStatus: 409,
+		},
+		{
+			name:       "Current Version",
+			body:       `{"nama_perusahaan":"PT B","posisi_jabatan":"Dev","tanggal_mulai_kerja":"2024-01-01","version":1}`,
+			wantStatus: 200,
+		},
+		{
+			name:       "Replayed Version",
+			body:       `{"nama_perusahaan":"PT C","posisi_jabatan":"Dev","tanggal_mulai_kerja":"2024-01-01","version":1}`,
+			wantStatus: 409,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest("PUT", "/pekerjaan/"+id.Hex(), strings.NewReader(tt.body))
+			req.Header.Set("Content-Type", "application/json")
+			resp, _ := app.Test(req)
+
+			if resp.StatusCode != tt.wantStatus {
+				t.Errorf("expected %d, got %d", tt.wantStatus, resp.StatusCode)
+			}
+		})
+	}
+
+	if got := mockRepo.Data[id.Hex()]; got.NamaPerusahaan != "PT B" || got.Version != 2 {
+		t.Errorf("expected PT B at version 2, got %s at version %d", got.NamaPerusahaan, got.Version)
+	}
+}
+
+func TestPekerjaanRevert(t *testing.T) {
+	mockRepo := repository.NewMockPekerjaanRepository()
+	alumniRepo := repository.NewMockAlumniRepository()
+	service := NewPekerjaanService(mockRepo, alumniRepo)
+
+	app := fiber.New()
+	app.Use(func(c *fiber.Ctx) error {
+		c.Locals("role", "admin")
+		c.Locals("user_id", primitive.NewObjectID())
+		return c.Next()
+	})
+	app.Get("/pekerjaan/:id/history", service.GetHistory)
+	app.Post("/pekerjaan/:id/revert/:version", service.Revert)
+
+	// seed: versi 0 lalu diupdate menjadi versi 1
+	id := primitive.NewObjectID()
+	mockRepo.Data[id.Hex()] = model.PekerjaanAlumni{ID: id, NamaPerusahaan: "PT Awal"}
+	updated := mockRepo.Data[id.Hex()]
+	updated.NamaPerusahaan = "PT Salah"
+	mockRepo.Update(context.Background(), id, &updated)
+
+	t.Run("History", func(t *testing.T) {
+		req := httptest.NewRequest("GET", "/pekerjaan/"+id.Hex()+"/history", nil)
+		resp, _ := app.Test(req)
+
+		if resp.StatusCode != 200 {
+			t.Errorf("expected 200, got %d", resp.StatusCode)
+		}
+	})
+
+	t.Run("Unknown Version", func(t *testing.T) {
+		req := httptest.NewRequest("POST", "/pekerjaan/"+id.Hex()+"/revert/7", nil)
+		resp, _ := app.Test(req)
+
+		if resp.StatusCode != 404 {
+			t.Errorf("expected 404, got %d", resp.StatusCode)
+		}
+	})
+
+	t.Run("Valid Revert", func(t *testing.T) {
+		req := httptest.NewRequest("POST", "/pekerjaan/"+id.Hex()+"/revert/0", nil)
+		resp, _ := app.Test(req)
+
+		if resp.StatusCode != 200 {
+			t.Errorf("expected 200, got %d", resp.StatusCode)
+		}
+		if got := mockRepo.Data[id.Hex()]; got.NamaPerusahaan != "PT Awal" || got.Version != 2 {
+			t.Errorf("expected PT Awal at version 2, got %s at version %d", got.NamaPerusahaan, got.Version)
+		}
+	})
+}
@@ -0,0 +1,203 @@
+package service
+
+import (
+	"alumni-app/app/mongodb/model"
+	"bytes"
+	"encoding/csv"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// GetResults godoc
+// @Summary Rekap jawaban kuesioner tracer study (admin)
+// @Description Jumlah responden, tingkat respon terhadap alumni target, jumlah per opsi untuk pertanyaan pilihan,
+// @Description dan min/max/rata-rata untuk pertanyaan angka. Bisa difilter per jurusan dan tahun lulus.
+// @Tags Surveys
+// @Produce json
+// @Param id path string true "ID kuesioner"
+// @Param jurusan query string false "Filter jurusan"
+// @Param tahun_lulus query int false "Filter tahun lulus"
+// @Success 200 {object} model.SurveyResults
+// @Failure 400 {object} fiber.Map
+// @Failure 403 {object} fiber.Map
+// @Failure 404 {object} fiber.Map
+// @Failure 500 {object} fiber.Map
+// @Security BearerAuth
+// @Router /surveys/{id}/results [get]
+func (s *SurveyService) GetResults(c *fiber.Ctx) error {
+	survey, status, err := s.loadSurvey(c)
+	if err != nil {
+		return c.Status(status).JSON(fiber.Map{"error": err.Error()})
+	}
+	cohort := model.AlumniFilter{Jurusan: c.Query("jurusan"), TahunLulus: c.QueryInt("tahun_lulus")}
+
+	responses, err := s.responseRepo.FindBySurvey(c.UserContext(), survey.ID, cohort)
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": err.Error()})
+	}
+	targeted, err := s.targetedAlumni(c.UserContext(), survey.Target, cohort)
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	results := aggregateResponses(survey, responses)
+	results.Targeted = len(targeted)
+	if results.Targeted > 0 {
+		results.ResponseRate = math.Round(float64(results.Responses)/float64(results.Targeted)*10000) / 10000
+	}
+	return c.JSON(fiber.Map{"success": true, "data": results})
+}
+
+// Export godoc
+// @Summary Export jawaban kuesioner tracer study ke CSV (admin)
+// @Description Satu baris per responden: nim, nama, jurusan, tahun_lulus, submitted_at, lalu satu kolom per ID pertanyaan.
+// @Description Jawaban multiple_choice dipisah "; ".
+// @Tags Surveys
+// @Produce text/csv
+// @Param id path string true "ID kuesioner"
+// @Param jurusan query string false "Filter jurusan"
+// @Param tahun_lulus query int false "Filter tahun lulus"
+// @Success 200 {file} file
+// @Failure 400 {object} fiber.Map
+// @Failure 403 {object} fiber.Map
+// @Failure 404 {object} fiber.Map
+// @Failure 500 {object} fiber.Map
+// @Security BearerAuth
+// @Router /surveys/{id}/export [get]
+func (s *SurveyService) Export(c *fiber.Ctx) error {
+	survey, status, err := s.loadSurvey(c)
+	if err != nil {
+		return c.Status(status).JSON(fiber.Map{"error": err.Error()})
+	}
+	cohort := model.AlumniFilter{Jurusan: c.Query("jurusan"), TahunLulus: c.QueryInt("tahun_lulus")}
+
+	responses, err := s.responseRepo.FindBySurvey(c.UserContext(), survey.ID, cohort)
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	var buf bytes.Buffer
+	if err := writeResponsesCSV(&buf, survey, responses); err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": err.Error()})
+	}
+	c.Set(fiber.HeaderContentType, "text/csv; charset=utf-8")
+	c.Set(fiber.HeaderContentDisposition, fmt.Sprintf(`attachment; filename="tracer-study-%s.csv"`, survey.ID.Hex()))
+	return c.Send(buf.Bytes())
+}
+
+// aggregateResponses menghitung rekap per pertanyaan; Targeted dan ResponseRate diisi pemanggil
+func aggregateResponses(survey model.Survey, responses []model.SurveyResponse) model.SurveyResults {
+	results := model.SurveyResults{
+		SurveyID:  survey.ID,
+		Title:     survey.Title,
+		Responses: len(responses),
+		Questions: make([]model.QuestionResult, len(survey.Questions)),
+	}
+
+	index := make(map[string]int, len(survey.Questions))
+	optionIndex := make([]map[string]int, len(survey.Questions))
+	sums := make([]float64, len(survey.Questions))
+	for i, q := range survey.Questions {
+		index[q.ID] = i
+		results.Questions[i] = model.QuestionResult{ID: q.ID, Label: q.Label, Type: q.Type}
+		if len(q.Options) > 0 {
+			optionIndex[i] = make(map[string]int, len(q.Options))
+			for j, o := range q.Options {
+				optionIndex[i][o] = j
+				results.Questions[i].Options = append(results.Questions[i].Options, model.OptionCount{Option: o})
+			}
+		}
+	}
+
+	for _, r := range responses {
+		for _, a := range r.Answers {
+			i, ok := index[a.QuestionID]
+			if !ok {
+				continue
+			}
+			qr := &results.Questions[i]
+			qr.Answered++
+			for _, choice := range a.Choices {
+				if j, ok := optionIndex[i][choice]; ok {
+					qr.Options[j].Count++
+				}
+			}
+			if a.Number != nil {
+				n := *a.Number
+				sums[i] += n
+				if qr.Min == nil || n < *qr.Min {
+					qr.Min = &n
+				}
+				if qr.Max == nil || n > *qr.Max {
+					qr.Max = &n
+				}
+			}
+		}
+	}
+
+	for i := range results.Questions {
+		qr := &results.Questions[i]
+		if qr.Type == model.QuestionNumber && qr.Answered > 0 {
+			mean := sums[i] / float64(qr.Answered)
+			qr.Mean = &mean
+		}
+	}
+	return results
+}
+
+func writeResponsesCSV(buf *bytes.Buffer, survey model.Survey, responses []model.SurveyResponse) error {
+	w := csv.NewWriter(buf)
+
+	header := []string{"nim", "nama", "jurusan", "tahun_lulus", "submitted_at"}
+	for _, q := range survey.Questions {
+		header = append(header, q.ID)
+	}
+	if err := w.Write(header); err != nil {
+		return err
+	}
+
+	for _, r := range responses {
+		answers := make(map[string]model.SurveyAnswer, len(r.Answers))
+		for _, a := range r.Answers {
+			answers[a.QuestionID] = a
+		}
+
+		row := []string{
+			csvSafe(r.NIM),
+			csvSafe(r.Nama),
+			csvSafe(r.Jurusan),
+			strconv.Itoa(r.TahunLulus),
+			r.SubmittedAt.UTC().Format(time.RFC3339),
+		}
+		for _, q := range survey.Questions {
+			a := answers[q.ID]
+			switch {
+			case a.Number != nil:
+				row = append(row, strconv.FormatFloat(*a.Number, 'f', -1, 64))
+			case len(a.Choices) > 0:
+				row = append(row, csvSafe(strings.Join(a.Choices, "; ")))
+			default:
+				row = append(row, csvSafe(a.Text))
+			}
+		}
+		if err := w.Write(row); err != nil {
+			return err
+		}
+	}
+
+	w.Flush()
+	return w.Error()
+}
+
+// csvSafe mencegah isian teks alumni dibaca sebagai formula saat CSV dibuka di spreadsheet
+func csvSafe(v string) string {
+	if v != "" && strings.ContainsRune("=+-@\t\r", rune(v[0])) {
+		return "'" + v
+	}
+	return v
+}
@@ -0,0 +1,637 @@
+package service
+
+import (
+	"alumni-app/app/mongodb/model"
+	"alumni-app/app/mongodb/repository"
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"strings"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// maxSurveyTextLength membatasi panjang jawaban pertanyaan text
+const maxSurveyTextLength = 2000
+
+type SurveyService struct {
+	repo         repository.SurveyRepositoryInterface
+	responseRepo repository.SurveyResponseRepositoryInterface
+	alumniRepo   repository.AlumniRepositoryInterface
+	notifier     AlumniNotifier
+}
+
+func NewSurveyService(
+	repo repository.SurveyRepositoryInterface,
+	responseRepo repository.SurveyResponseRepositoryInterface,
+	alumniRepo repository.AlumniRepositoryInterface,
+	notifier AlumniNotifier,
+) *SurveyService {
+	return &SurveyService{
+		repo:         repo,
+		responseRepo: responseRepo,
+		alumniRepo:   alumniRepo,
+		notifier:     notifier,
+	}
+}
+
+// GetAll godoc
+// @Summary Daftar kuesioner tracer study (admin)
+// @Tags Surveys
+// @Produce json
+// @Param status query string false "draft, open, atau closed"
+// @Success 200 {array} model.Survey
+// @Failure 403 {object} fiber.Map
+// @Failure 500 {object} fiber.Map
+// @Security BearerAuth
+// @Router /surveys [get]
+func (s *SurveyService) GetAll(c *fiber.Ctx) error {
+	list, err := s.repo.FindAll(c.UserContext(), c.Query("status"))
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": err.Error()})
+	}
+	return c.JSON(fiber.Map{"success": true, "data": list})
+}
+
+// GetByID godoc
+// @Summary Detail kuesioner tracer study (admin)
+// @Tags Surveys
+// @Produce json
+// @Param id path string true "ID kuesioner"
+// @Success 200 {object} model.Survey
+// @Failure 400 {object} fiber.Map
+// @Failure 403 {object} fiber.Map
+// @Failure 404 {object} fiber.Map
+// @Security BearerAuth
+// @Router /surveys/{id} [get]
+func (s *SurveyService) GetByID(c *fiber.Ctx) error {
+	survey, status, err := s.loadSurvey(c)
+	if err != nil {
+		return c.Status(status).JSON(fiber.Map{"error": err.Error()})
+	}
+	return c.JSON(fiber.Map{"success": true, "data": survey})
+}
+
+// Create godoc
+// @Summary Buat kuesioner tracer study (admin)
+// @Description Kuesioner dibuat sebagai draft. Tipe pertanyaan: text, number, single_choice, multiple_choice.
+// @Description Target kosong berarti semua alumni.
+// @Tags Surveys
+// @Accept json
+// @Produce json
+// @Param body body model.SurveyRequest true "Judul, pertanyaan, dan target"
+// @Success 201 {object} model.Survey
+// @Failure 400 {object} fiber.Map
+// @Failure 403 {object} fiber.Map
+// @Failure 500 {object} fiber.Map
+// @Security BearerAuth
+// @Router /surveys [post]
+func (s *SurveyService) Create(c *fiber.Ctx) error {
+	var req model.SurveyRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": "Request tidak valid"})
+	}
+	survey, err := surveyFromRequest(req)
+	if err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": err.Error()})
+	}
+	survey.CreatedBy, _ = c.Locals("user_id").(primitive.ObjectID)
+
+	if err := s.repo.Create(c.UserContext(), &survey); err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": err.Error()})
+	}
+	return c.Status(201).JSON(fiber.Map{"success": true, "message": "Kuesioner berhasil dibuat", "data": survey})
+}
+
+// Update godoc
+// @Summary Perbarui kuesioner tracer study (admin)
+// @Description Hanya kuesioner draft yang bisa diubah agar rekap jawaban tetap konsisten
+// @Tags Surveys
+// @Accept json
+// @Produce json
+// @Param id path string true "ID kuesioner"
+// @Param body body model.SurveyRequest true "Judul, pertanyaan, dan target"
+// @Success 200 {object} model.Survey
+// @Failure 400 {object} fiber.Map
+// @Failure 403 {object} fiber.Map
+// @Failure 404 {object} fiber.Map
+// @Failure 409 {object} fiber.Map
+// @Failure 500 {object} fiber.Map
+// @Security BearerAuth
+// @Router /surveys/{id} [put]
+func (s *SurveyService) Update(c *fiber.Ctx) error {
+	existing, status, err := s.loadSurvey(c)
+	if err != nil {
+		return c.Status(status).JSON(fiber.Map{"error": err.Error()})
+	}
+	var req model.SurveyRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": "Request tidak valid"})
+	}
+	survey, err := surveyFromRequest(req)
+	if err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	if err := s.repo.Update(c.UserContext(), existing.ID, &survey); err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return c.Status(409).JSON(fiber.Map{"error": "Kuesioner yang sudah dibuka tidak bisa diubah"})
+		}
+		return c.Status(500).JSON(fiber.Map{"error": err.Error()})
+	}
+	survey.ID = existing.ID
+	survey.Status = existing.Status
+	survey.CreatedBy = existing.CreatedBy
+	survey.CreatedAt = existing.CreatedAt
+	return c.JSON(fiber.Map{"success": true, "message": "Kuesioner berhasil diperbarui", "data": survey})
+}
+
+// UpdateStatus godoc
+// @Summary Buka atau tutup kuesioner tracer study (admin)
+// @Description draft → open, open → closed, closed → open (dibuka kembali)
+// @Tags Surveys
+// @Accept json
+// @Produce json
+// @Param id path string true "ID kuesioner"
+// @Param body body model.SurveyStatusRequest true "Status baru"
+// @Success 200 {object} fiber.Map
+// @Failure 400 {object} fiber.Map
+// @Failure 403 {object} fiber.Map
+// @Failure 404 {object} fiber.Map
+// @Failure 409 {object} fiber.Map
+// @Failure 500 {object} fiber.Map
+// @Security BearerAuth
+// @Router /surveys/{id}/status [put]
+func (s *SurveyService) UpdateStatus(c *fiber.Ctx) error {
+	survey, status, err := s.loadSurvey(c)
+	if err != nil {
+		return c.Status(status).JSON(fiber.Map{"error": err.Error()})
+	}
+	var req model.SurveyStatusRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": "Request tidak valid"})
+	}
+	if req.Status != model.SurveyOpen && req.Status != model.SurveyClosed {
+		return c.Status(400).JSON(fiber.Map{"error": "Status harus open atau closed"})
+	}
+	if req.Status == survey.Status || (req.Status == model.SurveyClosed && survey.Status != model.SurveyOpen) {
+		return c.Status(409).JSON(fiber.Map{"error": fmt.Sprintf("Kuesioner berstatus %s tidak bisa diubah menjadi %s", survey.Status, req.Status)})
+	}
+
+	if err := s.repo.SetStatus(c.UserContext(), survey.ID, survey.Status, req.Status); err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return c.Status(409).JSON(fiber.Map{"error": "Status kuesioner sudah diubah, muat ulang lalu coba lagi"})
+		}
+		return c.Status(500).JSON(fiber.Map{"error": err.Error()})
+	}
+	return c.JSON(fiber.Map{"success": true, "message": "Status kuesioner menjadi " + req.Status})
+}
+
+// Delete godoc
+// @Summary Hapus kuesioner draft (admin)
+// @Description Kuesioner yang sudah pernah dibuka tidak bisa dihapus; tutup saja agar jawaban tetap tersimpan
+// @Tags Surveys
+// @Produce json
+// @Param id path string true "ID kuesioner"
+// @Success 200 {object} fiber.Map
+// @Failure 400 {object} fiber.Map
+// @Failure 403 {object} fiber.Map
+// @Failure 404 {object} fiber.Map
+// @Failure 409 {object} fiber.Map
+// @Failure 500 {object} fiber.Map
+// @Security BearerAuth
+// @Router /surveys/{id} [delete]
+func (s *SurveyService) Delete(c *fiber.Ctx) error {
+	survey, status, err := s.loadSurvey(c)
+	if err != nil {
+		return c.Status(status).JSON(fiber.Map{"error": err.Error()})
+	}
+	if err := s.repo.Delete(c.UserContext(), survey.ID); err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return c.Status(409).JSON(fiber.Map{"error": "Hanya kuesioner draft yang bisa dihapus"})
+		}
+		return c.Status(500).JSON(fiber.Map{"error": err.Error()})
+	}
+	return c.JSON(fiber.Map{"success": true, "message": "Kuesioner berhasil dihapus"})
+}
+
+// Remind godoc
+// @Summary Kirim pengingat tracer study (admin)
+// @Description Mengirim template survey_reminder ke alumni target yang belum mengisi kuesioner
+// @Tags Surveys
+// @Accept json
+// @Produce json
+// @Param id path string true "ID kuesioner"
+// @Param body body model.SurveyRemindRequest false "Link halaman pengisian"
+// @Success 202 {object} fiber.Map
+// @Failure 400 {object} fiber.Map
+// @Failure 403 {object} fiber.Map
+// @Failure 404 {object} fiber.Map
+// @Failure 409 {object} fiber.Map
+// @Failure 500 {object} fiber.Map
+// @Security BearerAuth
+// @Router /surveys/{id}/remind [post]
+func (s *SurveyService) Remind(c *fiber.Ctx) error {
+	survey, status, err := s.loadSurvey(c)
+	if err != nil {
+		return c.Status(status).JSON(fiber.Map{"error": err.Error()})
+	}
+	if survey.Status != model.SurveyOpen {
+		return c.Status(409).JSON(fiber.Map{"error": "Pengingat hanya bisa dikirim untuk kuesioner yang sedang dibuka"})
+	}
+	var req model.SurveyRemindRequest
+	if len(c.Body()) > 0 {
+		if err := c.BodyParser(&req); err != nil {
+			return c.Status(400).JSON(fiber.Map{"error": "Request tidak valid"})
+		}
+	}
+
+	targeted, err := s.targetedAlumni(c.UserContext(), survey.Target, model.AlumniFilter{})
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": err.Error()})
+	}
+	responses, err := s.responseRepo.FindBySurvey(c.UserContext(), survey.ID, model.AlumniFilter{})
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": err.Error()})
+	}
+	answered := make(map[primitive.ObjectID]bool, len(responses))
+	for _, r := range responses {
+		answered[r.AlumniID] = true
+	}
+	var pending []model.Alumni
+	for _, a := range targeted {
+		if !answered[a.ID] {
+			pending = append(pending, a)
+		}
+	}
+
+	if s.notifier != nil && len(pending) > 0 {
+		data := map[string]string{"link": req.Link, "survey": survey.Title}
+		if err := s.notifier.NotifyAlumni(c.UserContext(), pending, "survey_reminder", data); err != nil {
+			return c.Status(500).JSON(fiber.Map{"error": err.Error()})
+		}
+	}
+	return c.Status(202).JSON(fiber.Map{"success": true, "message": "Pengingat diproses", "recipients": len(pending)})
+}
+
+// GetMySurveys godoc
+// @Summary Kuesioner tracer study untuk alumni login
+// @Description Kuesioner yang sedang dibuka dan ditujukan ke angkatan/jurusan alumni login, beserta status pengisiannya
+// @Tags Me
+// @Produce json
+// @Success 200 {array} model.MySurvey
+// @Failure 404 {object} fiber.Map
+// @Failure 500 {object} fiber.Map
+// @Security BearerAuth
+// @Router /me/surveys [get]
+func (s *SurveyService) GetMySurveys(c *fiber.Ctx) error {
+	userID, _ := c.Locals("user_id").(primitive.ObjectID)
+	alumni, err := s.alumniRepo.GetByUserID(c.UserContext(), userID)
+	if err != nil {
+		return c.Status(404).JSON(fiber.Map{"error": "Data alumni untuk user ini tidak ditemukan"})
+	}
+
+	open, err := s.repo.FindAll(c.UserContext(), model.SurveyOpen)
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": err.Error()})
+	}
+	responses, err := s.responseRepo.FindByAlumni(c.UserContext(), alumni.ID)
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": err.Error()})
+	}
+	submitted := make(map[primitive.ObjectID]time.Time, len(responses))
+	for _, r := range responses {
+		submitted[r.SurveyID] = r.SubmittedAt
+	}
+
+	list := []model.MySurvey{}
+	for _, survey := range open {
+		if !survey.Target.Matches(alumni) {
+			continue
+		}
+		item := model.MySurvey{Survey: survey}
+		if at, ok := submitted[survey.ID]; ok {
+			item.Answered = true
+			item.SubmittedAt = &at
+		}
+		list = append(list, item)
+	}
+	return c.JSON(fiber.Map{"success": true, "data": list})
+}
+
+// GetMySurvey godoc
+// @Summary Detail kuesioner beserta jawaban alumni login
+// @Tags Me
+// @Produce json
+// @Param id path string true "ID kuesioner"
+// @Success 200 {object} fiber.Map
+// @Failure 400 {object} fiber.Map
+// @Failure 404 {object} fiber.Map
+// @Failure 500 {object} fiber.Map
+// @Security BearerAuth
+// @Router /me/surveys/{id} [get]
+func (s *SurveyService) GetMySurvey(c *fiber.Ctx) error {
+	alumni, survey, status, err := s.loadMySurvey(c)
+	if err != nil {
+		return c.Status(status).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	var answer *model.SurveyResponse
+	resp, err := s.responseRepo.GetByAlumni(c.UserContext(), survey.ID, alumni.ID)
+	switch {
+	case err == nil:
+		answer = &resp
+	case !errors.Is(err, mongo.ErrNoDocuments):
+		return c.Status(500).JSON(fiber.Map{"error": err.Error()})
+	}
+	return c.JSON(fiber.Map{"success": true, "data": survey, "response": answer})
+}
+
+// Submit godoc
+// @Summary Isi kuesioner tracer study
+// @Description Jawaban dikirim per ID pertanyaan. Selama kuesioner dibuka, submit ulang mengganti jawaban sebelumnya.
+// @Tags Me
+// @Accept json
+// @Produce json
+// @Param id path string true "ID kuesioner"
+// @Param body body model.SubmitSurveyRequest true "Jawaban"
+// @Success 200 {object} model.SurveyResponse
+// @Failure 400 {object} fiber.Map
+// @Failure 403 {object} fiber.Map
+// @Failure 404 {object} fiber.Map
+// @Failure 409 {object} fiber.Map
+// @Failure 500 {object} fiber.Map
+// @Security BearerAuth
+// @Router /me/surveys/{id}/responses [post]
+func (s *SurveyService) Submit(c *fiber.Ctx) error {
+	alumni, survey, status, err := s.loadMySurvey(c)
+	if err != nil {
+		return c.Status(status).JSON(fiber.Map{"error": err.Error()})
+	}
+	if survey.Status != model.SurveyOpen {
+		return c.Status(409).JSON(fiber.Map{"error": "Kuesioner sudah ditutup"})
+	}
+
+	var req model.SubmitSurveyRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": "Request tidak valid"})
+	}
+	answers, field, err := parseAnswers(survey.Questions, req.Answers)
+	if err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": err.Error(), "field": field})
+	}
+
+	resp := model.SurveyResponse{
+		SurveyID:   survey.ID,
+		AlumniID:   alumni.ID,
+		UserID:     alumni.UserID,
+		NIM:        alumni.NIM,
+		Nama:       alumni.Nama,
+		Jurusan:    alumni.Jurusan,
+		TahunLulus: alumni.TahunLulus,
+		Answers:    answers,
+	}
+	if err := s.responseRepo.Upsert(c.UserContext(), &resp); err != nil {
+		if errors.Is(err, repository.ErrConflict) {
+			return c.Status(409).JSON(fiber.Map{"error": "Jawaban sedang disimpan oleh request lain, coba lagi"})
+		}
+		return c.Status(500).JSON(fiber.Map{"error": err.Error()})
+	}
+	return c.JSON(fiber.Map{"success": true, "message": "Jawaban berhasil disimpan", "data": resp})
+}
+
+// loadSurvey mengambil kuesioner dari parameter :id; status adalah kode HTTP jika gagal
+func (s *SurveyService) loadSurvey(c *fiber.Ctx) (model.Survey, int, error) {
+	id, err := primitive.ObjectIDFromHex(c.Params("id"))
+	if err != nil {
+		return model.Survey{}, 400, errors.New("ID tidak valid")
+	}
+	survey, err := s.repo.GetByID(c.UserContext(), id)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return model.Survey{}, 404, errors.New("Kuesioner tidak ditemukan")
+	}
+	if err != nil {
+		return model.Survey{}, 500, err
+	}
+	return survey, 0, nil
+}
+
+// loadMySurvey mengambil data alumni user login dan kuesioner yang ditujukan kepadanya.
+// Draft dan kuesioner di luar target dijawab 404 agar tidak terlihat oleh alumni lain.
+func (s *SurveyService) loadMySurvey(c *fiber.Ctx) (model.Alumni, model.Survey, int, error) {
+	userID, _ := c.Locals("user_id").(primitive.ObjectID)
+	alumni, err := s.alumniRepo.GetByUserID(c.UserContext(), userID)
+	if err != nil {
+		return model.Alumni{}, model.Survey{}, 404, errors.New("Data alumni untuk user ini tidak ditemukan")
+	}
+	survey, status, err := s.loadSurvey(c)
+	if err != nil {
+		return model.Alumni{}, model.Survey{}, status, err
+	}
+	if survey.Status == model.SurveyDraft || !survey.Target.Matches(alumni) {
+		return model.Alumni{}, model.Survey{}, 404, errors.New("Kuesioner tidak ditemukan")
+	}
+	return alumni, survey, 0, nil
+}
+
+// targetedAlumni mengambil alumni yang termasuk target kuesioner dan cocok dengan cohort
+// (filter laporan). Semua target diambil dengan satu query; SurveyTarget.Matches menjadi
+// penentu akhir agar hasilnya sama dengan pengecekan target saat alumni mengisi kuesioner.
+func (s *SurveyService) targetedAlumni(ctx context.Context, target model.SurveyTarget, cohort model.AlumniFilter) ([]model.Alumni, error) {
+	found, err := s.alumniRepo.FindBySurveyTarget(ctx, target)
+	if err != nil {
+		return nil, err
+	}
+
+	list := []model.Alumni{}
+	for _, a := range found {
+		if !target.Matches(a) ||
+			(cohort.Jurusan != "" && !strings.EqualFold(a.Jurusan, cohort.Jurusan)) ||
+			(cohort.TahunLulus != 0 && a.TahunLulus != cohort.TahunLulus) {
+			continue
+		}
+		list = append(list, a)
+	}
+	return list, nil
+}
+
+// surveyFromRequest memvalidasi pertanyaan dan target kuesioner
+func surveyFromRequest(req model.SurveyRequest) (model.Survey, error) {
+	req.Title = strings.TrimSpace(req.Title)
+	if req.Title == "" {
+		return model.Survey{}, errors.New("Judul kuesioner wajib diisi")
+	}
+	if len(req.Questions) == 0 {
+		return model.Survey{}, errors.New("Kuesioner minimal berisi satu pertanyaan")
+	}
+
+	ids := make(map[string]bool, len(req.Questions))
+	for i := range req.Questions {
+		q := &req.Questions[i]
+		q.ID = strings.TrimSpace(q.ID)
+		if q.ID == "" {
+			q.ID = fmt.Sprintf("q%d", i+1)
+		}
+		if ids[q.ID] {
+			return model.Survey{}, fmt.Errorf("ID pertanyaan %q duplikat", q.ID)
+		}
+		ids[q.ID] = true
+
+		q.Label = strings.TrimSpace(q.Label)
+		if q.Label == "" {
+			return model.Survey{}, fmt.Errorf("Pertanyaan %s: label wajib diisi", q.ID)
+		}
+		switch q.Type {
+		case model.QuestionText, model.QuestionNumber:
+			if len(q.Options) > 0 {
+				return model.Survey{}, fmt.Errorf("Pertanyaan %s: opsi hanya untuk single_choice dan multiple_choice", q.ID)
+			}
+		case model.QuestionSingleChoice, model.QuestionMultipleChoice:
+			if err := validateOptions(q.Options); err != nil {
+				return model.Survey{}, fmt.Errorf("Pertanyaan %s: %w", q.ID, err)
+			}
+		default:
+			return model.Survey{}, fmt.Errorf("Pertanyaan %s: tipe %q tidak dikenal (text, number, single_choice, multiple_choice)", q.ID, q.Type)
+		}
+	}
+
+	for i, j := range req.Target.Jurusan {
+		req.Target.Jurusan[i] = strings.TrimSpace(j)
+		if req.Target.Jurusan[i] == "" {
+			return model.Survey{}, errors.New("Target jurusan tidak boleh kosong")
+		}
+	}
+	for _, y := range req.Target.TahunLulus {
+		if y < 1900 || y > 9999 {
+			return model.Survey{}, fmt.Errorf("Target tahun lulus %d tidak valid", y)
+		}
+	}
+
+	return model.Survey{
+		Title:       req.Title,
+		Description: strings.TrimSpace(req.Description),
+		Questions:   req.Questions,
+		Target:      req.Target,
+	}, nil
+}
+
+func validateOptions(options []string) error {
+	if len(options) < 2 {
+		return errors.New("minimal dua opsi")
+	}
+	seen := make(map[string]bool, len(options))
+	for i, o := range options {
+		options[i] = strings.TrimSpace(o)
+		if options[i] == "" {
+			return errors.New("opsi tidak boleh kosong")
+		}
+		if seen[options[i]] {
+			return fmt.Errorf("opsi %q duplikat", options[i])
+		}
+		seen[options[i]] = true
+	}
+	return nil
+}
+
+// parseAnswers mencocokkan jawaban mentah dengan pertanyaan kuesioner.
+// Jika gagal, field berisi ID pertanyaan yang bermasalah.
+func parseAnswers(questions []model.SurveyQuestion, raw map[string]interface{}) (answers []model.SurveyAnswer, field string, err error) {
+	byID := make(map[string]model.SurveyQuestion, len(questions))
+	for _, q := range questions {
+		byID[q.ID] = q
+	}
+	for id := range raw {
+		if _, ok := byID[id]; !ok {
+			return nil, id, fmt.Errorf("Pertanyaan %q tidak ada di kuesioner", id)
+		}
+	}
+
+	for _, q := range questions {
+		answer, answered, err := parseAnswer(q, raw[q.ID])
+		if err != nil {
+			return nil, q.ID, err
+		}
+		if !answered {
+			if q.Required {
+				return nil, q.ID, fmt.Errorf("Pertanyaan %q wajib dijawab", q.Label)
+			}
+			continue
+		}
+		answers = append(answers, answer)
+	}
+	return answers, "", nil
+}
+
+// parseAnswer mengubah satu jawaban JSON sesuai tipe pertanyaan; answered false jika jawaban kosong
+func parseAnswer(q model.SurveyQuestion, value interface{}) (model.SurveyAnswer, bool, error) {
+	answer := model.SurveyAnswer{QuestionID: q.ID}
+	if value == nil {
+		return answer, false, nil
+	}
+
+	switch q.Type {
+	case model.QuestionText:
+		text, ok := value.(string)
+		if !ok {
+			return answer, false, fmt.Errorf("Jawaban %q harus berupa teks", q.Label)
+		}
+		answer.Text = strings.TrimSpace(text)
+		if len([]rune(answer.Text)) > maxSurveyTextLength {
+			return answer, false, fmt.Errorf("Jawaban %q maksimal %d karakter", q.Label, maxSurveyTextLength)
+		}
+		return answer, answer.Text != "", nil
+
+	case model.QuestionNumber:
+		n, ok := value.(float64)
+		if !ok || math.IsNaN(n) || math.IsInf(n, 0) {
+			return answer, false, fmt.Errorf("Jawaban %q harus berupa angka", q.Label)
+		}
+		answer.Number = &n
+		return answer, true, nil
+
+	case model.QuestionSingleChoice:
+		choice, ok := value.(string)
+		if !ok {
+			return answer, false, fmt.Errorf("Jawaban %q harus berupa salah satu opsi", q.Label)
+		}
+		if choice == "" {
+			return answer, false, nil
+		}
+		if !hasOption(q.Options, choice) {
+			return answer, false, fmt.Errorf("Opsi %q tidak ada di pertanyaan %q", choice, q.Label)
+		}
+		answer.Choices = []string{choice}
+		return answer, true, nil
+
+	case model.QuestionMultipleChoice:
+		list, ok := value.([]interface{})
+		if !ok {
+			return answer, false, fmt.Errorf("Jawaban %q harus berupa daftar opsi", q.Label)
+		}
+		for _, v := range list {
+			choice, ok := v.(string)
+			if !ok || !hasOption(q.Options, choice) {
+				return answer, false, fmt.Errorf("Opsi %v tidak ada di pertanyaan %q", v, q.Label)
+			}
+			if hasOption(answer.Choices, choice) {
+				continue
+			}
+			answer.Choices = append(answer.Choices, choice)
+		}
+		return answer, len(answer.Choices) > 0, nil
+	}
+	return answer, false, fmt.Errorf("Tipe pertanyaan %q tidak dikenal", q.Type)
+}
+
+func hasOption(options []string, o string) bool {
+	for _, opt := range options {
+		if opt == o {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,434 @@
+package service
+
+import (
+	"alumni-app/app/mongodb/model"
+	"alumni-app/app/mongodb/repository/mock"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func TestCreateSurvey(t *testing.T) {
+	alumniRepo := repository.NewMockAlumniRepository()
+	notifications := NewNotificationService(repository.NewMockNotificationTemplateRepository(), repository.NewMockNotificationRepository(), repository.NewMockEmailQueueRepository(), alumniRepo, &captureNotifier{}, testNotificationCfg)
+	service := NewSurveyService(repository.NewMockSurveyRepository(), repository.NewMockSurveyResponseRepository(), alumniRepo, notifications)
+
+	app := fiber.New()
+	app.Post("/surveys", service.Create)
+
+	t.Run("Invalid Survey", func(t *testing.T) {
+		for _, body := range []string{
+			`{"title":"","questions":[{"label":"a","type":"text"}]}`,
+			`{"title":"x","questions":[]}`,
+			`{"title":"x","questions":[{"label":"a","type":"date"}]}`,
+			`{"title":"x","questions":[{"label":"a","type":"single_choice","options":["ya"]}]}`,
+			`{"title":"x","questions":[{"id":"a","label":"a","type":"text"},{"id":"a","label":"b","type":"text"}]}`,
+		} {
+			if code, _ := sendAs(app, "POST", "/surveys", "", body); code != 400 {
+				t.Errorf("expected 400 for %s, got %d", body, code)
+			}
+		}
+	})
+
+	t.Run("Valid Survey", func(t *testing.T) {
+		code, body := sendAs(app, "POST", "/surveys", "", `{
+			"title": "Tracer Study 2024",
+			"target": {"tahun_lulus": [2020], "jurusan": ["TI"]},
+			"questions": [
+				{"id": "status", "label": "Status saat ini", "type": "single_choice", "options": ["Bekerja", "Wirausaha", "Belum bekerja"], "required": true},
+				{"id": "gaji", "label": "Gaji pertama (juta)", "type": "number"},
+				{"id": "skill", "label": "Kompetensi yang dipakai", "type": "multiple_choice", "options": ["Coding", "Komunikasi", "Manajemen"]},
+				{"label": "Saran", "type": "text"}
+			]}`)
+		if code != 201 {
+			t.Fatalf("expected 201, got %d: %s", code, body)
+		}
+		var created struct{ Data model.Survey }
+		json.Unmarshal(body, &created)
+		if created.Data.Status != model.SurveyDraft || created.Data.Questions[3].ID != "q4" {
+			t.Errorf("unexpected survey: %+v", created.Data)
+		}
+	})
+}
+
+func TestUpdateSurveyStatus(t *testing.T) {
+	surveyRepo := repository.NewMockSurveyRepository()
+	alumniRepo := repository.NewMockAlumniRepository()
+	notifications := NewNotificationService(repository.NewMockNotificationTemplateRepository(), repository.NewMockNotificationRepository(), repository.NewMockEmailQueueRepository(), alumniRepo, &captureNotifier{}, testNotificationCfg)
+	service := NewSurveyService(surveyRepo, repository.NewMockSurveyResponseRepository(), alumniRepo, notifications)
+
+	app := fiber.New()
+	app.Put("/surveys/:id", service.Update)
+	app.Delete("/surveys/:id", service.Delete)
+	app.Put("/surveys/:id/status", service.UpdateStatus)
+
+	survey := model.Survey{Title: "Tracer Study 2024", Questions: []model.SurveyQuestion{{ID: "q1", Label: "Saran", Type: model.QuestionText}}}
+	surveyRepo.Create(context.Background(), &survey)
+	id := survey.ID.Hex()
+
+	t.Run("Open", func(t *testing.T) {
+		if code, _ := sendAs(app, "PUT", "/surveys/"+id+"/status", "", `{"status":"open"}`); code != 200 {
+			t.Fatalf("expected 200 opening survey, got %d", code)
+		}
+	})
+
+	t.Run("Locked After Open", func(t *testing.T) {
+		if code, _ := sendAs(app, "PUT", "/surveys/"+id, "", `{"title":"x","questions":[{"label":"a","type":"text"}]}`); code != 409 {
+			t.Errorf("expected 409 updating open survey, got %d", code)
+		}
+		if code, _ := sendAs(app, "DELETE", "/surveys/"+id, "", ""); code != 409 {
+			t.Errorf("expected 409 deleting open survey, got %d", code)
+		}
+	})
+
+	t.Run("Close", func(t *testing.T) {
+		if code, _ := sendAs(app, "PUT", "/surveys/"+id+"/status", "", `{"status":"closed"}`); code != 200 {
+			t.Fatalf("expected 200 closing survey, got %d", code)
+		}
+		if code, _ := sendAs(app, "PUT", "/surveys/"+id+"/status", "", `{"status":"closed"}`); code != 409 {
+			t.Errorf("expected 409 closing twice, got %d", code)
+		}
+	})
+}
+
+func TestSubmitSurvey(t *testing.T) {
+	surveyRepo := repository.NewMockSurveyRepository()
+	responseRepo := repository.NewMockSurveyResponseRepository()
+	alumniRepo := repository.NewMockAlumniRepository()
+	notifications := NewNotificationService(repository.NewMockNotificationTemplateRepository(), repository.NewMockNotificationRepository(), repository.NewMockEmailQueueRepository(), alumniRepo, &captureNotifier{}, testNotificationCfg)
+	service := NewSurveyService(surveyRepo, responseRepo, alumniRepo, notifications)
+
+	// farid termasuk target TI 2020, budi lulus 2019
+	users := map[string]primitive.ObjectID{"farid": primitive.NewObjectID(), "budi": primitive.NewObjectID()}
+	alumniRepo.Create(context.Background(), &model.Alumni{NIM: "1", Nama: "Farid", Jurusan: "TI", TahunLulus: 2020, UserID: users["farid"]})
+	alumniRepo.Create(context.Background(), &model.Alumni{NIM: "3", Nama: "Budi", Jurusan: "TI", TahunLulus: 2019, UserID: users["budi"]})
+
+	app := fiber.New()
+	app.Post("/me/surveys/:id/responses", func(c *fiber.Ctx) error {
+		c.Locals("user_id", users[c.Get("X-Test-User")])
+		return service.Submit(c)
+	})
+
+	questions := []model.SurveyQuestion{
+		{ID: "status", Label: "Status saat ini", Type: model.QuestionSingleChoice, Options: []string{"Bekerja", "Wirausaha", "Belum bekerja"}, Required: true},
+		{ID: "gaji", Label: "Gaji pertama (juta)", Type: model.QuestionNumber},
+		{ID: "skill", Label: "Kompetensi yang dipakai", Type: model.QuestionMultipleChoice, Options: []string{"Coding", "Komunikasi", "Manajemen"}},
+		{ID: "q4", Label: "Saran", Type: model.QuestionText},
+	}
+	target := model.SurveyTarget{TahunLulus: []int{2020}, Jurusan: []string{"TI"}}
+	open := model.Survey{Title: "Tracer Study 2024", Target: target, Questions: questions}
+	draft := model.Survey{Title: "Tracer Study 2025", Target: target, Questions: questions}
+	closed := model.Survey{Title: "Tracer Study 2023", Target: target, Questions: questions}
+	for _, s := range []*model.Survey{&open, &draft, &closed} {
+		surveyRepo.Create(context.Background(), s)
+	}
+	surveyRepo.SetStatus(context.Background(), open.ID, model.SurveyDraft, model.SurveyOpen)
+	surveyRepo.SetStatus(context.Background(), closed.ID, model.SurveyDraft, model.SurveyClosed)
+	id := open.ID.Hex()
+
+	t.Run("Draft Hidden From Alumni", func(t *testing.T) {
+		if code, _ := sendAs(app, "POST", "/me/surveys/"+draft.ID.Hex()+"/responses", "farid", `{"answers":{"status":"Bekerja"}}`); code != 404 {
+			t.Errorf("expected 404 for draft survey, got %d", code)
+		}
+	})
+
+	t.Run("Validation", func(t *testing.T) {
+		cases := []struct{ body, field string }{
+			{`{"answers":{"gaji":5}}`, "status"},
+			{`{"answers":{"status":"Pensiun"}}`, "status"},
+			{`{"answers":{"status":"Bekerja","gaji":"lima"}}`, "gaji"},
+			{`{"answers":{"status":"Bekerja","skill":["Coding","Menyanyi"]}}`, "skill"},
+			{`{"answers":{"status":"Bekerja","umur":30}}`, "umur"},
+		}
+		for _, tc := range cases {
+			code, body := sendAs(app, "POST", "/me/surveys/"+id+"/responses", "farid", tc.body)
+			var res map[string]string
+			json.Unmarshal(body, &res)
+			if code != 400 || res["field"] != tc.field {
+				t.Errorf("%s: expected 400 on %s, got %d %s", tc.body, tc.field, code, body)
+			}
+		}
+	})
+
+	t.Run("Outside Target", func(t *testing.T) {
+		if code, _ := sendAs(app, "POST", "/me/surveys/"+id+"/responses", "budi", `{"answers":{"status":"Bekerja"}}`); code != 404 {
+			t.Errorf("expected 404 for alumni outside target, got %d", code)
+		}
+	})
+
+	t.Run("Submit And Resubmit", func(t *testing.T) {
+		if code, body := sendAs(app, "POST", "/me/surveys/"+id+"/responses", "farid", `{"answers":{"status":"Belum bekerja"}}`); code != 200 {
+			t.Fatalf("expected 200, got %d: %s", code, body)
+		}
+		body := `{"answers":{"status":"Bekerja","gaji":7.5,"skill":["Coding","Komunikasi","Coding"],"q4":"Perbanyak magang"}}`
+		if code, _ := sendAs(app, "POST", "/me/surveys/"+id+"/responses", "farid", body); code != 200 {
+			t.Fatalf("expected 200, got %d", code)
+		}
+		if len(responseRepo.Data) != 1 {
+			t.Fatalf("expected resubmission to replace the response, got %d", len(responseRepo.Data))
+		}
+		for _, r := range responseRepo.Data {
+			if len(r.Answers) != 4 || len(r.Answers[2].Choices) != 2 {
+				t.Errorf("expected deduplicated answers, got %+v", r.Answers)
+			}
+		}
+	})
+
+	t.Run("Closed Survey", func(t *testing.T) {
+		if code, _ := sendAs(app, "POST", "/me/surveys/"+closed.ID.Hex()+"/responses", "farid", `{"answers":{"status":"Bekerja"}}`); code != 409 {
+			t.Errorf("expected 409 submitting closed survey, got %d", code)
+		}
+	})
+}
+
+func TestGetMySurveys(t *testing.T) {
+	surveyRepo := repository.NewMockSurveyRepository()
+	responseRepo := repository.NewMockSurveyResponseRepository()
+	alumniRepo := repository.NewMockAlumniRepository()
+	notifications := NewNotificationService(repository.NewMockNotificationTemplateRepository(), repository.NewMockNotificationRepository(), repository.NewMockEmailQueueRepository(), alumniRepo, &captureNotifier{}, testNotificationCfg)
+	service := NewSurveyService(surveyRepo, responseRepo, alumniRepo, notifications)
+
+	users := map[string]primitive.ObjectID{"farid": primitive.NewObjectID(), "budi": primitive.NewObjectID()}
+	farid := model.Alumni{NIM: "1", Nama: "Farid", Jurusan: "TI", TahunLulus: 2020, UserID: users["farid"]}
+	alumniRepo.Create(context.Background(), &farid)
+	alumniRepo.Create(context.Background(), &model.Alumni{NIM: "3", Nama: "Budi", Jurusan: "TI", TahunLulus: 2019, UserID: users["budi"]})
+
+	app := fiber.New()
+	app.Get("/me/surveys", func(c *fiber.Ctx) error {
+		c.Locals("user_id", users[c.Get("X-Test-User")])
+		return service.GetMySurveys(c)
+	})
+
+	survey := model.Survey{
+		Title:     "Tracer Study 2024",
+		Target:    model.SurveyTarget{TahunLulus: []int{2020}, Jurusan: []string{"TI"}},
+		Questions: []model.SurveyQuestion{{ID: "status", Label: "Status saat ini", Type: model.QuestionSingleChoice, Options: []string{"Bekerja", "Wirausaha"}}},
+	}
+	surveyRepo.Create(context.Background(), &survey)
+	surveyRepo.SetStatus(context.Background(), survey.ID, model.SurveyDraft, model.SurveyOpen)
+	responseRepo.Upsert(context.Background(), &model.SurveyResponse{
+		SurveyID: survey.ID, AlumniID: farid.ID, UserID: farid.UserID,
+		Answers: []model.SurveyAnswer{{QuestionID: "status", Choices: []string{"Bekerja"}}},
+	})
+
+	t.Run("Answered", func(t *testing.T) {
+		_, body := sendAs(app, "GET", "/me/surveys", "farid", "")
+		var mine struct{ Data []model.MySurvey }
+		json.Unmarshal(body, &mine)
+		if len(mine.Data) != 1 || !mine.Data[0].Answered {
+			t.Errorf("expected one answered survey, got %+v", mine.Data)
+		}
+	})
+
+	t.Run("Outside Target", func(t *testing.T) {
+		_, body := sendAs(app, "GET", "/me/surveys", "budi", "")
+		var mine struct{ Data []model.MySurvey }
+		json.Unmarshal(body, &mine)
+		if len(mine.Data) != 0 {
+			t.Errorf("expected no surveys outside target, got %+v", mine.Data)
+		}
+	})
+}
+
+func TestRemindSurvey(t *testing.T) {
+	surveyRepo := repository.NewMockSurveyRepository()
+	responseRepo := repository.NewMockSurveyResponseRepository()
+	alumniRepo := repository.NewMockAlumniRepository()
+	templateRepo := repository.NewMockNotificationTemplateRepository()
+	notificationRepo := repository.NewMockNotificationRepository()
+	notifications := NewNotificationService(templateRepo, notificationRepo, repository.NewMockEmailQueueRepository(), alumniRepo, &captureNotifier{}, testNotificationCfg)
+	service := NewSurveyService(surveyRepo, responseRepo, alumniRepo, notifications)
+
+	// farid sudah menjawab, sari belum, budi di luar target
+	farid := model.Alumni{NIM: "1", Nama: "Farid", Jurusan: "TI", TahunLulus: 2020, UserID: primitive.NewObjectID()}
+	sari := model.Alumni{NIM: "2", Nama: "Sari", Jurusan: "ti", TahunLulus: 2020, UserID: primitive.NewObjectID()}
+	for _, a := range []*model.Alumni{&farid, &sari, {NIM: "3", Nama: "Budi", Jurusan: "TI", TahunLulus: 2019, UserID: primitive.NewObjectID()}} {
+		alumniRepo.Create(context.Background(), a)
+	}
+	templateRepo.Create(context.Background(), &model.NotificationTemplate{Key: "survey_reminder", Title: "Pengingat", Body: "{{.Data.survey}}", Channels: []string{model.ChannelInApp}})
+
+	app := fiber.New()
+	app.Post("/surveys/:id/remind", service.Remind)
+
+	target := model.SurveyTarget{TahunLulus: []int{2020}, Jurusan: []string{"TI"}}
+	questions := []model.SurveyQuestion{{ID: "q1", Label: "Saran", Type: model.QuestionText}}
+	open := model.Survey{Title: "Tracer Study 2024", Target: target, Questions: questions}
+	draft := model.Survey{Title: "Tracer Study 2025", Target: target, Questions: questions}
+	surveyRepo.Create(context.Background(), &open)
+	surveyRepo.Create(context.Background(), &draft)
+	surveyRepo.SetStatus(context.Background(), open.ID, model.SurveyDraft, model.SurveyOpen)
+	responseRepo.Upsert(context.Background(), &model.SurveyResponse{
+		SurveyID: open.ID, AlumniID: farid.ID, UserID: farid.UserID,
+		Answers: []model.SurveyAnswer{{QuestionID: "q1", Text: "Perbanyak magang"}},
+	})
+
+	t.Run("Remind Pending Alumni", func(t *testing.T) {
+		code, body := sendAs(app, "POST", "/surveys/"+open.ID.Hex()+"/remind", "", "")
+		var res struct{ Recipients int }
+		json.Unmarshal(body, &res)
+		if code != 202 || res.Recipients != 1 {
+			t.Fatalf("expected reminder to the one pending alumni, got %d %s", code, body)
+		}
+		for _, n := range notificationRepo.Data {
+			if n.UserID != sari.UserID || n.Body != "Tracer Study 2024" {
+				t.Errorf("unexpected reminder: %+v", n)
+			}
+		}
+	})
+
+	t.Run("Draft Survey", func(t *testing.T) {
+		if code, _ := sendAs(app, "POST", "/surveys/"+draft.ID.Hex()+"/remind", "", ""); code != 409 {
+			t.Errorf("expected 409 reminding draft survey, got %d", code)
+		}
+	})
+}
+
+func TestSurveyResults(t *testing.T) {
+	surveyRepo := repository.NewMockSurveyRepository()
+	responseRepo := repository.NewMockSurveyResponseRepository()
+	alumniRepo := repository.NewMockAlumniRepository()
+	notifications := NewNotificationService(repository.NewMockNotificationTemplateRepository(), repository.NewMockNotificationRepository(), repository.NewMockEmailQueueRepository(), alumniRepo, &captureNotifier{}, testNotificationCfg)
+	service := NewSurveyService(surveyRepo, responseRepo, alumniRepo, notifications)
+
+	farid := model.Alumni{NIM: "1", Nama: "Farid", Jurusan: "TI", TahunLulus: 2020, UserID: primitive.NewObjectID()}
+	sari := model.Alumni{NIM: "2", Nama: "Sari", Jurusan: "ti", TahunLulus: 2020, UserID: primitive.NewObjectID()}
+	alumniRepo.Create(context.Background(), &farid)
+	alumniRepo.Create(context.Background(), &sari)
+
+	app := fiber.New()
+	app.Get("/surveys/:id/results", service.GetResults)
+
+	survey := model.Survey{
+		Title:  "Tracer Study 2024",
+		Target: model.SurveyTarget{TahunLulus: []int{2020}, Jurusan: []string{"TI"}},
+		Questions: []model.SurveyQuestion{
+			{ID: "status", Label: "Status saat ini", Type: model.QuestionSingleChoice, Options: []string{"Bekerja", "Wirausaha", "Belum bekerja"}, Required: true},
+			{ID: "gaji", Label: "Gaji pertama (juta)", Type: model.QuestionNumber},
+			{ID: "skill", Label: "Kompetensi yang dipakai", Type: model.QuestionMultipleChoice, Options: []string{"Coding", "Komunikasi", "Manajemen"}},
+			{ID: "q4", Label: "Saran", Type: model.QuestionText},
+		},
+	}
+	surveyRepo.Create(context.Background(), &survey)
+	surveyRepo.SetStatus(context.Background(), survey.ID, model.SurveyDraft, model.SurveyOpen)
+	high, low := 7.5, 2.5
+	responseRepo.Upsert(context.Background(), &model.SurveyResponse{
+		SurveyID: survey.ID, AlumniID: farid.ID, UserID: farid.UserID, Jurusan: farid.Jurusan, TahunLulus: farid.TahunLulus,
+		Answers: []model.SurveyAnswer{
+			{QuestionID: "status", Choices: []string{"Bekerja"}},
+			{QuestionID: "gaji", Number: &high},
+			{QuestionID: "skill", Choices: []string{"Coding", "Komunikasi"}},
+			{QuestionID: "q4", Text: "Perbanyak magang"},
+		},
+	})
+	responseRepo.Upsert(context.Background(), &model.SurveyResponse{
+		SurveyID: survey.ID, AlumniID: sari.ID, UserID: sari.UserID, Jurusan: sari.Jurusan, TahunLulus: sari.TahunLulus,
+		Answers: []model.SurveyAnswer{
+			{QuestionID: "status", Choices: []string{"Wirausaha"}},
+			{QuestionID: "gaji", Number: &low},
+			{QuestionID: "skill", Choices: []string{"Manajemen"}},
+		},
+	})
+	id := survey.ID.Hex()
+
+	t.Run("Totals And Stats", func(t *testing.T) {
+		code, body := sendAs(app, "GET", "/surveys/"+id+"/results", "", "")
+		if code != 200 {
+			t.Fatalf("expected 200, got %d", code)
+		}
+		var res struct{ Data model.SurveyResults }
+		json.Unmarshal(body, &res)
+		r := res.Data
+		if r.Responses != 2 || r.Targeted != 2 || r.ResponseRate != 1 {
+			t.Errorf("unexpected totals: %+v", r)
+		}
+		if got := r.Questions[0].Options; got[0].Count != 1 || got[1].Count != 1 || got[2].Count != 0 {
+			t.Errorf("unexpected status counts: %+v", got)
+		}
+		if q := r.Questions[1]; q.Answered != 2 || *q.Min != 2.5 || *q.Max != 7.5 || *q.Mean != 5 {
+			t.Errorf("unexpected number stats: %+v", q)
+		}
+		if got := r.Questions[2].Options; got[0].Count != 1 || got[1].Count != 1 || got[2].Count != 1 {
+			t.Errorf("unexpected skill counts: %+v", got)
+		}
+		if r.Questions[3].Answered != 1 {
+			t.Errorf("expected one text answer, got %d", r.Questions[3].Answered)
+		}
+	})
+
+	t.Run("Cohort Filter", func(t *testing.T) {
+		_, body := sendAs(app, "GET", "/surveys/"+id+"/results?tahun_lulus=2019", "", "")
+		var res struct{ Data model.SurveyResults }
+		json.Unmarshal(body, &res)
+		if res.Data.Responses != 0 || res.Data.Targeted != 0 {
+			t.Errorf("expected empty cohort, got %+v", res.Data)
+		}
+	})
+}
+
+func TestExportSurvey(t *testing.T) {
+	surveyRepo := repository.NewMockSurveyRepository()
+	responseRepo := repository.NewMockSurveyResponseRepository()
+	alumniRepo := repository.NewMockAlumniRepository()
+	notifications := NewNotificationService(repository.NewMockNotificationTemplateRepository(), repository.NewMockNotificationRepository(), repository.NewMockEmailQueueRepository(), alumniRepo, &captureNotifier{}, testNotificationCfg)
+	service := NewSurveyService(surveyRepo, responseRepo, alumniRepo, notifications)
+
+	app := fiber.New()
+	app.Get("/surveys/:id/export", service.Export)
+
+	survey := model.Survey{
+		Title: "Tracer Study 2024",
+		Questions: []model.SurveyQuestion{
+			{ID: "status", Label: "Status saat ini", Type: model.QuestionSingleChoice, Options: []string{"Bekerja", "Wirausaha"}},
+			{ID: "gaji", Label: "Gaji pertama (juta)", Type: model.QuestionNumber},
+			{ID: "skill", Label: "Kompetensi yang dipakai", Type: model.QuestionMultipleChoice, Options: []string{"Coding", "Komunikasi", "Manajemen"}},
+			{ID: "q4", Label: "Saran", Type: model.QuestionText},
+		},
+	}
+	surveyRepo.Create(context.Background(), &survey)
+	gaji := 7.5
+	responseRepo.Upsert(context.Background(), &model.SurveyResponse{
+		SurveyID: survey.ID, AlumniID: primitive.NewObjectID(), NIM: "1", Nama: "Farid", Jurusan: "TI", TahunLulus: 2020,
+		Answers: []model.SurveyAnswer{
+			{QuestionID: "status", Choices: []string{"Bekerja"}},
+			{QuestionID: "gaji", Number: &gaji},
+			{QuestionID: "skill", Choices: []string{"Coding", "Komunikasi"}},
+			{QuestionID: "q4", Text: "Perbanyak magang"},
+		},
+	})
+	responseRepo.Upsert(context.Background(), &model.SurveyResponse{
+		SurveyID: survey.ID, AlumniID: primitive.NewObjectID(), NIM: "2", Nama: "=Sari", Jurusan: "TI", TahunLulus: 2020,
+		Answers: []model.SurveyAnswer{{QuestionID: "status", Choices: []string{"Wirausaha"}}},
+	})
+
+	t.Run("Export CSV", func(t *testing.T) {
+		resp, _ := app.Test(httptest.NewRequest("GET", "/surveys/"+survey.ID.Hex()+"/export", nil))
+		if resp.StatusCode != 200 || !strings.HasPrefix(resp.Header.Get("Content-Type"), "text/csv") {
+			t.Fatalf("expected csv, got %d %s", resp.StatusCode, resp.Header.Get("Content-Type"))
+		}
+		rows, err := csv.NewReader(resp.Body).ReadAll()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(rows) != 3 || strings.Join(rows[0], ",") != "nim,nama,jurusan,tahun_lulus,submitted_at,status,gaji,skill,q4" {
+			t.Fatalf("unexpected csv: %v", rows)
+		}
+		for _, row := range rows[1:] {
+			switch row[0] {
+			case "1":
+				if row[5] != "Bekerja" || row[6] != "7.5" || row[7] != "Coding; Komunikasi" || row[8] != "Perbanyak magang" {
+					t.Errorf("unexpected row: %v", row)
+				}
+			case "2":
+				if row[1] != "'=Sari" {
+					t.Errorf("expected formula-like name to be escaped, got %q", row[1])
+				}
+			}
+		}
+	})
+}
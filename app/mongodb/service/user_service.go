@@ -3,7 +3,13 @@ package service
 import (
 	"alumni-app/app/mongodb/model"
 	"alumni-app/app/mongodb/repository"
+	"alumni-app/config"
 	"alumni-app/utils/mongodb"
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
 	"time"
 
 	"github.com/gofiber/fiber/v2"
@@ -11,23 +17,52 @@ import (
 	"golang.org/x/crypto/bcrypt"
 )
 
+// dummyHash dipakai saat username tidak ditemukan agar waktu respon login tetap sama
+const dummyHash = "$2a$10$hjxjv80WeTxyPkAPgy0S2Od3IycFF4ic.gzpihUR5O7tiAH5QdxkS"
+
 type UserService struct {
-	repo repository.UserRepositoryInterface
+	repo       repository.UserRepositoryInterface
+	alumniRepo repository.AlumniRepositoryInterface
+	uow        repository.UnitOfWork
+	limiter    utils.RateLimitStore
+	rateCfg    config.RateLimitConfig
+	pwCfg      config.PasswordConfig
+	notifier   utils.Notifier
+	verifyCfg  config.EmailVerificationConfig
 }
 
-func NewUserService(r repository.UserRepositoryInterface) *UserService {
-	return &UserService{repo: r}
+func NewUserService(
+	r repository.UserRepositoryInterface,
+	alumniRepo repository.AlumniRepositoryInterface,
+	uow repository.UnitOfWork,
+	limiter utils.RateLimitStore,
+	rateCfg config.RateLimitConfig,
+	pwCfg config.PasswordConfig,
+	notifier utils.Notifier,
+	verifyCfg config.EmailVerificationConfig,
+) *UserService {
+	return &UserService{
+		repo:       r,
+		alumniRepo: alumniRepo,
+		uow:        uow,
+		limiter:    limiter,
+		rateCfg:    rateCfg,
+		pwCfg:      pwCfg,
+		notifier:   notifier,
+		verifyCfg:  verifyCfg,
+	}
 }
 
 // Register godoc
 // @Summary Registrasi user baru
-// @Description Membuat akun user baru dengan username, email, dan password terenkripsi
+// @Description Membuat akun user baru dengan username, email, dan password terenkripsi, lalu mengirim link verifikasi ke email. Jika field alumni diisi, profil alumni dibuat dalam transaksi yang sama.
 // @Tags Users
 // @Accept json
 // @Produce json
 // @Param body body model.RegisterRequest true "Data user baru"
 // @Success 201 {object} model.User
 // @Failure 400 {object} fiber.Map
+// @Failure 409 {object} fiber.Map "Username, email, atau NIM sudah digunakan (tanpa memperhatikan huruf besar/kecil)"
 // @Failure 500 {object} fiber.Map
 // @Router /register [post]
 func (s *UserService) Register(c *fiber.Ctx) error {
@@ -38,31 +73,72 @@ func (s *UserService) Register(c *fiber.Ctx) error {
 	if req.Username == "" || req.Email == "" || req.PasswordHash == "" {
 		return c.Status(400).JSON(fiber.Map{"error": "Username, email, dan password wajib diisi"})
 	}
+	if err := utils.ValidatePassword(req.PasswordHash, s.pwCfg); err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": err.Error()})
+	}
+	if req.Alumni != nil && (strings.TrimSpace(req.Alumni.NIM) == "" || strings.TrimSpace(req.Alumni.Nama) == "") {
+		return c.Status(400).JSON(fiber.Map{"error": "NIM dan nama alumni wajib diisi"})
+	}
 
 	hashed, err := bcrypt.GenerateFromPassword([]byte(req.PasswordHash), bcrypt.DefaultCost)
 	if err != nil {
 		return c.Status(500).JSON(fiber.Map{"error": "Gagal enkripsi password"})
 	}
 
-	role := req.Role
-	if role == "" {
-		role = "user"
-	}
-
+	// registrasi publik selalu membuat akun biasa; role admin hanya diberikan langsung di database
 	user := model.User{
 		ID:           primitive.NewObjectID(),
-		Username:     req.Username,
-		Email:        req.Email,
+		Username:     strings.TrimSpace(req.Username),
+		Email:        strings.TrimSpace(req.Email),
 		PasswordHash: string(hashed),
-		Role:         role,
+		Role:         "user",
 		CreatedAt:    time.Now(),
 	}
 
-	if err := s.repo.Create(&user); err != nil {
-	return c.Status(500).JSON(fiber.Map{"error": err.Error()})
+	// akun dan profil alumni dibuat bersama; NIM yang sudah dipakai membatalkan pembuatan akun
+	var alumni *model.Alumni
+	err = s.uow.Do(c.UserContext(), func(ctx context.Context) error {
+		if err := s.repo.Create(ctx, &user); err != nil {
+			return err
+		}
+		if !repository.InTransaction(ctx) {
+			repository.OnRollback(ctx, func(ctx context.Context) {
+				if err := s.repo.Delete(ctx, user.ID); err != nil {
+					fmt.Println("⚠️ Warning: gagal menghapus user dari registrasi yang gagal:", err)
+				}
+			})
+		}
+		if req.Alumni == nil {
+			return nil
+		}
+		alumni = &model.Alumni{
+			NIM:        strings.TrimSpace(req.Alumni.NIM),
+			Nama:       strings.TrimSpace(req.Alumni.Nama),
+			Jurusan:    req.Alumni.Jurusan,
+			Angkatan:   req.Alumni.Angkatan,
+			TahunLulus: req.Alumni.TahunLulus,
+			Email:      user.Email,
+			NoTelepon:  req.Alumni.NoTelepon,
+			Alamat:     req.Alumni.Alamat,
+			UserID:     user.ID,
+		}
+		return s.alumniRepo.Create(ctx, alumni)
+	})
+	if err != nil {
+		var conflict *repository.ConflictError
+		if errors.As(err, &conflict) {
+			return c.Status(409).JSON(fiber.Map{"error": conflict.Field + " sudah digunakan", "field": conflict.Field})
+		}
+		return c.Status(500).JSON(fiber.Map{"error": err.Error()})
 	}
 
-	return c.Status(201).JSON(fiber.Map{"success": true, "data": user, "message": "User berhasil didaftarkan"})
+	s.sendVerification(c.UserContext(), user)
+
+	resp := fiber.Map{"success": true, "data": user, "message": "User berhasil didaftarkan, cek email untuk verifikasi"}
+	if alumni != nil {
+		resp["alumni"] = alumni
+	}
+	return c.Status(201).JSON(resp)
 }
 
 // Login godoc
@@ -75,6 +151,8 @@ func (s *UserService) Register(c *fiber.Ctx) error {
 // @Success 200 {object} fiber.Map
 // @Failure 400 {object} fiber.Map
 // @Failure 401 {object} fiber.Map
+// @Failure 403 {object} fiber.Map "Email belum diverifikasi (jika EMAIL_VERIFICATION_REQUIRED=true)"
+// @Failure 429 {object} fiber.Map
 // @Failure 500 {object} fiber.Map
 // @Router /login [post]
 func (s *UserService) Login(c *fiber.Ctx) error {
@@ -83,13 +161,26 @@ func (s *UserService) Login(c *fiber.Ctx) error {
 		return c.Status(400).JSON(fiber.Map{"error": "Request tidak valid"})
 	}
 
-	user, err := s.repo.GetByUsername(req.Username)
+	// lockout per username, berlaku juga untuk username yang tidak terdaftar
+	failKey := "login_fail:" + strings.ToLower(req.Username)
+	if count, resetAt, _ := s.limiter.Get(failKey); count >= s.rateCfg.MaxFailures {
+		c.Set(fiber.HeaderRetryAfter, strconv.Itoa(int(time.Until(resetAt).Seconds())+1))
+		return c.Status(429).JSON(fiber.Map{"error": "Terlalu banyak percobaan login gagal, coba lagi nanti"})
+	}
+
+	user, err := s.repo.GetByUsername(c.UserContext(), req.Username)
 	if err != nil {
-		return c.Status(401).JSON(fiber.Map{"error": "Username tidak ditemukan"})
+		utils.CheckPassword(dummyHash, req.PasswordHash)
 	}
+	if err != nil || !utils.CheckPassword(user.PasswordHash, req.PasswordHash) {
+		recordFailure(s.limiter, failKey, s.rateCfg.MaxFailures, s.rateCfg.Lockout)
+		return c.Status(401).JSON(fiber.Map{"error": "Username atau password salah"})
+	}
+	s.limiter.Reset(failKey)
 
-	if !utils.CheckPassword(user.PasswordHash, req.PasswordHash) {
-		return c.Status(401).JSON(fiber.Map{"error": "Password salah"})
+	// dicek setelah password benar agar status verifikasi tidak bocor ke orang lain
+	if s.verifyCfg.Required && !user.EmailVerified {
+		return c.Status(403).JSON(fiber.Map{"error": "Email belum diverifikasi, cek email atau minta kirim ulang link verifikasi"})
 	}
 
 	token, err := utils.GenerateToken(user.ID.Hex(), user.Username, user.Role)
@@ -106,3 +197,31 @@ func (s *UserService) Login(c *fiber.Ctx) error {
 		},
 	})
 }
+
+// Me godoc
+// @Summary Data akun user login
+// @Description Mengambil data user berdasarkan user_id di token JWT
+// @Tags Me
+// @Accept json
+// @Produce json
+// @Success 200 {object} model.User
+// @Failure 404 {object} fiber.Map
+// @Security BearerAuth
+// @Router /me [get]
+func (s *UserService) Me(c *fiber.Ctx) error {
+	userID, _ := c.Locals("user_id").(primitive.ObjectID)
+
+	user, err := s.repo.GetByID(c.UserContext(), userID)
+	if err != nil {
+		return c.Status(404).JSON(fiber.Map{"error": "User tidak ditemukan"})
+	}
+	return c.JSON(fiber.Map{"success": true, "data": user})
+}
+
+// recordFailure menghitung satu percobaan gagal. Begitu batas tercapai, window diperpanjang
+// agar lockout berlaku penuh sejak kegagalan terakhir, bukan sisa window sejak kegagalan pertama.
+func recordFailure(limiter utils.RateLimitStore, key string, max int, lockout time.Duration) {
+	if count, _, err := limiter.Increment(key, lockout); err == nil && count >= max {
+		limiter.Extend(key, lockout)
+	}
+}
@@ -3,18 +3,49 @@ package service
 import (
 	"alumni-app/app/mongodb/model"
 	"alumni-app/app/mongodb/repository/mock"
+	"alumni-app/config"
+	"alumni-app/utils/mongodb"
+	"context"
+	"encoding/json"
 	"net/http/httptest"
+	"net/url"
+	"regexp"
 	"strings"
+	"sync"
 	"testing"
+	"time"
 
 	"github.com/gofiber/fiber/v2"
 	"go.mongodb.org/mongo-driver/bson/primitive"
 	"golang.org/x/crypto/bcrypt"
 )
 
+var testRateCfg = config.RateLimitConfig{
+	IPMax:       100,
+	IPWindow:    time.Minute,
+	MaxFailures: 3,
+	Lockout:     time.Minute,
+
+	ClaimMaxFailures: 3,
+	ClaimLockout:     time.Minute,
+}
+
+var testVerifyCfg = config.EmailVerificationConfig{
+	TokenTTL: time.Hour,
+	Secret:   "test-secret",
+	LinkURL:  "http://localhost/api/v1/verify-email",
+}
+
+var testPasswordCfg = config.PasswordConfig{
+	MinLength:     8,
+	RequireLower:  true,
+	RequireDigit:  true,
+	ResetTokenTTL: time.Minute,
+}
+
 func TestRegister(t *testing.T) {
 	mockRepo := repository.NewMockUserRepository()
-	service := NewUserService(mockRepo)
+	service := NewUserService(mockRepo, repository.NewMockAlumniRepository(), repository.NewMockUnitOfWork(), utils.NewMemoryRateLimitStore(), testRateCfg, testPasswordCfg, &captureNotifier{}, testVerifyCfg)
 
 	app := fiber.New()
 
@@ -28,9 +59,14 @@ func TestRegister(t *testing.T) {
 	}{
 		{
 			name:       "Valid Register",
-			body:       `{"username":"shendy","email":"s@example.com","password":"12345"}`,
+			body:       `{"username":"shendy","email":"s@example.com","password":"rahasia123"}`,
 			wantStatus: 201,
 		},
+		{
+			name:       "Weak Password",
+			body:       `{"username":"lemah","email":"l@example.com","password":"12345"}`,
+			wantStatus: 400,
+		},
 		{
 			name:       "Missing Fields",
 			body:       `{"username":"","email":"x","password":""}`,
@@ -58,9 +94,171 @@ func TestRegister(t *testing.T) {
 	}
 }
 
+func TestRegisterIgnoresRole(t *testing.T) {
+	mockRepo := repository.NewMockUserRepository()
+	service := NewUserService(mockRepo, repository.NewMockAlumniRepository(), repository.NewMockUnitOfWork(), utils.NewMemoryRateLimitStore(), testRateCfg, testPasswordCfg, &captureNotifier{}, testVerifyCfg)
+
+	app := fiber.New()
+	app.Post("/register", service.Register)
+
+	// role dari body tidak boleh dipakai untuk mendaftar sebagai admin
+	req := httptest.NewRequest("POST", "/register", strings.NewReader(`{"username":"nakal","email":"n@example.com","password":"rahasia123","role":"admin"}`))
+	req.Header.Set("Content-Type", "application/json")
+	resp, _ := app.Test(req, -1)
+	if resp.StatusCode != 201 {
+		t.Fatalf("Expected 201, got %d", resp.StatusCode)
+	}
+
+	user, err := mockRepo.GetByUsername(context.Background(), "nakal")
+	if err != nil || user.Role != "user" {
+		t.Errorf("Expected role user, got %q (%v)", user.Role, err)
+	}
+}
+
+func TestRegisterConflict(t *testing.T) {
+	mockRepo := repository.NewMockUserRepository()
+	service := NewUserService(mockRepo, repository.NewMockAlumniRepository(), repository.NewMockUnitOfWork(), utils.NewMemoryRateLimitStore(), testRateCfg, testPasswordCfg, &captureNotifier{}, testVerifyCfg)
+
+	app := fiber.New()
+	app.Post("/register", service.Register)
+
+	register := func(body string) (int, map[string]interface{}) {
+		req := httptest.NewRequest("POST", "/register", strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		resp, err := app.Test(req, -1)
+		if err != nil {
+			t.Fatalf("request failed: %v", err)
+		}
+		var out map[string]interface{}
+		json.NewDecoder(resp.Body).Decode(&out)
+		return resp.StatusCode, out
+	}
+
+	if status, _ := register(`{"username":"shendy","email":"s@example.com","password":"rahasia123"}`); status != 201 {
+		t.Fatalf("Expected 201, got %d", status)
+	}
+
+	tests := []struct {
+		name      string
+		body      string
+		wantField string
+	}{
+		{
+			name:      "Username Different Case",
+			body:      `{"username":"SHENDY","email":"lain@example.com","password":"rahasia123"}`,
+			wantField: "username",
+		},
+		{
+			name:      "Username With Spaces",
+			body:      `{"username":"  Shendy ","email":"lain@example.com","password":"rahasia123"}`,
+			wantField: "username",
+		},
+		{
+			name:      "Email Different Case",
+			body:      `{"username":"baru","email":"S@Example.COM","password":"rahasia123"}`,
+			wantField: "email",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			status, out := register(tt.body)
+			if status != 409 {
+				t.Fatalf("got %d, want 409", status)
+			}
+			if out["field"] != tt.wantField {
+				t.Errorf("Expected field %q, got %v", tt.wantField, out["field"])
+			}
+		})
+	}
+
+	t.Run("Concurrent Register", func(t *testing.T) {
+		const n = 8
+		statuses := make(chan int, n)
+		var wg sync.WaitGroup
+		for i := 0; i < n; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				status, _ := register(`{"username":"balapan","email":"balapan@example.com","password":"rahasia123"}`)
+				statuses <- status
+			}()
+		}
+		wg.Wait()
+		close(statuses)
+
+		created := 0
+		for status := range statuses {
+			switch status {
+			case 201:
+				created++
+			case 409:
+			default:
+				t.Errorf("Unexpected status %d", status)
+			}
+		}
+		if created != 1 {
+			t.Errorf("Expected exactly one 201, got %d", created)
+		}
+	})
+}
+
+func TestRegisterWithAlumni(t *testing.T) {
+	mockRepo := repository.NewMockUserRepository()
+	alumniRepo := repository.NewMockAlumniRepository()
+	uow := repository.NewMockUnitOfWork()
+	service := NewUserService(mockRepo, alumniRepo, uow, utils.NewMemoryRateLimitStore(), testRateCfg, testPasswordCfg, &captureNotifier{}, testVerifyCfg)
+
+	app := fiber.New()
+	app.Post("/register", service.Register)
+
+	register := func(body string) (int, map[string]interface{}) {
+		req := httptest.NewRequest("POST", "/register", strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		resp, _ := app.Test(req, -1)
+		var out map[string]interface{}
+		json.NewDecoder(resp.Body).Decode(&out)
+		return resp.StatusCode, out
+	}
+
+	status, _ := register(`{"username":"farid","email":"farid@example.com","password":"rahasia123","alumni":{"nim":"2101","nama":"Farid","jurusan":"TI","angkatan":2019,"tahun_lulus":2023}}`)
+	if status != 201 {
+		t.Fatalf("Expected 201, got %d", status)
+	}
+	if uow.Calls != 1 {
+		t.Errorf("Expected one unit of work, got %d", uow.Calls)
+	}
+	user, err := mockRepo.GetByUsername(context.Background(), "farid")
+	if err != nil {
+		t.Fatal("user not created")
+	}
+	alumni, err := alumniRepo.GetByUserID(context.Background(), user.ID)
+	if err != nil || alumni.NIM != "2101" || alumni.Email != "farid@example.com" {
+		t.Errorf("Expected alumni profile linked to user, got %+v (%v)", alumni, err)
+	}
+
+	t.Run("Missing NIM", func(t *testing.T) {
+		status, _ := register(`{"username":"baru","email":"baru@example.com","password":"rahasia123","alumni":{"nama":"Baru"}}`)
+		if status != 400 {
+			t.Errorf("Expected 400, got %d", status)
+		}
+	})
+
+	t.Run("Duplicate NIM", func(t *testing.T) {
+		status, out := register(`{"username":"lain","email":"lain@example.com","password":"rahasia123","alumni":{"nim":"2101","nama":"Lain"}}`)
+		if status != 409 || out["field"] != "nim" {
+			t.Errorf("Expected 409 on nim, got %d %v", status, out)
+		}
+		// tanpa transaksi, akun yang sudah terlanjur dibuat harus dihapus lagi
+		if _, err := mockRepo.GetByUsername(context.Background(), "lain"); err == nil {
+			t.Error("Expected user to be removed after alumni creation failed")
+		}
+	})
+}
+
 func TestLogin(t *testing.T) {
 	mockRepo := repository.NewMockUserRepository()
-	service := NewUserService(mockRepo)
+	service := NewUserService(mockRepo, repository.NewMockAlumniRepository(), repository.NewMockUnitOfWork(), utils.NewMemoryRateLimitStore(), testRateCfg, testPasswordCfg, &captureNotifier{}, testVerifyCfg)
 
 	app := fiber.New()
 
@@ -76,7 +274,7 @@ func TestLogin(t *testing.T) {
 		PasswordHash: string(hashed),
 		Role:         "user",
 	}
-	mockRepo.Create(&user)
+	mockRepo.Create(context.Background(), &user)
 
 	tests := []struct {
 		name       string
@@ -119,3 +317,114 @@ func TestLogin(t *testing.T) {
 		})
 	}
 }
+
+func TestLoginLockout(t *testing.T) {
+	mockRepo := repository.NewMockUserRepository()
+	service := NewUserService(mockRepo, repository.NewMockAlumniRepository(), repository.NewMockUnitOfWork(), utils.NewMemoryRateLimitStore(), testRateCfg, testPasswordCfg, &captureNotifier{}, testVerifyCfg)
+
+	app := fiber.New()
+	app.Post("/login", service.Login)
+
+	hashed, _ := bcrypt.GenerateFromPassword([]byte("12345"), bcrypt.DefaultCost)
+	mockRepo.Create(context.Background(), &model.User{
+		ID:           primitive.NewObjectID(),
+		Username:     "shendy",
+		PasswordHash: string(hashed),
+		Role:         "user",
+	})
+
+	login := func(password string) int {
+		req := httptest.NewRequest("POST", "/login", strings.NewReader(`{"username":"shendy","password":"`+password+`"}`))
+		req.Header.Set("Content-Type", "application/json")
+		resp, _ := app.Test(req, -1)
+		return resp.StatusCode
+	}
+
+	for i := 0; i < testRateCfg.MaxFailures; i++ {
+		if got := login("wrong"); got != 401 {
+			t.Fatalf("attempt %d: got %d, want 401", i+1, got)
+		}
+	}
+
+	// password benar pun ditolak selama akun terkunci
+	if got := login("12345"); got != 429 {
+		t.Errorf("got %d, want 429", got)
+	}
+}
+
+func TestEmailVerification(t *testing.T) {
+	mockRepo := repository.NewMockUserRepository()
+	notifier := &captureNotifier{}
+	verifyCfg := testVerifyCfg
+	verifyCfg.Required = true
+	service := NewUserService(mockRepo, repository.NewMockAlumniRepository(), repository.NewMockUnitOfWork(), utils.NewMemoryRateLimitStore(), testRateCfg, testPasswordCfg, notifier, verifyCfg)
+
+	app := fiber.New()
+	app.Post("/register", service.Register)
+	app.Post("/login", service.Login)
+	app.Get("/verify-email", service.VerifyEmail)
+	app.Post("/resend-verification", service.ResendVerification)
+
+	post := func(path, body string) int {
+		req := httptest.NewRequest("POST", path, strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		resp, _ := app.Test(req, -1)
+		return resp.StatusCode
+	}
+	verify := func(token string) int {
+		resp, _ := app.Test(httptest.NewRequest("GET", "/verify-email?token="+url.QueryEscape(token), nil), -1)
+		return resp.StatusCode
+	}
+
+	if code := post("/register", `{"username":"farid","email":"farid@example.com","password":"rahasia123"}`); code != 201 {
+		t.Fatalf("expected 201, got %d", code)
+	}
+	if len(notifier.sent) != 1 || notifier.sent[0].To != "farid@example.com" {
+		t.Fatalf("expected one verification email, got %+v", notifier.sent)
+	}
+	token := regexp.MustCompile(`token=(\S+)`).FindStringSubmatch(notifier.sent[0].Body)
+	if token == nil {
+		t.Fatalf("verification link not found in %q", notifier.sent[0].Body)
+	}
+	plain, _ := url.QueryUnescape(token[1])
+
+	login := `{"username":"farid","password":"rahasia123"}`
+	t.Run("Login Blocked Before Verification", func(t *testing.T) {
+		if code := post("/login", login); code != 403 {
+			t.Errorf("expected 403, got %d", code)
+		}
+	})
+
+	t.Run("Resend", func(t *testing.T) {
+		post("/resend-verification", `{"email":"FARID@example.com"}`)
+		if sent := notifier.waitSent(2); len(sent) != 2 {
+			t.Errorf("expected verification email resent, got %d messages", len(sent))
+		}
+		if code := post("/resend-verification", `{"email":"unknown@example.com"}`); code != 200 {
+			t.Errorf("expected 200 for unknown email, got %d", code)
+		}
+	})
+
+	t.Run("Invalid Token", func(t *testing.T) {
+		if code := verify(plain + "x"); code != 400 {
+			t.Errorf("expected 400 for tampered token, got %d", code)
+		}
+		user, _ := mockRepo.GetByUsername(context.Background(), "farid")
+		expired := utils.SignToken([]byte(verifyCfg.Secret), verifyEmailPurpose, user.ID.Hex()+":farid@example.com", time.Now().Add(-time.Minute))
+		if code := verify(expired); code != 400 {
+			t.Errorf("expected 400 for expired token, got %d", code)
+		}
+	})
+
+	t.Run("Verify Then Login", func(t *testing.T) {
+		if code := verify(plain); code != 200 {
+			t.Fatalf("expected 200, got %d", code)
+		}
+		if code := post("/login", login); code != 200 {
+			t.Errorf("expected 200 after verification, got %d", code)
+		}
+		if code := verify(plain); code != 200 {
+			t.Errorf("expected verifying twice to be harmless, got %d", code)
+		}
+	})
+}
@@ -15,7 +15,6 @@ type RegisterRequest struct {
     Username string `json:"username" validate:"required"`
     Email    string `json:"email" validate:"required,email"`
     PasswordHash string `json:"password" validate:"required,min=6"`
-    Role     string `json:"role"`
 }
 
 type LoginRequest struct {
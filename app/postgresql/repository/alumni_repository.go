@@ -3,6 +3,7 @@ package repository
 import (
 	"alumni-app/app/postgresql/model"
 	"alumni-app/database/postgresql"
+	"context"
 	"database/sql"
 	"fmt"
 	"time"
@@ -84,7 +85,7 @@ func (r *AlumniRepository) GetByID(id int) (model.Alumni, error) {
 
 func (r *AlumniRepository) Create(a *model.Alumni) error {
 	now := time.Now()
-	return database.DB.QueryRow(`
+	err := database.DB.QueryRow(`
 		INSERT INTO alumni (
 			nim, nama, jurusan, angkatan, tahun_lulus, email,
 			no_telepon, alamat, created_at, updated_at, user_id
@@ -95,6 +96,7 @@ func (r *AlumniRepository) Create(a *model.Alumni) error {
 		a.NIM, a.Nama, a.Jurusan, a.Angkatan, a.TahunLulus,
 		a.Email, a.NoTelepon, a.Alamat, now, now, a.UserID,
 	).Scan(&a.ID)
+	return translateDuplicate(err)
 }
 
 func (r *AlumniRepository) Update(id int, a *model.Alumni) error {
@@ -110,8 +112,8 @@ func (r *AlumniRepository) Update(id int, a *model.Alumni) error {
 	return err
 }
 
-func (r *AlumniRepository) DeleteByID(id int) error {
-	res, err := database.DB.Exec(`
+func (r *AlumniRepository) DeleteByID(ctx context.Context, id int) error {
+	res, err := conn(ctx).ExecContext(ctx, `
 		UPDATE alumni SET deleted_at = NOW()
 		WHERE id = $1 AND deleted_at IS NULL
 	`, id)
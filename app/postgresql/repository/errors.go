@@ -0,0 +1,49 @@
+package repository
+
+import (
+	"errors"
+
+	"github.com/lib/pq"
+)
+
+// ErrConflict adalah induk semua *ConflictError; pakai errors.Is(err, ErrConflict)
+var ErrConflict = errors.New("duplicate value")
+
+// ConflictError dikembalikan Create jika nilai bentrok dengan unique index
+// (username, email, nim). Field adalah nama field yang bentrok.
+type ConflictError struct {
+	Field string
+}
+
+func (e *ConflictError) Error() string {
+	return e.Field + " already exists"
+}
+
+func (e *ConflictError) Unwrap() error {
+	return ErrConflict
+}
+
+// uniqueConstraintField memetakan nama index/constraint unik (lihat database/postgresql/migrations) ke field-nya
+var uniqueConstraintField = map[string]string{
+	"users_username_lower_key":  "username",
+	"users_email_lower_key":     "email",
+	"alumni_nim_lower_key":      "nim",
+	"alumni_user_id_active_key": "user_id",
+	"users_username_key":        "username",
+	"users_email_key":           "email",
+	"alumni_nim_key":            "nim",
+}
+
+// translateDuplicate mengubah unique_violation (23505) menjadi *ConflictError;
+// error lain dikembalikan apa adanya
+func translateDuplicate(err error) error {
+	var pqErr *pq.Error
+	if !errors.As(err, &pqErr) || pqErr.Code != "23505" {
+		return err
+	}
+	field, ok := uniqueConstraintField[pqErr.Constraint]
+	if !ok {
+		field = pqErr.Constraint
+	}
+	return &ConflictError{Field: field}
+}
@@ -3,6 +3,7 @@ package repository
 import (
 	"alumni-app/app/postgresql/model"
 	"alumni-app/database/postgresql"
+	"context"
 	"database/sql"
 	"time"
 )
@@ -155,6 +156,18 @@ func (r *PekerjaanRepository) Delete(id, userID int, isAdmin bool) error {
 	return nil
 }
 
+// SoftDeleteByAlumniID memindahkan semua pekerjaan aktif milik alumni ke trash
+func (r *PekerjaanRepository) SoftDeleteByAlumniID(ctx context.Context, alumniID int) (int64, error) {
+	res, err := conn(ctx).ExecContext(ctx, `
+		UPDATE pekerjaan SET deleted_at = NOW()
+		WHERE alumni_id = $1 AND deleted_at IS NULL
+	`, alumniID)
+	if err != nil {
+		return 0, err
+	}
+	return res.RowsAffected()
+}
+
 func (r *PekerjaanRepository) GetTrashed(userID int, isAdmin bool) ([]model.PekerjaanAlumni, error) {
 	query := `
 		SELECT p.id, p.alumni_id, p.nama_perusahaan, p.posisi_jabatan, p.bidang_industri, p.lokasi_kerja,
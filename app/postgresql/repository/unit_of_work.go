@@ -0,0 +1,60 @@
+package repository
+
+import (
+	"alumni-app/database/postgresql"
+	"context"
+	"database/sql"
+)
+
+// UnitOfWork menjalankan beberapa query dalam satu sql.Tx. Repository yang dipanggil
+// dengan ctx milik fn memakai transaksi tersebut; jika fn mengembalikan error semuanya di-rollback.
+type UnitOfWork interface {
+	Do(ctx context.Context, fn func(ctx context.Context) error) error
+}
+
+type sqlUnitOfWork struct {
+	db *sql.DB
+}
+
+func NewUnitOfWork(db *sql.DB) UnitOfWork {
+	return &sqlUnitOfWork{db: db}
+}
+
+func (u *sqlUnitOfWork) Do(ctx context.Context, fn func(ctx context.Context) error) error {
+	// unit of work bersarang ikut transaksi yang sedang berjalan
+	if txFrom(ctx) != nil {
+		return fn(ctx)
+	}
+
+	tx, err := u.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	if err := fn(context.WithValue(ctx, txKey{}, tx)); err != nil {
+		tx.Rollback()
+		return err
+	}
+	return tx.Commit()
+}
+
+type txKey struct{}
+
+func txFrom(ctx context.Context) *sql.Tx {
+	tx, _ := ctx.Value(txKey{}).(*sql.Tx)
+	return tx
+}
+
+// querier adalah method yang sama-sama dimiliki *sql.DB dan *sql.Tx
+type querier interface {
+	ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error)
+	QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error)
+	QueryRowContext(ctx context.Context, query string, args ...interface{}) *sql.Row
+}
+
+// conn mengembalikan transaksi milik ctx, atau koneksi biasa jika tidak sedang dalam unit of work
+func conn(ctx context.Context) querier {
+	if tx := txFrom(ctx); tx != nil {
+		return tx
+	}
+	return database.DB
+}
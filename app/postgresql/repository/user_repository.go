@@ -39,7 +39,7 @@ func (r *userRepository) GetByUsername(username string) (model.User, error) {
 	row := database.DB.QueryRow(`
 		SELECT id, username, email, password_hash, role, created_at
 		FROM users
-		WHERE username = $1
+		WHERE LOWER(username) = LOWER($1)
 	`, username)
 
 	err := row.Scan(&u.ID, &u.Username, &u.Email, &u.PasswordHash, &u.Role, &u.CreatedAt)
@@ -51,6 +51,7 @@ func (r *userRepository) GetByUsername(username string) (model.User, error) {
 
 func (r *userRepository) Create(user *model.User) error {
     query := `INSERT INTO users (username, email, password_hash, role) VALUES ($1, $2, $3, $4) RETURNING id`
-    return database.DB.QueryRow(query, user.Username, user.Email, user.PasswordHash, user.Role).Scan(&user.ID)
+    err := database.DB.QueryRow(query, user.Username, user.Email, user.PasswordHash, user.Role).Scan(&user.ID)
+    return translateDuplicate(err)
 }
 
@@ -3,17 +3,21 @@ package service
 import (
 	"alumni-app/app/postgresql/model"
 	"alumni-app/app/postgresql/repository"
+	"context"
 	"database/sql"
+	"errors"
 	"strconv"
 	"github.com/gofiber/fiber/v2"
 )
 
 type AlumniService struct {
-	repo *repository.AlumniRepository
+	repo          *repository.AlumniRepository
+	pekerjaanRepo *repository.PekerjaanRepository
+	uow           repository.UnitOfWork
 }
 
-func NewAlumniService(r *repository.AlumniRepository) *AlumniService {
-	return &AlumniService{repo: r}
+func NewAlumniService(r *repository.AlumniRepository, pekerjaanRepo *repository.PekerjaanRepository, uow repository.UnitOfWork) *AlumniService {
+	return &AlumniService{repo: r, pekerjaanRepo: pekerjaanRepo, uow: uow}
 }
 
 func (s *AlumniService) GetAll(c *fiber.Ctx) error {
@@ -91,6 +95,10 @@ func (s *AlumniService) Create(c *fiber.Ctx) error {
 	}
 
 	if err := s.repo.Create(&a); err != nil {
+		var conflict *repository.ConflictError
+		if errors.As(err, &conflict) {
+			return c.Status(409).JSON(fiber.Map{"error": conflict.Field + " sudah digunakan", "field": conflict.Field})
+		}
 		return c.Status(500).JSON(fiber.Map{"error": err.Error()})
 	}
 
@@ -146,7 +154,15 @@ func (s *AlumniService) Delete(c *fiber.Ctx) error {
 		return c.Status(403).JSON(fiber.Map{"error": "Hanya admin yang dapat menghapus data alumni"})
 	}
 
-	if err := s.repo.DeleteByID(id); err != nil {
+	// alumni dan pekerjaannya masuk trash bersama dalam satu transaksi
+	err = s.uow.Do(c.UserContext(), func(ctx context.Context) error {
+		if err := s.repo.DeleteByID(ctx, id); err != nil {
+			return err
+		}
+		_, err := s.pekerjaanRepo.SoftDeleteByAlumniID(ctx, id)
+		return err
+	})
+	if err != nil {
 		if err == sql.ErrNoRows {
 			return c.Status(404).JSON(fiber.Map{"error": "Data alumni tidak ditemukan"})
 		}
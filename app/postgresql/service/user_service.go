@@ -4,6 +4,7 @@ import (
 	"alumni-app/app/postgresql/model"
 	"alumni-app/app/postgresql/repository"
 	"alumni-app/utils/postgresql"
+	"errors"
 
 	"github.com/gofiber/fiber/v2"
 
@@ -25,12 +26,8 @@ func (s *UserService) Login(c *fiber.Ctx) error {
 	}
 
 	user, err := s.repo.GetByUsername(req.Username)
-	if err != nil {
-		return c.Status(401).JSON(fiber.Map{"error": "User tidak ditemukan"})
-	}
-
-	if !utils.CheckPassword(user.PasswordHash, req.PasswordHash) {
-		return c.Status(401).JSON(fiber.Map{"error": "Password salah"})
+	if err != nil || !utils.CheckPassword(user.PasswordHash, req.PasswordHash) {
+		return c.Status(401).JSON(fiber.Map{"error": "Username atau password salah"})
 	}
 
 	token, err := utils.GenerateToken(user.ID, user.Username, user.Role)
@@ -56,19 +53,18 @@ func (s *UserService) Register(c *fiber.Ctx) error {
 		return c.Status(500).JSON(fiber.Map{"error": "Gagal meng-hash password"})
 	}
 
-	role := req.Role
-	if role == "" {
-		role = "user"
-	}
-
 	user := &model.User{
 		Username:     req.Username,
 		Email:        req.Email,
 		PasswordHash: string(hashedPassword),
-		Role:         role,
+		Role:         "user",
 	}
 
 	if err = s.repo.Create(user); err != nil {
+		var conflict *repository.ConflictError
+		if errors.As(err, &conflict) {
+			return c.Status(409).JSON(fiber.Map{"error": conflict.Field + " sudah digunakan", "field": conflict.Field})
+		}
 		return c.Status(500).JSON(fiber.Map{"error": err.Error()})
 	}
 
@@ -0,0 +1,173 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"text/tabwriter"
+	"time"
+
+	"alumni-app/app/mongodb/model"
+	svc "alumni-app/app/mongodb/service"
+	"alumni-app/config"
+	dbmongo "alumni-app/database/mongodb"
+	dbpg "alumni-app/database/postgresql"
+)
+
+// runReconcile menjalankan `go run . reconcile [-orphans=...] [-dangling=...] [-legacy=...] [-min-age=...]`.
+// Tanpa flag hanya mencetak laporan (dry run).
+func runReconcile(fileService svc.FileService, args []string) int {
+	fs := flag.NewFlagSet("reconcile", flag.ExitOnError)
+	orphans := fs.String("orphans", model.ReconcileDryRun, "aksi untuk object tanpa record: report, delete, quarantine, register")
+	dangling := fs.String("dangling", model.ReconcileDryRun, "aksi untuk record tanpa object: report, delete, quarantine")
+	legacy := fs.String("legacy", model.ReconcileDryRun, "aksi untuk foto alumni dengan path statis /uploads/foto: report, relink")
+	minAge := fs.Duration("min-age", config.LoadUploadConfig().ReconcileMinAge, "lewati object/record yang lebih baru dari durasi ini")
+	fs.Parse(args)
+
+	report, err := fileService.ReconcileStorage(context.Background(), model.ReconcileOptions{
+		Orphans:  *orphans,
+		Dangling: *dangling,
+		Legacy:   *legacy,
+		MinAge:   *minAge,
+	})
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "reconcile:", err)
+		return 1
+	}
+
+	enc := json.NewEncoder(os.Stdout)
+	enc.SetIndent("", "  ")
+	enc.Encode(report)
+
+	fmt.Fprintf(os.Stderr, "%d objects, %d records: %d orphans, %d dangling, %d legacy foto, %d skipped (newer than %s)\n",
+		report.ScannedObjects, report.ScannedRecords, len(report.Orphans), len(report.Dangling), len(report.LegacyRefs), report.SkippedRecent, *minAge)
+	for _, item := range append(append(report.Orphans, report.Dangling...), report.LegacyRefs...) {
+		if item.Error != "" {
+			return 1
+		}
+	}
+	return 0
+}
+
+// runMigrate menjalankan `go run . migrate [up|down|status] [-db=mongo|postgres]`.
+// down membatalkan satu migrasi terakhir kecuali diberi -steps.
+func runMigrate(args []string) int {
+	action := "up"
+	if len(args) > 0 && args[0] != "" && args[0][0] != '-' {
+		action, args = args[0], args[1:]
+	}
+	fs := flag.NewFlagSet("migrate "+action, flag.ExitOnError)
+	driver := fs.String("db", "mongo", "database yang dimigrasikan: mongo atau postgres")
+	steps := fs.Int("steps", 1, "jumlah migrasi yang dibatalkan (khusus down)")
+	fs.Parse(args)
+
+	m, err := newSchemaMigrator(*driver)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "migrate:", err)
+		return 2
+	}
+
+	ctx := context.Background()
+	switch action {
+	case "up":
+		var n int
+		n, err = m.up(ctx)
+		if err == nil && n == 0 {
+			fmt.Println("Tidak ada migrasi baru")
+		}
+	case "down":
+		if *steps < 1 {
+			fmt.Fprintln(os.Stderr, "migrate: -steps must be >= 1")
+			return 2
+		}
+		_, err = m.down(ctx, *steps)
+	case "status":
+		var list []migrationRow
+		list, err = m.status(ctx)
+		w := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+		fmt.Fprintln(w, "VERSION\tNAME\tAPPLIED AT")
+		for _, st := range list {
+			applied := "pending"
+			if st.appliedAt != nil {
+				applied = st.appliedAt.Local().Format(time.RFC3339)
+			}
+			fmt.Fprintf(w, "%d\t%s\t%s\n", st.version, st.name, applied)
+		}
+		w.Flush()
+	default:
+		fmt.Fprintf(os.Stderr, "migrate: unknown action %q (up, down, status)\n", action)
+		return 2
+	}
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "migrate:", err)
+		return 1
+	}
+	return 0
+}
+
+type migrationRow struct {
+	version   int
+	name      string
+	appliedAt *time.Time
+}
+
+// schemaMigrator menyatukan migrator MongoDB dan PostgreSQL untuk subcommand migrate
+type schemaMigrator struct {
+	up     func(context.Context) (int, error)
+	down   func(context.Context, int) (int, error)
+	status func(context.Context) ([]migrationRow, error)
+}
+
+// newSchemaMigrator membuka koneksi ke database yang dipilih dan menyiapkan migratornya
+func newSchemaMigrator(driver string) (*schemaMigrator, error) {
+	switch driver {
+	case "mongo":
+		dbmongo.ConnectMongo()
+		m := dbmongo.NewMigrator(dbmongo.DB)
+		return &schemaMigrator{
+			up: func(ctx context.Context) (int, error) {
+				done, err := m.Up(ctx)
+				return len(done), err
+			},
+			down: func(ctx context.Context, steps int) (int, error) {
+				done, err := m.Down(ctx, steps)
+				return len(done), err
+			},
+			status: func(ctx context.Context) ([]migrationRow, error) {
+				list, err := m.Status(ctx)
+				rows := make([]migrationRow, len(list))
+				for i, st := range list {
+					rows[i] = migrationRow{st.Version, st.Name, st.AppliedAt}
+				}
+				return rows, err
+			},
+		}, nil
+	case "postgres":
+		dbpg.ConnectDB()
+		m, err := dbpg.NewMigrator(dbpg.DB)
+		if err != nil {
+			return nil, err
+		}
+		return &schemaMigrator{
+			up: func(ctx context.Context) (int, error) {
+				done, err := m.Up(ctx)
+				return len(done), err
+			},
+			down: func(ctx context.Context, steps int) (int, error) {
+				done, err := m.Down(ctx, steps)
+				return len(done), err
+			},
+			status: func(ctx context.Context) ([]migrationRow, error) {
+				list, err := m.Status(ctx)
+				rows := make([]migrationRow, len(list))
+				for i, st := range list {
+					rows[i] = migrationRow{st.Version, st.Name, st.AppliedAt}
+				}
+				return rows, err
+			},
+		}, nil
+	}
+	return nil, fmt.Errorf("unknown database %q (mongo, postgres)", driver)
+}
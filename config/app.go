@@ -18,10 +18,14 @@ func NewApp() *fiber.App {
 	// 🟩 Tambahkan CORS agar Swagger UI bisa fetch API tanpa error
 	app.Use(cors.New(cors.Config{
 		AllowOrigins: "*", // boleh ubah jadi "http://localhost:3000" kalau mau lebih aman
-		AllowMethods: "GET,POST,PUT,DELETE,OPTIONS",
-		AllowHeaders: "Origin, Content-Type, Accept, Authorization",
+		AllowMethods: "GET,HEAD,POST,PUT,PATCH,DELETE,OPTIONS",
+		AllowHeaders: "Origin, Content-Type, Accept, Authorization, If-Match, If-None-Match, Range, Upload-Offset, Upload-Checksum",
+		ExposeHeaders: "ETag, Location, Content-Range, Upload-Offset, Upload-Length, Upload-Expires",
 	}))
 
+	// context per request: query database berhenti saat klien memutus koneksi
+	app.Use(requestContext)
+
 	// 🟦 Logging middleware tetap dipertahankan
 	app.Use(logger.New(logger.Config{
 		Format: "[${time}] ${status} - ${latency} ${method} ${path}\n",
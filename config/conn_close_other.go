@@ -0,0 +1,10 @@
+//go:build !unix
+
+package config
+
+import "net"
+
+// watchConnClose tidak didukung di platform ini; context request tetap dibatalkan saat request selesai
+func watchConnClose(conn net.Conn, onClose func()) (stop func()) {
+	return func() {}
+}
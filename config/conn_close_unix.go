@@ -0,0 +1,52 @@
+//go:build unix
+
+package config
+
+import (
+	"errors"
+	"net"
+	"syscall"
+	"time"
+)
+
+// watchConnClose memanggil onClose jika klien menutup koneksi selama handler berjalan.
+// Socket hanya diintip (MSG_PEEK) sehingga data request berikutnya tidak ikut terbaca.
+// stop harus dipanggil sebelum handler selesai agar koneksi bisa dipakai request berikutnya.
+func watchConnClose(conn net.Conn, onClose func()) (stop func()) {
+	sc, ok := conn.(syscall.Conn)
+	if !ok {
+		// mis. koneksi TLS atau koneksi palsu app.Test
+		return func() {}
+	}
+	raw, err := sc.SyscallConn()
+	if err != nil {
+		return func() {}
+	}
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		buf := make([]byte, 1)
+		closed := false
+		raw.Read(func(fd uintptr) bool {
+			n, _, err := syscall.Recvfrom(int(fd), buf, syscall.MSG_PEEK|syscall.MSG_DONTWAIT)
+			if errors.Is(err, syscall.EAGAIN) || errors.Is(err, syscall.EWOULDBLOCK) || errors.Is(err, syscall.EINTR) {
+				// belum ada apa-apa: tunggu sampai socket bisa dibaca
+				return false
+			}
+			// n == 0 tanpa error berarti EOF; data (request pipelined) bukan tanda putus
+			closed = err != nil || n == 0
+			return true
+		})
+		if closed {
+			onClose()
+		}
+	}()
+
+	return func() {
+		// deadline lampau membangunkan raw.Read yang masih menunggu
+		conn.SetReadDeadline(time.Unix(1, 0))
+		<-done
+		conn.SetReadDeadline(time.Time{})
+	}
+}
@@ -0,0 +1,22 @@
+package config
+
+import "time"
+
+// DBTimeoutConfig mengatur batas waktu query MongoDB per jenis operasi.
+// Batas ini ditambahkan di atas context request, bukan menggantikannya.
+type DBTimeoutConfig struct {
+	// Read untuk mengambil satu dokumen atau satu halaman data
+	Read time.Duration
+	// Write untuk insert, update, dan delete
+	Write time.Duration
+	// Scan untuk membaca seluruh collection atau agregasi (reconcile, statistik, purge)
+	Scan time.Duration
+}
+
+func LoadDBTimeoutConfig() DBTimeoutConfig {
+	return DBTimeoutConfig{
+		Read:  GetEnvDuration("DB_READ_TIMEOUT", 5*time.Second),
+		Write: GetEnvDuration("DB_WRITE_TIMEOUT", 10*time.Second),
+		Scan:  GetEnvDuration("DB_SCAN_TIMEOUT", time.Minute),
+	}
+}
@@ -1,8 +1,12 @@
 package config
 
 import (
+	"crypto/rand"
+	"encoding/hex"
 	"log"
 	"os"
+	"strconv"
+	"time"
 
 	"github.com/joho/godotenv"
 )
@@ -21,3 +25,45 @@ func GetEnv(key, fallback string) string {
 	}
 	return value
 }
+
+// GetEnvInt membaca env sebagai integer, fallback jika kosong atau tidak valid
+func GetEnvInt(key string, fallback int) int {
+	value, err := strconv.Atoi(os.Getenv(key))
+	if err != nil {
+		return fallback
+	}
+	return value
+}
+
+// GetEnvDuration membaca env dengan format time.ParseDuration (mis. "15m"), fallback jika kosong atau tidak valid
+func GetEnvDuration(key string, fallback time.Duration) time.Duration {
+	value, err := time.ParseDuration(os.Getenv(key))
+	if err != nil {
+		return fallback
+	}
+	return value
+}
+
+// GetEnvBool membaca env sebagai boolean ("true", "1", ...), fallback jika kosong atau tidak valid
+func GetEnvBool(key string, fallback bool) bool {
+	value, err := strconv.ParseBool(os.Getenv(key))
+	if err != nil {
+		return fallback
+	}
+	return value
+}
+
+// GetEnvSecret membaca secret penandatangan dari env. Jika kosong dibuat secret acak per proses
+// (bukan konstanta bersama yang bisa dibaca siapa pun dari repo), sehingga token/URL yang
+// ditandatangani tidak berlaku lagi setelah restart dan tidak valid antar instance.
+func GetEnvSecret(key string) string {
+	if value := os.Getenv(key); value != "" {
+		return value
+	}
+	buf := make([]byte, 32)
+	if _, err := rand.Read(buf); err != nil {
+		log.Fatalf("gagal membuat secret acak untuk %s: %v", key, err)
+	}
+	log.Printf("⚠️  %s tidak diset, memakai secret acak per proses; set di .env untuk production", key)
+	return hex.EncodeToString(buf)
+}
@@ -0,0 +1,50 @@
+package config
+
+import "time"
+
+// MailConfig memilih cara pengiriman email ke user ("file", "log", atau "smtp")
+type MailConfig struct {
+	Sender string
+	// File adalah tujuan sender "file"
+	File string
+
+	SMTPHost     string
+	SMTPPort     int
+	SMTPUsername string
+	SMTPPassword string
+	SMTPTimeout  time.Duration
+	From         string
+}
+
+func LoadMailConfig() MailConfig {
+	return MailConfig{
+		Sender:       GetEnv("MAIL_SENDER", "file"),
+		File:         GetEnv("NOTIFIER_FILE", "logs/notifications.log"),
+		SMTPHost:     GetEnv("SMTP_HOST", "localhost"),
+		SMTPPort:     GetEnvInt("SMTP_PORT", 587),
+		SMTPUsername: GetEnv("SMTP_USERNAME", ""),
+		SMTPPassword: GetEnv("SMTP_PASSWORD", ""),
+		SMTPTimeout:  GetEnvDuration("SMTP_TIMEOUT", 10*time.Second),
+		From:         GetEnv("MAIL_FROM", "Alumni-App <no-reply@localhost>"),
+	}
+}
+
+// EmailVerificationConfig mengatur verifikasi email akun baru
+type EmailVerificationConfig struct {
+	// Required = true menolak login user yang emailnya belum diverifikasi
+	Required bool
+	TokenTTL time.Duration
+	// Secret dipakai untuk menandatangani token verifikasi; acak per proses jika tidak diset
+	Secret string
+	// LinkURL adalah alamat GET /verify-email yang dikirim di email, token ditambahkan sebagai query
+	LinkURL string
+}
+
+func LoadEmailVerificationConfig() EmailVerificationConfig {
+	return EmailVerificationConfig{
+		Required: GetEnvBool("EMAIL_VERIFICATION_REQUIRED", false),
+		TokenTTL: GetEnvDuration("EMAIL_VERIFICATION_TTL", 24*time.Hour),
+		Secret:   GetEnvSecret("EMAIL_VERIFICATION_SECRET"),
+		LinkURL:  GetEnv("EMAIL_VERIFICATION_URL", "http://localhost:3000/api/v1/verify-email"),
+	}
+}
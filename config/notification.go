@@ -0,0 +1,23 @@
+package config
+
+import "time"
+
+// NotificationConfig mengatur worker pengiriman email notifikasi
+type NotificationConfig struct {
+	// MaxAttempts adalah jumlah percobaan kirim sebelum email ditandai gagal
+	MaxAttempts int
+	// RetryBackoff adalah jeda sebelum percobaan kedua; jeda berikutnya berlipat dua
+	RetryBackoff time.Duration
+	// Lease adalah batas waktu satu pengiriman sebelum email boleh diambil worker lain
+	Lease          time.Duration
+	WorkerInterval time.Duration
+}
+
+func LoadNotificationConfig() NotificationConfig {
+	return NotificationConfig{
+		MaxAttempts:    GetEnvInt("NOTIFICATION_EMAIL_MAX_ATTEMPTS", 5),
+		RetryBackoff:   GetEnvDuration("NOTIFICATION_EMAIL_RETRY_BACKOFF", time.Minute),
+		Lease:          GetEnvDuration("NOTIFICATION_EMAIL_LEASE", 5*time.Minute),
+		WorkerInterval: GetEnvDuration("NOTIFICATION_WORKER_INTERVAL", 30*time.Second),
+	}
+}
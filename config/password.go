@@ -0,0 +1,24 @@
+package config
+
+import "time"
+
+// PasswordConfig mengatur aturan kekuatan password dan masa berlaku token reset
+type PasswordConfig struct {
+	MinLength     int
+	RequireUpper  bool
+	RequireLower  bool
+	RequireDigit  bool
+	RequireSymbol bool
+	ResetTokenTTL time.Duration
+}
+
+func LoadPasswordConfig() PasswordConfig {
+	return PasswordConfig{
+		MinLength:     GetEnvInt("PASSWORD_MIN_LENGTH", 8),
+		RequireUpper:  GetEnvBool("PASSWORD_REQUIRE_UPPER", false),
+		RequireLower:  GetEnvBool("PASSWORD_REQUIRE_LOWER", true),
+		RequireDigit:  GetEnvBool("PASSWORD_REQUIRE_DIGIT", true),
+		RequireSymbol: GetEnvBool("PASSWORD_REQUIRE_SYMBOL", false),
+		ResetTokenTTL: GetEnvDuration("PASSWORD_RESET_TTL", 30*time.Minute),
+	}
+}
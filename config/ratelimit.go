@@ -0,0 +1,29 @@
+package config
+
+import "time"
+
+// RateLimitConfig mengatur batas request /login & /register, lockout akun, dan batas klaim data alumni
+type RateLimitConfig struct {
+	// IPMax adalah jumlah request maksimal per IP dalam IPWindow
+	IPMax    int
+	IPWindow time.Duration
+	// MaxFailures adalah jumlah login gagal per username sebelum akun dikunci selama Lockout
+	MaxFailures int
+	Lockout     time.Duration
+	// ClaimMaxFailures adalah jumlah klaim alumni gagal per user sebelum diblokir selama ClaimLockout,
+	// mencegah menebak-nebak NIM
+	ClaimMaxFailures int
+	ClaimLockout     time.Duration
+}
+
+func LoadRateLimitConfig() RateLimitConfig {
+	return RateLimitConfig{
+		IPMax:       GetEnvInt("RATE_LIMIT_IP_MAX", 20),
+		IPWindow:    GetEnvDuration("RATE_LIMIT_IP_WINDOW", time.Minute),
+		MaxFailures: GetEnvInt("LOGIN_MAX_FAILURES", 5),
+		Lockout:     GetEnvDuration("LOGIN_LOCKOUT_DURATION", 15*time.Minute),
+
+		ClaimMaxFailures: GetEnvInt("CLAIM_MAX_FAILURES", 5),
+		ClaimLockout:     GetEnvDuration("CLAIM_LOCKOUT_DURATION", time.Hour),
+	}
+}
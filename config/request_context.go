@@ -0,0 +1,56 @@
+package config
+
+import (
+	"context"
+	"io"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// cancelLocal menyimpan cancel context request untuk SendStream
+const cancelLocal = "request_cancel"
+
+// requestContext memberi setiap request context yang dibatalkan saat koneksi klien terputus
+// atau request selesai, sehingga query Mongo milik request yang ditinggalkan ikut berhenti.
+// Tanpa ini UserContext() di Fiber v2 selalu context.Background().
+func requestContext(c *fiber.Ctx) error {
+	ctx, cancel := context.WithCancel(c.UserContext())
+	c.SetUserContext(ctx)
+	c.Locals(cancelLocal, cancel)
+
+	stop := watchConnClose(c.Context().Conn(), cancel)
+	defer stop()
+
+	err := c.Next()
+	// body stream dari SendStream (mis. unduhan dari S3) masih dibaca memakai context ini
+	// setelah handler selesai; context-nya dibatalkan saat fasthttp menutup stream
+	if _, ok := c.Response().BodyStream().(*cancelOnClose); !ok {
+		cancel()
+	}
+	return err
+}
+
+// SendStream mengirim r sebagai body response. Context request tetap hidup sampai stream selesai
+// dikirim atau gagal ditulis karena klien terputus, lalu dibatalkan saat fasthttp menutup stream.
+// Reader yang dibuka dengan c.UserContext() harus dikirim lewat sini, bukan c.SendStream.
+func SendStream(c *fiber.Ctx, r io.Reader, size int) error {
+	cancel, ok := c.Locals(cancelLocal).(context.CancelFunc)
+	if !ok {
+		return c.SendStream(r, size)
+	}
+	return c.SendStream(&cancelOnClose{Reader: r, cancel: cancel}, size)
+}
+
+// cancelOnClose menutup reader asli lalu membatalkan context request
+type cancelOnClose struct {
+	io.Reader
+	cancel context.CancelFunc
+}
+
+func (r *cancelOnClose) Close() error {
+	defer r.cancel()
+	if c, ok := r.Reader.(io.Closer); ok {
+		return c.Close()
+	}
+	return nil
+}
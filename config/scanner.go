@@ -0,0 +1,18 @@
+package config
+
+import "time"
+
+// ScannerConfig memilih pemindai malware untuk file upload ("none" atau "clamd")
+type ScannerConfig struct {
+	Backend   string
+	ClamdAddr string
+	Timeout   time.Duration
+}
+
+func LoadScannerConfig() ScannerConfig {
+	return ScannerConfig{
+		Backend:   GetEnv("SCANNER", "none"),
+		ClamdAddr: GetEnv("CLAMD_ADDR", "localhost:3310"),
+		Timeout:   GetEnvDuration("CLAMD_TIMEOUT", 30*time.Second),
+	}
+}
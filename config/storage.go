@@ -0,0 +1,34 @@
+package config
+
+// StorageConfig memilih backend penyimpanan file upload ("local" atau "s3")
+type StorageConfig struct {
+	Backend string
+
+	LocalRoot          string
+	LocalSignedBaseURL string
+	// SigningSecret dipakai untuk menandatangani URL sementara backend local; acak per proses jika tidak diset
+	SigningSecret string
+
+	S3Endpoint  string
+	S3Region    string
+	S3Bucket    string
+	S3AccessKey string
+	S3SecretKey string
+	// S3PathStyle = true untuk MinIO / server S3-compatible yang tidak mendukung virtual-host bucket
+	S3PathStyle bool
+}
+
+func LoadStorageConfig() StorageConfig {
+	return StorageConfig{
+		Backend:            GetEnv("STORAGE_BACKEND", "local"),
+		LocalRoot:          GetEnv("STORAGE_LOCAL_ROOT", "./uploads"),
+		LocalSignedBaseURL: GetEnv("STORAGE_LOCAL_SIGNED_BASE_URL", "/api/v1/files/signed"),
+		SigningSecret:      GetEnvSecret("STORAGE_SIGNING_SECRET"),
+		S3Endpoint:         GetEnv("S3_ENDPOINT", "http://localhost:9000"),
+		S3Region:           GetEnv("S3_REGION", "us-east-1"),
+		S3Bucket:           GetEnv("S3_BUCKET", "alumni-app"),
+		S3AccessKey:        GetEnv("S3_ACCESS_KEY", ""),
+		S3SecretKey:        GetEnv("S3_SECRET_KEY", ""),
+		S3PathStyle:        GetEnvBool("S3_PATH_STYLE", true),
+	}
+}
@@ -0,0 +1,22 @@
+package config
+
+import "time"
+
+// UploadConfig mengatur upload bertahap (resumable) untuk dokumen besar
+type UploadConfig struct {
+	MaxDocumentSize int64
+	MaxChunkSize    int64
+	SessionTTL      time.Duration
+	// ReconcileMinAge: object dan record yang lebih baru dari ini dilewati rekonsiliasi
+	// agar upload yang sedang berjalan tidak dianggap yatim atau dangling
+	ReconcileMinAge time.Duration
+}
+
+func LoadUploadConfig() UploadConfig {
+	return UploadConfig{
+		MaxDocumentSize: int64(GetEnvInt("UPLOAD_MAX_DOCUMENT_SIZE", 20*1024*1024)),
+		MaxChunkSize:    int64(GetEnvInt("UPLOAD_MAX_CHUNK_SIZE", 2*1024*1024)),
+		SessionTTL:      GetEnvDuration("UPLOAD_SESSION_TTL", 24*time.Hour),
+		ReconcileMinAge: GetEnvDuration("RECONCILE_MIN_AGE", time.Hour),
+	}
+}
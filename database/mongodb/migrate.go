@@ -0,0 +1,205 @@
+package database
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"sort"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// Migration adalah satu langkah perubahan skema (index, validator) yang bisa dibatalkan
+type Migration struct {
+	Version int
+	Name    string
+	Up      func(ctx context.Context, db *mongo.Database) error
+	Down    func(ctx context.Context, db *mongo.Database) error
+}
+
+// MigrationStatus adalah status satu migrasi; AppliedAt nil berarti belum dijalankan
+type MigrationStatus struct {
+	Version   int        `bson:"_id" json:"version"`
+	Name      string     `bson:"name" json:"name"`
+	AppliedAt *time.Time `bson:"applied_at,omitempty" json:"applied_at,omitempty"`
+}
+
+// Migrator menjalankan daftar migrasi dan mencatatnya di collection schema_migrations
+type Migrator struct {
+	DB         *mongo.Database
+	Migrations []Migration
+	Timeout    time.Duration // batas waktu tiap migrasi
+}
+
+func NewMigrator(db *mongo.Database) *Migrator {
+	return &Migrator{DB: db, Migrations: Migrations, Timeout: time.Minute}
+}
+
+func (m *Migrator) col() *mongo.Collection {
+	return m.DB.Collection("schema_migrations")
+}
+
+// Up menjalankan semua migrasi yang belum tercatat, urut dari versi terkecil
+func (m *Migrator) Up(ctx context.Context) ([]Migration, error) {
+	applied, err := m.applied(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var done []Migration
+	for _, mig := range m.sorted() {
+		if _, ok := applied[mig.Version]; ok {
+			continue
+		}
+		if err := m.run(ctx, mig.Up); err != nil {
+			return done, fmt.Errorf("migration %d_%s: %w", mig.Version, mig.Name, err)
+		}
+		_, err := m.col().InsertOne(ctx, MigrationStatus{Version: mig.Version, Name: mig.Name, AppliedAt: ptrTime(time.Now())})
+		if err != nil && !mongo.IsDuplicateKeyError(err) {
+			return done, err
+		}
+		log.Printf("✅ Migrasi %d_%s diterapkan\n", mig.Version, mig.Name)
+		done = append(done, mig)
+	}
+	return done, nil
+}
+
+// Down membatalkan steps migrasi terakhir yang sudah diterapkan
+func (m *Migrator) Down(ctx context.Context, steps int) ([]Migration, error) {
+	applied, err := m.applied(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	sorted := m.sorted()
+	var done []Migration
+	for i := len(sorted) - 1; i >= 0 && len(done) < steps; i-- {
+		mig := sorted[i]
+		if _, ok := applied[mig.Version]; !ok {
+			continue
+		}
+		if err := m.run(ctx, mig.Down); err != nil {
+			return done, fmt.Errorf("rollback %d_%s: %w", mig.Version, mig.Name, err)
+		}
+		if _, err := m.col().DeleteOne(ctx, bson.M{"_id": mig.Version}); err != nil {
+			return done, err
+		}
+		log.Printf("↩️ Migrasi %d_%s dibatalkan\n", mig.Version, mig.Name)
+		done = append(done, mig)
+	}
+	return done, nil
+}
+
+// Status mengembalikan semua migrasi yang dikenal beserta waktu penerapannya
+func (m *Migrator) Status(ctx context.Context) ([]MigrationStatus, error) {
+	applied, err := m.applied(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var list []MigrationStatus
+	for _, mig := range m.sorted() {
+		st := MigrationStatus{Version: mig.Version, Name: mig.Name}
+		if rec, ok := applied[mig.Version]; ok {
+			st.AppliedAt = rec.AppliedAt
+		}
+		list = append(list, st)
+	}
+	return list, nil
+}
+
+func (m *Migrator) applied(ctx context.Context) (map[int]MigrationStatus, error) {
+	cur, err := m.col().Find(ctx, bson.M{})
+	if err != nil {
+		return nil, err
+	}
+	defer cur.Close(ctx)
+
+	var records []MigrationStatus
+	if err := cur.All(ctx, &records); err != nil {
+		return nil, err
+	}
+	applied := make(map[int]MigrationStatus, len(records))
+	for _, r := range records {
+		applied[r.Version] = r
+	}
+	return applied, nil
+}
+
+func (m *Migrator) sorted() []Migration {
+	list := append([]Migration(nil), m.Migrations...)
+	sort.Slice(list, func(i, j int) bool { return list[i].Version < list[j].Version })
+	return list
+}
+
+func (m *Migrator) run(ctx context.Context, fn func(context.Context, *mongo.Database) error) error {
+	if fn == nil {
+		return nil
+	}
+	ctx, cancel := context.WithTimeout(ctx, m.Timeout)
+	defer cancel()
+	return fn(ctx, m.DB)
+}
+
+// createIndexes membuat index pada collection; index dengan definisi yang sama tidak dibuat ulang
+func createIndexes(ctx context.Context, db *mongo.Database, coll string, models ...mongo.IndexModel) error {
+	_, err := db.Collection(coll).Indexes().CreateMany(ctx, models)
+	return err
+}
+
+// dropIndexes menghapus index berdasarkan nama; index yang sudah tidak ada diabaikan
+func dropIndexes(ctx context.Context, db *mongo.Database, coll string, names ...string) error {
+	for _, name := range names {
+		_, err := db.Collection(coll).Indexes().DropOne(ctx, name)
+		if err != nil && !isNamespaceOrIndexNotFound(err) {
+			return err
+		}
+	}
+	return nil
+}
+
+// setValidator memasang $jsonSchema dengan validationLevel moderate: dokumen lama yang
+// belum valid tetap bisa diubah, dokumen baru wajib valid. Collection dibuat jika belum ada.
+func setValidator(ctx context.Context, db *mongo.Database, coll string, schema bson.M) error {
+	validator := bson.M{"$jsonSchema": schema}
+	err := db.RunCommand(ctx, bson.D{
+		{Key: "collMod", Value: coll},
+		{Key: "validator", Value: validator},
+		{Key: "validationLevel", Value: "moderate"},
+		{Key: "validationAction", Value: "error"},
+	}).Err()
+	if isNamespaceOrIndexNotFound(err) {
+		return db.CreateCollection(ctx, coll, options.CreateCollection().
+			SetValidator(validator).
+			SetValidationLevel("moderate").
+			SetValidationAction("error"))
+	}
+	return err
+}
+
+// dropValidator menonaktifkan validasi dokumen pada collection
+func dropValidator(ctx context.Context, db *mongo.Database, coll string) error {
+	err := db.RunCommand(ctx, bson.D{
+		{Key: "collMod", Value: coll},
+		{Key: "validator", Value: bson.M{}},
+		{Key: "validationLevel", Value: "off"},
+	}).Err()
+	if isNamespaceOrIndexNotFound(err) {
+		return nil
+	}
+	return err
+}
+
+// isNamespaceOrIndexNotFound: NamespaceNotFound (26) atau IndexNotFound (27)
+func isNamespaceOrIndexNotFound(err error) bool {
+	var ce mongo.CommandError
+	return errors.As(err, &ce) && (ce.Code == 26 || ce.Code == 27)
+}
+
+func ptrTime(t time.Time) *time.Time {
+	return &t
+}
@@ -0,0 +1,33 @@
+package database
+
+import "testing"
+
+func TestMigrationsAreVersioned(t *testing.T) {
+	seen := map[int]bool{}
+	last := 0
+	for _, m := range Migrations {
+		if m.Version <= last {
+			t.Errorf("migration %d_%s: versions must be unique and ascending", m.Version, m.Name)
+		}
+		if m.Name == "" || m.Up == nil || m.Down == nil {
+			t.Errorf("migration %d: name, Up and Down are required", m.Version)
+		}
+		seen[m.Version] = true
+		last = m.Version
+	}
+	if len(seen) != len(Migrations) {
+		t.Error("duplicate migration versions")
+	}
+}
+
+func TestMigratorSorted(t *testing.T) {
+	m := &Migrator{Migrations: []Migration{{Version: 3}, {Version: 1}, {Version: 2}}}
+	for i, mig := range m.sorted() {
+		if mig.Version != i+1 {
+			t.Fatalf("sorted()[%d] = %d", i, mig.Version)
+		}
+	}
+	if m.Migrations[0].Version != 3 {
+		t.Error("sorted must not reorder the original list")
+	}
+}
@@ -0,0 +1,379 @@
+package database
+
+import (
+	"context"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// Migrations adalah daftar migrasi skema MongoDB. Jangan mengubah migrasi yang sudah
+// dirilis; tambahkan versi baru di akhir daftar.
+var Migrations = []Migration{
+	{
+		Version: 1,
+		Name:    "users_unique_username",
+		Up: func(ctx context.Context, db *mongo.Database) error {
+			return createIndexes(ctx, db, "users", mongo.IndexModel{
+				Keys:    bson.D{{Key: "username", Value: 1}},
+				Options: options.Index().SetUnique(true),
+			})
+		},
+		Down: func(ctx context.Context, db *mongo.Database) error {
+			return dropIndexes(ctx, db, "users", "username_1")
+		},
+	},
+	{
+		Version: 2,
+		Name:    "alumni_unique_nim_user_id",
+		Up: func(ctx context.Context, db *mongo.Database) error {
+			return createIndexes(ctx, db, "alumni",
+				mongo.IndexModel{
+					Keys:    bson.D{{Key: "nim", Value: 1}},
+					Options: options.Index().SetUnique(true),
+				},
+				// alumni yang belum terhubung ke akun menyimpan user_id kosong (ObjectID nol)
+				mongo.IndexModel{
+					Keys: bson.D{{Key: "user_id", Value: 1}},
+					Options: options.Index().SetUnique(true).
+						SetPartialFilterExpression(bson.M{"user_id": bson.M{"$gt": primitive.NilObjectID}}),
+				},
+			)
+		},
+		Down: func(ctx context.Context, db *mongo.Database) error {
+			return dropIndexes(ctx, db, "alumni", "nim_1", "user_id_1")
+		},
+	},
+	{
+		Version: 3,
+		Name:    "pekerjaan_alumni_id",
+		Up: func(ctx context.Context, db *mongo.Database) error {
+			if err := createIndexes(ctx, db, "pekerjaan", mongo.IndexModel{
+				Keys: bson.D{{Key: "alumni_id", Value: 1}},
+			}); err != nil {
+				return err
+			}
+			return createIndexes(ctx, db, "pekerjaan_history", mongo.IndexModel{
+				Keys: bson.D{{Key: "pekerjaan_id", Value: 1}, {Key: "version", Value: -1}},
+			})
+		},
+		Down: func(ctx context.Context, db *mongo.Database) error {
+			if err := dropIndexes(ctx, db, "pekerjaan", "alumni_id_1"); err != nil {
+				return err
+			}
+			return dropIndexes(ctx, db, "pekerjaan_history", "pekerjaan_id_1_version_-1")
+		},
+	},
+	{
+		Version: 4,
+		Name:    "files_blobs_upload_sessions",
+		Up: func(ctx context.Context, db *mongo.Database) error {
+			if err := createIndexes(ctx, db, "files",
+				mongo.IndexModel{Keys: bson.D{{Key: "alumni_id", Value: 1}}},
+				mongo.IndexModel{Keys: bson.D{{Key: "uploaded_at", Value: -1}}},
+			); err != nil {
+				return err
+			}
+			if err := createIndexes(ctx, db, "blobs", mongo.IndexModel{
+				Keys:    bson.D{{Key: "sha256", Value: 1}},
+				Options: options.Index().SetUnique(true),
+			}); err != nil {
+				return err
+			}
+			return createIndexes(ctx, db, "upload_sessions", mongo.IndexModel{
+				Keys: bson.D{{Key: "expires_at", Value: 1}},
+			})
+		},
+		Down: func(ctx context.Context, db *mongo.Database) error {
+			if err := dropIndexes(ctx, db, "files", "alumni_id_1", "uploaded_at_-1"); err != nil {
+				return err
+			}
+			if err := dropIndexes(ctx, db, "blobs", "sha256_1"); err != nil {
+				return err
+			}
+			return dropIndexes(ctx, db, "upload_sessions", "expires_at_1")
+		},
+	},
+	{
+		Version: 5,
+		Name:    "json_schema_validators",
+		Up: func(ctx context.Context, db *mongo.Database) error {
+			for coll, schema := range schemaValidators {
+				if err := setValidator(ctx, db, coll, schema); err != nil {
+					return err
+				}
+			}
+			return nil
+		},
+		Down: func(ctx context.Context, db *mongo.Database) error {
+			for coll := range schemaValidators {
+				if err := dropValidator(ctx, db, coll); err != nil {
+					return err
+				}
+			}
+			return nil
+		},
+	},
+	{
+		Version: 6,
+		Name:    "case_insensitive_unique_username_email_nim",
+		// gagal jika sudah ada data kembar yang hanya berbeda huruf besar/kecil; rapikan datanya dulu
+		Up: func(ctx context.Context, db *mongo.Database) error {
+			if err := createIndexes(ctx, db, "users",
+				mongo.IndexModel{
+					Keys:    bson.D{{Key: "username", Value: 1}},
+					Options: options.Index().SetName("username_ci").SetUnique(true).SetCollation(caseInsensitive),
+				},
+				// user lama tanpa email tidak dianggap kembar
+				mongo.IndexModel{
+					Keys: bson.D{{Key: "email", Value: 1}},
+					Options: options.Index().SetName("email_ci").SetUnique(true).SetCollation(caseInsensitive).
+						SetPartialFilterExpression(bson.M{"email": bson.M{"$gt": ""}}),
+				},
+			); err != nil {
+				return err
+			}
+			if err := createIndexes(ctx, db, "alumni", mongo.IndexModel{
+				Keys:    bson.D{{Key: "nim", Value: 1}},
+				Options: options.Index().SetName("nim_ci").SetUnique(true).SetCollation(caseInsensitive),
+			}); err != nil {
+				return err
+			}
+			// index lama (case-sensitive) sudah digantikan
+			if err := dropIndexes(ctx, db, "users", "username_1"); err != nil {
+				return err
+			}
+			return dropIndexes(ctx, db, "alumni", "nim_1")
+		},
+		Down: func(ctx context.Context, db *mongo.Database) error {
+			if err := createIndexes(ctx, db, "users", mongo.IndexModel{
+				Keys:    bson.D{{Key: "username", Value: 1}},
+				Options: options.Index().SetUnique(true),
+			}); err != nil {
+				return err
+			}
+			if err := createIndexes(ctx, db, "alumni", mongo.IndexModel{
+				Keys:    bson.D{{Key: "nim", Value: 1}},
+				Options: options.Index().SetUnique(true),
+			}); err != nil {
+				return err
+			}
+			if err := dropIndexes(ctx, db, "users", "username_ci", "email_ci"); err != nil {
+				return err
+			}
+			return dropIndexes(ctx, db, "alumni", "nim_ci")
+		},
+	},
+	{
+		Version: 7,
+		Name:    "alumni_claims",
+		Up: func(ctx context.Context, db *mongo.Database) error {
+			return createIndexes(ctx, db, "alumni_claims",
+				// satu klaim pending per user dan data alumni
+				mongo.IndexModel{
+					Keys: bson.D{{Key: "user_id", Value: 1}, {Key: "alumni_id", Value: 1}},
+					Options: options.Index().SetName("claim_pending").SetUnique(true).
+						SetPartialFilterExpression(bson.M{"status": "pending"}),
+				},
+				mongo.IndexModel{Keys: bson.D{{Key: "status", Value: 1}, {Key: "created_at", Value: -1}}},
+			)
+		},
+		Down: func(ctx context.Context, db *mongo.Database) error {
+			return dropIndexes(ctx, db, "alumni_claims", "claim_pending", "status_1_created_at_-1")
+		},
+	},
+	{
+		Version: 8,
+		Name:    "users_email_verified",
+		// akun yang sudah ada sebelum verifikasi email dianggap terverifikasi agar tetap bisa login
+		Up: func(ctx context.Context, db *mongo.Database) error {
+			_, err := db.Collection("users").UpdateMany(ctx,
+				bson.M{"email_verified": bson.M{"$exists": false}},
+				bson.M{"$set": bson.M{"email_verified": true}},
+			)
+			return err
+		},
+		Down: func(ctx context.Context, db *mongo.Database) error {
+			_, err := db.Collection("users").UpdateMany(ctx, bson.M{},
+				bson.M{"$unset": bson.M{"email_verified": "", "email_verified_at": ""}},
+			)
+			return err
+		},
+	},
+	{
+		Version: 9,
+		Name:    "notifications",
+		Up: func(ctx context.Context, db *mongo.Database) error {
+			if err := createIndexes(ctx, db, "notification_templates", mongo.IndexModel{
+				Keys:    bson.D{{Key: "key", Value: 1}},
+				Options: options.Index().SetName("notification_template_key").SetUnique(true),
+			}); err != nil {
+				return err
+			}
+			if err := createIndexes(ctx, db, "notifications", mongo.IndexModel{
+				Keys: bson.D{{Key: "user_id", Value: 1}, {Key: "created_at", Value: -1}},
+			}); err != nil {
+				return err
+			}
+			if err := createIndexes(ctx, db, "email_queue", mongo.IndexModel{
+				Keys: bson.D{{Key: "status", Value: 1}, {Key: "next_attempt_at", Value: 1}},
+			}); err != nil {
+				return err
+			}
+			// template bawaan; admin bisa mengubah isinya lewat /notifications/templates
+			now := time.Now()
+			for _, tpl := range defaultNotificationTemplates {
+				tpl["created_at"] = now
+				tpl["updated_at"] = now
+				_, err := db.Collection("notification_templates").UpdateOne(ctx,
+					bson.M{"key": tpl["key"]},
+					bson.M{"$setOnInsert": tpl},
+					options.Update().SetUpsert(true),
+				)
+				if err != nil {
+					return err
+				}
+			}
+			return nil
+		},
+		Down: func(ctx context.Context, db *mongo.Database) error {
+			// hanya template bawaan yang dihapus; notifikasi dan antrian email tetap disimpan
+			keys := bson.A{}
+			for _, tpl := range defaultNotificationTemplates {
+				keys = append(keys, tpl["key"])
+			}
+			if _, err := db.Collection("notification_templates").DeleteMany(ctx, bson.M{"key": bson.M{"$in": keys}}); err != nil {
+				return err
+			}
+			if err := dropIndexes(ctx, db, "notification_templates", "notification_template_key"); err != nil {
+				return err
+			}
+			if err := dropIndexes(ctx, db, "notifications", "user_id_1_created_at_-1"); err != nil {
+				return err
+			}
+			return dropIndexes(ctx, db, "email_queue", "status_1_next_attempt_at_1")
+		},
+	},
+	{
+		Version: 10,
+		Name:    "tracer_study_surveys",
+		Up: func(ctx context.Context, db *mongo.Database) error {
+			if err := createIndexes(ctx, db, "surveys", mongo.IndexModel{
+				Keys: bson.D{{Key: "status", Value: 1}, {Key: "created_at", Value: -1}},
+			}); err != nil {
+				return err
+			}
+			// satu jawaban per alumni per kuesioner; submit ulang memperbarui dokumen yang sama
+			return createIndexes(ctx, db, "survey_responses",
+				mongo.IndexModel{
+					Keys:    bson.D{{Key: "survey_id", Value: 1}, {Key: "alumni_id", Value: 1}},
+					Options: options.Index().SetName("survey_response_alumni").SetUnique(true),
+				},
+				mongo.IndexModel{Keys: bson.D{{Key: "alumni_id", Value: 1}}},
+			)
+		},
+		Down: func(ctx context.Context, db *mongo.Database) error {
+			if err := dropIndexes(ctx, db, "surveys", "status_1_created_at_-1"); err != nil {
+				return err
+			}
+			return dropIndexes(ctx, db, "survey_responses", "survey_response_alumni", "alumni_id_1")
+		},
+	},
+	{
+		Version: 11,
+		Name:    "lowongan",
+		Up: func(ctx context.Context, db *mongo.Database) error {
+			if err := createIndexes(ctx, db, "lowongan",
+				mongo.IndexModel{Keys: bson.D{{Key: "status", Value: 1}, {Key: "batas_lamaran", Value: 1}}},
+				mongo.IndexModel{Keys: bson.D{{Key: "posted_by", Value: 1}}},
+			); err != nil {
+				return err
+			}
+			// satu minat per alumni per lowongan
+			return createIndexes(ctx, db, "lowongan_interests",
+				mongo.IndexModel{
+					Keys:    bson.D{{Key: "lowongan_id", Value: 1}, {Key: "alumni_id", Value: 1}},
+					Options: options.Index().SetName("lowongan_interest_alumni").SetUnique(true),
+				},
+				mongo.IndexModel{Keys: bson.D{{Key: "alumni_id", Value: 1}, {Key: "created_at", Value: -1}}},
+			)
+		},
+		Down: func(ctx context.Context, db *mongo.Database) error {
+			if err := dropIndexes(ctx, db, "lowongan", "status_1_batas_lamaran_1", "posted_by_1"); err != nil {
+				return err
+			}
+			return dropIndexes(ctx, db, "lowongan_interests", "lowongan_interest_alumni", "alumni_id_1_created_at_-1")
+		},
+	},
+}
+
+// defaultNotificationTemplates dibuat oleh migrasi 9 jika key tersebut belum ada
+var defaultNotificationTemplates = []bson.M{
+	{
+		"key":      "certificate_reviewed",
+		"title":    "Sertifikat {{.Data.file_name}} {{if eq .Data.status \"approved\"}}disetujui{{else}}ditolak{{end}}",
+		"body":     "Halo {{.Nama}},\n\nSertifikat {{.Data.file_name}} sudah diverifikasi dengan status {{.Data.status}}.{{if .Data.notes}}\nCatatan: {{.Data.notes}}{{end}}",
+		"channels": bson.A{"in_app", "email"},
+	},
+	{
+		"key":      "survey_reminder",
+		"title":    "Pengingat tracer study",
+		"body":     "Halo {{.Nama}},\n\nMohon luangkan waktu untuk mengisi tracer study alumni {{.Jurusan}}: {{.Data.link}}",
+		"channels": bson.A{"in_app", "email"},
+	},
+	{
+		"key":      "job_posting",
+		"title":    "Lowongan baru: {{.Data.posisi}}",
+		"body":     "Halo {{.Nama}},\n\nAda lowongan {{.Data.posisi}} di {{.Data.perusahaan}}. Lihat detailnya: {{.Data.link}}",
+		"channels": bson.A{"in_app"},
+	},
+}
+
+// caseInsensitive: strength 2 membandingkan huruf tanpa memperhatikan besar/kecil
+var caseInsensitive = &options.Collation{Locale: "en", Strength: 2}
+
+var integer = bson.A{"int", "long"}
+
+// schemaValidators adalah $jsonSchema per collection, disesuaikan dengan struct di app/mongodb/model
+var schemaValidators = map[string]bson.M{
+	"users": {
+		"bsonType": "object",
+		"required": bson.A{"username", "password_hash", "role"},
+		"properties": bson.M{
+			"username":      bson.M{"bsonType": "string", "minLength": 1},
+			"email":         bson.M{"bsonType": "string"},
+			"password_hash": bson.M{"bsonType": "string", "minLength": 1},
+			"role":          bson.M{"bsonType": "string", "minLength": 1},
+			"created_at":    bson.M{"bsonType": "date"},
+		},
+	},
+	"alumni": {
+		"bsonType": "object",
+		"required": bson.A{"nim", "nama"},
+		"properties": bson.M{
+			"nim":         bson.M{"bsonType": "string", "minLength": 1},
+			"nama":        bson.M{"bsonType": "string", "minLength": 1},
+			"jurusan":     bson.M{"bsonType": "string"},
+			"angkatan":    bson.M{"bsonType": integer},
+			"tahun_lulus": bson.M{"bsonType": integer},
+			"email":       bson.M{"bsonType": "string"},
+			"user_id":     bson.M{"bsonType": "objectId"},
+			"version":     bson.M{"bsonType": integer},
+		},
+	},
+	"pekerjaan": {
+		"bsonType": "object",
+		"required": bson.A{"alumni_id", "nama_perusahaan", "posisi_jabatan"},
+		"properties": bson.M{
+			"alumni_id":           bson.M{"bsonType": "objectId"},
+			"nama_perusahaan":     bson.M{"bsonType": "string", "minLength": 1},
+			"posisi_jabatan":      bson.M{"bsonType": "string", "minLength": 1},
+			"gaji_range":          bson.M{"bsonType": integer},
+			"tanggal_mulai_kerja": bson.M{"bsonType": "date"},
+			"version":             bson.M{"bsonType": integer},
+		},
+	},
+}
@@ -4,6 +4,7 @@ import (
 	"database/sql"
 	"fmt"
 	"log"
+	"os"
 
 	_ "github.com/lib/pq"
 )
@@ -13,8 +14,11 @@ var DB *sql.DB
 func ConnectDB() {
 	var err error
 
-	// Ganti dengan connection string mu
-	dsn := "host=localhost user=postgres password=1234 dbname=alumni_db port=5432 sslmode=disable"
+	// Ganti dengan connection string mu, atau set POSTGRES_DSN
+	dsn := os.Getenv("POSTGRES_DSN")
+	if dsn == "" {
+		dsn = "host=localhost user=postgres password=1234 dbname=alumni_db port=5432 sslmode=disable"
+	}
 
 	DB, err = sql.Open("postgres", dsn)
 	if err != nil {
@@ -0,0 +1,73 @@
+package database
+
+import (
+	"io/fs"
+	"strings"
+	"testing"
+	"testing/fstest"
+)
+
+func TestEmbeddedMigrations(t *testing.T) {
+	sub, _ := fs.Sub(migrationFS, "migrations")
+	list, err := LoadMigrations(sub)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(list) == 0 {
+		t.Fatal("no embedded migrations")
+	}
+	for i, m := range list {
+		if m.Version != i+1 {
+			t.Errorf("migration %d_%s: versions must be sequential starting at 1", m.Version, m.Name)
+		}
+	}
+
+	// tabel yang dipakai repository harus dibuat berikut relasinya
+	all := ""
+	for _, m := range list {
+		all += m.Up
+	}
+	for _, want := range []string{
+		"CREATE TABLE IF NOT EXISTS users",
+		"CREATE TABLE IF NOT EXISTS alumni",
+		"CREATE TABLE IF NOT EXISTS pekerjaan",
+		"REFERENCES users (id)",
+		"REFERENCES alumni (id)",
+		"WHERE deleted_at IS NULL",
+	} {
+		if !strings.Contains(all, want) {
+			t.Errorf("migrations missing %q", want)
+		}
+	}
+}
+
+func TestLoadMigrationsValidation(t *testing.T) {
+	tests := []struct {
+		name  string
+		files fstest.MapFS
+	}{
+		{"Missing Down", fstest.MapFS{"0001_init.up.sql": {Data: []byte("SELECT 1")}}},
+		{"Bad Name", fstest.MapFS{"init.sql": {Data: []byte("SELECT 1")}}},
+		{"Conflicting Names", fstest.MapFS{
+			"0001_init.up.sql":    {Data: []byte("SELECT 1")},
+			"0001_other.down.sql": {Data: []byte("SELECT 1")},
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := LoadMigrations(tt.files); err == nil {
+				t.Error("expected error")
+			}
+		})
+	}
+
+	list, err := LoadMigrations(fstest.MapFS{
+		"0002_b.up.sql":   {Data: []byte("B")},
+		"0002_b.down.sql": {Data: []byte("-B")},
+		"0001_a.up.sql":   {Data: []byte("A")},
+		"0001_a.down.sql": {Data: []byte("-A")},
+	})
+	if err != nil || len(list) != 2 || list[0].Name != "a" || list[1].Down != "-B" {
+		t.Errorf("LoadMigrations = %+v, %v", list, err)
+	}
+}
@@ -0,0 +1,22 @@
+CREATE TABLE IF NOT EXISTS alumni (
+    id          SERIAL PRIMARY KEY,
+    nim         VARCHAR(20)  NOT NULL,
+    nama        VARCHAR(100) NOT NULL,
+    jurusan     VARCHAR(100) NOT NULL,
+    angkatan    INTEGER      NOT NULL,
+    tahun_lulus INTEGER      NOT NULL,
+    email       VARCHAR(100) NOT NULL,
+    no_telepon  VARCHAR(20)  NOT NULL DEFAULT '',
+    alamat      TEXT         NOT NULL DEFAULT '',
+    user_id     INTEGER      NOT NULL,
+    created_at  TIMESTAMPTZ  NOT NULL DEFAULT NOW(),
+    updated_at  TIMESTAMPTZ  NOT NULL DEFAULT NOW(),
+    deleted_at  TIMESTAMPTZ,
+
+    CONSTRAINT alumni_nim_key UNIQUE (nim),
+    CONSTRAINT alumni_user_id_fkey FOREIGN KEY (user_id)
+        REFERENCES users (id) ON DELETE RESTRICT
+);
+
+-- satu akun hanya terhubung ke satu alumni aktif; alumni yang di-soft-delete tidak dihitung
+CREATE UNIQUE INDEX IF NOT EXISTS alumni_user_id_active_key ON alumni (user_id) WHERE deleted_at IS NULL;
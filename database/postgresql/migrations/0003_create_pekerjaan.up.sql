@@ -0,0 +1,23 @@
+CREATE TABLE IF NOT EXISTS pekerjaan (
+    id                    SERIAL PRIMARY KEY,
+    alumni_id             INTEGER      NOT NULL,
+    nama_perusahaan       VARCHAR(100) NOT NULL,
+    posisi_jabatan        VARCHAR(100) NOT NULL,
+    bidang_industri       VARCHAR(50)  NOT NULL DEFAULT '',
+    lokasi_kerja          VARCHAR(100) NOT NULL DEFAULT '',
+    gaji_range            BIGINT       NOT NULL DEFAULT 0,
+    tanggal_mulai_kerja   DATE         NOT NULL,
+    tanggal_selesai_kerja DATE,
+    status_pekerjaan      VARCHAR(20)  NOT NULL DEFAULT '',
+    deskripsi_pekerjaan   TEXT         NOT NULL DEFAULT '',
+    created_at            TIMESTAMPTZ  NOT NULL DEFAULT NOW(),
+    updated_at            TIMESTAMPTZ  NOT NULL DEFAULT NOW(),
+    deleted_at            TIMESTAMPTZ,
+
+    CONSTRAINT pekerjaan_alumni_id_fkey FOREIGN KEY (alumni_id)
+        REFERENCES alumni (id) ON DELETE CASCADE,
+    CONSTRAINT pekerjaan_tanggal_check
+        CHECK (tanggal_selesai_kerja IS NULL OR tanggal_selesai_kerja >= tanggal_mulai_kerja)
+);
+
+CREATE INDEX IF NOT EXISTS pekerjaan_alumni_id_idx ON pekerjaan (alumni_id);
@@ -0,0 +1,8 @@
+DROP INDEX IF EXISTS pekerjaan_bidang_industri_trgm_idx;
+DROP INDEX IF EXISTS pekerjaan_posisi_jabatan_trgm_idx;
+DROP INDEX IF EXISTS pekerjaan_nama_perusahaan_trgm_idx;
+
+DROP INDEX IF EXISTS alumni_tahun_lulus_idx;
+DROP INDEX IF EXISTS alumni_email_trgm_idx;
+DROP INDEX IF EXISTS alumni_jurusan_trgm_idx;
+DROP INDEX IF EXISTS alumni_nama_trgm_idx;
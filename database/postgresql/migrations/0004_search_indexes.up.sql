@@ -0,0 +1,11 @@
+-- pencarian memakai ILIKE '%kata%', sehingga butuh index trigram (GIN)
+CREATE EXTENSION IF NOT EXISTS pg_trgm;
+
+CREATE INDEX IF NOT EXISTS alumni_nama_trgm_idx    ON alumni USING GIN (nama gin_trgm_ops);
+CREATE INDEX IF NOT EXISTS alumni_jurusan_trgm_idx ON alumni USING GIN (jurusan gin_trgm_ops);
+CREATE INDEX IF NOT EXISTS alumni_email_trgm_idx   ON alumni USING GIN (email gin_trgm_ops);
+CREATE INDEX IF NOT EXISTS alumni_tahun_lulus_idx  ON alumni (tahun_lulus);
+
+CREATE INDEX IF NOT EXISTS pekerjaan_nama_perusahaan_trgm_idx ON pekerjaan USING GIN (nama_perusahaan gin_trgm_ops);
+CREATE INDEX IF NOT EXISTS pekerjaan_posisi_jabatan_trgm_idx  ON pekerjaan USING GIN (posisi_jabatan gin_trgm_ops);
+CREATE INDEX IF NOT EXISTS pekerjaan_bidang_industri_trgm_idx ON pekerjaan USING GIN (bidang_industri gin_trgm_ops);
@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS pekerjaan_trashed_idx;
+DROP INDEX IF EXISTS pekerjaan_active_alumni_idx;
+DROP INDEX IF EXISTS alumni_active_idx;
@@ -0,0 +1,6 @@
+-- hampir semua query hanya membaca baris aktif (deleted_at IS NULL)
+CREATE INDEX IF NOT EXISTS alumni_active_idx ON alumni (id) WHERE deleted_at IS NULL;
+CREATE INDEX IF NOT EXISTS pekerjaan_active_alumni_idx ON pekerjaan (alumni_id, created_at DESC) WHERE deleted_at IS NULL;
+
+-- daftar trash diurutkan berdasarkan waktu hapus
+CREATE INDEX IF NOT EXISTS pekerjaan_trashed_idx ON pekerjaan (deleted_at DESC) WHERE deleted_at IS NOT NULL;
@@ -0,0 +1,7 @@
+ALTER TABLE alumni ADD CONSTRAINT alumni_nim_key UNIQUE (nim);
+ALTER TABLE users ADD CONSTRAINT users_email_key UNIQUE (email);
+ALTER TABLE users ADD CONSTRAINT users_username_key UNIQUE (username);
+
+DROP INDEX IF EXISTS alumni_nim_lower_key;
+DROP INDEX IF EXISTS users_email_lower_key;
+DROP INDEX IF EXISTS users_username_lower_key;
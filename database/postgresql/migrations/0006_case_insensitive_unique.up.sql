@@ -0,0 +1,9 @@
+-- username, email, dan nim unik tanpa memperhatikan huruf besar/kecil.
+-- Gagal jika sudah ada data kembar yang hanya berbeda huruf; rapikan datanya dulu.
+CREATE UNIQUE INDEX IF NOT EXISTS users_username_lower_key ON users (LOWER(username));
+CREATE UNIQUE INDEX IF NOT EXISTS users_email_lower_key ON users (LOWER(email));
+CREATE UNIQUE INDEX IF NOT EXISTS alumni_nim_lower_key ON alumni (LOWER(nim));
+
+ALTER TABLE users DROP CONSTRAINT IF EXISTS users_username_key;
+ALTER TABLE users DROP CONSTRAINT IF EXISTS users_email_key;
+ALTER TABLE alumni DROP CONSTRAINT IF EXISTS alumni_nim_key;
@@ -1,11 +1,19 @@
 package main
 
 import (
+	"context"
+	"log"
+	"os"
+	"time"
+
 	repo "alumni-app/app/mongodb/repository"
 	svc "alumni-app/app/mongodb/service"
 	"alumni-app/config"
 	dbmongo "alumni-app/database/mongodb"
+	"alumni-app/storage"
 	routepkg "alumni-app/route/mongodb"
+	"alumni-app/scanner"
+	utils "alumni-app/utils/mongodb"
 	fiberSwagger "github.com/swaggo/fiber-swagger"
     _ "alumni-app/docs"
 )
@@ -21,31 +29,123 @@ import (
 // @name Authorization
 func main() {
 	config.LoadEnv()
+
+	// subcommand CLI: `go run . migrate [up|down|status] [-db=mongo|postgres]` mengelola skema database
+	if len(os.Args) > 1 && os.Args[1] == "migrate" {
+		os.Exit(runMigrate(os.Args[2:]))
+	}
+
 	dbmongo.ConnectMongo()
 
+	// migrasi yang belum jalan diterapkan saat startup (MONGO_AUTO_MIGRATE=false untuk mematikan)
+	if config.GetEnvBool("MONGO_AUTO_MIGRATE", true) {
+		if _, err := dbmongo.NewMigrator(dbmongo.DB).Up(context.Background()); err != nil {
+			log.Fatal("❌ Migrasi MongoDB gagal: ", err)
+		}
+	}
+
 	app := config.NewApp()
 
+	// batas waktu query per jenis operasi (DB_READ_TIMEOUT, DB_WRITE_TIMEOUT, DB_SCAN_TIMEOUT)
+	dbTimeouts := config.LoadDBTimeoutConfig()
+
 	// repositories
-	userRepo := repo.NewUserRepository(dbmongo.DB)
-	alumniRepo := repo.NewAlumniRepository(dbmongo.DB)
-	pekerjaanRepo := repo.NewPekerjaanRepository(dbmongo.DB)
+	userRepo := repo.NewUserRepository(dbmongo.DB, dbTimeouts)
+	alumniRepo := repo.NewAlumniRepository(dbmongo.DB, dbTimeouts)
+	pekerjaanRepo := repo.NewPekerjaanRepository(dbmongo.DB, dbTimeouts)
+	alumniClaimRepo := repo.NewAlumniClaimRepository(dbmongo.DB, dbTimeouts)
 	// file repo needs the DB handle; ensure dbmongo.DB is exported: var DB *mongo.Database
-	fileRepo := repo.NewFileRepository(dbmongo.DB)
+	fileRepo := repo.NewFileRepository(dbmongo.DB, dbTimeouts)
+	passwordResetRepo := repo.NewPasswordResetRepository(dbmongo.DB, dbTimeouts)
+	uploadSessionRepo := repo.NewUploadSessionRepository(dbmongo.DB, dbTimeouts)
+	blobRepo := repo.NewBlobRepository(dbmongo.DB, dbTimeouts)
+	notificationTemplateRepo := repo.NewNotificationTemplateRepository(dbmongo.DB, dbTimeouts)
+	notificationRepo := repo.NewNotificationRepository(dbmongo.DB, dbTimeouts)
+	emailQueueRepo := repo.NewEmailQueueRepository(dbmongo.DB, dbTimeouts)
+	surveyRepo := repo.NewSurveyRepository(dbmongo.DB, dbTimeouts)
+	surveyResponseRepo := repo.NewSurveyResponseRepository(dbmongo.DB, dbTimeouts)
+	lowonganRepo := repo.NewLowonganRepository(dbmongo.DB, dbTimeouts)
+	lowonganInterestRepo := repo.NewLowonganInterestRepository(dbmongo.DB, dbTimeouts)
+	// transaksi untuk penulisan ke beberapa collection sekaligus (butuh replica set)
+	uow := repo.NewUnitOfWork(context.Background(), dbmongo.DB)
+
+	// rate limiting login/register (in-memory, per instance)
+	rateCfg := config.LoadRateLimitConfig()
+	limiter := utils.NewMemoryRateLimitStore()
+
+	// password policy, verifikasi email & pengiriman email (MAIL_SENDER=file|log|smtp)
+	pwCfg := config.LoadPasswordConfig()
+	notifier, err := utils.NewNotifier(config.LoadMailConfig())
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	// backend penyimpanan file upload (STORAGE_BACKEND=local|s3)
+	store, err := storage.New(config.LoadStorageConfig())
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	// pemindai malware untuk file upload (SCANNER=none|clamd)
+	fileScanner, err := scanner.New(config.LoadScannerConfig())
+	if err != nil {
+		log.Fatal(err)
+	}
 
 	// services
-	userService := svc.NewUserService(userRepo)
-	alumniService := svc.NewAlumniService(alumniRepo)
+	userService := svc.NewUserService(userRepo, alumniRepo, uow, limiter, rateCfg, pwCfg, notifier, config.LoadEmailVerificationConfig())
+	passwordService := svc.NewPasswordService(userRepo, passwordResetRepo, notifier, pwCfg)
+	alumniService := svc.NewAlumniService(alumniRepo, pekerjaanRepo, alumniClaimRepo, uow, limiter, rateCfg)
 	pekerjaanService := svc.NewPekerjaanService(pekerjaanRepo, alumniRepo)
-	fileService := svc.NewFileService(fileRepo, alumniRepo, "./uploads")
+	notificationCfg := config.LoadNotificationConfig()
+	notificationService := svc.NewNotificationService(notificationTemplateRepo, notificationRepo, emailQueueRepo, alumniRepo, notifier, notificationCfg)
+	surveyService := svc.NewSurveyService(surveyRepo, surveyResponseRepo, alumniRepo, notificationService)
+	lowonganService := svc.NewLowonganService(lowonganRepo, lowonganInterestRepo, pekerjaanRepo, alumniRepo, userRepo, uow)
+	fileService := svc.NewFileService(fileRepo, alumniRepo, store, blobRepo, fileScanner, uploadSessionRepo, config.LoadUploadConfig(), uow, notificationService)
+
+	// subcommand CLI: `go run . reconcile` mencocokkan isi storage dengan collection files
+	if len(os.Args) > 1 && os.Args[1] == "reconcile" {
+		os.Exit(runReconcile(fileService, os.Args[2:]))
+	}
+
+	// bersihkan sesi upload bertahap yang ditinggalkan
+	go func() {
+		for range time.Tick(time.Hour) {
+			if n, err := fileService.PurgeExpiredUploads(context.Background()); err != nil {
+				log.Println("purge expired uploads:", err)
+			} else if n > 0 {
+				log.Printf("purged %d expired upload sessions\n", n)
+			}
+		}
+	}()
+
+	// kirim email notifikasi yang mengantri, termasuk percobaan ulang yang sudah jatuh tempo
+	go func() {
+		for range time.Tick(notificationCfg.WorkerInterval) {
+			if sent, failed, err := notificationService.DeliverEmails(context.Background()); err != nil {
+				log.Println("deliver notification emails:", err)
+			} else if sent+failed > 0 {
+				log.Printf("notification emails: %d sent, %d failed\n", sent, failed)
+			}
+		}
+	}()
 
-	// static files
-	app.Static("/uploads", "./uploads")
+	// tandai lowongan yang batas lamarannya sudah lewat
+	go func() {
+		for range time.Tick(time.Hour) {
+			if n, err := lowonganService.ExpirePostings(context.Background()); err != nil {
+				log.Println("expire lowongan:", err)
+			} else if n > 0 {
+				log.Printf("expired %d lowongan\n", n)
+			}
+		}
+	}()
 
 	// Swagger endpoint (UI Dokumentasi API)
 	app.Get("/swagger/*", fiberSwagger.WrapHandler)
 
 	// routes — PASS fileService here
-	routepkg.RegisterRoutes(app, alumniService, pekerjaanService, userService, fileService)
+	routepkg.RegisterRoutes(app, alumniService, pekerjaanService, userService, passwordService, fileService, notificationService, surveyService, lowonganService, limiter, rateCfg)
 
 	port := config.GetEnv("PORT", "3000")
 	config.StartServer(app, port)
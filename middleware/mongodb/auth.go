@@ -55,3 +55,16 @@ func AdminOnly() fiber.Handler {
 		return c.Next()
 	}
 }
+
+// StaffOnly memastikan hanya staff (atau admin) yang bisa akses, mis. antrian verifikasi sertifikat
+func StaffOnly() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		role := c.Locals("role")
+		if role != "admin" && role != "staff" {
+			return c.Status(fiber.StatusForbidden).JSON(fiber.Map{
+				"error": "Access forbidden: Staff only",
+			})
+		}
+		return c.Next()
+	}
+}
@@ -0,0 +1,30 @@
+package middleware
+
+import (
+	"alumni-app/utils/mongodb"
+	"strconv"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// RateLimit membatasi jumlah request per IP dalam satu window waktu.
+// prefix membedakan counter antar grup route yang memakai store yang sama.
+func RateLimit(store utils.RateLimitStore, prefix string, max int, window time.Duration) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		count, resetAt, err := store.Increment(prefix+":"+c.IP(), window)
+		if err != nil {
+			// jangan blokir user hanya karena store bermasalah
+			return c.Next()
+		}
+
+		if count > max {
+			retry := int(time.Until(resetAt).Seconds()) + 1
+			c.Set(fiber.HeaderRetryAfter, strconv.Itoa(retry))
+			return c.Status(fiber.StatusTooManyRequests).JSON(fiber.Map{
+				"error": "Terlalu banyak request, coba lagi nanti",
+			})
+		}
+		return c.Next()
+	}
+}
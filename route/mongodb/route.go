@@ -2,7 +2,9 @@ package route
 
 import (
 	svc "alumni-app/app/mongodb/service"
+	"alumni-app/config"
 	middleware "alumni-app/middleware/mongodb"
+	"alumni-app/utils/mongodb"
 
 	"github.com/gofiber/fiber/v2"
 )
@@ -13,13 +15,43 @@ func RegisterRoutes(
 	alumniService *svc.AlumniService,
 	pekerjaanService *svc.PekerjaanService,
 	userService *svc.UserService,
+	passwordService *svc.PasswordService,
 	fileService svc.FileService,
+	notificationService *svc.NotificationService,
+	surveyService *svc.SurveyService,
+	lowonganService *svc.LowonganService,
+	limiter utils.RateLimitStore,
+	rateCfg config.RateLimitConfig,
 ) {
 	api := app.Group("/api/v1")
 
 	// ====================== AUTH ROUTES ======================
-	api.Post("/login", userService.Login)
-	api.Post("/register", userService.Register)
+	authLimit := middleware.RateLimit(limiter, "auth", rateCfg.IPMax, rateCfg.IPWindow)
+	api.Post("/login", authLimit, userService.Login)
+	api.Post("/register", authLimit, userService.Register)
+	api.Post("/forgot-password", authLimit, passwordService.ForgotPassword)
+	api.Post("/reset-password", authLimit, passwordService.ResetPassword)
+	api.Get("/verify-email", authLimit, userService.VerifyEmail)
+	api.Post("/resend-verification", authLimit, userService.ResendVerification)
+
+	// ====================== ME (USER LOGIN) ROUTES ======================
+	me := api.Group("/me", middleware.AuthRequired())
+	me.Get("/", userService.Me)
+	me.Put("/password", passwordService.ChangePassword)
+	me.Get("/alumni", alumniService.GetMine)
+	me.Put("/alumni", alumniService.UpdateMine)
+	me.Post("/alumni/claim", middleware.RateLimit(limiter, "claim", rateCfg.IPMax, rateCfg.IPWindow), alumniService.Claim)
+	me.Get("/alumni/claims", alumniService.GetMyClaims)
+	me.Get("/pekerjaan", pekerjaanService.GetMine)
+	me.Get("/files", fileService.GetMyFiles)
+	me.Get("/notifications", notificationService.GetMine)
+	me.Put("/notifications/read-all", notificationService.MarkAllRead)
+	me.Put("/notifications/:id/read", notificationService.MarkRead)
+	me.Get("/surveys", surveyService.GetMySurveys)
+	me.Get("/surveys/:id", surveyService.GetMySurvey)
+	me.Post("/surveys/:id/responses", surveyService.Submit)
+	me.Get("/lowongan", lowonganService.GetMine)
+	me.Get("/lowongan/interests", lowonganService.GetMyInterests)
 
 	// ====================== PEKERJAAN ROUTES ======================
 	pekerjaan := api.Group("/pekerjaan", middleware.AuthRequired())
@@ -36,6 +68,10 @@ func RegisterRoutes(
 	pekerjaan.Put("/:id/restore", pekerjaanService.Restore)
 	pekerjaan.Delete("/:id/permanent", pekerjaanService.HardDelete)
 
+	// === Riwayat Perubahan (Versioning) ===
+	pekerjaan.Get("/:id/history", pekerjaanService.GetHistory)
+	pekerjaan.Post("/:id/revert/:version", pekerjaanService.Revert)
+
 	// === Fitur Tambahan ===
 	pekerjaan.Get("/alumni/:alumni_id", pekerjaanService.GetByAlumniID)
 
@@ -43,17 +79,71 @@ func RegisterRoutes(
 	alumni := api.Group("/alumni", middleware.AuthRequired())
 	alumni.Get("/", alumniService.GetAll)
 	// alumni.Post("/:id/upload", alumniService.UploadFiles)
+	alumni.Get("/claims", middleware.AdminOnly(), alumniService.GetClaims)
+	alumni.Post("/claims/:id/review", middleware.AdminOnly(), alumniService.ReviewClaim)
 	alumni.Get("/:id", alumniService.GetByID)
+	alumni.Put("/:id", alumniService.Update)
 	alumni.Delete("/:id", alumniService.Delete)
 
+	// ====================== NOTIFICATION ROUTES ======================
+	notifications := api.Group("/notifications", middleware.AuthRequired(), middleware.StaffOnly())
+	notifications.Get("/templates", notificationService.GetTemplates)
+	notifications.Post("/templates", notificationService.CreateTemplate)
+	notifications.Put("/templates/:key", notificationService.UpdateTemplate)
+	notifications.Delete("/templates/:key", notificationService.DeleteTemplate)
+	notifications.Post("/send", notificationService.Send)
+
+	// ====================== LOWONGAN KERJA ROUTES ======================
+	lowongan := api.Group("/lowongan", middleware.AuthRequired())
+	lowongan.Get("/", lowonganService.GetAll)
+	lowongan.Post("/", lowonganService.Create)
+	lowongan.Get("/vocabulary", lowonganService.GetVocabulary)
+	lowongan.Get("/:id", lowonganService.GetByID)
+	lowongan.Put("/:id", lowonganService.Update)
+	lowongan.Delete("/:id", lowonganService.Delete)
+	lowongan.Post("/:id/interest", lowonganService.Interest)
+	lowongan.Delete("/:id/interest", lowonganService.Withdraw)
+	lowongan.Get("/:id/interests", lowonganService.GetInterests)
+
+	// ====================== TRACER STUDY ROUTES ======================
+	surveys := api.Group("/surveys", middleware.AuthRequired(), middleware.AdminOnly())
+	surveys.Get("/", surveyService.GetAll)
+	surveys.Post("/", surveyService.Create)
+	surveys.Get("/:id", surveyService.GetByID)
+	surveys.Put("/:id", surveyService.Update)
+	surveys.Delete("/:id", surveyService.Delete)
+	surveys.Put("/:id/status", surveyService.UpdateStatus)
+	surveys.Post("/:id/remind", surveyService.Remind)
+	surveys.Get("/:id/results", surveyService.GetResults)
+	surveys.Get("/:id/export", surveyService.Export)
+
 	// ====================== FILE UPLOAD ROUTES ======================
+	// URL sementara (signed) tidak memakai JWT; harus didaftarkan sebelum group /files yang memasang AuthRequired
+	api.Get("/files/signed/*", fileService.ServeSigned)
+
 	files := api.Group("/files", middleware.AuthRequired())
 
 	// Upload file (foto / sertifikat)
 	files.Post("/upload-foto/:alumni_id", fileService.UploadFoto)
 	files.Post("/upload-sertifikat/:alumni_id", fileService.UploadSertifikat)
 	files.Get("/", fileService.GetAllFiles)
+	files.Get("/dedup-report", middleware.AdminOnly(), fileService.GetDedupReport)
+	files.Post("/reconcile", middleware.AdminOnly(), fileService.Reconcile)
+	files.Get("/reviews", middleware.StaffOnly(), fileService.GetReviewQueue)
+
+	// Upload bertahap (resumable) untuk dokumen besar
+	files.Post("/uploads", fileService.CreateUpload)
+	files.Get("/uploads/:id", fileService.GetUpload)
+	files.Patch("/uploads/:id", fileService.PatchUpload)
+	files.Post("/uploads/:id/finalize", fileService.FinalizeUpload)
+	files.Delete("/uploads/:id", fileService.AbortUpload)
+
 	files.Get("/:id", fileService.GetFileByID)
+	files.Get("/:id/thumb", fileService.GetThumbnail)
+	files.Get("/:id/download", fileService.Download)
+	files.Get("/:id/signed-url", fileService.GetSignedURL)
+	files.Post("/:id/scan", middleware.AdminOnly(), fileService.ScanFile)
+	files.Post("/:id/review", middleware.StaffOnly(), fileService.ReviewCertificate)
 	files.Delete("/:id", fileService.DeleteFile)
 
 	// ====================== ROOT ROUTE ======================
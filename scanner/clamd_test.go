@@ -0,0 +1,120 @@
+package scanner
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/binary"
+	"io"
+	"net"
+	"strings"
+	"testing"
+	"time"
+)
+
+const eicar = `X5O!P%@AP[4\PZX54(P^)7CC)7}$EICAR-STANDARD-ANTIVIRUS-TEST-FILE!$H+H*`
+
+// fakeClamd menjalankan server clamd palsu yang mendeteksi string EICAR dan menolak stream > maxSize
+func fakeClamd(t *testing.T, maxSize int) string {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { ln.Close() })
+
+	go func() {
+		for {
+			conn, err := ln.Accept()
+			if err != nil {
+				return
+			}
+			go func(conn net.Conn) {
+				defer conn.Close()
+				r := bufio.NewReader(conn)
+				cmd, err := r.ReadString(0)
+				if err != nil {
+					return
+				}
+				switch cmd {
+				case "zPING\x00":
+					conn.Write([]byte("PONG\x00"))
+					return
+				case "zINSTREAM\x00":
+				default:
+					conn.Write([]byte("UNKNOWN COMMAND\x00"))
+					return
+				}
+
+				var data bytes.Buffer
+				size := make([]byte, 4)
+				for {
+					if _, err := io.ReadFull(r, size); err != nil {
+						return
+					}
+					n := binary.BigEndian.Uint32(size)
+					if n == 0 {
+						break
+					}
+					if _, err := io.CopyN(&data, r, int64(n)); err != nil {
+						return
+					}
+					if data.Len() > maxSize {
+						conn.Write([]byte("INSTREAM size limit exceeded. ERROR\x00"))
+						return
+					}
+				}
+
+				if strings.Contains(data.String(), eicar) {
+					conn.Write([]byte("stream: Eicar-Test-Signature FOUND\x00"))
+				} else {
+					conn.Write([]byte("stream: OK\x00"))
+				}
+			}(conn)
+		}
+	}()
+	return ln.Addr().String()
+}
+
+func TestClamdScan(t *testing.T) {
+	c := NewClamd(fakeClamd(t, 1024), 5*time.Second)
+	c.ChunkSize = 16 // paksa beberapa chunk INSTREAM
+	ctx := context.Background()
+
+	if err := c.Ping(ctx); err != nil {
+		t.Fatalf("ping: %v", err)
+	}
+
+	tests := []struct {
+		name      string
+		content   string
+		wantClean bool
+		wantSig   string
+		wantErr   bool
+	}{
+		{"Clean PDF", "%PDF-1.4 sertifikat alumni", true, "", false},
+		{"EICAR", "%PDF-1.4 " + eicar, false, "Eicar-Test-Signature", false},
+		{"Too Large", strings.Repeat("a", 2048), false, "", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			res, err := c.Scan(ctx, strings.NewReader(tt.content))
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("err = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err == nil && (res.Clean != tt.wantClean || res.Signature != tt.wantSig) {
+				t.Errorf("result = %+v", res)
+			}
+		})
+	}
+}
+
+func TestClamdUnavailable(t *testing.T) {
+	ln, _ := net.Listen("tcp", "127.0.0.1:0")
+	addr := ln.Addr().String()
+	ln.Close()
+
+	c := NewClamd(addr, time.Second)
+	if _, err := c.Scan(context.Background(), strings.NewReader("x")); err == nil {
+		t.Error("expected error when clamd is unreachable")
+	}
+}
@@ -0,0 +1,43 @@
+package scanner
+
+import (
+	"context"
+	"fmt"
+	"io"
+
+	"alumni-app/config"
+)
+
+// Result adalah hasil pemindaian satu file
+type Result struct {
+	Clean bool
+	// Signature berisi nama temuan (mis. "Eicar-Test-Signature") jika file tidak bersih
+	Signature string
+}
+
+// Scanner memindai isi file upload sebelum file boleh diakses
+type Scanner interface {
+	Name() string
+	Scan(ctx context.Context, r io.Reader) (Result, error)
+}
+
+// New membuat Scanner sesuai konfigurasi SCANNER
+func New(cfg config.ScannerConfig) (Scanner, error) {
+	switch cfg.Backend {
+	case "", "none":
+		return Noop{}, nil
+	case "clamd":
+		return NewClamd(cfg.ClamdAddr, cfg.Timeout), nil
+	default:
+		return nil, fmt.Errorf("scanner: unknown backend %q", cfg.Backend)
+	}
+}
+
+// Noop menganggap semua file bersih; dipakai jika tidak ada scanner yang dikonfigurasi
+type Noop struct{}
+
+func (Noop) Name() string { return "none" }
+
+func (Noop) Scan(ctx context.Context, r io.Reader) (Result, error) {
+	return Result{Clean: true}, nil
+}
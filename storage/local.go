@@ -0,0 +1,189 @@
+package storage
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"mime"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ErrInvalidSignature dikembalikan jika URL sementara tidak valid atau sudah kedaluwarsa
+var ErrInvalidSignature = errors.New("storage: invalid or expired signature")
+
+// Local menyimpan file di filesystem lokal di bawah Root
+type Local struct {
+	Root          string
+	SignedBaseURL string
+	secret        []byte
+}
+
+func NewLocal(root, signedBaseURL string, secret []byte) *Local {
+	return &Local{Root: root, SignedBaseURL: strings.TrimRight(signedBaseURL, "/"), secret: secret}
+}
+
+func (l *Local) Name() string { return "local" }
+
+// Path mengembalikan lokasi file di disk untuk key tersebut
+func (l *Local) Path(key string) string {
+	return filepath.Join(l.Root, filepath.FromSlash(key))
+}
+
+func (l *Local) Put(ctx context.Context, key string, r io.Reader, size int64, contentType string) error {
+	key, err := CleanKey(key)
+	if err != nil {
+		return err
+	}
+	p := l.Path(key)
+	if err := os.MkdirAll(filepath.Dir(p), os.ModePerm); err != nil {
+		return err
+	}
+
+	// tulis ke file sementara lalu rename supaya tidak ada file setengah jadi
+	tmp, err := os.CreateTemp(filepath.Dir(p), ".upload-*")
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(tmp, r); err != nil {
+		tmp.Close()
+		os.Remove(tmp.Name())
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		os.Remove(tmp.Name())
+		return err
+	}
+	if err := os.Chmod(tmp.Name(), 0644); err != nil {
+		os.Remove(tmp.Name())
+		return err
+	}
+	return os.Rename(tmp.Name(), p)
+}
+
+func (l *Local) Get(ctx context.Context, key string) (io.ReadCloser, Object, error) {
+	obj, err := l.Stat(ctx, key)
+	if err != nil {
+		return nil, Object{}, err
+	}
+	f, err := os.Open(l.Path(obj.Key))
+	if err != nil {
+		return nil, Object{}, mapOSError(err)
+	}
+	return f, obj, nil
+}
+
+func (l *Local) Delete(ctx context.Context, key string) error {
+	key, err := CleanKey(key)
+	if err != nil {
+		return err
+	}
+	return mapOSError(os.Remove(l.Path(key)))
+}
+
+func (l *Local) Stat(ctx context.Context, key string) (Object, error) {
+	key, err := CleanKey(key)
+	if err != nil {
+		return Object{}, err
+	}
+	info, err := os.Stat(l.Path(key))
+	if err != nil {
+		return Object{}, mapOSError(err)
+	}
+	if info.IsDir() {
+		return Object{}, ErrNotFound
+	}
+	return Object{
+		Key:         key,
+		Size:        info.Size(),
+		ContentType: contentTypeByKey(key),
+		ModTime:     info.ModTime(),
+	}, nil
+}
+
+func (l *Local) List(ctx context.Context, prefix string) ([]Object, error) {
+	var list []Object
+	root := filepath.Clean(l.Root)
+	err := filepath.WalkDir(l.Path(prefix), func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			if errors.Is(err, fs.ErrNotExist) {
+				return nil
+			}
+			return err
+		}
+		// lewati direktori dan file sementara milik Put yang belum selesai
+		if d.IsDir() || strings.HasPrefix(d.Name(), ".upload-") {
+			return nil
+		}
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(root, p)
+		if err != nil {
+			return err
+		}
+		key := filepath.ToSlash(rel)
+		list = append(list, Object{Key: key, Size: info.Size(), ContentType: contentTypeByKey(key), ModTime: info.ModTime()})
+		return nil
+	})
+	return list, err
+}
+
+// SignedURL membuat URL ke SignedBaseURL yang ditandatangani HMAC; cek dengan Verify
+func (l *Local) SignedURL(ctx context.Context, key string, expiry time.Duration) (string, error) {
+	key, err := CleanKey(key)
+	if err != nil {
+		return "", err
+	}
+	expires := strconv.FormatInt(time.Now().Add(expiry).Unix(), 10)
+	q := url.Values{}
+	q.Set("expires", expires)
+	q.Set("signature", l.sign(key, expires))
+	return l.SignedBaseURL + "/" + key + "?" + q.Encode(), nil
+}
+
+// Verify memeriksa tanda tangan URL hasil SignedURL
+func (l *Local) Verify(key, expires, signature string) error {
+	key, err := CleanKey(key)
+	if err != nil {
+		return ErrInvalidSignature
+	}
+	exp, err := strconv.ParseInt(expires, 10, 64)
+	if err != nil || time.Now().Unix() > exp {
+		return ErrInvalidSignature
+	}
+	if !hmac.Equal([]byte(signature), []byte(l.sign(key, expires))) {
+		return ErrInvalidSignature
+	}
+	return nil
+}
+
+func (l *Local) sign(key, expires string) string {
+	mac := hmac.New(sha256.New, l.secret)
+	fmt.Fprintf(mac, "%s\n%s", key, expires)
+	return base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
+
+func mapOSError(err error) error {
+	if errors.Is(err, os.ErrNotExist) {
+		return ErrNotFound
+	}
+	return err
+}
+
+func contentTypeByKey(key string) string {
+	if ct := mime.TypeByExtension(filepath.Ext(key)); ct != "" {
+		return ct
+	}
+	return "application/octet-stream"
+}
@@ -0,0 +1,68 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"path"
+	"strings"
+	"time"
+
+	"alumni-app/config"
+)
+
+// ErrNotFound dikembalikan jika object dengan key tersebut tidak ada di backend
+var ErrNotFound = errors.New("storage: object not found")
+
+// Object adalah metadata file yang tersimpan di backend
+type Object struct {
+	Key         string
+	Size        int64
+	ContentType string
+	ModTime     time.Time
+}
+
+// Storage adalah backend penyimpanan file upload. Key selalu memakai "/" sebagai pemisah,
+// mis. "foto/FOTO_<alumni>_<uuid>.jpg".
+type Storage interface {
+	// Name adalah nama backend yang dicatat di model.File (mis. "local", "s3")
+	Name() string
+	Put(ctx context.Context, key string, r io.Reader, size int64, contentType string) error
+	Get(ctx context.Context, key string) (io.ReadCloser, Object, error)
+	Delete(ctx context.Context, key string) error
+	Stat(ctx context.Context, key string) (Object, error)
+	// List mengembalikan semua object dengan key berawalan prefix (mis. "foto/")
+	List(ctx context.Context, prefix string) ([]Object, error)
+	// SignedURL membuat URL sementara untuk mengunduh object tanpa token login
+	SignedURL(ctx context.Context, key string, expiry time.Duration) (string, error)
+}
+
+// New membuat Storage sesuai konfigurasi STORAGE_BACKEND
+func New(cfg config.StorageConfig) (Storage, error) {
+	switch cfg.Backend {
+	case "", "local":
+		return NewLocal(cfg.LocalRoot, cfg.LocalSignedBaseURL, []byte(cfg.SigningSecret)), nil
+	case "s3":
+		return NewS3(S3Config{
+			Endpoint:  cfg.S3Endpoint,
+			Region:    cfg.S3Region,
+			Bucket:    cfg.S3Bucket,
+			AccessKey: cfg.S3AccessKey,
+			SecretKey: cfg.S3SecretKey,
+			PathStyle: cfg.S3PathStyle,
+		})
+	default:
+		return nil, fmt.Errorf("storage: unknown backend %q", cfg.Backend)
+	}
+}
+
+// CleanKey menormalkan key dan menolak key yang keluar dari root (mis. "../")
+func CleanKey(key string) (string, error) {
+	key = strings.ReplaceAll(key, "\\", "/")
+	cleaned := path.Clean("/" + key)[1:]
+	if cleaned == "" || cleaned != strings.TrimPrefix(key, "/") {
+		return "", fmt.Errorf("storage: invalid key %q", key)
+	}
+	return cleaned, nil
+}
@@ -0,0 +1,39 @@
+package utils
+
+import (
+	"fmt"
+	"strings"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// ETag membuat entity tag dari ID dokumen dan nomor versinya
+func ETag(id primitive.ObjectID, version int) string {
+	return fmt.Sprintf(`"%s-v%d"`, id.Hex(), version)
+}
+
+// MatchStrongETag mengecek header If-Match dengan perbandingan strong (RFC 9110 13.1.1):
+// tag weak (W/) tidak pernah cocok karena tidak menjamin isi yang sama persis.
+// Header boleh berisi "*" atau beberapa tag yang dipisah koma.
+func MatchStrongETag(header, etag string) bool {
+	for _, tag := range strings.Split(header, ",") {
+		tag = strings.TrimSpace(tag)
+		if tag == "*" || tag == etag {
+			return true
+		}
+	}
+	return false
+}
+
+// MatchWeakETag mengecek header If-None-Match dengan perbandingan weak (RFC 9110 13.1.2):
+// prefix W/ diabaikan. Header boleh berisi "*" atau beberapa tag yang dipisah koma.
+func MatchWeakETag(header, etag string) bool {
+	etag = strings.TrimPrefix(etag, "W/")
+	for _, tag := range strings.Split(header, ",") {
+		tag = strings.TrimSpace(tag)
+		if tag == "*" || strings.TrimPrefix(tag, "W/") == etag {
+			return true
+		}
+	}
+	return false
+}
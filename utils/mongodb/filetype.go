@@ -0,0 +1,98 @@
+package utils
+
+import (
+	"bytes"
+	"fmt"
+	"image"
+	_ "image/jpeg"
+	_ "image/png"
+	"io"
+	"net/http"
+)
+
+// extensionByMime adalah ekstensi baku untuk tipe file yang boleh diunggah
+var extensionByMime = map[string]string{
+	"image/jpeg":      ".jpg",
+	"image/png":       ".png",
+	"application/pdf": ".pdf",
+}
+
+// ExtensionFor mengembalikan ekstensi baku untuk mime hasil deteksi
+func ExtensionFor(mime string) string {
+	return extensionByMime[mime]
+}
+
+// SniffContentType mendeteksi tipe file dari magic bytes (512 byte pertama), bukan dari header klien
+func SniffContentType(r io.ReadSeeker) (string, error) {
+	head := make([]byte, 512)
+	n, err := io.ReadFull(r, head)
+	if err != nil && err != io.ErrUnexpectedEOF && err != io.EOF {
+		return "", err
+	}
+	if _, err := r.Seek(0, io.SeekStart); err != nil {
+		return "", err
+	}
+	return http.DetectContentType(head[:n]), nil
+}
+
+// ValidateImage memastikan file benar-benar JPG/PNG dari magic bytes dan header gambar.
+// Hanya header yang dibaca (image.DecodeConfig) sehingga dimensi raksasa ditolak sebelum
+// bitmap dialokasikan; isi gambar yang rusak baru ketahuan saat ProcessPhoto. Mengembalikan mime hasil deteksi.
+func ValidateImage(r io.ReadSeeker) (string, error) {
+	mime, err := SniffContentType(r)
+	if err != nil {
+		return "", err
+	}
+	if mime != "image/jpeg" && mime != "image/png" {
+		return mime, fmt.Errorf("detected %s, only JPG/PNG allowed", mime)
+	}
+
+	cfg, format, err := image.DecodeConfig(r)
+	if _, serr := r.Seek(0, io.SeekStart); serr != nil {
+		return mime, serr
+	}
+	if err != nil {
+		return mime, fmt.Errorf("corrupt or unsupported image: %v", err)
+	}
+	if "image/"+format != mime {
+		return mime, fmt.Errorf("image content (%s) does not match its signature (%s)", format, mime)
+	}
+	if cfg.Width*cfg.Height > maxPhotoPixels {
+		return mime, fmt.Errorf("image dimensions too large (%dx%d)", cfg.Width, cfg.Height)
+	}
+	return mime, nil
+}
+
+// ValidatePDF mengecek struktur dasar PDF: header %PDF-, minimal satu object,
+// dan penanda %%EOF di bagian akhir file.
+func ValidatePDF(r io.ReaderAt, size int64) error {
+	if size < 16 {
+		return fmt.Errorf("file too small to be a PDF")
+	}
+
+	head := make([]byte, 1024)
+	n, err := r.ReadAt(head, 0)
+	if err != nil && err != io.EOF {
+		return err
+	}
+	head = head[:n]
+	if !bytes.HasPrefix(head, []byte("%PDF-1.")) && !bytes.HasPrefix(head, []byte("%PDF-2.")) {
+		return fmt.Errorf("missing PDF header")
+	}
+
+	tailSize := int64(1024)
+	if size < tailSize {
+		tailSize = size
+	}
+	tail := make([]byte, tailSize)
+	if _, err := r.ReadAt(tail, size-tailSize); err != nil && err != io.EOF {
+		return err
+	}
+	if !bytes.Contains(tail, []byte("%%EOF")) {
+		return fmt.Errorf("missing PDF %%%%EOF trailer")
+	}
+	if !bytes.Contains(tail, []byte("startxref")) && !bytes.Contains(head, []byte(" obj")) {
+		return fmt.Errorf("missing PDF objects or cross-reference table")
+	}
+	return nil
+}
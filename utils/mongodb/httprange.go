@@ -0,0 +1,61 @@
+package utils
+
+import (
+	"errors"
+	"strconv"
+	"strings"
+)
+
+// ErrRangeNotSatisfiable berarti header Range valid tapi di luar ukuran file (HTTP 416)
+var ErrRangeNotSatisfiable = errors.New("range not satisfiable")
+
+// ParseRange membaca header Range satu rentang ("bytes=0-99", "bytes=100-", "bytes=-100").
+// ok=false berarti header kosong, multi-range, atau tidak dikenali sehingga seluruh file dikirim.
+func ParseRange(header string, size int64) (start, end int64, ok bool, err error) {
+	if header == "" || !strings.HasPrefix(header, "bytes=") {
+		return 0, 0, false, nil
+	}
+	spec := strings.TrimSpace(strings.TrimPrefix(header, "bytes="))
+	if strings.Contains(spec, ",") {
+		return 0, 0, false, nil
+	}
+	from, to, found := strings.Cut(spec, "-")
+	if !found {
+		return 0, 0, false, nil
+	}
+	from, to = strings.TrimSpace(from), strings.TrimSpace(to)
+
+	if from == "" {
+		// suffix range: n byte terakhir
+		n, perr := strconv.ParseInt(to, 10, 64)
+		if perr != nil || n < 0 {
+			return 0, 0, false, nil
+		}
+		if n == 0 || size == 0 {
+			return 0, 0, false, ErrRangeNotSatisfiable
+		}
+		if n > size {
+			n = size
+		}
+		return size - n, size - 1, true, nil
+	}
+
+	start, perr := strconv.ParseInt(from, 10, 64)
+	if perr != nil || start < 0 {
+		return 0, 0, false, nil
+	}
+	if start >= size {
+		return 0, 0, false, ErrRangeNotSatisfiable
+	}
+	end = size - 1
+	if to != "" {
+		e, perr := strconv.ParseInt(to, 10, 64)
+		if perr != nil || e < start {
+			return 0, 0, false, nil
+		}
+		if e < end {
+			end = e
+		}
+	}
+	return start, end, true, nil
+}
@@ -0,0 +1,256 @@
+package utils
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"image"
+	"image/draw"
+	"image/jpeg"
+	"image/png"
+	"io"
+)
+
+// PhotoVariant adalah ukuran turunan foto; MaxSize = sisi terpanjang dalam pixel
+type PhotoVariant struct {
+	Name    string
+	MaxSize int
+}
+
+// PhotoVariants adalah ukuran standar foto alumni
+var PhotoVariants = []PhotoVariant{
+	{Name: "thumb", MaxSize: 150},
+	{Name: "medium", MaxSize: 600},
+	{Name: "original", MaxSize: 2048},
+}
+
+// maxPhotoPixels mencegah decompression bomb (gambar kecil di disk tapi raksasa setelah di-decode)
+const maxPhotoPixels = 40_000_000
+
+// ProcessedImage adalah hasil encode satu varian foto
+type ProcessedImage struct {
+	Variant     string
+	Data        []byte
+	Width       int
+	Height      int
+	ContentType string
+}
+
+// ProcessPhoto men-decode foto JPG/PNG, memutar sesuai EXIF orientation, lalu meng-encode ulang
+// tiap varian di PhotoVariants. Encode ulang membuang seluruh metadata EXIF (termasuk GPS).
+func ProcessPhoto(r io.ReadSeeker) ([]ProcessedImage, error) {
+	cfg, format, err := image.DecodeConfig(r)
+	if err != nil {
+		return nil, fmt.Errorf("corrupt or unsupported image: %v", err)
+	}
+	if cfg.Width*cfg.Height > maxPhotoPixels {
+		return nil, fmt.Errorf("image dimensions too large (%dx%d)", cfg.Width, cfg.Height)
+	}
+	if _, err := r.Seek(0, io.SeekStart); err != nil {
+		return nil, err
+	}
+
+	orientation := 1
+	if format == "jpeg" {
+		orientation = jpegOrientation(r)
+		if _, err := r.Seek(0, io.SeekStart); err != nil {
+			return nil, err
+		}
+	}
+
+	src, _, err := image.Decode(r)
+	if err != nil {
+		return nil, fmt.Errorf("corrupt or unsupported image: %v", err)
+	}
+	img := applyOrientation(toRGBA(src), orientation)
+
+	var out []ProcessedImage
+	for _, v := range PhotoVariants {
+		resized := fit(img, v.MaxSize)
+
+		buf := &bytes.Buffer{}
+		contentType := "image/" + format
+		if format == "png" {
+			err = png.Encode(buf, resized)
+		} else {
+			err = jpeg.Encode(buf, resized, &jpeg.Options{Quality: 85})
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		b := resized.Bounds()
+		out = append(out, ProcessedImage{
+			Variant:     v.Name,
+			Data:        buf.Bytes(),
+			Width:       b.Dx(),
+			Height:      b.Dy(),
+			ContentType: contentType,
+		})
+	}
+	return out, nil
+}
+
+func toRGBA(src image.Image) *image.RGBA {
+	b := src.Bounds()
+	dst := image.NewRGBA(image.Rect(0, 0, b.Dx(), b.Dy()))
+	draw.Draw(dst, dst.Bounds(), src, b.Min, draw.Src)
+	return dst
+}
+
+// applyOrientation memutar/membalik gambar sesuai nilai EXIF orientation (1-8)
+func applyOrientation(src *image.RGBA, orientation int) *image.RGBA {
+	if orientation < 2 || orientation > 8 {
+		return src
+	}
+
+	w, h := src.Bounds().Dx(), src.Bounds().Dy()
+	dw, dh := w, h
+	if orientation >= 5 {
+		dw, dh = h, w
+	}
+	dst := image.NewRGBA(image.Rect(0, 0, dw, dh))
+
+	for dy := 0; dy < dh; dy++ {
+		for dx := 0; dx < dw; dx++ {
+			var sx, sy int
+			switch orientation {
+			case 2: // cermin horizontal
+				sx, sy = w-1-dx, dy
+			case 3: // putar 180
+				sx, sy = w-1-dx, h-1-dy
+			case 4: // cermin vertikal
+				sx, sy = dx, h-1-dy
+			case 5: // transpose
+				sx, sy = dy, dx
+			case 6: // putar 90 searah jarum jam
+				sx, sy = dy, h-1-dx
+			case 7: // transverse
+				sx, sy = w-1-dy, h-1-dx
+			case 8: // putar 90 berlawanan jarum jam
+				sx, sy = w-1-dy, dx
+			}
+			si := src.PixOffset(sx, sy)
+			di := dst.PixOffset(dx, dy)
+			copy(dst.Pix[di:di+4], src.Pix[si:si+4])
+		}
+	}
+	return dst
+}
+
+// fit memperkecil gambar (box filter) agar sisi terpanjang <= maxSize; gambar kecil tidak diperbesar
+func fit(src *image.RGBA, maxSize int) *image.RGBA {
+	sw, sh := src.Bounds().Dx(), src.Bounds().Dy()
+	if sw <= maxSize && sh <= maxSize {
+		return src
+	}
+
+	dw, dh := maxSize, sh*maxSize/sw
+	if sh > sw {
+		dw, dh = sw*maxSize/sh, maxSize
+	}
+	if dw < 1 {
+		dw = 1
+	}
+	if dh < 1 {
+		dh = 1
+	}
+
+	dst := image.NewRGBA(image.Rect(0, 0, dw, dh))
+	for dy := 0; dy < dh; dy++ {
+		y0, y1 := dy*sh/dh, (dy+1)*sh/dh
+		if y1 <= y0 {
+			y1 = y0 + 1
+		}
+		for dx := 0; dx < dw; dx++ {
+			x0, x1 := dx*sw/dw, (dx+1)*sw/dw
+			if x1 <= x0 {
+				x1 = x0 + 1
+			}
+
+			var r, g, b, a, n uint32
+			for y := y0; y < y1; y++ {
+				i := src.PixOffset(x0, y)
+				for x := x0; x < x1; x++ {
+					r += uint32(src.Pix[i])
+					g += uint32(src.Pix[i+1])
+					b += uint32(src.Pix[i+2])
+					a += uint32(src.Pix[i+3])
+					n++
+					i += 4
+				}
+			}
+			di := dst.PixOffset(dx, dy)
+			dst.Pix[di] = uint8(r / n)
+			dst.Pix[di+1] = uint8(g / n)
+			dst.Pix[di+2] = uint8(b / n)
+			dst.Pix[di+3] = uint8(a / n)
+		}
+	}
+	return dst
+}
+
+// jpegOrientation membaca tag Orientation (0x0112) dari segmen EXIF APP1; 1 jika tidak ada
+func jpegOrientation(r io.Reader) int {
+	var soi [2]byte
+	if _, err := io.ReadFull(r, soi[:]); err != nil || soi != [2]byte{0xFF, 0xD8} {
+		return 1
+	}
+
+	for {
+		var marker [4]byte
+		if _, err := io.ReadFull(r, marker[:]); err != nil || marker[0] != 0xFF {
+			return 1
+		}
+		// SOS: data gambar dimulai, EXIF tidak mungkin muncul setelah ini
+		if marker[1] == 0xDA {
+			return 1
+		}
+		length := int(binary.BigEndian.Uint16(marker[2:])) - 2
+		if length < 0 {
+			return 1
+		}
+		seg := make([]byte, length)
+		if _, err := io.ReadFull(r, seg); err != nil {
+			return 1
+		}
+		if marker[1] == 0xE1 && bytes.HasPrefix(seg, []byte("Exif\x00\x00")) {
+			return exifOrientation(seg[6:])
+		}
+	}
+}
+
+func exifOrientation(tiff []byte) int {
+	if len(tiff) < 8 {
+		return 1
+	}
+	var order binary.ByteOrder
+	switch string(tiff[:2]) {
+	case "II":
+		order = binary.LittleEndian
+	case "MM":
+		order = binary.BigEndian
+	default:
+		return 1
+	}
+
+	ifd := int(order.Uint32(tiff[4:8]))
+	if ifd+2 > len(tiff) {
+		return 1
+	}
+	count := int(order.Uint16(tiff[ifd:]))
+	for i := 0; i < count; i++ {
+		entry := ifd + 2 + i*12
+		if entry+12 > len(tiff) {
+			return 1
+		}
+		if order.Uint16(tiff[entry:]) == 0x0112 {
+			v := int(order.Uint16(tiff[entry+8:]))
+			if v >= 1 && v <= 8 {
+				return v
+			}
+			return 1
+		}
+	}
+	return 1
+}
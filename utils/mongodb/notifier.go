@@ -0,0 +1,72 @@
+package utils
+
+import (
+	"alumni-app/config"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// Message adalah pesan yang dikirim ke user (email, dsb.)
+type Message struct {
+	To      string
+	Subject string
+	Body    string
+}
+
+// Notifier mengirim pesan ke user. Implementasi bisa diganti (file, SMTP, dll.)
+type Notifier interface {
+	Send(msg Message) error
+}
+
+// NewNotifier membuat Notifier sesuai cfg.Sender: "file" (default), "log", atau "smtp"
+func NewNotifier(cfg config.MailConfig) (Notifier, error) {
+	switch cfg.Sender {
+	case "", "file":
+		return NewFileNotifier(cfg.File), nil
+	case "log":
+		return LogNotifier{}, nil
+	case "smtp":
+		return NewSMTPNotifier(cfg), nil
+	}
+	return nil, fmt.Errorf("unknown MAIL_SENDER %q (file|log|smtp)", cfg.Sender)
+}
+
+// LogNotifier mencetak pesan ke log aplikasi, untuk development
+type LogNotifier struct{}
+
+func (LogNotifier) Send(msg Message) error {
+	log.Printf("📧 To: %s | Subject: %s\n%s", msg.To, msg.Subject, msg.Body)
+	return nil
+}
+
+// FileNotifier menulis pesan ke file, untuk development lokal tanpa mail server
+type FileNotifier struct {
+	path string
+	mu   sync.Mutex
+}
+
+func NewFileNotifier(path string) *FileNotifier {
+	return &FileNotifier{path: path}
+}
+
+func (n *FileNotifier) Send(msg Message) error {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	if err := os.MkdirAll(filepath.Dir(n.path), os.ModePerm); err != nil {
+		return err
+	}
+	f, err := os.OpenFile(n.path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0600)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	_, err = fmt.Fprintf(f, "=== %s\nTo: %s\nSubject: %s\n\n%s\n\n",
+		time.Now().Format(time.RFC3339), msg.To, msg.Subject, msg.Body)
+	return err
+}
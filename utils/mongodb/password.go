@@ -1,6 +1,11 @@
 package utils
 
 import (
+	"alumni-app/config"
+	"errors"
+	"fmt"
+	"unicode"
+
 	"golang.org/x/crypto/bcrypt"
 )
 
@@ -15,3 +20,36 @@ func CheckPassword(hash, password string) bool {
 	err := bcrypt.CompareHashAndPassword([]byte(hash), []byte(password))
 	return err == nil
 }
+
+// ValidatePassword mengecek password terhadap aturan kekuatan password
+func ValidatePassword(password string, policy config.PasswordConfig) error {
+	if len([]rune(password)) < policy.MinLength {
+		return fmt.Errorf("Password minimal %d karakter", policy.MinLength)
+	}
+
+	var upper, lower, digit, symbol bool
+	for _, r := range password {
+		switch {
+		case unicode.IsUpper(r):
+			upper = true
+		case unicode.IsLower(r):
+			lower = true
+		case unicode.IsDigit(r):
+			digit = true
+		case unicode.IsPunct(r) || unicode.IsSymbol(r):
+			symbol = true
+		}
+	}
+
+	switch {
+	case policy.RequireUpper && !upper:
+		return errors.New("Password harus mengandung huruf besar")
+	case policy.RequireLower && !lower:
+		return errors.New("Password harus mengandung huruf kecil")
+	case policy.RequireDigit && !digit:
+		return errors.New("Password harus mengandung angka")
+	case policy.RequireSymbol && !symbol:
+		return errors.New("Password harus mengandung simbol")
+	}
+	return nil
+}
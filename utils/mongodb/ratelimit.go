@@ -0,0 +1,101 @@
+package utils
+
+import (
+	"sync"
+	"time"
+)
+
+// RateLimitStore menyimpan jumlah percobaan per key dalam satu window waktu.
+// Implementasi lain (mis. Redis) cukup memenuhi interface ini.
+type RateLimitStore interface {
+	// Increment menambah counter key dan mengembalikan jumlah serta waktu reset window.
+	// Window dimulai saat percobaan pertama.
+	Increment(key string, window time.Duration) (int, time.Time, error)
+	// Get mengembalikan counter key tanpa mengubahnya (0 jika window sudah lewat)
+	Get(key string) (int, time.Time, error)
+	// Extend memindahkan waktu reset key menjadi window dari sekarang tanpa mengubah counter,
+	// dipakai agar lockout berlaku penuh sejak kegagalan terakhir
+	Extend(key string, window time.Duration) (time.Time, error)
+	// Reset menghapus counter key
+	Reset(key string) error
+}
+
+type rateLimitEntry struct {
+	count   int
+	resetAt time.Time
+}
+
+// MemoryRateLimitStore adalah RateLimitStore in-memory untuk satu instance aplikasi
+type MemoryRateLimitStore struct {
+	mu        sync.Mutex
+	entries   map[string]*rateLimitEntry
+	lastSweep time.Time
+	now       func() time.Time
+}
+
+func NewMemoryRateLimitStore() *MemoryRateLimitStore {
+	return &MemoryRateLimitStore{
+		entries: make(map[string]*rateLimitEntry),
+		now:     time.Now,
+	}
+}
+
+func (s *MemoryRateLimitStore) Increment(key string, window time.Duration) (int, time.Time, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := s.now()
+	s.sweep(now)
+
+	e, ok := s.entries[key]
+	if !ok || !now.Before(e.resetAt) {
+		e = &rateLimitEntry{resetAt: now.Add(window)}
+		s.entries[key] = e
+	}
+	e.count++
+	return e.count, e.resetAt, nil
+}
+
+func (s *MemoryRateLimitStore) Get(key string) (int, time.Time, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	e, ok := s.entries[key]
+	if !ok || !s.now().Before(e.resetAt) {
+		return 0, time.Time{}, nil
+	}
+	return e.count, e.resetAt, nil
+}
+
+func (s *MemoryRateLimitStore) Extend(key string, window time.Duration) (time.Time, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	e, ok := s.entries[key]
+	if !ok {
+		return time.Time{}, nil
+	}
+	e.resetAt = s.now().Add(window)
+	return e.resetAt, nil
+}
+
+func (s *MemoryRateLimitStore) Reset(key string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	delete(s.entries, key)
+	return nil
+}
+
+// sweep membuang entry kadaluarsa paling sering sekali per menit agar map tidak terus membesar
+func (s *MemoryRateLimitStore) sweep(now time.Time) {
+	if now.Sub(s.lastSweep) < time.Minute {
+		return
+	}
+	s.lastSweep = now
+	for k, e := range s.entries {
+		if !now.Before(e.resetAt) {
+			delete(s.entries, k)
+		}
+	}
+}
@@ -0,0 +1,38 @@
+package utils
+
+import (
+	"testing"
+	"time"
+)
+
+func TestMemoryRateLimitStoreExtend(t *testing.T) {
+	now := time.Date(2024, 1, 1, 10, 0, 0, 0, time.UTC)
+	store := NewMemoryRateLimitStore()
+	store.now = func() time.Time { return now }
+
+	window := 15 * time.Minute
+	store.Increment("k", window)
+
+	// kegagalan berikutnya terjadi menjelang akhir window
+	now = now.Add(14 * time.Minute)
+	count, resetAt, _ := store.Increment("k", window)
+	if count != 2 {
+		t.Fatalf("count = %d, want 2", count)
+	}
+	if got := resetAt.Sub(now); got != time.Minute {
+		t.Fatalf("sisa window = %v, want 1m", got)
+	}
+
+	resetAt, _ = store.Extend("k", window)
+	if got := resetAt.Sub(now); got != window {
+		t.Errorf("sisa window setelah Extend = %v, want %v", got, window)
+	}
+	now = now.Add(10 * time.Minute)
+	if count, _, _ := store.Get("k"); count != 2 {
+		t.Errorf("counter hilang sebelum lockout selesai: count = %d", count)
+	}
+
+	if resetAt, _ := store.Extend("missing", window); !resetAt.IsZero() {
+		t.Errorf("Extend key yang tidak ada = %v, want zero", resetAt)
+	}
+}
@@ -0,0 +1,121 @@
+package utils
+
+import (
+	"alumni-app/config"
+	"bufio"
+	"net"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+// fakeSMTP menjalankan server SMTP palsu tanpa STARTTLS/AUTH dan mengirim setiap email yang diterima ke channel
+func fakeSMTP(t *testing.T) (string, int, <-chan string) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { ln.Close() })
+
+	received := make(chan string, 1)
+	go func() {
+		conn, err := ln.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		r := bufio.NewReader(conn)
+		reply := func(line string) { conn.Write([]byte(line + "\r\n")) }
+
+		reply("220 fake ESMTP")
+		var envelope, data strings.Builder
+		for {
+			line, err := r.ReadString('\n')
+			if err != nil {
+				return
+			}
+			cmd := strings.ToUpper(strings.TrimSpace(line))
+			switch {
+			case strings.HasPrefix(cmd, "EHLO"), strings.HasPrefix(cmd, "HELO"):
+				reply("250 fake")
+			case strings.HasPrefix(cmd, "MAIL FROM"), strings.HasPrefix(cmd, "RCPT TO"):
+				envelope.WriteString(strings.TrimSpace(line) + "\n")
+				reply("250 OK")
+			case cmd == "DATA":
+				reply("354 end with .")
+				for {
+					l, err := r.ReadString('\n')
+					if err != nil {
+						return
+					}
+					if l == ".\r\n" {
+						break
+					}
+					data.WriteString(l)
+				}
+				received <- envelope.String() + "\n" + data.String()
+				reply("250 queued")
+			case cmd == "QUIT":
+				reply("221 bye")
+				return
+			default:
+				reply("502 not implemented")
+			}
+		}
+	}()
+
+	host, port, _ := net.SplitHostPort(ln.Addr().String())
+	p, _ := strconv.Atoi(port)
+	return host, p, received
+}
+
+func TestSMTPNotifier(t *testing.T) {
+	host, port, received := fakeSMTP(t)
+	n := NewSMTPNotifier(config.MailConfig{
+		SMTPHost:    host,
+		SMTPPort:    port,
+		SMTPTimeout: 5 * time.Second,
+		From:        "Alumni-App <no-reply@example.com>",
+	})
+
+	err := n.Send(Message{
+		To:      "farid@example.com",
+		Subject: "Verifikasi\r\nBcc: attacker@example.com",
+		Body:    "Halo Farid,\n\nLink verifikasi terlampir.",
+	})
+	if err != nil {
+		t.Fatalf("send failed: %v", err)
+	}
+
+	var got string
+	select {
+	case got = <-received:
+	case <-time.After(5 * time.Second):
+		t.Fatal("stub did not receive any message")
+	}
+
+	for _, want := range []string{
+		"MAIL FROM:<no-reply@example.com>",
+		"RCPT TO:<farid@example.com>",
+		"To: <farid@example.com>\r\n",
+		"Content-Type: text/plain; charset=UTF-8\r\n",
+		"Halo Farid,\r\n\r\nLink verifikasi terlampir.\r\n",
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("message missing %q:\n%s", want, got)
+		}
+	}
+	if strings.Contains(got, "\r\nBcc:") {
+		t.Errorf("subject must not inject headers:\n%s", got)
+	}
+}
+
+func TestNewNotifier(t *testing.T) {
+	for sender, ok := range map[string]bool{"": true, "file": true, "log": true, "smtp": true, "pigeon": false} {
+		_, err := NewNotifier(config.MailConfig{Sender: sender, File: t.TempDir() + "/mail.log"})
+		if (err == nil) != ok {
+			t.Errorf("sender %q: unexpected error %v", sender, err)
+		}
+	}
+}
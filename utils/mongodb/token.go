@@ -0,0 +1,66 @@
+package utils
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ErrInvalidToken dikembalikan VerifySignedToken jika token rusak, salah tanda tangan, atau kadaluarsa
+var ErrInvalidToken = errors.New("invalid or expired token")
+
+// NewRandomToken membuat token acak untuk dikirim ke user beserta hash-nya untuk disimpan di DB
+func NewRandomToken() (plain string, hash string, err error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", "", err
+	}
+	plain = hex.EncodeToString(b)
+	return plain, HashToken(plain), nil
+}
+
+// HashToken menghasilkan SHA-256 (hex) dari token; token asli tidak pernah disimpan
+func HashToken(plain string) string {
+	sum := sha256.Sum256([]byte(plain))
+	return hex.EncodeToString(sum[:])
+}
+
+// SignToken membuat token bertanda tangan HMAC yang membawa subject dan waktu kadaluarsa,
+// sehingga tidak perlu disimpan di database. purpose membedakan token antar fitur.
+func SignToken(secret []byte, purpose, subject string, expires time.Time) string {
+	payload := base64.RawURLEncoding.EncodeToString([]byte(subject + "\n" + strconv.FormatInt(expires.Unix(), 10)))
+	return payload + "." + signPayload(secret, purpose, payload)
+}
+
+// VerifySignedToken memeriksa token dari SignToken dan mengembalikan subject-nya
+func VerifySignedToken(secret []byte, purpose, token string) (string, error) {
+	payload, sig, ok := strings.Cut(token, ".")
+	if !ok || !hmac.Equal([]byte(sig), []byte(signPayload(secret, purpose, payload))) {
+		return "", ErrInvalidToken
+	}
+	raw, err := base64.RawURLEncoding.DecodeString(payload)
+	if err != nil {
+		return "", ErrInvalidToken
+	}
+	subject, exp, ok := strings.Cut(string(raw), "\n")
+	if !ok {
+		return "", ErrInvalidToken
+	}
+	unix, err := strconv.ParseInt(exp, 10, 64)
+	if err != nil || time.Now().Unix() > unix {
+		return "", ErrInvalidToken
+	}
+	return subject, nil
+}
+
+func signPayload(secret []byte, purpose, payload string) string {
+	mac := hmac.New(sha256.New, secret)
+	mac.Write([]byte(purpose + "\n" + payload))
+	return base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}